@@ -0,0 +1,125 @@
+// Package cli is the command line of the backend binary: it serves the API
+// and runs the maintenance tasks around it with the same configuration.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/logging"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// errUsage makes Run print the usage of the command and exit with 2.
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "", "Run the HTTP API (the default)", serve},
+	{"migrate", "up | down [n] | status | create <name> | force <version>", "Apply, roll back or add SQL migrations", migrateCmd},
+	{"seed", "-confirm seed", "Replace the data with sample data (needs ALLOW_DESTRUCTIVE_OPS)", seedCmd},
+	{"user", "promote-admin [-reason text] <email>", "Grant the admin role to an account", userCmd},
+	{"reconcile-balances", "[-fix]", "Check wallets and creator earnings against sales", reconcileCmd},
+	{"purge-deleted", "", "Remove deleted content past its retention period now", purgeCmd},
+}
+
+// Run runs the command named by args[0], or serves when there is none, and
+// returns the exit code.
+func Run(args []string) int {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(os.Stderr, "usage: %s %s %s\n", os.Args[0], cmd.name, cmd.args)
+			return 2
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+	usage()
+	return 2
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// setup loads the configuration from the environment (and .env), connects
+// the database and starts the logger, in that order: the logger also writes
+// to the database.
+func setup() *zap.Logger {
+	if err := godotenv.Load(); err != nil {
+		zap.L().Warn(".env не найден, используем ENV переменные", zap.Error(err))
+	}
+	config.LoadConfig()
+	database.InitDB()
+	logger, _ := logging.InitLogger()
+	return logger
+}
+
+// flags returns a flag set for the command that reports errors instead of
+// exiting.
+func flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {}
+	return fs
+}
+
+// parse parses args and wants exactly n positional arguments.
+func parse(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil || fs.NArg() != n {
+		return errUsage
+	}
+	return nil
+}
+
+// record writes a maintenance run to the audit log with the operating
+// system user who started it. A failure is logged, not returned: the
+// operation already happened.
+func record(logger *zap.Logger, operation string) {
+	runBy := "unknown"
+	if u, err := user.Current(); err == nil {
+		runBy = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		runBy += "@" + host
+	}
+	audit := services.NewAuditService(&repository.GormAuditRepository{DB: database.GetDB()})
+	if err := audit.Record(services.AuditRecord{
+		Action:   "operation.run",
+		Target:   services.AuditOperation,
+		EntityID: operation,
+		After:    map[string]interface{}{"run_by": runBy, "command": strings.Join(os.Args[1:], " ")},
+		IP:       "cli",
+	}); err != nil {
+		logger.Error("Operation not recorded in the audit log", zap.String("operation", operation), zap.Error(err))
+	}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-backend/database"
+
+	"github.com/golang-migrate/migrate/v4"
+	"go.uber.org/zap"
+)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func migrateCmd(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	sub, args := args[0], args[1:]
+	if sub == "create" {
+		if len(args) != 1 || !migrationName.MatchString(args[0]) {
+			return errUsage
+		}
+		return createMigration(args[0])
+	}
+
+	logger := setup()
+	defer logger.Sync()
+	m, err := database.NewMigrator(database.GetDB(), database.MigrationsDir)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch sub {
+	case "up":
+		if len(args) != 0 {
+			return errUsage
+		}
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			return errUsage
+		}
+		if len(args) == 1 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return errUsage
+			}
+		}
+		err = m.Steps(-steps)
+	case "status":
+		if len(args) != 0 {
+			return errUsage
+		}
+		return migrationStatus(m)
+	case "force":
+		if len(args) != 1 {
+			return errUsage
+		}
+		version, convErr := strconv.Atoi(args[0])
+		if convErr != nil {
+			return errUsage
+		}
+		err = m.Force(version)
+	default:
+		return errUsage
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	version, dirty, _ := m.Version()
+	logger.Info("Migration completed", zap.String("command", sub), zap.Uint("version", version), zap.Bool("dirty", dirty))
+	record(logger, "migrate "+sub)
+	return nil
+}
+
+// migrationStatus prints the applied version and the migrations not yet
+// applied.
+func migrationStatus(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	files, err := migrationFiles()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d", version)
+	if dirty {
+		fmt.Print(" (dirty, fix the schema and run migrate force)")
+	}
+	fmt.Println()
+	pending := 0
+	for _, f := range files {
+		if f.version > version {
+			fmt.Printf("pending: %s\n", f.name)
+			pending++
+		}
+	}
+	if pending == 0 {
+		fmt.Println("up to date")
+	}
+	return nil
+}
+
+type migrationFile struct {
+	version uint
+	name    string
+}
+
+// migrationFiles lists the up migrations in version order.
+func migrationFiles() ([]migrationFile, error) {
+	paths, err := filepath.Glob(filepath.Join(database.MigrationsDir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	var files []migrationFile
+	for _, p := range paths {
+		name := strings.TrimSuffix(filepath.Base(p), ".up.sql")
+		number, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no version number", p)
+		}
+		files = append(files, migrationFile{version: uint(version), name: name})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+	return files, nil
+}
+
+// createMigration adds an empty up and down migration after the latest one.
+func createMigration(name string) error {
+	files, err := migrationFiles()
+	if err != nil {
+		return err
+	}
+	var next uint = 1
+	if len(files) > 0 {
+		next = files[len(files)-1].version + 1
+	}
+	base := filepath.Join(database.MigrationsDir, fmt.Sprintf("%06d_%s", next, name))
+	for _, suffix := range []string{".up.sql", ".down.sql"} {
+		f, err := os.OpenFile(base+suffix, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		fmt.Println(base + suffix)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+)
+
+// purgeCmd runs one pass of the purge job the server runs every
+// PURGE_INTERVAL.
+func purgeCmd(args []string) error {
+	if err := parse(flags("purge-deleted"), args, 0); err != nil {
+		return err
+	}
+	logger := setup()
+	defer logger.Sync()
+	result, err := services.NewRetentionService(&repository.GormRetentionRepository{DB: database.GetDB()}).Purge()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d posts, %d videos, %d images\n", result.Posts, result.Videos, result.Images)
+	record(logger, "purge-deleted")
+	return nil
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+)
+
+// reconcileCmd prints the reconciliation report as JSON and fails while it
+// has findings, so it can run from cron.
+func reconcileCmd(args []string) error {
+	fs := flags("reconcile-balances")
+	fix := fs.Bool("fix", false, "")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	logger := setup()
+	defer logger.Sync()
+	report, err := services.NewReconciliationService(&repository.GormReconciliationRepository{DB: database.GetDB()}).Reconcile(*fix)
+	if err != nil {
+		return err
+	}
+	if report.Backfilled > 0 {
+		record(logger, "reconcile-balances")
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	if !report.Clean() {
+		return errors.New("balances do not reconcile")
+	}
+	return nil
+}
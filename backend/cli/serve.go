@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/logging"
+	"go-backend/middleware"
+	"go-backend/repository"
+	"go-backend/routes"
+	"go-backend/services"
+	"go-backend/storage"
+	"go-backend/tracking"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds how long serve waits for requests in flight on
+// SIGINT or SIGTERM; docker-compose gives the container a little longer.
+const shutdownTimeout = 20 * time.Second
+
+func serve(args []string) error {
+	if err := parse(flags("serve"), args, 0); err != nil {
+		return err
+	}
+	logger := setup()
+	defer logger.Sync()
+	logger.Info("Сервис запущен")
+
+	if config.AppConfig.MigrateOnStart {
+		if err := database.MigrateUp(); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+
+	// ✅ Инициализация Firebase (только если ключи заданы)
+	if config.AppConfig.FirebaseProjectID != "" {
+		middleware.InitFirebase(logger)
+		logger.Info("Firebase подключен")
+	} else {
+		logger.Warn("Firebase пропущен (нет конфигурации)")
+	}
+
+	// ✅ Создаём Gin
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// ✅ Request ID middleware
+	r.Use(logging.RequestIDMiddleware())
+
+	// ✅ Настройка CORS
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"http://localhost:3000", "http://127.0.0.1:3000", "http://159.223.94.49:3000"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Session-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+
+	// ✅ Подключаем middleware
+	r.Use(middleware.LoggerMiddleware(logger))
+	r.Use(middleware.ErrorHandler(logger))
+	r.Use(middleware.UserMiddleware(logger, middleware.OptionalAuth))
+
+	r.SetTrustedProxies([]string{"127.0.0.1"})
+
+	// ✅ Роуты
+	routes.InitRoutes(r, logger)
+
+	// Lift suspensions that have run out
+	stopSweeper := services.NewAccountStatusService(
+		&repository.GormAccountStatusRepository{DB: database.GetDB()},
+		&repository.GormUserRepository{DB: database.GetDB()},
+	).StartSuspensionSweeper(config.AppConfig.SuspensionSweepInterval)
+	defer stopSweeper()
+
+	// Purge deleted content once its retention period is over
+	stopPurger := services.NewRetentionService(&repository.GormRetentionRepository{DB: database.GetDB()}).
+		StartPurger(config.AppConfig.PurgeInterval)
+	defer stopPurger()
+
+	// Remove data exports whose download link ran out and erase accounts
+	// whose deletion is due
+	if store, err := storage.Private(); err != nil {
+		logger.Error("Private storage unavailable, expired data exports and due account deletions are kept", zap.Error(err))
+	} else {
+		stopCleaner := services.NewDataExportService(&repository.GormDataExportRepository{DB: database.GetDB()}, store, nil).
+			StartCleaner(config.AppConfig.PurgeInterval)
+		defer stopCleaner()
+		stopEraser := services.NewAccountDeletionService(&repository.GormAccountDeletionRepository{DB: database.GetDB()}, store).
+			StartEraser(config.AppConfig.PurgeInterval)
+		defer stopEraser()
+	}
+
+	// ✅ Запускаем сервер
+	srv := &http.Server{Addr: "0.0.0.0:" + config.AppConfig.AppPort, Handler: r}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Finish requests in flight, then write the views and watch progress
+	// still queued. Event streams never finish on their own; they are cut
+	// once the timeout is up and clients reconnect to another instance.
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Connections still open at shutdown are closed", zap.Error(err))
+		srv.Close()
+	}
+	tracking.Default().Close()
+	return nil
+}
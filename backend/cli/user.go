@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"go.uber.org/zap"
+)
+
+func userCmd(args []string) error {
+	if len(args) == 0 || args[0] != "promote-admin" {
+		return errUsage
+	}
+	fs := flags("promote-admin")
+	reason := fs.String("reason", "promoted from the command line", "")
+	if err := parse(fs, args[1:], 1); err != nil {
+		return err
+	}
+	email := fs.Arg(0)
+
+	logger := setup()
+	defer logger.Sync()
+	users := &repository.GormUserRepository{DB: database.GetDB()}
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		return fmt.Errorf("no account with email %s: %w", email, err)
+	}
+	// A system change: the role change has no actor.
+	_, err = services.NewRoleService(&repository.GormRoleRepository{DB: database.GetDB()}, users).
+		Grant(nil, user.ID, models.RoleAdmin, *reason)
+	if errors.Is(err, services.ErrRoleAlreadyHeld) {
+		fmt.Printf("%s is already an admin\n", email)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("Admin promoted", zap.String("user_id", user.ID.String()))
+	record(logger, "user promote-admin "+user.ID.String())
+	return nil
+}
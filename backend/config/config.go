@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -32,6 +33,13 @@ type Config struct {
 	// Video Uploads
 	UploadPath string
 
+	// Private files (identity documents). PrivateStorageDriver is "local"
+	// (files under PrivateStoragePath) or "bunny" (a zone without pull zone).
+	PrivateStorageDriver string
+	PrivateStoragePath   string
+	PrivateBunnyZone     string
+	PrivateBunnyKey      string
+
 	// Firebase
 	FirebaseType                string
 	FirebaseProjectID           string
@@ -48,6 +56,92 @@ type Config struct {
 	BunnyStreamAPIKey    string
 	BunnyStreamLibraryID string
 	BunnyStreamHost      string
+
+	// Native auth (JWT sessions)
+	JWTSecret       string
+	JWTIssuer       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Two-factor authentication
+	TOTPIssuer            string // account label shown in authenticator apps
+	RequireStaffTwoFactor bool   // staff routes reject sessions that did not pass 2FA
+
+	// Links in emails point here (verification, password reset)
+	FrontendURL string
+
+	// Outgoing mail. MailDriver is "smtp" or "file"; the file driver writes
+	// messages to MailDir and is meant for local development and tests.
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+
+	// Email verification / password reset tokens
+	VerifyEmailTTL   time.Duration
+	PasswordResetTTL time.Duration
+	// At most MailRateLimit emails of one kind per address within MailRateWindow
+	MailRateLimit  int
+	MailRateWindow time.Duration
+
+	// Creator payouts. Amounts are in balance units, which payout currencies
+	// map 1:1 (stablecoins). PayoutProvider is "manual" (staff transfers and
+	// records the transaction) or "plisio".
+	PlatformFeePercent int           // platform's cut of every sale
+	PayoutHoldPeriod   time.Duration // earnings become withdrawable after this
+	MinPayoutAmount    int
+	PayoutProvider     string
+	PayoutCurrencies   []string
+
+	// View tracking. Views of the same post by the same viewer within
+	// ViewDedupWindow count once; events are written in batches of
+	// ViewBatchSize at least every ViewFlushInterval.
+	ViewDedupWindow   time.Duration
+	ViewBatchSize     int
+	ViewFlushInterval time.Duration
+
+	// Bounds of a single tip, in balance units
+	MinTipAmount int
+	MaxTipAmount int
+
+	// Reports on one piece of content that hide it until a moderator
+	// resolves the case; 0 never hides automatically
+	ReportHideThreshold int
+
+	// How often suspensions that have run out are lifted
+	SuspensionSweepInterval time.Duration
+
+	// Deleted posts, users and uploads can be restored for DeletedRetention;
+	// the purge job then removes them and their stored files every
+	// PurgeInterval.
+	DeletedRetention time.Duration
+	PurgeInterval    time.Duration
+
+	// Accounts whose owner asked for erasure are anonymized after this grace
+	// period; deleted accounts are anonymized after DeletedRetention.
+	AccountDeletionGrace time.Duration
+
+	// Data exports can be downloaded for this long; expired ones are removed
+	// every PurgeInterval.
+	DataExportTTL time.Duration
+
+	// Unread notifications of one type about the same post absorb new events
+	// for this long ("12 people liked your post").
+	NotificationBatchWindow time.Duration
+
+	// Comment lines sent on idle event streams so proxies keep them open
+	EventHeartbeatInterval time.Duration
+
+	// Operations that wipe data, like seeding, refuse to run unless this is
+	// set; leave it off in production.
+	AllowDestructiveOps bool
+
+	// serve applies pending SQL migrations before it starts listening; turn
+	// it off where migrations are run as a separate deploy step.
+	MigrateOnStart bool
 }
 
 var AppConfig *Config
@@ -84,6 +178,11 @@ func LoadConfig() {
 
 		UploadPath: getEnv("UPLOAD_PATH", "uploads"),
 
+		PrivateStorageDriver: getEnv("PRIVATE_STORAGE_DRIVER", "local"),
+		PrivateStoragePath:   getEnv("PRIVATE_STORAGE_PATH", "private"),
+		PrivateBunnyZone:     getEnv("PRIVATE_BUNNY_STORAGE_ZONE", ""),
+		PrivateBunnyKey:      getEnv("PRIVATE_BUNNY_STORAGE_KEY", ""),
+
 		// Firebase
 		FirebaseType:                getEnv("GOOGLE_TYPE", ""),
 		FirebaseProjectID:           getEnv("GOOGLE_PROJECT_ID", ""),
@@ -100,7 +199,82 @@ func LoadConfig() {
 		BunnyStreamAPIKey:    getEnv("BUNNY_STREAM_API_KEY", ""),
 		BunnyStreamLibraryID: getEnv("BUNNY_STREAM_LIBRARY_ID", ""),
 		BunnyStreamHost:      getEnv("BUNNY_STREAM_HOSTNAME", ""),
+
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		JWTIssuer:       getEnv("JWT_ISSUER", "clicx"),
+		AccessTokenTTL:  getDuration("JWT_ACCESS_TTL", "15m"),
+		RefreshTokenTTL: getDuration("JWT_REFRESH_TTL", "720h"),
+
+		TOTPIssuer:            getEnv("TOTP_ISSUER", "Clicx"),
+		RequireStaffTwoFactor: getBool("REQUIRE_STAFF_2FA", "true"),
+
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "file"),
+		MailFrom:     getEnv("MAIL_FROM", "Clicx <no-reply@clicx.local>"),
+		MailDir:      getEnv("MAIL_DIR", "mail"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getInt("SMTP_PORT", "587"),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		VerifyEmailTTL:   getDuration("VERIFY_EMAIL_TTL", "24h"),
+		PasswordResetTTL: getDuration("PASSWORD_RESET_TTL", "1h"),
+		MailRateLimit:    getInt("MAIL_RATE_LIMIT", "3"),
+		MailRateWindow:   getDuration("MAIL_RATE_WINDOW", "1h"),
+
+		PlatformFeePercent: getInt("PLATFORM_FEE_PERCENT", "20"),
+		PayoutHoldPeriod:   getDuration("PAYOUT_HOLD_PERIOD", "168h"),
+		MinPayoutAmount:    getInt("PAYOUT_MIN_AMOUNT", "50"),
+		PayoutProvider:     getEnv("PAYOUT_PROVIDER", "manual"),
+		PayoutCurrencies:   strings.Split(getEnv("PAYOUT_CURRENCIES", "USDT_TRX,USDT"), ","),
+
+		ViewDedupWindow:   getDuration("VIEW_DEDUP_WINDOW", "30m"),
+		ViewBatchSize:     getInt("VIEW_BATCH_SIZE", "100"),
+		ViewFlushInterval: getDuration("VIEW_FLUSH_INTERVAL", "5s"),
+
+		MinTipAmount: getInt("TIP_MIN_AMOUNT", "1"),
+		MaxTipAmount: getInt("TIP_MAX_AMOUNT", "1000"),
+
+		ReportHideThreshold:     getInt("REPORT_HIDE_THRESHOLD", "5"),
+		SuspensionSweepInterval: getDuration("SUSPENSION_SWEEP_INTERVAL", "1m"),
+
+		DeletedRetention: getDuration("DELETED_RETENTION", "720h"),
+		PurgeInterval:    getDuration("PURGE_INTERVAL", "1h"),
+		DataExportTTL:    getDuration("DATA_EXPORT_TTL", "168h"),
+
+		AccountDeletionGrace: getDuration("ACCOUNT_DELETION_GRACE", "720h"),
+
+		NotificationBatchWindow: getDuration("NOTIFICATION_BATCH_WINDOW", "24h"),
+		EventHeartbeatInterval:  getDuration("EVENT_HEARTBEAT_INTERVAL", "25s"),
+
+		AllowDestructiveOps: getBool("ALLOW_DESTRUCTIVE_OPS", "false"),
+		MigrateOnStart:      getBool("MIGRATE_ON_START", "true"),
+	}
+}
+
+func getDuration(key, fallback string) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, fallback))
+	if err != nil {
+		zap.L().Fatal("Невалидная длительность в "+key, zap.Error(err))
+	}
+	return d
+}
+
+func getInt(key, fallback string) int {
+	n, err := strconv.Atoi(getEnv(key, fallback))
+	if err != nil {
+		zap.L().Fatal("Невалидное число в "+key, zap.Error(err))
+	}
+	return n
+}
+
+func getBool(key, fallback string) bool {
+	b, err := strconv.ParseBool(getEnv(key, fallback))
+	if err != nil {
+		zap.L().Fatal("Невалидное логическое значение в "+key, zap.Error(err))
 	}
+	return b
 }
 
 func getEnv(key, fallback string) string {
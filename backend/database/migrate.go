@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go-backend/models"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrationsDir holds the SQL migrations, relative to the working directory
+// (the Docker image copies it next to the binary). They are the only
+// definition of the schema; the models are checked against them by the
+// schema drift test.
+const MigrationsDir = "migrations"
+
+// Models lists every model backed by a table.
+var Models = []interface{}{
+	&models.User{},
+	&models.ModelProfile{},
+	&models.Media{},
+	&models.Comment{},
+	&models.Post{},
+	&models.Order{},
+	&models.Payment{},
+	&models.Like{},
+	&models.Purchase{},
+	&models.SavedPost{},
+	&models.Follow{},
+	&models.Referral{},
+	&models.Log{},
+	&models.Video{},
+	&models.Image{},
+	&models.RefreshToken{},
+	&models.EmailToken{},
+	&models.TwoFactor{},
+	&models.RecoveryCode{},
+	&models.UserRole{},
+	&models.RoleChange{},
+	&models.CreatorApplication{},
+	&models.CreatorDocument{},
+	&models.Earning{},
+	&models.Payout{},
+	&models.PostView{},
+	&models.VideoWatch{},
+	&models.Notification{},
+	&models.NotificationActor{},
+	&models.NotificationPreference{},
+	&models.Conversation{},
+	&models.ConversationMember{},
+	&models.Message{},
+	&models.MessageAttachment{},
+	&models.MessageUnlock{},
+	&models.Stream{},
+	&models.ChatRoom{},
+	&models.Tip{},
+	&models.UserBlock{},
+	&models.UserMute{},
+	&models.ModerationCase{},
+	&models.Report{},
+	&models.AccountStatusChange{},
+	&models.DataExport{},
+	&models.AccountDeletion{},
+	&models.AuditEntry{},
+	&models.UsedOpsConfirmation{},
+}
+
+// NewMigrator runs the migrations in dir against db, tracking the applied
+// version in schema_migrations of the connection's current schema. It holds
+// one connection of the pool until closed; closing it leaves db open.
+func NewMigrator(db *gorm.DB, dir string) (*migrate.Migrate, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithConnection(context.Background(), conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return migrate.NewWithDatabaseInstance("file://"+dir, "postgres", driver)
+}
+
+var migrateUp = applyMigrations
+
+// SetMigrateUp replaces what MigrateUp does. The tests run on SQLite, where
+// the Postgres migrations cannot apply, and build the schema from Models.
+func SetMigrateUp(fn func() error) {
+	migrateUp = fn
+}
+
+// MigrateUp applies the pending migrations to DB.
+func MigrateUp() error {
+	return migrateUp()
+}
+
+func applyMigrations() error {
+	m, err := NewMigrator(GetDB(), MigrationsDir)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	version, _, _ := m.Version()
+	zap.L().Info("Migrations applied", zap.Uint("version", version))
+	return nil
+}
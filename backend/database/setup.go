@@ -3,7 +3,6 @@ package database
 import (
         "fmt"
         "go-backend/config"
-        "go-backend/models"
 
         "go.uber.org/zap"
 
@@ -35,27 +34,3 @@ func GetDB() *gorm.DB {
         }
 	return DB
 }
-
-func MigrateAll() error {
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.ModelProfile{},
-		&models.Media{},
-		&models.Comment{},
-		&models.Post{},
-		&models.Order{},
-		&models.Payment{},
-		&models.Like{},
-		&models.Purchase{},
-		&models.SavedPost{},
-		&models.Follow{},
-		&models.Referral{},
-		&models.Log{},
-	)
-        if err != nil {
-                return fmt.Errorf("ошибка миграции: %w", err)
-        }
-
-        zap.L().Info("Миграции прошли успешно")
-        return nil
-}
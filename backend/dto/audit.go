@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"go-backend/models"
+)
+
+// AuditEntryResponseDTO is an audit log entry with its changes as a JSON
+// object of field: {from, to}.
+type AuditEntryResponseDTO struct {
+	models.AuditEntry
+	Changes json.RawMessage `json:"changes"`
+}
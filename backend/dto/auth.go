@@ -0,0 +1,94 @@
+package dto
+
+import "time"
+
+type RegisterDTO struct {
+	Email        string `json:"email" validate:"required,email"`
+	Nickname     string `json:"nickname" validate:"required,min=3,max=32"`
+	Password     string `json:"password" validate:"required,min=8,max=72"`
+	ReferralCode string `json:"referral_code"`
+}
+
+type LoginDTO struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type TokenPairDTO struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type AuthResponseDTO struct {
+	User   UserResponseDTO `json:"user"`
+	Tokens TokenPairDTO    `json:"tokens"`
+}
+
+type EmailDTO struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type EmailTokenDTO struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResetPasswordDTO struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// LoginChallengeDTO is returned by /auth/login instead of tokens when the
+// account has two-factor authentication enabled.
+type LoginChallengeDTO struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorStatusDTO struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+type TwoFactorSetupDTO struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"` // encode as a QR code for authenticator apps
+}
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorVerifyDTO completes a login (with ChallengeToken) or upgrades the
+// current session. Either Code or RecoveryCode is required.
+type TwoFactorVerifyDTO struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorDisableDTO struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorEnabledDTO struct {
+	RecoveryCodes []string     `json:"recovery_codes"`
+	Tokens        TokenPairDTO `json:"tokens"`
+}
+
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
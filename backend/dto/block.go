@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RelatedUserDTO is a user on the current user's block or mute list.
+type RelatedUserDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatarUrl"`
+	Since     time.Time `json:"since"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// CreatorApplicationDTO is the form part of a creator application; the
+// documents are sent as the id_document and selfie files.
+type CreatorApplicationDTO struct {
+	LegalName   string `form:"legal_name" validate:"required,min=2,max=128"`
+	DateOfBirth string `form:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Country     string `form:"country" validate:"required,len=2,alpha"`
+	Name        string `form:"name" validate:"required,min=2,max=64"`
+	Bio         string `form:"bio" validate:"max=512"`
+	Banner      string `form:"banner"`
+}
+
+// CreatorReviewDTO carries the moderator's decision reason. It is required
+// for rejections and optional for approvals.
+type CreatorReviewDTO struct {
+	Reason string `json:"reason" validate:"max=1024"`
+}
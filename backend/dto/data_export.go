@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportResponseDTO describes a data export. DownloadURL, relative to
+// the API, is set while a ready export can be downloaded; the link works
+// without a session until ExpiresAt.
+type DataExportResponseDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FollowUserDTO is a user on a followers or following list. IsFollowing
+// tells whether the viewer follows them.
+type FollowUserDTO struct {
+	ID             uuid.UUID `json:"id"`
+	Nickname       string    `json:"nickname"`
+	AvatarURL      string    `json:"avatarUrl"`
+	FollowersCount int       `json:"followers_count"`
+	FollowingCount int       `json:"following_count"`
+	IsFollowing    bool      `json:"is_following"`
+	FollowedAt     time.Time `json:"followed_at"`
+}
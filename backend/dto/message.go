@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ConversationCreateDTO struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+type MessageAttachmentInputDTO struct {
+	Type string `json:"type" validate:"required,oneof=photo video"`
+	URL  string `json:"url" validate:"required,url,max=2048"`
+}
+
+// MessageCreateDTO is a new message. A price above zero, allowed for verified
+// creators only, locks the attachments until the recipient pays.
+type MessageCreateDTO struct {
+	Text        string                      `json:"text" validate:"max=4000"`
+	Price       int                         `json:"price" validate:"min=0"`
+	Attachments []MessageAttachmentInputDTO `json:"attachments" validate:"max=10,dive"`
+}
+
+type ConversationUserDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatarUrl"`
+}
+
+type MessageAttachmentDTO struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// MessageResponseDTO is a message as the viewer sees it. Attachments of a
+// locked paid message are withheld; AttachmentCount still tells how many
+// there are. Read is set on the viewer's own messages once the recipient
+// has read them.
+type MessageResponseDTO struct {
+	ID              uuid.UUID              `json:"id"`
+	ConversationID  uuid.UUID              `json:"conversation_id"`
+	SenderID        uuid.UUID              `json:"sender_id"`
+	Text            string                 `json:"text"`
+	Price           int                    `json:"price"`
+	Locked          bool                   `json:"locked"`
+	Attachments     []MessageAttachmentDTO `json:"attachments"`
+	AttachmentCount int                    `json:"attachment_count"`
+	Read            bool                   `json:"read"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
+
+// ConversationResponseDTO is a conversation from one member's side.
+// Blocked is set while the viewer blocks the other member, BlockedBy while
+// the other member blocks the viewer.
+type ConversationResponseDTO struct {
+	ID            uuid.UUID           `json:"id"`
+	With          ConversationUserDTO `json:"with"`
+	LastMessage   *MessageResponseDTO `json:"last_message"`
+	Unread        int                 `json:"unread"`
+	LastMessageAt time.Time           `json:"last_message_at"`
+	Blocked       bool                `json:"blocked"`
+	BlockedBy     bool                `json:"blocked_by"`
+}
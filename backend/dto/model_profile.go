@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ModelProfileCreateDTO struct {
 	UserID uuid.UUID `json:"user_id" validate:"required"`
@@ -10,9 +14,17 @@ type ModelProfileCreateDTO struct {
 }
 
 type ModelProfileResponseDTO struct {
-	ID     uuid.UUID `json:"id"`
-	UserID uuid.UUID `json:"user_id"`
-	Name   string    `json:"name"`
-	Bio    string    `json:"bio"`
-	Banner string    `json:"banner"`
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	Bio        string     `json:"bio"`
+	Banner     string     `json:"banner"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	HiddenAt   *time.Time `json:"hidden_at,omitempty"`
+	// Follow graph of the profile's user; IsFollowing tells whether the
+	// viewer follows them.
+	FollowersCount int  `json:"followers_count"`
+	FollowingCount int  `json:"following_count"`
+	IsFollowing    bool `json:"is_following"`
 }
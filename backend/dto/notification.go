@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationActorDTO is the public profile of whoever caused a notification.
+type NotificationActorDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatarUrl"`
+}
+
+type NotificationResponseDTO struct {
+	ID         uuid.UUID             `json:"id"`
+	Type       string                `json:"type"`
+	Message    string                `json:"message"`
+	SubjectID  *uuid.UUID            `json:"subject_id"`
+	Actor      *NotificationActorDTO `json:"actor"` // latest actor
+	ActorCount int                   `json:"actor_count"`
+	Read       bool                  `json:"read"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// NotificationPreferencesDTO maps notification types to whether they are
+// delivered. Types left out are not changed.
+type NotificationPreferencesDTO map[string]bool
@@ -0,0 +1,7 @@
+package dto
+
+// RunOperationDTO runs a maintenance operation with a token from
+// POST /admin/ops/:operation/confirmation.
+type RunOperationDTO struct {
+	Confirm string `json:"confirm" validate:"required"`
+}
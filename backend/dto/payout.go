@@ -0,0 +1,17 @@
+package dto
+
+type PayoutRequestDTO struct {
+	Amount   int    `json:"amount" validate:"required,min=1"`
+	Currency string `json:"currency" validate:"required"`
+	Address  string `json:"address" validate:"required,min=20,max=128"`
+}
+
+// PayoutApproveDTO carries the transaction hash of a manual transfer. It is
+// ignored when payouts go through a payment provider.
+type PayoutApproveDTO struct {
+	TxRef string `json:"tx_ref" validate:"max=256"`
+}
+
+type PayoutRejectDTO struct {
+	Reason string `json:"reason" validate:"required,max=1024"`
+}
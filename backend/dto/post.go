@@ -5,6 +5,7 @@ import "github.com/google/uuid"
 type PostCreateDTO struct {
 	Text      string    `json:"text" validate:"required"`
 	IsPremium bool      `json:"isPremium"`
+	Price     int       `json:"price" validate:"min=0"`
 	UserID    uuid.UUID `json:"userId" validate:"required"`
 	ModelID   uuid.UUID `json:"modelId" validate:"required"`
 }
@@ -19,3 +20,7 @@ type PostResponseDTO struct {
 	UserID      uuid.UUID `json:"userId"`
 	ModelID     uuid.UUID `json:"modelId"`
 }
+
+type CommentCreateDTO struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
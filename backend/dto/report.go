@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/google/uuid"
+
+// ReportCreateDTO flags a post, comment, model profile or message.
+type ReportCreateDTO struct {
+	TargetType string    `json:"target_type" validate:"required"`
+	TargetID   uuid.UUID `json:"target_id" validate:"required"`
+	Reason     string    `json:"reason" validate:"required"`
+	Details    string    `json:"details" validate:"max=1000"`
+}
+
+// ResolveCaseDTO closes a moderation case. SuspendDays is required for the
+// suspend action and ignored otherwise.
+type ResolveCaseDTO struct {
+	Action      string `json:"action" validate:"required"`
+	Note        string `json:"note" validate:"max=1024"`
+	SuspendDays int    `json:"suspend_days" validate:"min=0,max=3650"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type RoleGrantDTO struct {
+	Role   string `json:"role" validate:"required"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+// RoleDTO describes a role and the permissions it grants.
+type RoleDTO struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
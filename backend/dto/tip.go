@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TipCreateDTO is a tip to a model profile. At most one of PostID, StreamID
+// and MessageID may be set to say what it is for.
+type TipCreateDTO struct {
+	Amount    int    `json:"amount" validate:"required,min=1"`
+	PostID    string `json:"post_id" validate:"omitempty,uuid"`
+	StreamID  string `json:"stream_id" validate:"omitempty,uuid"`
+	MessageID string `json:"message_id" validate:"omitempty,uuid"`
+	Note      string `json:"note" validate:"max=255"`
+}
+
+type TipperDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatarUrl"`
+}
+
+type TipResponseDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	ModelID   uuid.UUID  `json:"model_id"`
+	From      *TipperDTO `json:"from,omitempty"`
+	Amount    int        `json:"amount"`
+	PostID    *uuid.UUID `json:"post_id"`
+	StreamID  *uuid.UUID `json:"stream_id"`
+	MessageID *uuid.UUID `json:"message_id"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+}
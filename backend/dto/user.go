@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserCreateDTO struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -8,13 +12,60 @@ type UserCreateDTO struct {
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+// UserUpdateDTO holds the profile fields a user may change; empty fields are
+// left untouched. Passwords are changed through POST /auth/password.
+type UserUpdateDTO struct {
+	Email     string `json:"email" validate:"omitempty,email"`
+	Nickname  string `json:"nickname" validate:"omitempty,min=3,max=32"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
 type UserResponseDTO struct {
-	ID           uuid.UUID  `json:"id"`
-	Email        string     `json:"email"`
-	Nickname     string     `json:"nickname"`
-	Balance      int        `json:"balance"`
-	AvatarURL    string     `json:"avatarUrl"`
-	IsAdmin      bool       `json:"isAdmin"`
-	ReferralCode *string    `json:"referral_code"`
-	ReferredBy   *uuid.UUID `json:"referred_by"`
+	ID            uuid.UUID  `json:"id"`
+	Email         string     `json:"email"`
+	Nickname      string     `json:"nickname"`
+	Balance       int        `json:"balance"`
+	AvatarURL     string     `json:"avatarUrl"`
+	IsAdmin       bool       `json:"isAdmin"` // kept for older clients, same as "admin" in Roles
+	Roles         []string   `json:"roles"`
+	ReferralCode  *string    `json:"referral_code"`
+	ReferredBy    *uuid.UUID `json:"referred_by"`
+	EmailVerified bool       `json:"email_verified"`
+	Status        string     `json:"status"` // account state, see models.AccountStates
+	// Follow graph; IsFollowing tells whether the viewer follows this user.
+	FollowersCount int  `json:"followers_count"`
+	FollowingCount int  `json:"following_count"`
+	IsFollowing    bool `json:"is_following"`
+}
+
+// PublicUserDTO is what anyone may see of a user: GET /users/:id answers
+// with it unless the caller is the user or holds users:read.
+type PublicUserDTO struct {
+	ID             uuid.UUID `json:"id"`
+	Nickname       string    `json:"nickname"`
+	AvatarURL      string    `json:"avatarUrl"`
+	FollowersCount int       `json:"followers_count"`
+	FollowingCount int       `json:"following_count"`
+	IsFollowing    bool      `json:"is_following"`
+}
+
+// AccountStatusDTO moves an account to another state. Until is required
+// for suspended and ignored otherwise.
+type AccountStatusDTO struct {
+	Status string     `json:"status" validate:"required"`
+	Reason string     `json:"reason" validate:"required,max=1024"`
+	Until  *time.Time `json:"until"`
+}
+
+// AccountDeletionDTO requests the erasure of the current user's account.
+type AccountDeletionDTO struct {
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+// AccountStatusResponseDTO is a user's current account state.
+type AccountStatusResponseDTO struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
 }
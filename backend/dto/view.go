@@ -0,0 +1,13 @@
+package dto
+
+// PostViewDTO optionally names the media item of the post that was viewed.
+type PostViewDTO struct {
+	MediaID string `json:"media_id" validate:"omitempty,uuid"`
+}
+
+// WatchProgressDTO reports the playback position in seconds. Duration is
+// only needed when the media item has no known duration.
+type WatchProgressDTO struct {
+	Position int `json:"position" validate:"min=0,max=86400"`
+	Duration int `json:"duration" validate:"min=0,max=86400"`
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/storage"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// accountDeletionService aborts with 500 when the private store is misconfigured.
+func accountDeletionService(c *gin.Context) (*services.AccountDeletionService, bool) {
+	store, err := storage.Private()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return nil, false
+	}
+	return services.NewAccountDeletionService(&repository.GormAccountDeletionRepository{DB: database.GetDB()}, store), true
+}
+
+// abortAccountDeletionError maps AccountDeletionService errors to HTTP responses.
+func abortAccountDeletionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "No pending account deletion", err)
+	case errors.Is(err, services.ErrDeletionPending):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// RequestAccountDeletion godoc
+// @Summary      Request the erasure of the current user's account
+// @Description  The account stays usable and the request can be cancelled until due_at. Then personal data is anonymized; purchases, payments and payouts are kept without it.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.AccountDeletionDTO  false  "Optional reason"
+// @Success      202 {object} models.AccountDeletion
+// @Failure      409 {object} gin.H
+// @Router       /me/deletion [post]
+func RequestAccountDeletion(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	var input dto.AccountDeletionDTO
+	if c.Request.ContentLength != 0 && !utils.BindAndValidate(c, &input) {
+		return
+	}
+	svc, ok := accountDeletionService(c)
+	if !ok {
+		return
+	}
+	deletion, err := svc.Request(user, input.Reason)
+	if err != nil {
+		abortAccountDeletionError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetAccountDeletion godoc
+// @Summary      Pending deletion request of the current user
+// @Tags         users
+// @Produce      json
+// @Success      200 {object} models.AccountDeletion
+// @Failure      404 {object} gin.H
+// @Router       /me/deletion [get]
+func GetAccountDeletion(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := accountDeletionService(c)
+	if !ok {
+		return
+	}
+	deletion, err := svc.Pending(user.ID)
+	if err != nil {
+		abortAccountDeletionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deletion)
+}
+
+// CancelAccountDeletion godoc
+// @Summary      Cancel the pending deletion of the current user's account
+// @Tags         users
+// @Produce      json
+// @Success      200 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /me/deletion [delete]
+func CancelAccountDeletion(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := accountDeletionService(c)
+	if !ok {
+		return
+	}
+	if err := svc.Cancel(user.ID); err != nil {
+		abortAccountDeletionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/mailer"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newAccountEmailService() (*services.AccountEmailService, error) {
+	m, err := mailer.Get()
+	if err != nil {
+		return nil, err
+	}
+	db := database.GetDB()
+	return services.NewAccountEmailService(
+		&repository.GormUserRepository{DB: db},
+		&repository.GormEmailTokenRepository{DB: db},
+		&repository.GormRefreshTokenRepository{DB: db},
+		m,
+	), nil
+}
+
+// abortAccountEmailError maps AccountEmailService errors to HTTP responses.
+func abortAccountEmailError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEmailToken):
+		utils.AbortWithCode(c, http.StatusBadRequest, "invalid_email_token", err.Error())
+	case errors.Is(err, services.ErrAlreadyVerified):
+		utils.AbortWithCode(c, http.StatusConflict, "already_verified", err.Error())
+	case errors.Is(err, services.ErrMailRateLimited):
+		utils.AbortWithCode(c, http.StatusTooManyRequests, "rate_limited", err.Error())
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// sendVerificationAfterRegister mails the first verification link. A failure
+// does not fail the registration; the user can ask for another link.
+func sendVerificationAfterRegister(c *gin.Context, user *models.User) {
+	service, err := newAccountEmailService()
+	if err == nil {
+		err = service.SendVerification(c.Request.Context(), user)
+	}
+	if err != nil {
+		logging.GetLogger().Error("Verification email not sent", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+}
+
+// SendVerificationEmail godoc
+// @Summary      Send a new email verification link to the current user
+// @Tags         auth
+// @Produce      json
+// @Success      202 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Failure      429 {object} gin.H
+// @Router       /auth/verify-email/send [post]
+func SendVerificationEmail(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
+	service, err := newAccountEmailService()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusServiceUnavailable, "Mail is not configured", err)
+		return
+	}
+	if err := service.SendVerification(c.Request.Context(), user); err != nil {
+		abortAccountEmailError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// VerifyEmail godoc
+// @Summary      Confirm an email address with the token from the email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.EmailTokenDTO  true  "Token"
+// @Success      200 {object} dto.UserResponseDTO
+// @Failure      400 {object} gin.H
+// @Router       /auth/verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	var input dto.EmailTokenDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	service, err := newAccountEmailService()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusServiceUnavailable, "Mail is not configured", err)
+		return
+	}
+	user, err := service.VerifyEmail(input.Token)
+	if err != nil {
+		abortAccountEmailError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, services.ToUserResponseDTO(user))
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset link
+// @Description  Always answers 202 so the endpoint does not reveal which addresses have accounts
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.EmailDTO  true  "Email"
+// @Success      202 {object} gin.H
+// @Router       /auth/password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var input dto.EmailDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	service, err := newAccountEmailService()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusServiceUnavailable, "Mail is not configured", err)
+		return
+	}
+	if err := service.RequestPasswordReset(c.Request.Context(), input.Email); err != nil {
+		abortAccountEmailError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address belongs to an account, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary      Set a new password with the token from the reset email
+// @Description  Ends all sessions of the account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ResetPasswordDTO  true  "Token and new password"
+// @Success      200 {object} gin.H
+// @Failure      400 {object} gin.H
+// @Router       /auth/password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var input dto.ResetPasswordDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	service, err := newAccountEmailService()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusServiceUnavailable, "Mail is not configured", err)
+		return
+	}
+	if err := service.ResetPassword(input.Token, input.NewPassword); err != nil {
+		abortAccountEmailError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func accountStatusService() *services.AccountStatusService {
+	db := database.GetDB()
+	return services.NewAccountStatusService(&repository.GormAccountStatusRepository{DB: db}, &repository.GormUserRepository{DB: db})
+}
+
+// abortAccountStatusError maps AccountStatusService errors to HTTP responses.
+func abortAccountStatusError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
+	case errors.Is(err, services.ErrAccountStatus),
+		errors.Is(err, services.ErrStatusReasonRequired),
+		errors.Is(err, services.ErrSuspensionEnd),
+		errors.Is(err, services.ErrStatusSelf):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrStatusUnchanged):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// GetAccountStatus godoc
+// @Summary      Account state of a user
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} dto.AccountStatusResponseDTO
+// @Failure      404 {object} gin.H
+// @Router       /admin/users/{id}/status [get]
+func GetAccountStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	status, err := accountStatusService().Status(id)
+	if err != nil {
+		abortAccountStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ChangeAccountStatus godoc
+// @Summary      Suspend, ban, deactivate or reactivate an account
+// @Description  Suspensions need an until time in the future and are lifted automatically once it passes. Banned and deactivated creators disappear from feeds and search.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                true  "User ID"
+// @Param        input  body      dto.AccountStatusDTO  true  "Status, reason and suspension end"
+// @Success      200 {object} dto.AccountStatusResponseDTO
+// @Failure      400 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/users/{id}/status [put]
+func ChangeAccountStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var input dto.AccountStatusDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	status, err := accountStatusService().Change(actor, id, input)
+	if err != nil {
+		abortAccountStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// GetAccountStatusHistory godoc
+// @Summary      Account state changes of a user, newest first
+// @Tags         admin
+// @Produce      json
+// @Param        id      path      string  true   "User ID"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.AccountStatusChange
+// @Failure      404 {object} gin.H
+// @Router       /admin/users/{id}/status/history [get]
+func GetAccountStatusHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	changes, err := accountStatusService().History(id, limit, offset)
+	if err != nil {
+		abortAccountStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultAnalyticsDays is the period reported when from is omitted.
+const defaultAnalyticsDays = 30
+
+// GetModelAnalytics godoc
+// @Summary      Creator analytics of a model profile
+// @Description  Revenue, purchases, new followers, likes and saves per day, week or month, with totals and the top-earning posts. Dates are UTC and inclusive; the range defaults to the last 30 days and is at most 366 days.
+// @Tags         models
+// @Produce      json
+// @Param        id        path      string  true   "Model profile ID"
+// @Param        from      query     string  false  "First day, YYYY-MM-DD"
+// @Param        to        query     string  false  "Last day, YYYY-MM-DD (default today)"
+// @Param        interval  query     string  false  "day (default), week or month"
+// @Success      200 {object} services.ModelAnalytics
+// @Failure      400 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /models/{id}/analytics [get]
+func GetModelAnalytics(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+	var profile models.ModelProfile
+	if err := database.DB.First(&profile, "id = ?", id).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanViewModelAnalytics(actor, &profile, utils.StaffTwoFactor(c))) {
+		return
+	}
+
+	to := time.Now().UTC()
+	if s := c.Query("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			utils.AbortWithError(c, http.StatusBadRequest, "Invalid to date, expected YYYY-MM-DD", err)
+			return
+		}
+	}
+	from := to.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			utils.AbortWithError(c, http.StatusBadRequest, "Invalid from date, expected YYYY-MM-DD", err)
+			return
+		}
+	}
+
+	svc := services.NewAnalyticsService(&repository.GormAnalyticsRepository{DB: database.GetDB()})
+	report, err := svc.ModelAnalytics(profile.ID, profile.UserID, from, to, c.DefaultQuery("interval", services.IntervalDay))
+	if err != nil {
+		if errors.Is(err, services.ErrAnalyticsInterval) || errors.Is(err, services.ErrAnalyticsRange) {
+			utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func auditService() *services.AuditService {
+	return services.NewAuditService(&repository.GormAuditRepository{DB: database.GetDB()})
+}
+
+// GetAuditLog godoc
+// @Summary      Audit log of staff actions, newest first
+// @Tags         admin
+// @Produce      json
+// @Param        actor_id     query     string  false  "Acting user"
+// @Param        action       query     string  false  "Action, e.g. account_status.change"
+// @Param        entity_type  query     string  false  "Entity type, e.g. user"
+// @Param        entity_id    query     string  false  "Entity ID"
+// @Param        request_id   query     string  false  "Request ID"
+// @Param        since        query     string  false  "From (RFC 3339)"
+// @Param        until        query     string  false  "Before (RFC 3339)"
+// @Param        limit        query     int     false  "Limit"
+// @Param        offset       query     int     false  "Offset"
+// @Success      200 {array} dto.AuditEntryResponseDTO
+// @Failure      400 {object} gin.H
+// @Router       /admin/audit [get]
+func GetAuditLog(c *gin.Context) {
+	filter := repository.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+	}
+	if v := c.Query("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			utils.AbortWithError(c, http.StatusBadRequest, "Invalid actor_id", err)
+			return
+		}
+		filter.ActorID = &id
+	}
+	for _, p := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.AbortWithError(c, http.StatusBadRequest, "Invalid "+p.name, err)
+			return
+		}
+		*p.dest = &t
+	}
+	limit, offset := utils.GetPagination(c)
+	entries, err := auditService().List(filter, limit, offset)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyAuditLog godoc
+// @Summary      Check the hash chain of the audit log
+// @Description  Reports the first entry that was altered, removed or inserted since it was written.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} services.AuditVerification
+// @Router       /admin/audit/verify [get]
+func VerifyAuditLog(c *gin.Context) {
+	result, err := auditService().Verify()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -1,59 +1,189 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"go-backend/database"
-	"go-backend/models"
+	"go-backend/dto"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
 	"go-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Register(c *gin.Context) {
-	var input struct {
-		Email        string `json:"email" validate:"required,email"`
-		Nickname     string `json:"nickname" validate:"required,min=3,max=32"`
-		Password     string `json:"password" validate:"required,min=8"`
-		ReferralCode string `json:"referral_code"`
+func newAuthService() *services.AuthService {
+	return services.NewAuthService(
+		&repository.GormUserRepository{DB: database.GetDB()},
+		&repository.GormRefreshTokenRepository{DB: database.GetDB()},
+		&repository.GormTwoFactorRepository{DB: database.GetDB()},
+	)
+}
+
+func sessionMeta(c *gin.Context) services.SessionMeta {
+	return services.SessionMeta{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// abortAuthError maps AuthService errors to HTTP responses.
+func abortAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmailTaken):
+		utils.AbortWithCode(c, http.StatusConflict, "email_taken", err.Error())
+	case errors.Is(err, services.ErrInvalidCredentials):
+		utils.AbortWithCode(c, http.StatusUnauthorized, "invalid_credentials", err.Error())
+	case errors.Is(err, services.ErrInvalidRefreshToken):
+		utils.AbortWithCode(c, http.StatusUnauthorized, "invalid_refresh_token", err.Error())
+	case errors.Is(err, services.ErrInvalidAccessToken):
+		utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeInvalidToken, err.Error())
+	case errors.Is(err, services.ErrAuthNotConfigured):
+		utils.AbortWithCode(c, http.StatusServiceUnavailable, "auth_not_configured", err.Error())
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
 	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid input"})
+}
+
+// Register godoc
+// @Summary      Register with email and password
+// @Description  Sends an email verification link to the address
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.RegisterDTO  true  "Registration"
+// @Success      201 {object} dto.AuthResponseDTO
+// @Failure      409 {object} gin.H
+// @Router       /auth/register [post]
+func Register(c *gin.Context) {
+	var input dto.RegisterDTO
+	if !utils.BindAndValidate(c, &input) {
 		return
 	}
-	if err := utils.ValidateStruct(input); err != nil {
-		c.JSON(400, gin.H{"error": "Validation failed", "details": err.Error()})
+	user, tokens, err := newAuthService().Register(&input, sessionMeta(c))
+	if err != nil {
+		abortAuthError(c, err)
 		return
 	}
+	sendVerificationAfterRegister(c, user)
+	c.JSON(http.StatusCreated, dto.AuthResponseDTO{User: services.ToUserResponseDTO(user), Tokens: tokens})
+}
 
-	// Hash the password
-	hashedPassword, err := utils.HashPassword(input.Password)
+// Login godoc
+// @Summary      Log in with email and password
+// @Description  Accounts with two-factor authentication get a dto.LoginChallengeDTO instead of tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.LoginDTO  true  "Credentials"
+// @Success      200 {object} dto.AuthResponseDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/login [post]
+func Login(c *gin.Context) {
+	var input dto.LoginDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	service := newAuthService()
+	user, tokens, err := service.Login(input.Email, input.Password, sessionMeta(c))
+	if errors.Is(err, services.ErrTwoFactorRequired) {
+		challenge, expiresAt, err := service.IssueChallenge(user)
+		if err != nil {
+			abortAuthError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.LoginChallengeDTO{TwoFactorRequired: true, ChallengeToken: challenge, ExpiresAt: expiresAt})
+		return
+	}
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Internal server error"})
+		abortAuthError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, dto.AuthResponseDTO{User: services.ToUserResponseDTO(user), Tokens: tokens})
+}
 
-	user := models.User{
-		Email:    input.Email,
-		Nickname: input.Nickname,
-		Password: hashedPassword,
+// RefreshToken godoc
+// @Summary      Rotate a refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.RefreshTokenDTO  true  "Refresh token"
+// @Success      200 {object} dto.TokenPairDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var input dto.RefreshTokenDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
 	}
-
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(500, gin.H{"error": "Could not create user"})
+	tokens, err := newAuthService().Refresh(input.RefreshToken, sessionMeta(c))
+	if err != nil {
+		abortAuthError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, tokens)
+}
 
-	// Генерация referral_code
-	code := utils.GenerateReferralCode(8)
-	user.ReferralCode = &code
+// Logout godoc
+// @Summary      Revoke the session of a refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.RefreshTokenDTO  true  "Refresh token"
+// @Success      200 {object} gin.H
+// @Router       /auth/logout [post]
+func Logout(c *gin.Context) {
+	var input dto.RefreshTokenDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	if err := newAuthService().Logout(input.RefreshToken); err != nil {
+		abortAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
 
-	// Проверка реферального кода
-	if input.ReferralCode != "" {
-		var referrer models.User
-		if err := database.DB.Where("referral_code = ?", input.ReferralCode).First(&referrer).Error; err == nil {
-			user.ReferredBy = &referrer.ID
-		}
+// LogoutAll godoc
+// @Summary      Revoke all sessions of the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} gin.H
+// @Router       /auth/logout-all [post]
+func LogoutAll(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
 	}
+	if err := newAuthService().LogoutAll(user); err != nil {
+		abortAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions revoked"})
+}
 
-	database.DB.Save(&user)
-	c.JSON(200, gin.H{"message": "User registered", "user": user})
+// ChangePassword godoc
+// @Summary      Change the current user's password
+// @Description  Revokes all sessions and returns a new token pair
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ChangePasswordDTO  true  "Passwords"
+// @Success      200 {object} dto.TokenPairDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/password [post]
+func ChangePassword(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
+	var input dto.ChangePasswordDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	tokens, err := newAuthService().ChangePassword(user, input.CurrentPassword, input.NewPassword, utils.TwoFactorPassed(c), sessionMeta(c))
+	if err != nil {
+		abortAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
 }
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func blockService() *services.BlockService {
+	db := database.GetDB()
+	return services.NewBlockService(&repository.GormBlockRepository{DB: db}, &repository.GormUserRepository{DB: db})
+}
+
+// abortBlockError maps BlockService errors to HTTP responses.
+func abortBlockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
+	case errors.Is(err, services.ErrBlockSelf):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrUserBlocked):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// checkInteraction aborts with 403 when the current user and otherID block
+// each other either way.
+func checkInteraction(c *gin.Context, otherID uuid.UUID) bool {
+	if err := blockService().CheckInteraction(viewerID(c), otherID); err != nil {
+		abortBlockError(c, err)
+		return false
+	}
+	return true
+}
+
+// BlockUser godoc
+// @Summary      Block a user
+// @Description  Removes follows both ways and stops follows, likes, comments, messages and tips between the two users.
+// @Tags         blocks
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Failure      400 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /blocks/{id} [post]
+func BlockUser(c *gin.Context) {
+	setRelation(c, blockService().Block, "blocked", "already blocked")
+}
+
+// UnblockUser godoc
+// @Summary      Unblock a user
+// @Tags         blocks
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Router       /blocks/{id} [delete]
+func UnblockUser(c *gin.Context) {
+	setRelation(c, blockService().Unblock, "unblocked", "not blocked")
+}
+
+// MuteUser godoc
+// @Summary      Mute a user
+// @Description  Hides the user's posts, comments and notifications from the current user only.
+// @Tags         blocks
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Failure      400 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /mutes/{id} [post]
+func MuteUser(c *gin.Context) {
+	setRelation(c, blockService().Mute, "muted", "already muted")
+}
+
+// UnmuteUser godoc
+// @Summary      Unmute a user
+// @Tags         blocks
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Router       /mutes/{id} [delete]
+func UnmuteUser(c *gin.Context) {
+	setRelation(c, blockService().Unmute, "unmuted", "not muted")
+}
+
+// GetBlockedUsers godoc
+// @Summary      Users the current user blocked, most recent first
+// @Tags         blocks
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.RelatedUserDTO
+// @Router       /blocks [get]
+func GetBlockedUsers(c *gin.Context) {
+	limit, offset := utils.GetPagination(c)
+	users, err := blockService().Blocked(viewerID(c), limit, offset)
+	if err != nil {
+		abortBlockError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GetMutedUsers godoc
+// @Summary      Users the current user muted, most recent first
+// @Tags         blocks
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.RelatedUserDTO
+// @Router       /mutes [get]
+func GetMutedUsers(c *gin.Context) {
+	limit, offset := utils.GetPagination(c)
+	users, err := blockService().Muted(viewerID(c), limit, offset)
+	if err != nil {
+		abortBlockError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// setRelation applies a block or mute change to the :id user and reports
+// whether anything changed.
+func setRelation(c *gin.Context, apply func(userID, targetID uuid.UUID) (bool, error), done, unchanged string) {
+	targetID, ok := followTarget(c)
+	if !ok {
+		return
+	}
+	changed, err := apply(viewerID(c), targetID)
+	if err != nil {
+		abortBlockError(c, err)
+		return
+	}
+	if !changed {
+		c.JSON(http.StatusOK, gin.H{"message": unchanged})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": done})
+}
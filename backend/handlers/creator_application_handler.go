@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/storage"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newCreatorApplicationService() (*services.CreatorApplicationService, error) {
+	store, err := storage.Private()
+	if err != nil {
+		return nil, err
+	}
+	db := database.GetDB()
+	return services.NewCreatorApplicationService(&repository.GormCreatorApplicationRepository{DB: db}, &repository.GormRoleRepository{DB: db}, store), nil
+}
+
+// creatorApplicationService aborts with 500 when the private store is misconfigured.
+func creatorApplicationService(c *gin.Context) (*services.CreatorApplicationService, bool) {
+	svc, err := newCreatorApplicationService()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return nil, false
+	}
+	return svc, true
+}
+
+// abortCreatorApplicationError maps CreatorApplicationService errors to HTTP responses.
+func abortCreatorApplicationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, storage.ErrNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Application not found", err)
+	case errors.Is(err, services.ErrApplicantUnderage), errors.Is(err, services.ErrDocumentMissing),
+		errors.Is(err, services.ErrDocumentType), errors.Is(err, services.ErrReviewReasonRequired):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrDocumentTooLarge):
+		utils.AbortWithError(c, http.StatusRequestEntityTooLarge, err.Error(), err)
+	case errors.Is(err, services.ErrApplicationPending), errors.Is(err, services.ErrAlreadyCreator), errors.Is(err, services.ErrApplicationReviewed):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// SubmitCreatorApplication godoc
+// @Summary      Apply to become a creator
+// @Tags         creators
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        legal_name     formData  string  true   "Legal name as on the ID document"
+// @Param        date_of_birth  formData  string  true   "Date of birth (YYYY-MM-DD)"
+// @Param        country        formData  string  true   "ISO 3166-1 alpha-2 country of the ID document"
+// @Param        name           formData  string  true   "Public creator name"
+// @Param        bio            formData  string  false  "Public bio"
+// @Param        banner         formData  string  false  "Banner URL"
+// @Param        id_document    formData  file    true   "Government ID (JPEG, PNG or PDF)"
+// @Param        selfie         formData  file    true   "Selfie holding the ID (JPEG or PNG)"
+// @Success      201 {object} models.CreatorApplication
+// @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /creators/applications [post]
+func SubmitCreatorApplication(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	var input dto.CreatorApplicationDTO
+	if err := c.ShouldBind(&input); err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	if err := utils.ValidateStruct(&input); err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+	dob, _ := time.Parse("2006-01-02", input.DateOfBirth)
+
+	var docs []services.DocumentUpload
+	for _, kind := range []string{models.DocumentID, models.DocumentSelfie} {
+		doc, closer, err := documentUpload(c, kind)
+		if err != nil {
+			utils.AbortWithError(c, http.StatusBadRequest, services.ErrDocumentMissing.Error(), err)
+			return
+		}
+		defer closer.Close()
+		docs = append(docs, doc)
+	}
+
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	app, err := svc.Submit(c.Request.Context(), user, services.CreatorApplicationInput{
+		LegalName:   input.LegalName,
+		DateOfBirth: dob,
+		Country:     input.Country,
+		Name:        input.Name,
+		Bio:         input.Bio,
+		Banner:      input.Banner,
+	}, docs)
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, app)
+}
+
+// documentUpload opens the form file named kind. The content type is sniffed
+// from the file itself rather than taken from the client.
+func documentUpload(c *gin.Context, kind string) (services.DocumentUpload, io.Closer, error) {
+	header, err := c.FormFile(kind)
+	if err != nil {
+		return services.DocumentUpload{}, nil, fmt.Errorf("%s: %w", kind, err)
+	}
+	f, err := header.Open()
+	if err != nil {
+		return services.DocumentUpload{}, nil, err
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return services.DocumentUpload{}, nil, err
+	}
+	return services.DocumentUpload{
+		Kind:        kind,
+		ContentType: http.DetectContentType(head[:n]),
+		Size:        header.Size,
+		Body:        f,
+	}, f, nil
+}
+
+// GetMyCreatorApplication godoc
+// @Summary      Status of the current user's latest creator application
+// @Tags         creators
+// @Produce      json
+// @Success      200 {object} models.CreatorApplication
+// @Failure      404 {object} gin.H
+// @Router       /creators/applications/me [get]
+func GetMyCreatorApplication(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	app, err := svc.Latest(user.ID)
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, app)
+}
+
+// GetCreatorApplications godoc
+// @Summary      Creator verification queue, oldest first
+// @Tags         admin
+// @Produce      json
+// @Param        status  query     string  false  "pending (default), approved, rejected or all"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.CreatorApplication
+// @Router       /admin/creator-applications [get]
+func GetCreatorApplications(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ApplicationPending)
+	switch status {
+	case "all":
+		status = ""
+	case models.ApplicationPending, models.ApplicationApproved, models.ApplicationRejected:
+	default:
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid status", fmt.Errorf("unknown status %q", status))
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	apps, err := svc.Queue(status, limit, offset)
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, apps)
+}
+
+// GetCreatorApplication godoc
+// @Summary      Creator application with identity data
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Application ID"
+// @Success      200 {object} models.CreatorApplication
+// @Failure      404 {object} gin.H
+// @Router       /admin/creator-applications/{id} [get]
+func GetCreatorApplication(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid application ID", err)
+		return
+	}
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	app, err := svc.Get(id)
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, app)
+}
+
+// GetCreatorDocument godoc
+// @Summary      Download an identity document of an application
+// @Tags         admin
+// @Produce      application/octet-stream
+// @Param        id     path      string  true  "Application ID"
+// @Param        docId  path      string  true  "Document ID"
+// @Success      200 {file} file
+// @Failure      404 {object} gin.H
+// @Router       /admin/creator-applications/{id}/documents/{docId} [get]
+func GetCreatorDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid application ID", err)
+		return
+	}
+	docID, err := uuid.Parse(c.Param("docId"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid document ID", err)
+		return
+	}
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	doc, body, err := svc.OpenDocument(c.Request.Context(), id, docID)
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	defer body.Close()
+	// identity documents must never end up in shared caches
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, body, nil)
+}
+
+// ApproveCreatorApplication godoc
+// @Summary      Approve a creator application
+// @Description  Creates or verifies the applicant's model profile and grants the creator role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                true   "Application ID"
+// @Param        input  body      dto.CreatorReviewDTO  false  "Optional note"
+// @Success      200 {object} models.CreatorApplication
+// @Failure      409 {object} gin.H
+// @Router       /admin/creator-applications/{id}/approve [post]
+func ApproveCreatorApplication(c *gin.Context) {
+	reviewCreatorApplication(c, true)
+}
+
+// RejectCreatorApplication godoc
+// @Summary      Reject a creator application
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                true  "Application ID"
+// @Param        input  body      dto.CreatorReviewDTO  true  "Reason shown to the applicant"
+// @Success      200 {object} models.CreatorApplication
+// @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/creator-applications/{id}/reject [post]
+func RejectCreatorApplication(c *gin.Context) {
+	reviewCreatorApplication(c, false)
+}
+
+func reviewCreatorApplication(c *gin.Context, approve bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid application ID", err)
+		return
+	}
+	var input dto.CreatorReviewDTO
+	if c.Request.ContentLength != 0 && !utils.BindAndValidate(c, &input) {
+		return
+	}
+	svc, ok := creatorApplicationService(c)
+	if !ok {
+		return
+	}
+	reviewer, _ := utils.GetCurrentUser(c)
+	var app *models.CreatorApplication
+	if approve {
+		app, _, err = svc.Approve(reviewer, id, input.Reason)
+	} else {
+		app, err = svc.Reject(reviewer, id, input.Reason)
+	}
+	if err != nil {
+		abortCreatorApplicationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, app)
+}
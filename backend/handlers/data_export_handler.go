@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/storage"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// dataExportService aborts with 500 when the private store is misconfigured.
+func dataExportService(c *gin.Context) (*services.DataExportService, bool) {
+	store, err := storage.Private()
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return nil, false
+	}
+	return services.NewDataExportService(&repository.GormDataExportRepository{DB: database.GetDB()}, store, notificationService()), true
+}
+
+// abortDataExportError maps DataExportService errors to HTTP responses.
+func abortDataExportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, storage.ErrNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Export not found", err)
+	case errors.Is(err, services.ErrExportLink):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, services.ErrExportExpired):
+		utils.AbortWithError(c, http.StatusGone, err.Error(), err)
+	case errors.Is(err, services.ErrExportPending):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// RequestDataExport godoc
+// @Summary      Export all data kept about the current user
+// @Description  Builds a ZIP archive with one JSON file per kind of data in the background. A notification arrives once it is ready; the download link is listed under /me/exports.
+// @Tags         users
+// @Produce      json
+// @Success      202 {object} dto.DataExportResponseDTO
+// @Failure      409 {object} gin.H
+// @Router       /me/export [post]
+func RequestDataExport(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := dataExportService(c)
+	if !ok {
+		return
+	}
+	export, err := svc.Request(user)
+	if err != nil {
+		abortDataExportError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, export)
+}
+
+// GetDataExports godoc
+// @Summary      Data exports of the current user, newest first
+// @Tags         users
+// @Produce      json
+// @Success      200 {array} dto.DataExportResponseDTO
+// @Router       /me/exports [get]
+func GetDataExports(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := dataExportService(c)
+	if !ok {
+		return
+	}
+	exports, err := svc.List(user.ID)
+	if err != nil {
+		abortDataExportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, exports)
+}
+
+// DownloadDataExport godoc
+// @Summary      Download a data export
+// @Description  The signed link from /me/exports is the credential, so no session is needed.
+// @Tags         users
+// @Produce      application/zip
+// @Param        id         path      string  true  "Export ID"
+// @Param        expires    query     int     true  "Link expiry (unix time)"
+// @Param        signature  query     string  true  "Link signature"
+// @Success      200 {file} file
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      410 {object} gin.H
+// @Router       /me/exports/{id}/download [get]
+func DownloadDataExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid export ID", err)
+		return
+	}
+	svc, ok := dataExportService(c)
+	if !ok {
+		return
+	}
+	export, body, err := svc.Open(c.Request.Context(), id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		abortDataExportError(c, err)
+		return
+	}
+	defer body.Close()
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, export.Size, "application/zip", body, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="data-export-%s.zip"`, export.CreatedAt.Format("2006-01-02")),
+	})
+}
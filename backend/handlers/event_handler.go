@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/realtime"
+	"go-backend/repository"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// reconnectDelay is the retry interval suggested to EventSource clients.
+const reconnectDelay = 3 * time.Second
+
+// StreamEvents godoc
+// @Summary      Server-Sent Events stream of the current user
+// @Description  Streams notification, balance, purchase and direct message events. Each event carries an id; reconnecting with the Last-Event-ID header (or last_event_id query parameter) replays recent events published since. Browsers may pass the token as access_token because EventSource cannot set headers. Idle streams get a comment line every EVENT_HEARTBEAT_INTERVAL.
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        access_token   query     string  false  "Bearer token, for clients that cannot send headers"
+// @Param        last_event_id  query     string  false  "Replay events after this id"
+// @Success      200
+// @Router       /events [get]
+func StreamEvents(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	serveEvents(c, user.ID)
+}
+
+// LiveStreamEvents godoc
+// @Summary      Server-Sent Events stream of a live stream's audience
+// @Description  Streams the tip events of a live stream to its viewers, for the overlay in the stream chat. Replay and heartbeats work as for /events. No session is needed.
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        id             path      string  true   "Stream ID"
+// @Param        last_event_id  query     string  false  "Replay events after this id"
+// @Success      200
+// @Failure      404 {object} gin.H
+// @Router       /streams/{id}/events [get]
+func LiveStreamEvents(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid stream ID", err)
+		return
+	}
+	stream, err := (&repository.GormStreamRepository{DB: database.GetDB()}).FindByID(id)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Stream not found", err)
+		return
+	}
+	serveEvents(c, stream.ID)
+}
+
+// serveEvents streams the events published under key until the client goes
+// away.
+func serveEvents(c *gin.Context, key uuid.UUID) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	ctx := c.Request.Context()
+	events, err := realtime.Default().Subscribe(ctx, key, lastEventID)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusServiceUnavailable, "Event stream unavailable", err)
+		return
+	}
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // nginx would buffer the stream otherwise
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", reconnectDelay.Milliseconds())
+	c.Writer.Flush()
+
+	interval := config.AppConfig.EventHeartbeatInterval
+	if interval <= 0 {
+		interval = 25 * time.Second
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
@@ -1,80 +1,184 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"go-backend/database"
 	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// FollowUser handles POST /follow/:id to follow another user.
-func FollowUser(c *gin.Context) {
-	val, exists := c.Get("user")
-	if !exists || val == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
+func followService() *services.FollowService {
+	db := database.GetDB()
+	return services.NewFollowService(
+		&repository.GormFollowRepository{DB: db},
+		&repository.GormUserRepository{DB: db},
+		&repository.GormBlockRepository{DB: db},
+	)
+}
+
+// viewerID is the current user's ID on routes with optional auth, or
+// uuid.Nil for anonymous requests.
+func viewerID(c *gin.Context) uuid.UUID {
+	if user, ok := utils.GetCurrentUser(c); ok && user != nil {
+		return user.ID
 	}
-	user := val.(*models.User)
+	return uuid.Nil
+}
 
-	targetID := c.Param("id")
-	var target models.User
-	if err := database.DB.First(&target, targetID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
+// abortFollowError maps FollowService errors to HTTP responses.
+func abortFollowError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
+	case errors.Is(err, services.ErrFollowSelf):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrUserBlocked):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
 	}
+}
 
-	// prevent duplicate follows
-	var f models.Follow
-	if err := database.DB.Where("follower_id = ? AND followed_id = ?", user.ID, target.ID).First(&f).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"message": "already following"})
-		return
+func followTarget(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return uuid.Nil, false
 	}
+	return id, true
+}
 
-	follow := models.Follow{FollowerID: user.ID, FollowedID: target.ID}
-	if err := database.DB.Create(&follow).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not follow user"})
+// FollowUser godoc
+// @Summary      Follow a user
+// @Tags         follows
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Failure      400 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /follow/{id} [post]
+func FollowUser(c *gin.Context) {
+	targetID, ok := followTarget(c)
+	if !ok {
 		return
 	}
+	user, _ := utils.GetCurrentUser(c)
+	created, err := followService().Follow(user, targetID)
+	if err != nil {
+		abortFollowError(c, err)
+		return
+	}
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"message": "already following"})
+		return
+	}
+	notify(services.NotificationEvent{Type: models.NotifyFollow, UserID: targetID, Actor: user})
 	c.JSON(http.StatusOK, gin.H{"status": "followed"})
 }
 
-// UnfollowUser handles DELETE /follow/:id.
+// UnfollowUser godoc
+// @Summary      Unfollow a user
+// @Tags         follows
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Router       /follow/{id} [delete]
 func UnfollowUser(c *gin.Context) {
-	val, exists := c.Get("user")
-	if !exists || val == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	targetID, ok := followTarget(c)
+	if !ok {
 		return
 	}
-	user := val.(*models.User)
-
-	targetID := c.Param("id")
-	var f models.Follow
-	if err := database.DB.Where("follower_id = ? AND followed_id = ?", user.ID, targetID).First(&f).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"message": "not following"})
+	user, _ := utils.GetCurrentUser(c)
+	deleted, err := followService().Unfollow(user.ID, targetID)
+	if err != nil {
+		abortFollowError(c, err)
 		return
 	}
-	if err := database.DB.Delete(&f).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not unfollow user"})
+	if !deleted {
+		c.JSON(http.StatusOK, gin.H{"message": "not following"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "unfollowed"})
 }
 
-// GetFollowers returns the list of followers for the current user.
+// GetFollowers godoc
+// @Summary      Followers of the current user, most recent first
+// @Tags         follows
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.FollowUserDTO
+// @Router       /followers [get]
 func GetFollowers(c *gin.Context) {
-	val, exists := c.Get("user")
-	if !exists || val == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
+	user, _ := utils.GetCurrentUser(c)
+	listFollows(c, user.ID, false)
+}
+
+// GetFollowing godoc
+// @Summary      Users the current user follows, most recent first
+// @Tags         follows
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.FollowUserDTO
+// @Router       /following [get]
+func GetFollowing(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	listFollows(c, user.ID, true)
+}
+
+// GetUserFollowers godoc
+// @Summary      Followers of a user, most recent first
+// @Tags         follows
+// @Produce      json
+// @Param        id      path      string  true   "User ID"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.FollowUserDTO
+// @Failure      404 {object} gin.H
+// @Router       /users/{id}/followers [get]
+func GetUserFollowers(c *gin.Context) {
+	if id, ok := followTarget(c); ok {
+		listFollows(c, id, false)
 	}
-	user := val.(*models.User)
+}
 
-	var followers []models.Follow
-	if err := database.DB.Where("followed_id = ?", user.ID).Find(&followers).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+// GetUserFollowing godoc
+// @Summary      Users a user follows, most recent first
+// @Tags         follows
+// @Produce      json
+// @Param        id      path      string  true   "User ID"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.FollowUserDTO
+// @Failure      404 {object} gin.H
+// @Router       /users/{id}/following [get]
+func GetUserFollowing(c *gin.Context) {
+	if id, ok := followTarget(c); ok {
+		listFollows(c, id, true)
+	}
+}
+
+func listFollows(c *gin.Context, userID uuid.UUID, following bool) {
+	limit, offset := utils.GetPagination(c)
+	svc := followService()
+	list := svc.Followers
+	if following {
+		list = svc.Following
+	}
+	users, err := list(viewerID(c), userID, limit, offset)
+	if err != nil {
+		abortFollowError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, followers)
+	c.JSON(http.StatusOK, users)
 }
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"go-backend/policy"
 	"go-backend/services"
+	"go-backend/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,13 +18,17 @@ func InitImageHandler(service *services.ImageService) {
 
 // POST /images/upload
 func UploadImage(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
 	file, err := c.FormFile("image")
 	if err != nil {
 		imageService.Logger.Error("No image file in request")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
 		return
 	}
-	image, err := imageService.UploadImage(file)
+	image, err := imageService.UploadImage(user.ID, file)
 	if err != nil {
 		imageService.Logger.Error("Image upload failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -58,6 +64,15 @@ func DeleteImage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image id"})
 		return
 	}
+	image, err := imageService.GetImage(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyImage(actor, image, utils.StaffTwoFactor(c))) {
+		return
+	}
 	if err := imageService.DeleteImage(id); err != nil {
 		imageService.Logger.Error("Image delete failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
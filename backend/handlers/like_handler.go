@@ -3,10 +3,12 @@ package handlers
 import (
 	"go-backend/database"
 	"go-backend/models"
+	"go-backend/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func ToggleLikePost(c *gin.Context) {
@@ -25,19 +27,25 @@ func ToggleLikePost(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
+	if !checkInteraction(c, post.UserID) {
+		return
+	}
 
 	var like models.Like
 	err = database.DB.Where("user_id = ? AND post_id = ?", user.ID, post.ID).First(&like).Error
 
 	if err == nil {
 		// Already liked → delete
-		if err := database.DB.Delete(&like).Error; err != nil {
+		res := database.DB.Delete(&like)
+		if res.Error != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not unlike post"})
 			return
 		}
-		post.LikesCount--
-		database.DB.Save(&post)
-		c.JSON(http.StatusOK, gin.H{"message": "Like removed", "likes_count": post.LikesCount})
+		// a concurrent unlike may have removed it first
+		if res.RowsAffected > 0 {
+			addLikes(post.ID, -1)
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Like removed", "likes_count": likesCount(post.ID)})
 		return
 	}
 
@@ -48,8 +56,21 @@ func ToggleLikePost(c *gin.Context) {
 		return
 	}
 
-	post.LikesCount++
-	database.DB.Save(&post)
+	addLikes(post.ID, 1)
+	notify(services.NotificationEvent{Type: models.NotifyLike, UserID: post.UserID, Actor: user, SubjectID: &post.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Like added", "likes_count": likesCount(post.ID)})
+}
+
+// addLikes changes the post's like counter in place, so concurrent likes
+// are not lost the way saving the whole post would lose them.
+func addLikes(postID uuid.UUID, delta int) {
+	database.DB.Model(&models.Post{}).Where("id = ?", postID).
+		UpdateColumn("likes_count", gorm.Expr("likes_count + ?", delta))
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Like added", "likes_count": post.LikesCount})
+func likesCount(postID uuid.UUID) int {
+	var count int
+	database.DB.Model(&models.Post{}).Where("id = ?", postID).Select("likes_count").Scan(&count)
+	return count
 }
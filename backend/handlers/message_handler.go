@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/realtime"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func messageService() *services.MessageService {
+	db := database.GetDB()
+	return services.NewMessageService(
+		&repository.GormMessageRepository{DB: db},
+		&repository.GormUserRepository{DB: db},
+		&repository.GormModelProfileRepository{DB: db},
+		&repository.GormBlockRepository{DB: db},
+	)
+}
+
+// abortMessageError maps MessageService errors to HTTP responses.
+func abortMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Not found", err)
+	case errors.Is(err, services.ErrMessageSelf),
+		errors.Is(err, services.ErrEmptyMessage),
+		errors.Is(err, services.ErrPaidMessageAttachments):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrNotCreatorConversation),
+		errors.Is(err, services.ErrConversationBlocked),
+		errors.Is(err, services.ErrUserBlocked):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, services.ErrMessageFree),
+		errors.Is(err, services.ErrMessageUnlocked):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		utils.AbortWithError(c, http.StatusPaymentRequired, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+func conversationID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid conversation ID", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetConversations godoc
+// @Summary      Conversations of the current user, most recent activity first
+// @Tags         messages
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.ConversationResponseDTO
+// @Router       /conversations [get]
+func GetConversations(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	convs, err := messageService().Conversations(user.ID, limit, offset)
+	if err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, convs)
+}
+
+// OpenConversation godoc
+// @Summary      Start or reopen a conversation with another user
+// @Description  One of the two users must be a creator. Returns 201 when the conversation is new.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ConversationCreateDTO  true  "Other user"
+// @Success      200 {object} dto.ConversationResponseDTO
+// @Success      201 {object} dto.ConversationResponseDTO
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /conversations [post]
+func OpenConversation(c *gin.Context) {
+	var input dto.ConversationCreateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	conv, created, err := messageService().Open(user, uuid.MustParse(input.UserID))
+	if err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, conv)
+}
+
+// GetMessages godoc
+// @Summary      Messages of a conversation, newest first
+// @Tags         messages
+// @Produce      json
+// @Param        id      path      string  true   "Conversation ID"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.MessageResponseDTO
+// @Failure      404 {object} gin.H
+// @Router       /conversations/{id}/messages [get]
+func GetMessages(c *gin.Context) {
+	id, ok := conversationID(c)
+	if !ok {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	messages, err := messageService().Messages(user.ID, id, limit, offset)
+	if err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// SendMessage godoc
+// @Summary      Send a message
+// @Description  A price above zero locks the attachments until the recipient pays; only verified creators may set one.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                 true  "Conversation ID"
+// @Param        input  body      dto.MessageCreateDTO   true  "Message"
+// @Success      201 {object} dto.MessageResponseDTO
+// @Failure      400 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Router       /conversations/{id}/messages [post]
+func SendMessage(c *gin.Context) {
+	id, ok := conversationID(c)
+	if !ok {
+		return
+	}
+	var input dto.MessageCreateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	if input.Price > 0 {
+		profileRepo := &repository.GormModelProfileRepository{DB: database.GetDB()}
+		profile, err := profileRepo.FindByUserID(user.ID)
+		if err != nil {
+			utils.AbortWithError(c, http.StatusForbidden, "Only creators can send paid messages", err)
+			return
+		}
+		if !utils.Authorize(c, policy.RequireVerifiedCreator(&profile)) {
+			return
+		}
+	}
+	msg, err := messageService().Send(user, id, input)
+	if err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, msg)
+}
+
+// MarkConversationRead godoc
+// @Summary      Mark a conversation as read up to now
+// @Tags         messages
+// @Param        id   path      string  true  "Conversation ID"
+// @Success      204
+// @Failure      404 {object} gin.H
+// @Router       /conversations/{id}/read [post]
+func MarkConversationRead(c *gin.Context) {
+	id, ok := conversationID(c)
+	if !ok {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	if err := messageService().MarkRead(user.ID, id); err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// BlockConversation godoc
+// @Summary      Block the other member of a conversation
+// @Description  While either member blocks, neither can send messages.
+// @Tags         messages
+// @Param        id   path      string  true  "Conversation ID"
+// @Success      204
+// @Failure      404 {object} gin.H
+// @Router       /conversations/{id}/block [post]
+func BlockConversation(c *gin.Context) {
+	setConversationBlocked(c, true)
+}
+
+// UnblockConversation godoc
+// @Summary      Unblock the other member of a conversation
+// @Tags         messages
+// @Param        id   path      string  true  "Conversation ID"
+// @Success      204
+// @Failure      404 {object} gin.H
+// @Router       /conversations/{id}/block [delete]
+func UnblockConversation(c *gin.Context) {
+	setConversationBlocked(c, false)
+}
+
+func setConversationBlocked(c *gin.Context, blocked bool) {
+	id, ok := conversationID(c)
+	if !ok {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	if err := messageService().SetBlocked(user.ID, id, blocked); err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockMessage godoc
+// @Summary      Pay for a paid message to see its attachments
+// @Tags         messages
+// @Produce      json
+// @Param        id   path      string  true  "Message ID"
+// @Success      200 {object} dto.MessageResponseDTO
+// @Failure      402 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /messages/{id}/unlock [post]
+func UnlockMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	msg, err := messageService().Unlock(user, id)
+	if err != nil {
+		abortMessageError(c, err)
+		return
+	}
+	var buyer models.User
+	if err := database.DB.Select("balance").First(&buyer, "id = ?", user.ID).Error; err == nil {
+		realtime.Publish(user.ID, realtime.EventBalance, gin.H{"balance": buyer.Balance})
+	}
+	c.JSON(http.StatusOK, msg)
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"go-backend/database"
 	"go-backend/models"
 	"net/http"
 
 	"go-backend/dto"
+	"go-backend/policy"
 	"go-backend/repository"
 	"go-backend/services"
 	"go-backend/utils"
@@ -23,9 +25,17 @@ type ModelProfileInput struct {
 
 func GetModelProfiles(c *gin.Context) {
 	limit, offset := utils.GetPagination(c)
+	hidden, err := blockService().SearchHidden(viewerID(c))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
 	profileRepo := &repository.GormModelProfileRepository{DB: database.GetDB()}
 	service := services.NewModelProfileService(profileRepo)
-	resp, err := service.GetModelProfiles(limit, offset)
+	resp, err := service.GetModelProfiles(limit, offset, hidden)
+	if err == nil {
+		err = followService().DecorateProfiles(viewerID(c), resp)
+	}
 	if err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		return
@@ -45,9 +55,39 @@ func GetModelProfileByID(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
 		return
 	}
-	c.JSON(http.StatusOK, profile)
+	renderModelProfile(c, &profile)
 }
 
+// renderModelProfile responds with the profile and the viewer's view of its
+// follow graph. Hidden profiles and those of banned or deactivated accounts
+// are only shown to their owner and moderators, and profiles look missing to
+// users blocked by or blocking their owner. The User must be preloaded.
+func renderModelProfile(c *gin.Context, profile *models.ModelProfile) {
+	if profile.HiddenAt != nil || profile.User.Withdrawn() {
+		actor, _ := utils.GetCurrentUser(c)
+		if err := policy.CanModifyModelProfile(actor, profile, utils.StaffTwoFactor(c)); err != nil {
+			utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
+			return
+		}
+	}
+	if err := blockService().CheckInteraction(viewerID(c), profile.UserID); err != nil {
+		if errors.Is(err, services.ErrUserBlocked) {
+			utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
+		} else {
+			utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		}
+		return
+	}
+	resp := []dto.ModelProfileResponseDTO{services.ToModelProfileResponseDTO(profile)}
+	if err := followService().DecorateProfiles(viewerID(c), resp); err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp[0])
+}
+
+// CreateModelProfile lets staff create an unverified profile for any user.
+// Everyone else becomes a creator through SubmitCreatorApplication.
 func CreateModelProfile(c *gin.Context) {
 	var input dto.ModelProfileCreateDTO
 	if !utils.BindAndValidate(c, &input) {
@@ -71,10 +111,14 @@ func UpdateModelProfile(c *gin.Context) {
 		return
 	}
 	var existing models.ModelProfile
-	if err := database.DB.Preload("User").First(&existing, id).Error; err != nil {
+	if err := database.DB.Preload("User").First(&existing, "id = ?", id).Error; err != nil {
 		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyModelProfile(actor, &existing, utils.StaffTwoFactor(c))) {
+		return
+	}
 	var input struct {
 		Name   string `json:"name"`
 		Bio    string `json:"bio"`
@@ -90,7 +134,7 @@ func UpdateModelProfile(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to update model profile", err)
 		return
 	}
-	database.DB.Preload("User").First(&existing, id)
+	database.DB.Preload("User").First(&existing, "id = ?", id)
 	c.JSON(http.StatusOK, existing)
 }
 
@@ -102,10 +146,14 @@ func DeleteModelProfile(c *gin.Context) {
 		return
 	}
 	var profile models.ModelProfile
-	if err := database.DB.First(&profile, id).Error; err != nil {
+	if err := database.DB.First(&profile, "id = ?", id).Error; err != nil {
 		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyModelProfile(actor, &profile, utils.StaffTwoFactor(c))) {
+		return
+	}
 	if err := database.DB.Delete(&profile).Error; err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to delete model profile", err)
 		return
@@ -121,9 +169,9 @@ func GetModelProfileByUserID(c *gin.Context) {
 		return
 	}
 	var profile models.ModelProfile
-	if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+	if err := database.DB.Preload("User").Where("user_id = ?", userID).First(&profile).Error; err != nil {
 		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
 		return
 	}
-	c.JSON(http.StatusOK, profile)
+	renderModelProfile(c, &profile)
 }
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func notificationService() *services.NotificationService {
+	db := database.GetDB()
+	return services.NewNotificationService(&repository.GormNotificationRepository{DB: db}, &repository.GormBlockRepository{DB: db})
+}
+
+// notify records a notification as a side effect of a request. Failures are
+// logged by the service and never fail the request.
+func notify(e services.NotificationEvent) {
+	_ = notificationService().Notify(e)
+}
+
+// abortNotificationError maps NotificationService errors to HTTP responses.
+func abortNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Notification not found", err)
+	case errors.Is(err, services.ErrNotificationType):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// GetNotifications godoc
+// @Summary      Notification inbox of the current user, most recent first
+// @Tags         notifications
+// @Produce      json
+// @Param        unread  query     bool    false  "Only unread notifications"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.NotificationResponseDTO
+// @Router       /notifications [get]
+func GetNotifications(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	notifications, err := notificationService().Inbox(user.ID, c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// GetUnreadNotificationCount godoc
+// @Summary      Number of unread notifications
+// @Tags         notifications
+// @Produce      json
+// @Success      200 {object} gin.H
+// @Router       /notifications/unread-count [get]
+func GetUnreadNotificationCount(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	count, err := notificationService().UnreadCount(user.ID)
+	if err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// MarkNotificationRead godoc
+// @Summary      Mark a notification as read
+// @Tags         notifications
+// @Param        id   path      string  true  "Notification ID"
+// @Success      204
+// @Failure      404 {object} gin.H
+// @Router       /notifications/{id}/read [post]
+func MarkNotificationRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid notification ID", err)
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	if err := notificationService().MarkRead(user.ID, id); err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary      Mark every notification as read
+// @Tags         notifications
+// @Produce      json
+// @Success      200 {object} gin.H
+// @Router       /notifications/read-all [post]
+func MarkAllNotificationsRead(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	n, err := notificationService().MarkAllRead(user.ID)
+	if err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": n})
+}
+
+// GetNotificationPreferences godoc
+// @Summary      Which notification types the current user receives
+// @Tags         notifications
+// @Produce      json
+// @Success      200 {object} dto.NotificationPreferencesDTO
+// @Router       /notifications/preferences [get]
+func GetNotificationPreferences(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	prefs, err := notificationService().Preferences(user.ID)
+	if err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary      Turn notification types on or off
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.NotificationPreferencesDTO  true  "Types to change, e.g. {\"like\": false}"
+// @Success      200 {object} dto.NotificationPreferencesDTO
+// @Failure      400 {object} gin.H
+// @Router       /notifications/preferences [put]
+func UpdateNotificationPreferences(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	var input dto.NotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	prefs, err := notificationService().UpdatePreferences(user.ID, input)
+	if err != nil {
+		abortNotificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func opsService() *services.OpsService {
+	return services.NewOpsService(&repository.GormOpsConfirmationRepository{DB: database.GetDB()})
+}
+
+func abortOpsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownOperation):
+		utils.AbortWithError(c, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, services.ErrOperationDisabled), errors.Is(err, services.ErrOpsConfirmation):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Operation failed", err)
+	}
+}
+
+// ConfirmOperation godoc
+// @Summary      Get a confirmation token for a maintenance operation
+// @Description  The token lets the caller run the operation once within 5 minutes. Destructive operations (seed) are refused unless ALLOW_DESTRUCTIVE_OPS is set.
+// @Tags         admin
+// @Produce      json
+// @Param        operation  path      string  true  "migrate or seed"
+// @Success      201 {object} services.OpsConfirmation
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /admin/ops/{operation}/confirmation [post]
+func ConfirmOperation(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	confirmation, err := opsService().Confirm(user.ID, c.Param("operation"))
+	if err != nil {
+		abortOpsError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, confirmation)
+}
+
+// RunOperation godoc
+// @Summary      Run a maintenance operation
+// @Description  migrate applies the schema; seed replaces the data with sample data. The confirmation token is used up by the run; a second run needs a new one.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        operation  path      string               true  "migrate or seed"
+// @Param        input      body      dto.RunOperationDTO  true  "Confirmation token"
+// @Success      200 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /admin/ops/{operation} [post]
+func RunOperation(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	var input dto.RunOperationDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	name := c.Param("operation")
+	if err := opsService().Run(user.ID, name, input.Confirm); err != nil {
+		abortOpsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"operation": name, "message": "Operation completed"})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"go-backend/dto"
+	"go-backend/policy"
 	"go-backend/repository"
 	"go-backend/services"
 	"go-backend/utils"
@@ -14,11 +15,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// GetOrders lists the caller's orders, or everyone's for holders of
+// orders:read.
 func GetOrders(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
+	userID := &user.ID
+	if policy.Can(user, policy.PermReadOrders) {
+		if !utils.Authorize(c, policy.RequireTwoFactor(user, utils.StaffTwoFactor(c))) {
+			return
+		}
+		userID = nil
+	}
 	limit, offset := utils.GetPagination(c)
 	orderRepo := &repository.GormOrderRepository{DB: database.GetDB()}
 	service := services.NewOrderService(orderRepo)
-	resp, err := service.GetOrders(limit, offset)
+	resp, err := service.GetOrders(userID, limit, offset)
 	if err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to get orders", err)
 		return
@@ -38,6 +52,10 @@ func GetOrderByID(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "Order not found", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.OwnerOr(actor, order.UserID, policy.PermReadOrders, utils.StaffTwoFactor(c))) {
+		return
+	}
 	c.JSON(http.StatusOK, order)
 }
 
@@ -46,6 +64,10 @@ func CreateOrder(c *gin.Context) {
 	if !utils.BindAndValidate(c, &input) {
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.OwnerOr(actor, input.UserID, policy.PermManageOrders, utils.StaffTwoFactor(c))) {
+		return
+	}
 	orderRepo := &repository.GormOrderRepository{DB: database.GetDB()}
 	service := services.NewOrderService(orderRepo)
 	resp, err := service.CreateOrder(&input)
@@ -68,9 +90,19 @@ func UpdateOrder(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "Order not found", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyOrder(actor, &order, utils.StaffTwoFactor(c))) {
+		return
+	}
+	ownerID := order.UserID
 	if !utils.BindAndValidate(c, &order) {
 		return
 	}
+	// The body must not move the order to another ID or, unless order manager, another user.
+	order.ID = id
+	if !policy.Can(actor, policy.PermManageOrders) {
+		order.UserID = ownerID
+	}
 	if err := database.DB.Save(&order).Error; err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to update order", err)
 		return
@@ -85,7 +117,16 @@ func DeleteOrder(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusBadRequest, "Invalid order ID", err)
 		return
 	}
-	if err := database.DB.Delete(&models.Order{}, "id = ?", id).Error; err != nil {
+	var order models.Order
+	if err := database.DB.First(&order, "id = ?", id).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Order not found", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyOrder(actor, &order, utils.StaffTwoFactor(c))) {
+		return
+	}
+	if err := database.DB.Delete(&order).Error; err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to delete order", err)
 		return
 	}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// payoutService aborts with 500 when PAYOUT_PROVIDER is misconfigured.
+func payoutService(c *gin.Context) (*services.PayoutService, bool) {
+	provider, err := services.NewPayoutProvider(config.AppConfig.PayoutProvider)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return nil, false
+	}
+	return services.NewPayoutService(&repository.GormPayoutRepository{DB: database.GetDB()}, provider), true
+}
+
+// abortPayoutError maps PayoutService errors to HTTP responses.
+func abortPayoutError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Payout not found", err)
+	case errors.Is(err, services.ErrPayoutBelowMinimum), errors.Is(err, services.ErrPayoutCurrency),
+		errors.Is(err, services.ErrPayoutRefRequired), errors.Is(err, services.ErrPayoutReasonRequired):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, services.ErrPayoutReviewed):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// GetPayoutBalance godoc
+// @Summary      Earnings balance of the current creator
+// @Tags         payouts
+// @Produce      json
+// @Success      200 {object} services.PayoutBalance
+// @Router       /payouts/balance [get]
+func GetPayoutBalance(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	balance, err := svc.Balance(user.ID)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetEarnings godoc
+// @Summary      Earnings ledger of the current creator, newest first
+// @Tags         payouts
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.Earning
+// @Router       /payouts/earnings [get]
+func GetEarnings(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	earnings, err := svc.Earnings(user.ID, limit, offset)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, earnings)
+}
+
+// GetPayouts godoc
+// @Summary      Payout history of the current creator
+// @Tags         payouts
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.Payout
+// @Router       /payouts [get]
+func GetPayouts(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	payouts, err := svc.History(user.ID, limit, offset)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payouts)
+}
+
+// RequestPayout godoc
+// @Summary      Request a withdrawal to a crypto address
+// @Description  Only verified creators with a session that passed 2FA. The amount is reserved until staff review it.
+// @Tags         payouts
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.PayoutRequestDTO  true  "Amount, currency and address"
+// @Success      201 {object} models.Payout
+// @Failure      400 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /payouts [post]
+func RequestPayout(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	var input dto.PayoutRequestDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	profileRepo := &repository.GormModelProfileRepository{DB: database.GetDB()}
+	var profile *models.ModelProfile
+	if p, err := profileRepo.FindByUserID(user.ID); err == nil {
+		profile = &p
+	}
+	if !utils.Authorize(c, policy.RequireVerifiedCreator(profile)) {
+		return
+	}
+	if !utils.Authorize(c, policy.RequireTwoFactor(user, utils.TwoFactorPassed(c))) {
+		return
+	}
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	payout, err := svc.Request(user, services.PayoutRequest{Amount: input.Amount, Currency: input.Currency, Address: input.Address})
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, payout)
+}
+
+// GetPayoutQueue godoc
+// @Summary      Payout requests, pending ones oldest first
+// @Tags         admin
+// @Produce      json
+// @Param        status  query     string  false  "pending (default), processing, paid, rejected, failed or all"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.Payout
+// @Router       /admin/payouts [get]
+func GetPayoutQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.PayoutPending)
+	switch status {
+	case "all":
+		status = ""
+	case models.PayoutPending, models.PayoutProcessing, models.PayoutPaid, models.PayoutRejected, models.PayoutFailed:
+	default:
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid status", fmt.Errorf("unknown status %q", status))
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	payouts, err := svc.Queue(status, limit, offset)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payouts)
+}
+
+// ApprovePayout godoc
+// @Summary      Approve and send a payout
+// @Description  With PAYOUT_PROVIDER=manual staff transfer the funds themselves and pass the transaction hash as tx_ref.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                true   "Payout ID"
+// @Param        input  body      dto.PayoutApproveDTO  false  "Transaction hash of a manual transfer"
+// @Success      200 {object} models.Payout
+// @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/payouts/{id}/approve [post]
+func ApprovePayout(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid payout ID", err)
+		return
+	}
+	var input dto.PayoutApproveDTO
+	if c.Request.ContentLength != 0 && !utils.BindAndValidate(c, &input) {
+		return
+	}
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	reviewer, _ := utils.GetCurrentUser(c)
+	payout, err := svc.Approve(c.Request.Context(), reviewer, id, input.TxRef)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payout)
+}
+
+// RejectPayout godoc
+// @Summary      Reject a payout and release the reserved amount
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string               true  "Payout ID"
+// @Param        input  body      dto.PayoutRejectDTO  true  "Reason shown to the creator"
+// @Success      200 {object} models.Payout
+// @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/payouts/{id}/reject [post]
+func RejectPayout(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid payout ID", err)
+		return
+	}
+	var input dto.PayoutRejectDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	svc, ok := payoutService(c)
+	if !ok {
+		return
+	}
+	reviewer, _ := utils.GetCurrentUser(c)
+	payout, err := svc.Reject(reviewer, id, input.Reason)
+	if err != nil {
+		abortPayoutError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payout)
+}
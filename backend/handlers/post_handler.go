@@ -4,6 +4,8 @@ import (
 	"go-backend/database"
 	"go-backend/dto"
 	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
 	"go-backend/services"
 	"go-backend/utils"
 	"net/http"
@@ -16,8 +18,13 @@ import (
 
 func GetPosts(c *gin.Context) {
 	limit, offset := utils.GetPagination(c)
-	postRepo := services.NewPostService(nil) // Replace with DI if available
-	resp, err := postRepo.GetPosts(limit, offset)
+	hidden, err := blockService().FeedHidden(viewerID(c))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to get posts", err)
+		return
+	}
+	postRepo := services.NewPostService(&repository.GormPostRepository{DB: database.GetDB()})
+	resp, err := postRepo.GetPosts(limit, offset, hidden)
 	if err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to get posts", err)
 		return
@@ -33,11 +40,11 @@ func GetPostByID(c *gin.Context) {
 		return
 	}
 	var post models.Post
-	err = database.DB.
-		Preload("User").
+	err = database.DB.Unscoped().
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
 		Preload("Media").
 		Preload("ModelProfile").
-		Preload("Comments").
+		Preload("Comments", "hidden_at IS NULL").
 		Preload("Comments.User").
 		First(&post, "id = ?", id).Error
 
@@ -45,6 +52,35 @@ func GetPostByID(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
 		return
 	}
+	bought := post.DeletedAt.Valid && boughtDeletedPost(c, &post)
+	if post.DeletedAt.Valid && !bought {
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", gorm.ErrRecordNotFound)
+		return
+	}
+	// Hidden posts and posts of banned or deactivated accounts are only shown
+	// to their author and moderators. Buyers keep posts of authors who left,
+	// but not of banned ones.
+	left := bought && post.User.Status != models.AccountBanned
+	if post.HiddenAt != nil || (post.User.Withdrawn() && !left) {
+		actor, _ := utils.GetCurrentUser(c)
+		if err := policy.CanModifyPost(actor, &post, utils.StaffTwoFactor(c)); err != nil {
+			utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+			return
+		}
+	}
+	// Posts of users the viewer is blocked with do not exist for them, and
+	// comments by blocked or muted users are left out.
+	blocks := blockService()
+	if err := blocks.CheckInteraction(viewerID(c), post.UserID); err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+		return
+	}
+	hidden, err := blocks.FeedHidden(viewerID(c))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	post.Comments = visibleComments(post.Comments, hidden)
 
 	userVal, exists := c.Get("user")
 	if exists {
@@ -58,31 +94,116 @@ func GetPostByID(c *gin.Context) {
 	c.JSON(http.StatusOK, post)
 }
 
+// boughtDeletedPost reports whether the viewer bought the post before its
+// author deleted it. Posts removed by moderation are gone for buyers too.
+func boughtDeletedPost(c *gin.Context, post *models.Post) bool {
+	viewer, ok := utils.GetCurrentUser(c)
+	if !ok || post.DeletedBy != models.DeletedByOwner {
+		return false
+	}
+	purchases := &repository.GormPurchaseRepository{DB: database.GetDB()}
+	purchase, err := purchases.FindByUserAndPost(viewer.ID, post.ID)
+	return err == nil && purchase.Completed
+}
+
+// visibleComments drops the comments written by hiddenUserIDs.
+func visibleComments(comments []models.Comment, hiddenUserIDs []uuid.UUID) []models.Comment {
+	if len(hiddenUserIDs) == 0 {
+		return comments
+	}
+	hidden := make(map[uuid.UUID]bool, len(hiddenUserIDs))
+	for _, id := range hiddenUserIDs {
+		hidden[id] = true
+	}
+	visible := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if !hidden[comment.UserID] {
+			visible = append(visible, comment)
+		}
+	}
+	return visible
+}
+
 func CreatePost(c *gin.Context) {
 	var input struct {
-		Text      string    `json:"text"`
-		IsPremium bool      `json:"isPremium"`
-		UserID    uuid.UUID `json:"userId"`
-		ModelID   uuid.UUID `json:"modelId"`
+		Text      string `json:"text"`
+		IsPremium bool   `json:"isPremium"`
+		Price     int    `json:"price" validate:"min=0"`
 	}
 	if !utils.BindAndValidate(c, &input) {
 		return
 	}
-	postRepo := services.NewPostService(nil) // Replace with DI if available
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
+	// Posts are always published under the caller's own model profile.
+	profileRepo := &repository.GormModelProfileRepository{DB: database.GetDB()}
+	profile, err := profileRepo.FindByUserID(user.ID)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusForbidden, "Only models can create posts", err)
+		return
+	}
+	if input.IsPremium && !utils.Authorize(c, policy.RequireVerifiedCreator(&profile)) {
+		return
+	}
+	postRepo := services.NewPostService(&repository.GormPostRepository{DB: database.GetDB()})
 	dto := &dto.PostCreateDTO{
 		Text:      input.Text,
 		IsPremium: input.IsPremium,
-		UserID:    input.UserID,
-		ModelID:   input.ModelID,
+		Price:     input.Price,
+		UserID:    user.ID,
+		ModelID:   profile.ID,
 	}
 	resp, err := postRepo.CreatePost(dto)
 	if err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to create post", err)
 		return
 	}
+	_ = notificationService().NotifyFollowers(user, resp.ID)
 	c.JSON(http.StatusCreated, resp)
 }
 
+// CreateComment godoc
+// @Summary      Comment on a post
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string                true  "Post ID"
+// @Param        input  body      dto.CommentCreateDTO  true  "Comment text"
+// @Success      201 {object} models.Comment
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /posts/{id}/comments [post]
+func CreateComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid post ID", err)
+		return
+	}
+	var input dto.CommentCreateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	var post models.Post
+	if err := database.DB.Select("id", "user_id").First(&post, "id = ?", id).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+		return
+	}
+	if !checkInteraction(c, post.UserID) {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	comment := models.Comment{ID: uuid.New(), PostID: post.ID, UserID: user.ID, Text: input.Text, Time: time.Now()}
+	if err := database.DB.Create(&comment).Error; err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to create comment", err)
+		return
+	}
+	notify(services.NotificationEvent{Type: models.NotifyComment, UserID: post.UserID, Actor: user, SubjectID: &post.ID})
+	comment.User = *user
+	c.JSON(http.StatusCreated, comment)
+}
+
 func UpdatePost(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -95,9 +216,14 @@ func UpdatePost(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyPost(actor, &post, utils.StaffTwoFactor(c))) {
+		return
+	}
 	var input struct {
 		Text        string `json:"text"`
 		IsPremium   bool   `json:"isPremium"`
+		Price       *int   `json:"price" validate:"omitempty,min=0"` // unchanged when omitted
 		PublishedAt string `json:"published_time"`
 		Media       struct {
 			Type     string `json:"type"`
@@ -105,19 +231,27 @@ func UpdatePost(c *gin.Context) {
 			Cover    string `json:"cover"`
 			Duration int    `json:"duration"`
 		} `json:"media"`
+		// Only presentation fields of the author can be edited through a post;
+		// email and balance are managed by the user and payment endpoints.
 		Model struct {
 			Name     string `json:"name"`
 			Nickname string `json:"nickname"`
-			Email    string `json:"email"`
 			Avatar   string `json:"avatarUrl"`
-			Balance  int    `json:"balance"`
 		} `json:"model"`
 	}
 	if !utils.BindAndValidate(c, &input) {
 		return
 	}
+	// Premium posts need a verified creator; posts that already are premium
+	// stay editable.
+	if input.IsPremium && !post.IsPremium && !utils.Authorize(c, policy.RequireVerifiedCreator(&post.ModelProfile)) {
+		return
+	}
 	post.Text = input.Text
 	post.IsPremium = input.IsPremium
+	if input.Price != nil {
+		post.Price = *input.Price
+	}
 	parsedTime, err := time.Parse(time.RFC3339, input.PublishedAt)
 	if err != nil {
 		utils.AbortWithError(c, http.StatusBadRequest, "invalid published_time format (use RFC3339)", err)
@@ -138,9 +272,7 @@ func UpdatePost(c *gin.Context) {
 	}
 	post.ModelProfile.Name = input.Model.Name
 	post.User.Nickname = input.Model.Nickname
-	post.User.Email = input.Model.Email
 	post.User.AvatarURL = input.Model.Avatar
-	post.User.Balance = input.Model.Balance
 	if err := tx.Save(&post.User).Error; err != nil {
 		tx.Rollback()
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to update user", err)
@@ -168,7 +300,22 @@ func DeletePost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
 		return
 	}
-	if err := database.DB.Delete(&models.Post{}, "id = ?", id).Error; err != nil {
+	var post models.Post
+	if err := database.DB.Preload("ModelProfile").First(&post, "id = ?", id).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyPost(actor, &post, utils.StaffTwoFactor(c))) {
+		return
+	}
+	// Buyers keep posts their author deleted, not those removed by staff.
+	by := models.DeletedByModeration
+	if actor.ID == post.UserID || actor.ID == post.ModelProfile.UserID {
+		by = models.DeletedByOwner
+	}
+	postRepo := &repository.GormPostRepository{DB: database.GetDB()}
+	if err := postRepo.Delete(post.ID, by); err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to delete post", err)
 		return
 	}
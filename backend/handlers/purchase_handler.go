@@ -7,12 +7,14 @@ import (
 	"net/http"
 
 	"go-backend/dto"
+	"go-backend/realtime"
 	"go-backend/repository"
 	"go-backend/services"
 	"go-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type PurchaseRequest struct {
@@ -33,10 +35,31 @@ func BuyContent(c *gin.Context) {
 		return
 	}
 	resp, err := service.BuyContent(user, &input)
-	if err != nil {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+		return
+	case errors.Is(err, services.ErrNotPremium):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	case errors.Is(err, services.ErrAlreadyPurchased):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+		return
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		utils.AbortWithError(c, http.StatusPaymentRequired, err.Error(), err)
+		return
+	case err != nil:
 		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to buy content", err)
 		return
 	}
+	if post, err := postRepo.FindByID(resp.PostID); err == nil {
+		notify(services.NotificationEvent{Type: models.NotifyPurchase, UserID: post.UserID, Actor: user, SubjectID: &post.ID})
+	}
+	realtime.Publish(user.ID, realtime.EventPurchase, resp)
+	var buyer models.User
+	if err := database.DB.Select("balance").First(&buyer, "id = ?", user.ID).Error; err == nil {
+		realtime.Publish(user.ID, realtime.EventBalance, gin.H{"balance": buyer.Balance})
+	}
 	c.JSON(http.StatusCreated, resp)
 }
 
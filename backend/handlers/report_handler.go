@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func reportService() *services.ReportService {
+	db := database.GetDB()
+	return services.NewReportService(&repository.GormReportRepository{DB: db}, &repository.GormMessageRepository{DB: db})
+}
+
+// abortReportError maps ReportService errors to HTTP responses.
+func abortReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Not found", err)
+	case errors.Is(err, services.ErrReportTarget),
+		errors.Is(err, services.ErrReportReason),
+		errors.Is(err, services.ErrReportOwnContent),
+		errors.Is(err, services.ErrModerationAction),
+		errors.Is(err, services.ErrSuspensionDuration):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, repository.ErrDuplicateReport),
+		errors.Is(err, services.ErrCaseResolved),
+		errors.Is(err, services.ErrCaseClaimed):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+func caseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid case ID", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// CreateReport godoc
+// @Summary      Report a post, comment, model profile or message
+// @Description  Content is hidden once REPORT_HIDE_THRESHOLD users reported it, until a moderator resolves the case.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ReportCreateDTO  true  "Target and reason"
+// @Success      201 {object} models.Report
+// @Failure      400 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /reports [post]
+func CreateReport(c *gin.Context) {
+	var input dto.ReportCreateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	report, err := reportService().Report(user, input)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetMyReports godoc
+// @Summary      Reports filed by the current user, newest first
+// @Tags         reports
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} models.Report
+// @Router       /reports [get]
+func GetMyReports(c *gin.Context) {
+	limit, offset := utils.GetPagination(c)
+	user, _ := utils.GetCurrentUser(c)
+	reports, err := reportService().Mine(user.ID, limit, offset)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// GetModerationQueue godoc
+// @Summary      Moderation queue, oldest first
+// @Tags         admin
+// @Produce      json
+// @Param        status       query     string  false  "open (default), claimed, resolved or all"
+// @Param        target_type  query     string  false  "post, comment, profile or message"
+// @Param        limit        query     int     false  "Limit"
+// @Param        offset       query     int     false  "Offset"
+// @Success      200 {array} models.ModerationCase
+// @Router       /admin/moderation/cases [get]
+func GetModerationQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.CaseOpen)
+	switch status {
+	case "all":
+		status = ""
+	case models.CaseOpen, models.CaseClaimed, models.CaseResolved:
+	default:
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid status", fmt.Errorf("unknown status %q", status))
+		return
+	}
+	targetType := c.Query("target_type")
+	if targetType != "" && !isReportTarget(targetType) {
+		abortReportError(c, services.ErrReportTarget)
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	cases, err := reportService().Queue(status, targetType, limit, offset)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+// GetModerationCase godoc
+// @Summary      Moderation case with its reports
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Case ID"
+// @Success      200 {object} models.ModerationCase
+// @Failure      404 {object} gin.H
+// @Router       /admin/moderation/cases/{id} [get]
+func GetModerationCase(c *gin.Context) {
+	id, ok := caseID(c)
+	if !ok {
+		return
+	}
+	mc, err := reportService().Case(id)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, mc)
+}
+
+// ClaimModerationCase godoc
+// @Summary      Claim a moderation case
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Case ID"
+// @Success      200 {object} models.ModerationCase
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/moderation/cases/{id}/claim [post]
+func ClaimModerationCase(c *gin.Context) {
+	id, ok := caseID(c)
+	if !ok {
+		return
+	}
+	moderator, _ := utils.GetCurrentUser(c)
+	mc, err := reportService().Claim(moderator, id)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, mc)
+}
+
+// ResolveModerationCase godoc
+// @Summary      Resolve a moderation case
+// @Description  dismiss shows hidden content again, hide keeps it hidden, warn notifies the author and suspend hides the content and locks the author's account for suspend_days.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string              true  "Case ID"
+// @Param        input  body      dto.ResolveCaseDTO  true  "Action and note"
+// @Success      200 {object} models.ModerationCase
+// @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/moderation/cases/{id}/resolve [post]
+func ResolveModerationCase(c *gin.Context) {
+	id, ok := caseID(c)
+	if !ok {
+		return
+	}
+	var input dto.ResolveCaseDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	moderator, _ := utils.GetCurrentUser(c)
+	mc, err := reportService().Resolve(moderator, id, input)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	if mc.Action == models.ActionWarn {
+		_ = notificationService().Warn(mc.TargetUserID, mc.TargetID)
+	}
+	c.JSON(http.StatusOK, mc)
+}
+
+// GetModerationHistory godoc
+// @Summary      Resolved moderation cases, most recently resolved first
+// @Tags         admin
+// @Produce      json
+// @Param        target_type  query     string  false  "post, comment, profile or message"
+// @Param        target_id    query     string  false  "Content ID"
+// @Param        user_id      query     string  false  "Author of the content"
+// @Param        limit        query     int     false  "Limit"
+// @Param        offset       query     int     false  "Offset"
+// @Success      200 {array} models.ModerationCase
+// @Router       /admin/moderation/history [get]
+func GetModerationHistory(c *gin.Context) {
+	filter := repository.HistoryFilter{TargetType: c.Query("target_type")}
+	if filter.TargetType != "" && !isReportTarget(filter.TargetType) {
+		abortReportError(c, services.ErrReportTarget)
+		return
+	}
+	for param, dst := range map[string]**uuid.UUID{"target_id": &filter.TargetID, "user_id": &filter.TargetUserID} {
+		if v := c.Query(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				utils.AbortWithError(c, http.StatusBadRequest, "Invalid "+param, err)
+				return
+			}
+			*dst = &id
+		}
+	}
+	limit, offset := utils.GetPagination(c)
+	cases, err := reportService().History(filter, limit, offset)
+	if err != nil {
+		abortReportError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+func isReportTarget(t string) bool {
+	for _, known := range models.ReportTargets {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func retentionService() *services.RetentionService {
+	return services.NewRetentionService(&repository.GormRetentionRepository{DB: database.GetDB()})
+}
+
+// restore undeletes the content of the given kind named by the :id param.
+func restore(c *gin.Context, kind string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	err = retentionService().Restore(actor, kind, id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Restored"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Not found", err)
+	case errors.Is(err, repository.ErrNotDeleted):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// RestorePost godoc
+// @Summary      Restore a deleted post
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Post ID"
+// @Success      200 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/posts/{id}/restore [post]
+func RestorePost(c *gin.Context) {
+	restore(c, services.RestorePost)
+}
+
+// RestoreUser godoc
+// @Summary      Restore a deleted account
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/users/{id}/restore [post]
+func RestoreUser(c *gin.Context) {
+	restore(c, services.RestoreUser)
+}
+
+// RestoreVideo godoc
+// @Summary      Restore a deleted video
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Video ID"
+// @Success      200 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/videos/{id}/restore [post]
+func RestoreVideo(c *gin.Context) {
+	restore(c, services.RestoreVideo)
+}
+
+// RestoreImage godoc
+// @Summary      Restore a deleted image
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Image ID"
+// @Success      200 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Failure      409 {object} gin.H
+// @Router       /admin/images/{id}/restore [post]
+func RestoreImage(c *gin.Context) {
+	restore(c, services.RestoreImage)
+}
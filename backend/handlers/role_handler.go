@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newRoleService() *services.RoleService {
+	db := database.GetDB()
+	return services.NewRoleService(&repository.GormRoleRepository{DB: db}, &repository.GormUserRepository{DB: db})
+}
+
+// abortRoleError maps RoleService errors to HTTP responses.
+func abortRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
+	case errors.Is(err, services.ErrUnknownRole), errors.Is(err, services.ErrImplicitRole), errors.Is(err, services.ErrRoleReasonNeeded):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrRoleAlreadyHeld), errors.Is(err, services.ErrRoleNotHeld), errors.Is(err, services.ErrLastAdmin):
+		utils.AbortWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// GetRoles godoc
+// @Summary      List roles and their permissions
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array} dto.RoleDTO
+// @Router       /admin/roles [get]
+func GetRoles(c *gin.Context) {
+	roles := make([]dto.RoleDTO, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		perms := []string{}
+		for _, p := range policy.RolePermissions(role) {
+			perms = append(perms, string(p))
+		}
+		roles = append(roles, dto.RoleDTO{Role: string(role), Permissions: perms})
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetUserRoles godoc
+// @Summary      Roles granted to a user
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {array} models.UserRole
+// @Failure      404 {object} gin.H
+// @Router       /admin/users/{id}/roles [get]
+func GetUserRoles(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	roles, err := newRoleService().RolesOf(id)
+	if err != nil {
+		abortRoleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GrantRole godoc
+// @Summary      Grant a role to a user
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string            true  "User ID"
+// @Param        input  body      dto.RoleGrantDTO  true  "Role and reason"
+// @Success      201 {array} models.UserRole
+// @Failure      409 {object} gin.H
+// @Router       /admin/users/{id}/roles [post]
+func GrantRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var input dto.RoleGrantDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	roles, err := newRoleService().Grant(actor, id, models.Role(input.Role), input.Reason)
+	if err != nil {
+		abortRoleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, roles)
+}
+
+// RevokeRole godoc
+// @Summary      Revoke a role from a user
+// @Tags         admin
+// @Produce      json
+// @Param        id      path      string  true  "User ID"
+// @Param        role    path      string  true  "Role"
+// @Param        reason  query     string  true  "Why the role is revoked"
+// @Success      200 {array} models.UserRole
+// @Failure      409 {object} gin.H
+// @Router       /admin/users/{id}/roles/{role} [delete]
+func RevokeRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	roles, err := newRoleService().Revoke(actor, id, models.Role(c.Param("role")), c.Query("reason"))
+	if err != nil {
+		abortRoleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetRoleChanges godoc
+// @Summary      Audit trail of role changes
+// @Tags         admin
+// @Produce      json
+// @Param        user_id   query     string  false  "Changes of this user"
+// @Param        actor_id  query     string  false  "Changes made by this user"
+// @Param        role      query     string  false  "Role"
+// @Param        limit     query     int     false  "Limit"
+// @Param        offset    query     int     false  "Offset"
+// @Success      200 {array} models.RoleChange
+// @Router       /admin/roles/changes [get]
+func GetRoleChanges(c *gin.Context) {
+	limit, offset := utils.GetPagination(c)
+	filter := repository.RoleChangeFilter{Role: models.Role(c.Query("role")), Limit: limit, Offset: offset}
+	var ok bool
+	if filter.UserID, ok = uuidQuery(c, "user_id"); !ok {
+		return
+	}
+	if filter.ActorID, ok = uuidQuery(c, "actor_id"); !ok {
+		return
+	}
+	changes, err := newRoleService().Changes(filter)
+	if err != nil {
+		abortRoleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// uuidQuery parses an optional UUID query parameter. It aborts with 400 and
+// returns false when the value is malformed.
+func uuidQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid "+name, err)
+		return nil, false
+	}
+	return &id, true
+}
@@ -12,8 +12,10 @@ import (
 
 	"go-backend/database"
 	"go-backend/models"
+	"go-backend/policy"
 	"go-backend/repository"
 	"go-backend/services"
+	"go-backend/utils"
 )
 
 // ToggleSavePost adds or removes a post from user's saved list.
@@ -74,16 +76,30 @@ func GetSavedPosts(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
-// GetPurchasedPosts returns posts purchased by the specified user.
+// GetPurchasedPosts returns posts purchased by the specified user, including
+// those their author deleted since. Only the buyer and holders of
+// orders:read may list them; posts of users the buyer is blocked with are
+// left out.
 func GetPurchasedPosts(c *gin.Context) {
-	userID := c.Param("id")
-	var posts []models.Post
-	if err := database.DB.Joins("JOIN purchases ON purchases.post_id = posts.id").
-		Where("purchases.user_id = ?", userID).
-		Preload("User").Preload("Media").Preload("ModelProfile").
-		Find(&posts).Error; err != nil {
-		c.Error(err)
-		c.AbortWithStatus(http.StatusInternalServerError)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.OwnerOr(actor, userID, policy.PermReadOrders, utils.StaffTwoFactor(c))) {
+		return
+	}
+	hidden, err := blockService().SearchHidden(userID)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to get purchased posts", err)
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	purchases := &repository.GormPurchaseRepository{DB: database.GetDB()}
+	posts, err := purchases.PurchasedPosts(userID, hidden, limit, offset)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Failed to get purchased posts", err)
 		return
 	}
 	c.JSON(http.StatusOK, posts)
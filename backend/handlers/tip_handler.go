@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/realtime"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func tipService() *services.TipService {
+	db := database.GetDB()
+	return services.NewTipService(
+		&repository.GormTipRepository{DB: db},
+		&repository.GormPostRepository{DB: db},
+		&repository.GormStreamRepository{DB: db},
+		&repository.GormMessageRepository{DB: db},
+		&repository.GormBlockRepository{DB: db},
+	)
+}
+
+// abortTipError maps TipService errors to HTTP responses.
+func abortTipError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.AbortWithError(c, http.StatusNotFound, "Tip target not found", err)
+	case errors.Is(err, services.ErrTipSelf),
+		errors.Is(err, services.ErrTipAmount),
+		errors.Is(err, services.ErrTipContext):
+		utils.AbortWithError(c, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, services.ErrUserBlocked):
+		utils.AbortWithError(c, http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		utils.AbortWithError(c, http.StatusPaymentRequired, err.Error(), err)
+	default:
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+// tipProfile loads the model profile of the :id path parameter.
+func tipProfile(c *gin.Context) (*models.ModelProfile, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return nil, false
+	}
+	profile, err := (&repository.GormModelProfileRepository{DB: database.GetDB()}).FindByID(id)
+	if err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Profile not found", err)
+		return nil, false
+	}
+	return &profile, true
+}
+
+// TipModel godoc
+// @Summary      Tip a creator
+// @Description  Charges the wallet and credits the creator like a purchase. Optionally for one post or live stream of the creator or a message in the conversation with them, with a note. The creator is notified and gets a tip event; tips for a live stream also go to its viewers on /streams/{id}/events.
+// @Tags         tips
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string            true  "Model profile ID"
+// @Param        input  body      dto.TipCreateDTO  true  "Tip"
+// @Success      201 {object} dto.TipResponseDTO
+// @Failure      400 {object} gin.H
+// @Failure      402 {object} gin.H
+// @Failure      403 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /models/{id}/tips [post]
+func TipModel(c *gin.Context) {
+	profile, ok := tipProfile(c)
+	if !ok {
+		return
+	}
+	var input dto.TipCreateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	if !utils.Authorize(c, policy.RequireVerifiedCreator(profile)) {
+		return
+	}
+	user, _ := utils.GetCurrentUser(c)
+	tip, err := tipService().Tip(user, profile, input)
+	if err != nil {
+		abortTipError(c, err)
+		return
+	}
+	notify(services.NotificationEvent{Type: models.NotifyTip, UserID: profile.UserID, Actor: user, SubjectID: tip.PostID})
+	realtime.Publish(profile.UserID, realtime.EventTip, tip)
+	if tip.StreamID != nil {
+		realtime.PublishStream(*tip.StreamID, realtime.EventTip, tip)
+	}
+	var tipper models.User
+	if err := database.DB.Select("balance").First(&tipper, "id = ?", user.ID).Error; err == nil {
+		realtime.Publish(user.ID, realtime.EventBalance, gin.H{"balance": tipper.Balance})
+	}
+	c.JSON(http.StatusCreated, tip)
+}
+
+// GetModelTips godoc
+// @Summary      Tips a creator received, newest first
+// @Description  Visible to the creator and to payout managers.
+// @Tags         tips
+// @Produce      json
+// @Param        id      path      string  true   "Model profile ID"
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.TipResponseDTO
+// @Failure      403 {object} gin.H
+// @Router       /models/{id}/tips [get]
+func GetModelTips(c *gin.Context) {
+	profile, ok := tipProfile(c)
+	if !ok {
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.OwnerOr(actor, profile.UserID, policy.PermManagePayouts, utils.StaffTwoFactor(c))) {
+		return
+	}
+	limit, offset := utils.GetPagination(c)
+	tips, err := tipService().Received(profile.ID, limit, offset)
+	if err != nil {
+		abortTipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tips)
+}
+
+// GetTips godoc
+// @Summary      Tips the current user sent, newest first
+// @Tags         tips
+// @Produce      json
+// @Param        limit   query     int     false  "Limit"
+// @Param        offset  query     int     false  "Offset"
+// @Success      200 {array} dto.TipResponseDTO
+// @Router       /tips [get]
+func GetTips(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	limit, offset := utils.GetPagination(c)
+	tips, err := tipService().Sent(user.ID, limit, offset)
+	if err != nil {
+		abortTipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tips)
+}
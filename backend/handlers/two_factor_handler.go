@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTwoFactorService() *services.TwoFactorService {
+	return services.NewTwoFactorService(&repository.GormTwoFactorRepository{DB: database.GetDB()}, newAuthService())
+}
+
+// abortTwoFactorError maps TwoFactorService errors to HTTP responses.
+func abortTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		utils.AbortWithCode(c, http.StatusUnauthorized, "invalid_2fa_code", err.Error())
+	case errors.Is(err, services.ErrTwoFactorLocked):
+		utils.AbortWithCode(c, http.StatusTooManyRequests, "two_factor_locked", err.Error())
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		utils.AbortWithCode(c, http.StatusConflict, "two_factor_enabled", err.Error())
+	case errors.Is(err, services.ErrTwoFactorNotEnabled), errors.Is(err, services.ErrTwoFactorNotSetUp):
+		utils.AbortWithCode(c, http.StatusConflict, "two_factor_not_enabled", err.Error())
+	default:
+		abortAuthError(c, err)
+	}
+}
+
+// currentUser returns the authenticated user or aborts with 401.
+func currentUser(c *gin.Context) (*models.User, bool) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return nil, false
+	}
+	return user, true
+}
+
+// GetTwoFactorStatus godoc
+// @Summary      Two-factor status of the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} dto.TwoFactorStatusDTO
+// @Router       /auth/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	status, err := newTwoFactorService().Status(user)
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupTwoFactor godoc
+// @Summary      Start TOTP enrollment
+// @Description  Returns the secret and an otpauth:// URI to show as a QR code. 2FA stays off until /auth/2fa/enable
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} dto.TwoFactorSetupDTO
+// @Failure      409 {object} gin.H
+// @Router       /auth/2fa/setup [post]
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	setup, err := newTwoFactorService().Setup(user)
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// EnableTwoFactor godoc
+// @Summary      Confirm TOTP enrollment
+// @Description  Returns one-time recovery codes and a new session; all other sessions end
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.TwoFactorCodeDTO  true  "Code from the authenticator app"
+// @Success      200 {object} dto.TwoFactorEnabledDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/2fa/enable [post]
+func EnableTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var input dto.TwoFactorCodeDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	resp, err := newTwoFactorService().Enable(user, input.Code, sessionMeta(c))
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyTwoFactor godoc
+// @Summary      Pass two-factor verification
+// @Description  With challenge_token completes a password login; without it upgrades the current (e.g. Firebase) session. Returns a session that has passed 2FA
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.TwoFactorVerifyDTO  true  "Code or recovery code"
+// @Success      200 {object} dto.AuthResponseDTO
+// @Failure      401 {object} gin.H
+// @Failure      429 {object} gin.H
+// @Router       /auth/2fa/verify [post]
+func VerifyTwoFactor(c *gin.Context) {
+	var input dto.TwoFactorVerifyDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	var user *models.User
+	if input.ChallengeToken != "" {
+		u, err := newAuthService().ParseChallenge(input.ChallengeToken)
+		if err != nil {
+			abortAuthError(c, err)
+			return
+		}
+		user = u
+	} else {
+		u, ok := currentUser(c)
+		if !ok {
+			return
+		}
+		user = u
+	}
+	tokens, err := newTwoFactorService().Verify(user, input.Code, input.RecoveryCode, sessionMeta(c))
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.AuthResponseDTO{User: services.ToUserResponseDTO(user), Tokens: tokens})
+}
+
+// DisableTwoFactor godoc
+// @Summary      Turn off two-factor authentication
+// @Description  All sessions end; returns a new one
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.TwoFactorDisableDTO  true  "Code or recovery code"
+// @Success      200 {object} dto.TokenPairDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var input dto.TwoFactorDisableDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	tokens, err := newTwoFactorService().Disable(user, input.Code, input.RecoveryCode, sessionMeta(c))
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Replace the recovery codes
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.TwoFactorCodeDTO  true  "Code from the authenticator app"
+// @Success      200 {object} dto.RecoveryCodesDTO
+// @Failure      401 {object} gin.H
+// @Router       /auth/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var input dto.TwoFactorCodeDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	codes, err := newTwoFactorService().RegenerateRecoveryCodes(user, input.Code)
+	if err != nil {
+		abortTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.RecoveryCodesDTO{RecoveryCodes: codes})
+}
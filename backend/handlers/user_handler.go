@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"go-backend/database"
 	"go-backend/dto"
 	"go-backend/models"
+	"go-backend/policy"
 	"go-backend/repository"
 	"go-backend/services"
 	"go-backend/utils"
@@ -25,6 +27,9 @@ func GetUsers(c *gin.Context) {
 	userRepo := &repository.GormUserRepository{DB: database.GetDB()}
 	service := services.NewUserService(userRepo)
 	resp, err := service.GetUsers(limit, offset)
+	if err == nil {
+		err = followService().DecorateUsers(viewerID(c), resp)
+	}
 	if err != nil {
 		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
 		return
@@ -34,10 +39,11 @@ func GetUsers(c *gin.Context) {
 
 // GetUserByID godoc
 // @Summary      Get user by ID
+// @Description  The user and holders of users:read get the full account; everyone else a public summary (dto.PublicUserDTO).
 // @Tags         users
 // @Produce      json
-// @Param        id   path      int  true  "User ID"
-// @Success      200 {object} models.User
+// @Param        id   path      string  true  "User ID"
+// @Success      200 {object} dto.UserResponseDTO
 // @Failure      404 {object} gin.H
 // @Router       /users/{id} [get]
 func GetUserByID(c *gin.Context) {
@@ -53,7 +59,17 @@ func GetUserByID(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	users := []dto.UserResponseDTO{resp}
+	if err := followService().DecorateUsers(viewerID(c), users); err != nil {
+		utils.AbortWithError(c, http.StatusInternalServerError, "Internal server error", err)
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if policy.OwnerOr(actor, id, policy.PermReadUsers, utils.StaffTwoFactor(c)) != nil {
+		c.JSON(http.StatusOK, services.ToPublicUserDTO(users[0]))
+		return
+	}
+	c.JSON(http.StatusOK, users[0])
 }
 
 // CreateUser godoc
@@ -66,6 +82,10 @@ func GetUserByID(c *gin.Context) {
 // @Failure      400 {object} gin.H
 // @Router       /users [post]
 func CreateUser(c *gin.Context) {
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequirePermission(actor, policy.PermManageUsers)) {
+		return
+	}
 	var input dto.UserCreateDTO
 	if !utils.BindAndValidate(c, &input) {
 		return
@@ -91,9 +111,10 @@ func CreateUser(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id    path      int         true  "User ID"
-// @Param        user  body      models.User true  "User"
+// @Param        user  body      dto.UserUpdateDTO true  "User"
 // @Success      200 {object} models.User
 // @Failure      400 {object} gin.H
+// @Failure      409 {object} gin.H
 // @Router       /users/{id} [put]
 func UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -102,30 +123,41 @@ func UpdateUser(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyUser(actor, id, utils.StaffTwoFactor(c))) {
+		return
+	}
 	userRepo := &repository.GormUserRepository{DB: database.GetDB()}
 	service := services.NewUserService(userRepo)
-	userModel, err := service.GetUserByID(id)
+	var input dto.UserUpdateDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	user, err := userRepo.FindByID(id)
 	if err != nil {
 		utils.AbortWithError(c, http.StatusNotFound, "User not found", err)
 		return
 	}
-	var input models.User
-	if !utils.BindAndValidate(c, &input) {
-		return
+	if input.Email != "" && input.Email != user.Email {
+		// A new address has to be confirmed again.
+		user.Email = input.Email
+		user.EmailVerifiedAt = nil
 	}
-	user := models.User{
-		ID:           userModel.ID,
-		Email:        input.Email,
-		Nickname:     input.Nickname,
-		Password:     input.Password,
-		Balance:      userModel.Balance,
-		AvatarURL:    userModel.AvatarURL,
-		IsAdmin:      userModel.IsAdmin,
-		ReferralCode: userModel.ReferralCode,
-		ReferredBy:   input.ReferredBy,
+	if input.Nickname != "" {
+		user.Nickname = input.Nickname
+	}
+	if input.AvatarURL != "" {
+		user.AvatarURL = input.AvatarURL
 	}
 	if err := service.UpdateUser(&user); err != nil {
-		utils.AbortWithError(c, http.StatusInternalServerError, "Could not update user", err)
+		switch {
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.AbortWithCode(c, http.StatusConflict, "email_taken", err.Error())
+		case errors.Is(err, services.ErrNicknameTaken):
+			utils.AbortWithCode(c, http.StatusConflict, "nickname_taken", err.Error())
+		default:
+			utils.AbortWithError(c, http.StatusInternalServerError, "Could not update user", err)
+		}
 		return
 	}
 	resp, _ := service.GetUserByID(id)
@@ -146,6 +178,10 @@ func DeleteUser(c *gin.Context) {
 		utils.AbortWithError(c, http.StatusBadRequest, "Invalid user ID", err)
 		return
 	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyUser(actor, id, utils.StaffTwoFactor(c))) {
+		return
+	}
 	userRepo := &repository.GormUserRepository{DB: database.GetDB()}
 	service := services.NewUserService(userRepo)
 	if err := service.DeleteUser(id); err != nil {
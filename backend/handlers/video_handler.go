@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"go-backend/policy"
 	"go-backend/services"
+	"go-backend/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +18,10 @@ func InitVideoHandler(service *services.VideoService) {
 
 // POST /videos/upload
 func UploadVideo(c *gin.Context) {
+	user, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.RequireUser(user)) {
+		return
+	}
 	title := c.PostForm("title")
 	file, err := c.FormFile("video")
 	if err != nil || title == "" {
@@ -23,7 +29,7 @@ func UploadVideo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing title or video file"})
 		return
 	}
-	video, err := videoService.UploadVideo(title, file)
+	video, err := videoService.UploadVideo(user.ID, title, file)
 	if err != nil {
 		videoService.Logger.Error("Video upload failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -59,6 +65,15 @@ func DeleteVideo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid video id"})
 		return
 	}
+	video, err := videoService.GetVideo(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+	actor, _ := utils.GetCurrentUser(c)
+	if !utils.Authorize(c, policy.CanModifyVideo(actor, video, utils.StaffTwoFactor(c))) {
+		return
+	}
 	if err := videoService.DeleteVideo(id); err != nil {
 		videoService.Logger.Error("Video delete failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/tracking"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sessionHeader lets clients keep anonymous views apart when many viewers
+// share an address.
+const sessionHeader = "X-Session-ID"
+
+// viewer identifies who viewed for deduplication: the user when signed in,
+// otherwise a hash of the client session, or of address and user agent.
+func viewer(c *gin.Context) (string, *uuid.UUID) {
+	if user, ok := utils.GetCurrentUser(c); ok && user != nil {
+		id := user.ID
+		return "u:" + id.String(), &id
+	}
+	session := c.GetHeader(sessionHeader)
+	if session == "" {
+		session = c.ClientIP() + "|" + c.Request.UserAgent()
+	}
+	sum := sha256.Sum256([]byte(session))
+	return "s:" + hex.EncodeToString(sum[:]), nil
+}
+
+// RecordPostView godoc
+// @Summary      Record a view of a post or one of its media items
+// @Description  Views by the same viewer within VIEW_DEDUP_WINDOW count once. Views are written asynchronously, so counters lag by a few seconds.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string           true   "Post ID"
+// @Param        input  body      dto.PostViewDTO  false  "Viewed media item"
+// @Success      202 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /posts/{id}/view [post]
+func RecordPostView(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid post ID", err)
+		return
+	}
+	var input dto.PostViewDTO
+	if c.Request.ContentLength != 0 && !utils.BindAndValidate(c, &input) {
+		return
+	}
+	var post models.Post
+	if err := database.DB.Select("id").First(&post, "id = ?", postID).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Post not found", err)
+		return
+	}
+	view := tracking.View{PostID: postID}
+	if input.MediaID != "" {
+		mediaID := uuid.MustParse(input.MediaID)
+		var media models.Media
+		if err := database.DB.Select("id").First(&media, "id = ? AND post_id = ?", mediaID, postID).Error; err != nil {
+			utils.AbortWithError(c, http.StatusNotFound, "Media not found", err)
+			return
+		}
+		view.MediaID = &mediaID
+	}
+	view.Viewer, view.UserID = viewer(c)
+	counted := tracking.Default().RecordView(view)
+	c.JSON(http.StatusAccepted, gin.H{"counted": counted})
+}
+
+// RecordWatchProgress godoc
+// @Summary      Report playback progress of a video in a post
+// @Description  Clients report the position periodically and when playback stops. A viewer completes the video at 90% of its duration.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "Post ID"
+// @Param        mediaId  path      string                true  "Media ID of the video"
+// @Param        input    body      dto.WatchProgressDTO  true  "Position and, if unknown to the server, duration in seconds"
+// @Success      202
+// @Failure      400 {object} gin.H
+// @Failure      404 {object} gin.H
+// @Router       /posts/{id}/media/{mediaId}/progress [post]
+func RecordWatchProgress(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid post ID", err)
+		return
+	}
+	mediaID, err := uuid.Parse(c.Param("mediaId"))
+	if err != nil {
+		utils.AbortWithError(c, http.StatusBadRequest, "Invalid media ID", err)
+		return
+	}
+	var input dto.WatchProgressDTO
+	if !utils.BindAndValidate(c, &input) {
+		return
+	}
+	var media models.Media
+	if err := database.DB.First(&media, "id = ? AND post_id = ?", mediaID, postID).Error; err != nil {
+		utils.AbortWithError(c, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	if media.Type != "video" {
+		utils.AbortWithError(c, http.StatusBadRequest, "Media is not a video", errors.New("progress reported for "+media.Type))
+		return
+	}
+	duration := media.Duration
+	if duration <= 0 {
+		duration = input.Duration
+	}
+	if duration <= 0 {
+		utils.AbortWithError(c, http.StatusBadRequest, "Duration is required", errors.New("video has no known duration"))
+		return
+	}
+	progress := tracking.Progress{PostID: postID, MediaID: mediaID, Position: input.Position, Duration: duration}
+	progress.Viewer, progress.UserID = viewer(c)
+	tracking.Default().RecordProgress(progress)
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-backend/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FileMailer writes every message as an .eml file into Dir and logs it
+// instead of delivering it. Used in local development and tests.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	body, err := encode(m.From, msg)
+	if err != nil {
+		return err
+	}
+	// Names sort in the order the messages were sent.
+	name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102-150405.000000000"), uuid.NewString()[:8])
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+	logging.GetLogger().Info("Email written to file",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("path", path))
+	return nil
+}
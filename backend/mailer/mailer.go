@@ -0,0 +1,75 @@
+// Package mailer sends transactional emails. Messages are rendered from the
+// embedded templates and delivered by a Mailer chosen from the config: SMTP
+// in production, files on disk for local development and tests.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-backend/config"
+)
+
+// Message is a rendered email ready for delivery.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Mailer
+)
+
+// SetMailer overrides the mailer returned by Get.
+func SetMailer(m Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = m
+}
+
+// Get returns the configured mailer, building it from config.AppConfig on
+// first use.
+func Get() (Mailer, error) {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+	if m != nil {
+		return m, nil
+	}
+	m, err := FromConfig(config.AppConfig)
+	if err != nil {
+		return nil, err
+	}
+	SetMailer(m)
+	return m, nil
+}
+
+// FromConfig builds the mailer selected by MAIL_DRIVER.
+func FromConfig(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("mailer: SMTP_HOST is not set")
+		}
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}, nil
+	case "file", "":
+		return &FileMailer{Dir: cfg.MailDir, From: cfg.MailFrom}, nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown MAIL_DRIVER %q", cfg.MailDriver)
+	}
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Each email is a pair of templates: <name>.txt defines the "subject" and
+// "text" blocks, <name>.html the HTML body. Both get the same data.
+//
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+)
+
+// Template names.
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplatePasswordReset = "password_reset"
+)
+
+// Render builds a message for recipient to from the named template.
+func Render(name, to string, data any) (Message, error) {
+	txt := textTemplates.Lookup(name + ".txt")
+	html := htmlTemplates.Lookup(name + ".html")
+	if txt == nil || html == nil {
+		return Message{}, fmt.Errorf("mailer: unknown template %q", name)
+	}
+	var subject, text, body bytes.Buffer
+	if err := txt.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := txt.ExecuteTemplate(&text, "text", data); err != nil {
+		return Message{}, err
+	}
+	if err := html.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    body.String(),
+	}, nil
+}
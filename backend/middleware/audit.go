@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"go-backend/database"
+	"go-backend/logging"
+	"go-backend/policy"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Audit records successful requests of staff to the route in the audit log
+// under action, with the changes they made to target. Requests of other
+// users pass through unrecorded.
+func Audit(action string, target services.AuditTarget) gin.HandlerFunc {
+	param := target.Param
+	if param == "" {
+		param = "id"
+	}
+	return func(c *gin.Context) {
+		actor, _ := utils.GetCurrentUser(c)
+		if !policy.IsStaff(actor) {
+			c.Next()
+			return
+		}
+		logger := logging.GetLogger()
+		svc := services.NewAuditService(&repository.GormAuditRepository{DB: database.GetDB()})
+		id := c.Param(param)
+		before, err := svc.Snapshot(target, id)
+		if err != nil {
+			logger.Warn("Audit snapshot failed", zap.String("action", action), zap.String("entity_id", id), zap.Error(err))
+		}
+		c.Next()
+		if c.Writer.Status() >= 400 {
+			return
+		}
+		after, err := svc.Snapshot(target, id)
+		if err != nil {
+			logger.Warn("Audit snapshot failed", zap.String("action", action), zap.String("entity_id", id), zap.Error(err))
+		}
+		_ = svc.Record(services.AuditRecord{
+			Actor:     actor,
+			Action:    action,
+			Target:    target,
+			EntityID:  id,
+			Before:    before,
+			After:     after,
+			RequestID: logging.GetRequestID(c),
+			IP:        c.ClientIP(),
+		})
+	}
+}
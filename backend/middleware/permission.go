@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"go-backend/policy"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through only if the current user holds
+// perm through one of their roles. When REQUIRE_STAFF_2FA is on (the default)
+// the session must also have passed two-factor verification; Firebase
+// sessions get there by exchanging a code at /auth/2fa/verify.
+func RequirePermission(perm policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, _ := utils.GetCurrentUser(c)
+		if !utils.Authorize(c, policy.RequirePermission(user, perm)) {
+			return
+		}
+		if !utils.Authorize(c, policy.RequireTwoFactor(user, utils.StaffTwoFactor(c))) {
+			return
+		}
+		c.Next()
+	}
+}
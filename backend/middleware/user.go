@@ -2,32 +2,117 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"go-backend/database"
+	"go-backend/models"
 	"go-backend/repository"
 	"go-backend/services"
+	"go-backend/utils"
 
+	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// UserMiddleware validates Firebase token and loads user into context.
-func UserMiddleware(logger *zap.Logger) gin.HandlerFunc {
+// AuthMode declares whether a route requires an authenticated user.
+type AuthMode int
+
+const (
+	// OptionalAuth loads the user when a valid token is sent and lets
+	// anonymous requests through. Used for public GETs.
+	OptionalAuth AuthMode = iota
+	// RequireAuth rejects requests without a valid token with 401.
+	RequireAuth
+)
+
+// TokenVerifier verifies an ID token. *auth.Client satisfies it; tests
+// install their own with SetTokenVerifier.
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
+}
+
+var tokenVerifier TokenVerifier
+
+// SetTokenVerifier overrides the verifier used by UserMiddleware.
+func SetTokenVerifier(v TokenVerifier) {
+	tokenVerifier = v
+}
+
+func getTokenVerifier() TokenVerifier {
+	if tokenVerifier != nil {
+		return tokenVerifier
+	}
+	if client := GetFirebaseAuth(); client != nil {
+		return client
+	}
+	return nil
+}
+
+var errNoVerifier = errors.New("token verification is not configured")
+
+// UserMiddleware validates the bearer token (our own access JWT or a Firebase
+// ID token) and loads the user into context.
+// A request that already has a user (resolved by an earlier UserMiddleware)
+// is not verified twice. An invalid token is always rejected with 401; a
+// missing token is rejected only in RequireAuth mode.
+func UserMiddleware(logger *zap.Logger, mode AuthMode) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if _, ok := utils.GetCurrentUser(c); ok {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
+			if mode == RequireAuth {
+				utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeAuthRequired, "Authentication required")
+				return
+			}
 			c.Next()
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		client := GetFirebaseAuth()
-		decoded, err := client.VerifyIDToken(context.Background(), token)
+		userRepo := &repository.GormUserRepository{DB: database.GetDB()}
+
+		// Our own access JWTs are checked locally; anything else goes to Firebase.
+		authService := services.NewAuthService(
+			userRepo,
+			&repository.GormRefreshTokenRepository{DB: database.GetDB()},
+			&repository.GormTwoFactorRepository{DB: database.GetDB()},
+		)
+		if authService.IsNativeToken(token) {
+			user, mfa, err := authService.Authenticate(token)
+			if err != nil {
+				logger.Warn("invalid access token", zap.Error(err))
+				utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeInvalidToken, "Invalid or expired token")
+				return
+			}
+			if rejectInactive(c, user) {
+				return
+			}
+			c.Set("user", user)
+			if mfa {
+				utils.SetTwoFactorPassed(c)
+			}
+			c.Next()
+			return
+		}
+
+		verifier := getTokenVerifier()
+		if verifier == nil {
+			logger.Warn("invalid firebase token", zap.Error(errNoVerifier))
+			utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeInvalidToken, "Invalid or expired token")
+			return
+		}
+		decoded, err := verifier.VerifyIDToken(c.Request.Context(), token)
 		if err != nil {
 			logger.Warn("invalid firebase token", zap.Error(err))
-			c.Next()
+			utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeInvalidToken, "Invalid or expired token")
 			return
 		}
 
@@ -35,15 +120,67 @@ func UserMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		avatar, _ := decoded.Claims["picture"].(string)
 		refCode := c.GetHeader("X-Referral-Code")
 
-		userRepo := &repository.GormUserRepository{DB: database.GetDB()}
 		userService := services.NewUserService(userRepo)
-		user, err := userService.GetOrCreateUser(email, avatar, "changeme123", refCode)
+		// Firebase accounts get no local password until the user sets one.
+		user, err := userService.GetOrCreateUser(email, avatar, "", refCode)
+		if errors.Is(err, repository.ErrUserDeleted) {
+			utils.AbortWithCode(c, http.StatusUnauthorized, utils.CodeInvalidToken, "Account deleted")
+			return
+		}
 		if err != nil {
 			logger.Error("Failed to get or create user", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
+		// Firebase has already confirmed the address.
+		if verified, _ := decoded.Claims["email_verified"].(bool); verified && user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			if err := userRepo.Update(user); err != nil {
+				logger.Warn("Failed to mark email verified", zap.Error(err))
+			}
+		}
+		if rejectInactive(c, user) {
+			return
+		}
 		c.Set("user", user)
 		c.Next()
 	}
 }
+
+// rejectInactive aborts with 403 unless the user's account is active. A
+// suspension that has run out lets the user in before the sweeper catches up.
+func rejectInactive(c *gin.Context, user *models.User) bool {
+	switch {
+	case user.Status == models.AccountSuspended:
+		if !user.Suspended(time.Now()) {
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":           "Account suspended",
+			"code":            utils.CodeAccountSuspended,
+			"suspended_until": user.SuspendedUntil,
+		})
+	case user.Status == models.AccountBanned:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account banned", "code": utils.CodeAccountBanned})
+	case user.Status == models.AccountDeactivated:
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account deactivated", "code": utils.CodeAccountDeactivated})
+	default:
+		return false
+	}
+	return true
+}
+
+// TokenFromQuery lets a route take the bearer token from the access_token
+// query parameter. Browsers cannot set headers on an EventSource; every other
+// route must keep tokens out of URLs, where they end up in proxy logs.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
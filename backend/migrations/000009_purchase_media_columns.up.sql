@@ -1,3 +1,3 @@
 ALTER TABLE purchases
-  ADD COLUMN photo_id INTEGER REFERENCES media(id),
-  ADD COLUMN video_id UUID REFERENCES videos(id); 
\ No newline at end of file
+  ADD COLUMN photo_id UUID REFERENCES media(id),
+  ADD COLUMN video_id UUID REFERENCES videos(id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_images_user_id;
+DROP INDEX IF EXISTS idx_videos_user_id;
+ALTER TABLE images DROP COLUMN IF EXISTS user_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS user_id;
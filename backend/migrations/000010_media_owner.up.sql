@@ -0,0 +1,8 @@
+-- Track the uploader of standalone videos and images for ownership checks
+ALTER TABLE videos
+    ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE images
+    ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_videos_user_id ON videos(user_id);
+CREATE INDEX idx_images_user_id ON images(user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS refresh_tokens;
+ALTER TABLE users ALTER COLUMN password DROP DEFAULT;
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
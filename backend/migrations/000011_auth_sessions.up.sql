@@ -0,0 +1,22 @@
+-- Native email/password sessions
+ALTER TABLE users
+    ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+
+-- Firebase accounts no longer get a placeholder password
+ALTER TABLE users
+    ALTER COLUMN password SET DEFAULT '';
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    replaced_by_id UUID,
+    user_agent TEXT,
+    ip VARCHAR(64),
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
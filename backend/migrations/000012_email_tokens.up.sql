@@ -0,0 +1,16 @@
+-- Email verification and password reset
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE email_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_email_tokens_user_id ON email_tokens(user_id);
+CREATE INDEX idx_email_tokens_email ON email_tokens(email);
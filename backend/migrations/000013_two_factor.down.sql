@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS mfa;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS two_factors;
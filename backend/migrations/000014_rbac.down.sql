@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN DEFAULT false;
+
+UPDATE users SET is_admin = true
+WHERE id IN (SELECT user_id FROM user_roles WHERE role = 'admin');
+
+DROP TABLE IF EXISTS role_changes;
+DROP TABLE IF EXISTS user_roles;
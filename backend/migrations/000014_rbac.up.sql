@@ -0,0 +1,30 @@
+-- Roles replace the users.is_admin flag
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(32) NOT NULL,
+    granted_by UUID,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, role)
+);
+CREATE INDEX idx_user_roles_role ON user_roles(role);
+
+CREATE TABLE role_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    role VARCHAR(32) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_role_changes_user_id ON role_changes(user_id);
+CREATE INDEX idx_role_changes_actor_id ON role_changes(actor_id);
+CREATE INDEX idx_role_changes_created_at ON role_changes(created_at);
+
+INSERT INTO user_roles (user_id, role)
+SELECT id, 'admin' FROM users WHERE is_admin;
+
+INSERT INTO role_changes (user_id, role, action, reason)
+SELECT id, 'admin', 'grant', 'migrated from users.is_admin' FROM users WHERE is_admin;
+
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1,4 @@
+ALTER TABLE model_profiles DROP COLUMN IF EXISTS verified_at;
+
+DROP TABLE IF EXISTS creator_documents;
+DROP TABLE IF EXISTS creator_applications;
@@ -0,0 +1,36 @@
+-- Creator onboarding: applications with identity documents, reviewed by moderators
+CREATE TABLE creator_applications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    legal_name TEXT NOT NULL,
+    date_of_birth DATE NOT NULL,
+    country VARCHAR(2) NOT NULL,
+    name TEXT NOT NULL,
+    bio TEXT,
+    banner TEXT,
+    reviewer_id UUID,
+    review_reason TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_creator_applications_user_id ON creator_applications(user_id);
+CREATE INDEX idx_creator_applications_status ON creator_applications(status);
+CREATE INDEX idx_creator_applications_created_at ON creator_applications(created_at);
+
+-- Files live in the private store; only their keys are kept here
+CREATE TABLE creator_documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES creator_applications(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    storage_key TEXT NOT NULL,
+    content_type TEXT,
+    size BIGINT,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_creator_documents_application_id ON creator_documents(application_id);
+
+-- Existing profiles start unverified and must apply like everyone else
+ALTER TABLE model_profiles
+    ADD COLUMN verified_at TIMESTAMPTZ;
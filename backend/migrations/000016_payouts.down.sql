@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS earnings;
+
+ALTER TABLE purchases DROP COLUMN IF EXISTS price;
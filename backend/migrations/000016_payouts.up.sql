@@ -0,0 +1,44 @@
+-- Purchases remember what was paid
+ALTER TABLE purchases
+    ADD COLUMN price INTEGER DEFAULT 0;
+
+-- Creator earnings ledger, one row per sale
+CREATE TABLE earnings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    model_id UUID NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    source_id UUID NOT NULL,
+    post_id UUID,
+    gross INTEGER NOT NULL,
+    fee INTEGER NOT NULL,
+    net INTEGER NOT NULL,
+    available_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE UNIQUE INDEX idx_earning_source ON earnings(source, source_id);
+CREATE INDEX idx_earnings_creator_id ON earnings(creator_id);
+CREATE INDEX idx_earnings_model_id ON earnings(model_id);
+CREATE INDEX idx_earnings_post_id ON earnings(post_id);
+CREATE INDEX idx_earnings_available_at ON earnings(available_at);
+CREATE INDEX idx_earnings_created_at ON earnings(created_at);
+
+-- Withdrawal requests
+CREATE TABLE payouts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    currency VARCHAR(16) NOT NULL,
+    address TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    provider VARCHAR(16),
+    provider_ref TEXT,
+    reviewer_id UUID,
+    reason TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_payouts_user_id ON payouts(user_id);
+CREATE INDEX idx_payouts_status ON payouts(status);
+CREATE INDEX idx_payouts_created_at ON payouts(created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_earnings_model_id_created_at;
+DROP INDEX IF EXISTS idx_purchases_post_id_created_at;
+DROP INDEX IF EXISTS idx_follows_followed_id_created_at;
+DROP INDEX IF EXISTS idx_saved_posts_post_id_created_at;
+DROP INDEX IF EXISTS idx_likes_post_id_created_at;
@@ -0,0 +1,6 @@
+-- Creator analytics group events of a model's posts by day
+CREATE INDEX IF NOT EXISTS idx_likes_post_id_created_at ON likes(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saved_posts_post_id_created_at ON saved_posts(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_follows_followed_id_created_at ON follows(followed_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_purchases_post_id_created_at ON purchases(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_earnings_model_id_created_at ON earnings(model_id, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS video_watches;
+DROP TABLE IF EXISTS post_views;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS views_count;
@@ -0,0 +1,34 @@
+-- Aggregated view counter, kept in step with post_views by the tracker
+ALTER TABLE posts
+    ADD COLUMN views_count INTEGER NOT NULL DEFAULT 0;
+
+-- Deduplicated post and media views
+CREATE TABLE post_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    media_id UUID,
+    user_id UUID,
+    viewer VARCHAR(66) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_post_views_post_id_created_at ON post_views(post_id, created_at);
+CREATE INDEX idx_post_views_media_id ON post_views(media_id);
+CREATE INDEX idx_post_views_user_id ON post_views(user_id);
+CREATE INDEX idx_post_views_created_at ON post_views(created_at);
+
+-- Furthest position of each viewer in each post video
+CREATE TABLE video_watches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    media_id UUID NOT NULL,
+    viewer VARCHAR(66) NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID,
+    position INTEGER NOT NULL,
+    duration INTEGER NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE UNIQUE INDEX idx_video_watch_viewer ON video_watches(media_id, viewer);
+CREATE INDEX idx_video_watches_post_id ON video_watches(post_id);
+CREATE INDEX idx_video_watches_created_at ON video_watches(created_at);
@@ -0,0 +1,23 @@
+-- In-app notifications; likes, follows, comments and purchases are merged
+-- into one unread row per type and post
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL,
+    subject_id UUID,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_count INTEGER NOT NULL DEFAULT 1,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_notifications_inbox ON notifications(user_id, updated_at);
+CREATE INDEX idx_notifications_unread ON notifications(user_id, type, subject_id) WHERE read_at IS NULL;
+
+-- Notification types a user turned off; missing rows mean enabled
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
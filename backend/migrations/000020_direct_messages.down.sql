@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS message_unlocks;
+DROP TABLE IF EXISTS message_attachments;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversation_members;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,45 @@
+-- One-to-one conversations; pair_key holds both member ids in a fixed order
+CREATE TABLE conversations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    pair_key VARCHAR(73) NOT NULL UNIQUE,
+    last_message_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_conversations_last_message_at ON conversations(last_message_at);
+
+CREATE TABLE conversation_members (
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_at TIMESTAMPTZ,
+    blocked_at TIMESTAMPTZ,
+    PRIMARY KEY (conversation_id, user_id)
+);
+CREATE INDEX idx_conversation_members_user_id ON conversation_members(user_id);
+
+CREATE TABLE messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT,
+    price INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_messages_conversation ON messages(conversation_id, created_at);
+
+CREATE TABLE message_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL,
+    url TEXT NOT NULL
+);
+CREATE INDEX idx_message_attachments_message_id ON message_attachments(message_id);
+
+-- Paid messages a recipient unlocked
+CREATE TABLE message_unlocks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE UNIQUE INDEX idx_message_unlock ON message_unlocks(message_id, user_id);
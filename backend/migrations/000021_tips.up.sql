@@ -0,0 +1,16 @@
+-- Tips to creators, optionally for one post, stream or message
+CREATE TABLE tips (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    model_id UUID NOT NULL REFERENCES model_profiles(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    post_id UUID REFERENCES posts(id) ON DELETE SET NULL,
+    stream_id UUID REFERENCES streams(id) ON DELETE SET NULL,
+    message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_tips_user_id ON tips(user_id);
+CREATE INDEX idx_tips_model ON tips(model_id, created_at);
+CREATE INDEX idx_tips_post_id ON tips(post_id);
+CREATE INDEX idx_tips_stream_id ON tips(stream_id);
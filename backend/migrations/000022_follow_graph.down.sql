@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_follow_pair;
+CREATE INDEX idx_follows_follower_id ON follows(follower_id);
@@ -0,0 +1,8 @@
+-- One follow per pair and direction: drop self-follows and duplicates first
+DELETE FROM follows WHERE follower_id = followed_id;
+DELETE FROM follows f USING follows d
+WHERE f.follower_id = d.follower_id AND f.followed_id = d.followed_id AND f.id > d.id;
+
+-- The pair index also serves lookups by follower_id
+DROP INDEX IF EXISTS idx_follows_follower_id;
+CREATE UNIQUE INDEX idx_follow_pair ON follows(follower_id, followed_id);
@@ -0,0 +1,16 @@
+-- Blocks cut contact between two users both ways; mutes hide content from the muter
+CREATE TABLE user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+CREATE INDEX idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+CREATE TABLE user_mutes (
+    muter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (muter_id, muted_id)
+);
+CREATE INDEX idx_user_mutes_muted_id ON user_mutes(muted_id);
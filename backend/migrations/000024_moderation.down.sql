@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
+ALTER TABLE model_profiles DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS hidden_at;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS moderation_cases;
@@ -0,0 +1,44 @@
+-- Reports on content, grouped into moderation cases
+CREATE TABLE moderation_cases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_type VARCHAR(16) NOT NULL,
+    target_id UUID NOT NULL,
+    target_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    report_count INTEGER NOT NULL DEFAULT 0,
+    auto_hidden BOOLEAN NOT NULL DEFAULT false,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMPTZ,
+    action VARCHAR(16),
+    resolution_note TEXT,
+    suspended_until TIMESTAMPTZ,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_moderation_cases_target ON moderation_cases(target_type, target_id);
+CREATE INDEX idx_moderation_cases_target_user_id ON moderation_cases(target_user_id);
+CREATE INDEX idx_moderation_cases_status ON moderation_cases(status);
+CREATE INDEX idx_moderation_cases_assignee_id ON moderation_cases(assignee_id);
+CREATE INDEX idx_moderation_cases_created_at ON moderation_cases(created_at);
+-- At most one unresolved case per target
+CREATE UNIQUE INDEX idx_moderation_cases_unresolved ON moderation_cases(target_type, target_id) WHERE status <> 'resolved';
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    case_id UUID NOT NULL REFERENCES moderation_cases(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL,
+    details VARCHAR(1000),
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE UNIQUE INDEX idx_report_case_reporter ON reports(case_id, reporter_id);
+CREATE INDEX idx_reports_reporter_id ON reports(reporter_id);
+
+-- Content hidden by moderation, and moderation suspensions
+ALTER TABLE posts ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE comments ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE messages ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE model_profiles ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMPTZ;
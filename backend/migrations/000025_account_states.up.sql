@@ -0,0 +1,22 @@
+-- Account states: active, suspended, banned or deactivated
+ALTER TABLE users
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN status_reason TEXT;
+CREATE INDEX idx_users_status ON users(status);
+
+-- Moderation suspensions still running become suspended accounts
+UPDATE users SET status = 'suspended' WHERE suspended_until > now();
+
+-- Audit trail of account state changes
+CREATE TABLE account_status_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT,
+    until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_account_status_changes_user_id ON account_status_changes(user_id);
+CREATE INDEX idx_account_status_changes_created_at ON account_status_changes(created_at);
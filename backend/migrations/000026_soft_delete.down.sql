@@ -0,0 +1,5 @@
+ALTER TABLE images DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+-- Soft deletion: rows stay restorable until the purge job removes them
+ALTER TABLE posts
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by VARCHAR(16);
+CREATE INDEX idx_posts_deleted_at ON posts(deleted_at);
+
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
+
+ALTER TABLE videos ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_videos_deleted_at ON videos(deleted_at);
+
+ALTER TABLE images ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_images_deleted_at ON images(deleted_at);
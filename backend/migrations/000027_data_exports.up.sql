@@ -0,0 +1,15 @@
+-- Data exports users request of everything kept about them
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    object_key TEXT,
+    size BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id);
+CREATE INDEX idx_data_exports_status ON data_exports(status);
+CREATE INDEX idx_data_exports_expires_at ON data_exports(expires_at);
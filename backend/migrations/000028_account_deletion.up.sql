@@ -0,0 +1,15 @@
+-- Accounts whose personal data was erased
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+-- Erasure requests users can cancel during the grace period
+CREATE TABLE account_deletions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(1000),
+    due_at TIMESTAMPTZ NOT NULL,
+    cancelled_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_account_deletions_user_id ON account_deletions(user_id);
+CREATE INDEX idx_account_deletions_due_at ON account_deletions(due_at);
@@ -0,0 +1,23 @@
+-- Hash-chained log of staff actions. actor_id has no foreign key: entries
+-- are never rewritten, not even when the actor goes away.
+CREATE TABLE audit_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGINT NOT NULL,
+    actor_id UUID,
+    action VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(64),
+    changes TEXT,
+    request_id VARCHAR(64),
+    ip VARCHAR(64),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    prev_hash VARCHAR(64),
+    hash VARCHAR(64) NOT NULL
+);
+CREATE UNIQUE INDEX idx_audit_entries_seq ON audit_entries(seq);
+CREATE UNIQUE INDEX idx_audit_entries_hash ON audit_entries(hash);
+CREATE INDEX idx_audit_entries_actor_id ON audit_entries(actor_id);
+CREATE INDEX idx_audit_entries_action ON audit_entries(action);
+CREATE INDEX idx_audit_entity ON audit_entries(entity_type, entity_id);
+CREATE INDEX idx_audit_entries_request_id ON audit_entries(request_id);
+CREATE INDEX idx_audit_entries_created_at ON audit_entries(created_at);
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_users_referred_by;
+DROP INDEX IF EXISTS idx_unique_purchase;
+
+ALTER TABLE logs ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE referrals ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE saved_posts ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE payments ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE purchases ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE follows ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE likes ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE media ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE model_profiles ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE media ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE posts ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE users ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
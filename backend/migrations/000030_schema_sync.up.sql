@@ -0,0 +1,24 @@
+-- The SQL migrations are the only definition of the schema from here on.
+-- This brings what they built in line with the models.
+
+-- public_id was never used by the application
+ALTER TABLE users DROP COLUMN IF EXISTS public_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS public_id;
+ALTER TABLE media DROP COLUMN IF EXISTS public_id;
+ALTER TABLE model_profiles DROP COLUMN IF EXISTS public_id;
+
+-- Timestamps without time zone are read in the connection's time zone, the
+-- one they were written in
+ALTER TABLE media ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE likes ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE follows ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE purchases ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE payments ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE saved_posts ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE referrals ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE logs ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+-- Indexes the models declare. A post can be bought once per user; resolve
+-- duplicate purchases by hand if this fails.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_purchase ON purchases(user_id, post_id);
+CREATE INDEX IF NOT EXISTS idx_users_referred_by ON users(referred_by);
@@ -0,0 +1,3 @@
+-- Nothing to undo: the up migration only repairs a column 000009 now
+-- creates correctly.
+SELECT 1;
@@ -0,0 +1,17 @@
+-- 000009 first declared purchases.photo_id as INTEGER, which Postgres
+-- refuses to reference the UUID key of media, so no database got past it
+-- with that column. 000009 now creates it as UUID; this only converts a
+-- column that is still INTEGER and does nothing otherwise.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'purchases'
+          AND column_name = 'photo_id' AND udt_name <> 'uuid'
+    ) THEN
+        ALTER TABLE purchases DROP CONSTRAINT IF EXISTS purchases_photo_id_fkey;
+        ALTER TABLE purchases ALTER COLUMN photo_id TYPE UUID USING NULL;
+        ALTER TABLE purchases ADD CONSTRAINT purchases_photo_id_fkey FOREIGN KEY (photo_id) REFERENCES media(id);
+    END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_streams_model_id;
+ALTER TABLE streams DROP COLUMN IF EXISTS model_id;
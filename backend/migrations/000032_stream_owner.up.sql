@@ -0,0 +1,4 @@
+-- Streams belong to a creator, so tips can only name the creator's own.
+-- Streams created before have no owner and take no tips.
+ALTER TABLE streams ADD COLUMN IF NOT EXISTS model_id UUID REFERENCES model_profiles(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_streams_model_id ON streams(model_id);
@@ -0,0 +1,10 @@
+-- Nonces of ops confirmation tokens that were used; a token runs its
+-- operation once.
+CREATE TABLE used_ops_confirmations (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    operation VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+CREATE INDEX idx_used_ops_confirmations_actor_id ON used_ops_confirmations(actor_id);
@@ -0,0 +1,11 @@
+-- Distinct actors merged into a notification; actor_count is their number,
+-- so an actor repeating an action after someone else counts once
+CREATE TABLE notification_actors (
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (notification_id, actor_id)
+);
+
+-- Earlier actors of merged notifications are unknown; the latest is kept
+INSERT INTO notification_actors (notification_id, actor_id)
+SELECT id, actor_id FROM notifications WHERE actor_id IS NOT NULL;
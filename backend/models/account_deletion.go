@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountDeletion is a user's request to erase their account. Until DueAt
+// the user can cancel it; then their personal data is anonymized, while
+// financial records stay, pointing at the anonymized account.
+type AccountDeletion struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	Reason      string     `gorm:"type:varchar(1000)" json:"reason"`
+	DueAt       time.Time  `gorm:"index;not null" json:"due_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (d *AccountDeletion) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// Pending reports whether the request can still be cancelled.
+func (d *AccountDeletion) Pending() bool {
+	return d.CancelledAt == nil && d.CompletedAt == nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Account states of a User.
+const (
+	AccountActive      = "active"
+	AccountSuspended   = "suspended"   // locked until SuspendedUntil, then active again
+	AccountBanned      = "banned"      // locked for good; creator content is hidden
+	AccountDeactivated = "deactivated" // closed by the user or staff; can be reactivated
+)
+
+// AccountStates lists every account state.
+var AccountStates = []string{AccountActive, AccountSuspended, AccountBanned, AccountDeactivated}
+
+// WithdrawnStates are the states whose content is hidden from other users.
+var WithdrawnStates = []string{AccountBanned, AccountDeactivated}
+
+// AccountStatusChange is the audit trail of account state changes.
+type AccountStatusChange struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	ActorID   *uuid.UUID `gorm:"type:uuid" json:"actor_id"` // nil for system changes such as expired suspensions
+	From      string     `gorm:"column:from_status;type:varchar(16);not null" json:"from"`
+	To        string     `gorm:"column:to_status;type:varchar(16);not null" json:"to"`
+	Reason    string     `json:"reason"`
+	Until     *time.Time `json:"until"` // end of a suspension
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (c *AccountStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditEntry records one privileged change: who did what to which entity,
+// the fields it changed and where the request came from. Entries form a hash
+// chain in Seq order: Hash covers the entry and PrevHash, the Hash of the
+// entry before it, so editing or removing an entry breaks every later hash.
+type AuditEntry struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Seq        int64      `gorm:"uniqueIndex;not null" json:"seq"`
+	ActorID    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id"` // nil for operations run from the command line
+	Action     string     `gorm:"type:varchar(64);index;not null" json:"action"`
+	EntityType string     `gorm:"type:varchar(32);index:idx_audit_entity,priority:1;not null" json:"entity_type"`
+	EntityID   string     `gorm:"type:varchar(64);index:idx_audit_entity,priority:2" json:"entity_id"`
+	Changes    string     `gorm:"type:text" json:"-"` // JSON object of field: {from, to}
+	RequestID  string     `gorm:"type:varchar(64);index" json:"request_id"`
+	IP         string     `gorm:"type:varchar(64)" json:"ip"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	PrevHash   string     `gorm:"type:varchar(64)" json:"prev_hash"`
+	Hash       string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"hash"`
+}
+
+func (e *AuditEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserBlock cuts all contact between two users: neither can follow, like,
+// comment on, message or tip the other, and each disappears from the other's
+// feed and search results. It applies both ways whoever created it.
+type UserBlock struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey" json:"blocker_id"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserMute hides MutedID's posts, comments and notifications from MuterID
+// only; the muted user notices nothing.
+type UserMute struct {
+	MuterID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"muter_id"`
+	MutedID   uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -13,4 +13,6 @@ type Comment struct {
 	User   User      `json:"user"`
 	Text   string    `json:"text"`
 	Time   time.Time `json:"time"`
+	// HiddenAt is set while moderation hides the comment.
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
 }
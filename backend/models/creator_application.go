@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Review states of a CreatorApplication.
+const (
+	ApplicationPending  = "pending"
+	ApplicationApproved = "approved"
+	ApplicationRejected = "rejected"
+)
+
+// Kinds of CreatorDocument.
+const (
+	DocumentID     = "id_document"
+	DocumentSelfie = "selfie"
+)
+
+// CreatorApplication is a request to become a creator. It carries the
+// public profile the creator will get and the identity data a moderator
+// checks before approving it.
+type CreatorApplication struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;index;not null" json:"user_id"`
+	User        User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Status      string    `gorm:"type:varchar(16);index;not null" json:"status"`
+	LegalName   string    `gorm:"not null" json:"legal_name"`
+	DateOfBirth time.Time `gorm:"type:date;not null" json:"date_of_birth"`
+	Country     string    `gorm:"type:varchar(2);not null" json:"country"`
+
+	// Profile published on approval
+	Name   string `gorm:"not null" json:"name"`
+	Bio    string `json:"bio"`
+	Banner string `json:"banner"`
+
+	ReviewerID   *uuid.UUID        `gorm:"type:uuid" json:"reviewer_id"`
+	ReviewReason string            `json:"review_reason"`
+	ReviewedAt   *time.Time        `json:"reviewed_at"`
+	Documents    []CreatorDocument `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"documents"`
+	CreatedAt    time.Time         `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+func (a *CreatorApplication) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// CreatorDocument is an identity document attached to an application. The
+// file lives in the private store under StorageKey and is never public.
+type CreatorDocument struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;index;not null" json:"application_id"`
+	Kind          string    `gorm:"type:varchar(32);not null" json:"kind"`
+	StorageKey    string    `gorm:"not null" json:"-"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (d *CreatorDocument) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Purposes of an EmailToken.
+const (
+	EmailTokenVerify = "verify_email"
+	EmailTokenReset  = "password_reset"
+)
+
+// EmailToken is a single-use, expiring token mailed to a user to confirm
+// their address or reset their password. Only the SHA-256 hash is stored.
+type EmailToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	Email     string     `gorm:"index;not null" json:"email"` // address the token was sent to
+	Purpose   string     `gorm:"type:varchar(32);not null" json:"purpose"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *EmailToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
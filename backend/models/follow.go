@@ -6,10 +6,11 @@ import (
 	"github.com/google/uuid"
 )
 
-// Follow represents a user's subscription to another user.
+// Follow represents a user's subscription to another user. A pair of users
+// has at most one follow per direction.
 type Follow struct {
-	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
-	FollowerID uuid.UUID
-	FollowedID uuid.UUID
-	CreatedAt  time.Time
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FollowerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_follow_pair,priority:1" json:"follower_id"`
+	FollowedID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_follow_pair,priority:2;index" json:"followed_id"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -4,11 +4,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Image struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index" json:"user_id"` // uploader
 	Filename  string    `json:"filename"`
 	CDNUrl    string    `json:"cdn_url"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// The stored file is only removed once the purge job drops the row.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
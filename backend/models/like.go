@@ -8,7 +8,7 @@ import (
 
 type Like struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID    uuid.UUID
+	UserID    uuid.UUID `gorm:"type:uuid"`
 	PostID    uuid.UUID `gorm:"type:uuid" json:"post_id"`
 	CreatedAt time.Time
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Conversation is a private one-to-one thread. PairKey holds both member IDs
+// in a fixed order so a pair of users has at most one conversation.
+type Conversation struct {
+	ID            uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	PairKey       string               `gorm:"type:varchar(73);uniqueIndex;not null" json:"-"`
+	Members       []ConversationMember `gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE" json:"members"`
+	LastMessageAt time.Time            `gorm:"index" json:"last_message_at"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// ConversationMember is one side of a conversation. LastReadAt drives read
+// receipts; BlockedAt is set while this member blocks the other.
+type ConversationMember struct {
+	ConversationID uuid.UUID  `gorm:"type:uuid;primaryKey" json:"conversation_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	User           User       `json:"-"`
+	LastReadAt     *time.Time `json:"last_read_at"`
+	BlockedAt      *time.Time `json:"blocked_at"`
+}
+
+// Message is a direct message. A Price above zero makes its attachments
+// pay-to-unlock for the recipient; the text stays visible as a teaser.
+type Message struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	ConversationID uuid.UUID           `gorm:"type:uuid;index:idx_messages_conversation,priority:1;not null" json:"conversation_id"`
+	SenderID       uuid.UUID           `gorm:"type:uuid;not null" json:"sender_id"`
+	Text           string              `json:"text"`
+	Price          int                 `gorm:"not null;default:0" json:"price"`
+	Attachments    []MessageAttachment `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE" json:"attachments"`
+	CreatedAt      time.Time           `gorm:"index:idx_messages_conversation,priority:2" json:"created_at"`
+	HiddenAt       *time.Time          `json:"hidden_at,omitempty"` // set while hidden by moderation
+}
+
+func (m *Message) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// MessageAttachment is a photo or video, uploaded beforehand through the
+// media endpoints, attached to a message.
+type MessageAttachment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	MessageID uuid.UUID `gorm:"type:uuid;index;not null" json:"message_id"`
+	Type      string    `gorm:"type:varchar(16);not null" json:"type"` // "photo" or "video"
+	URL       string    `gorm:"not null" json:"url"`
+}
+
+func (a *MessageAttachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// MessageUnlock records that UserID paid for a paid message.
+type MessageUnlock struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	MessageID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_message_unlock" json:"message_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_message_unlock" json:"user_id"`
+	Price     int       `gorm:"not null" json:"price"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u *MessageUnlock) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -12,6 +14,17 @@ type ModelProfile struct {
 	Name   string    `json:"name"`
 	Bio    string    `json:"bio"`
 	Banner string    `json:"banner"`
+	// VerifiedAt is set when a creator application is approved. Only
+	// verified creators may publish premium posts and receive payouts.
+	VerifiedAt *time.Time `json:"verified_at"`
+	// HiddenAt is set while moderation hides the profile from everyone but
+	// its owner and moderators.
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+}
+
+// Verified reports whether the creator passed identity verification.
+func (m *ModelProfile) Verified() bool {
+	return m.VerifiedAt != nil
 }
 
 func (m *ModelProfile) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Types of a Notification.
+const (
+	NotifyFollow   = "follow"
+	NotifyLike     = "like"
+	NotifyComment  = "comment"
+	NotifyPurchase = "purchase"
+	NotifyNewPost  = "new_post"
+	NotifyTip      = "tip"
+	// NotifyWarning comes from moderation and NotifyDataExport says a data
+	// export is ready; they have no actor and cannot be turned off, so they
+	// are not in NotificationTypes.
+	NotifyWarning    = "warning"
+	NotifyDataExport = "data_export"
+)
+
+// NotificationTypes lists every type users can turn off, in the order
+// preferences are shown.
+var NotificationTypes = []string{NotifyFollow, NotifyLike, NotifyComment, NotifyPurchase, NotifyNewPost, NotifyTip}
+
+// Notification tells UserID that ActorID did something, about SubjectID (a
+// post) when set. Repeated events of one type about the same subject are
+// batched into a single unread notification: ActorID is the latest actor and
+// ActorCount how many distinct actors were merged in, see NotificationActor.
+type Notification struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index:idx_notifications_inbox,priority:1;not null" json:"user_id"`
+	Type       string     `gorm:"type:varchar(16);not null" json:"type"`
+	SubjectID  *uuid.UUID `gorm:"type:uuid" json:"subject_id"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id"`
+	Actor      *User      `gorm:"foreignKey:ActorID" json:"-"`
+	ActorCount int        `gorm:"not null;default:1" json:"actor_count"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"index:idx_notifications_inbox,priority:2" json:"updated_at"` // last merged event
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// NotificationActor records that ActorID is one of the actors merged into a
+// notification, so each of them counts once.
+type NotificationActor struct {
+	NotificationID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	ActorID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+}
+
+// NotificationPreference turns a notification type off for a user. Types
+// without a row are enabled.
+type NotificationPreference struct {
+	UserID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Type    string    `gorm:"type:varchar(16);primaryKey" json:"type"`
+	Enabled bool      `gorm:"not null" json:"enabled"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsedOpsConfirmation records the nonce of an ops confirmation token that
+// ran its operation, so the token cannot run it again.
+type UsedOpsConfirmation struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"` // the token's nonce
+	ActorID   uuid.UUID `gorm:"type:uuid;index;not null" json:"actor_id"`
+	Operation string    `gorm:"type:varchar(32);not null" json:"operation"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
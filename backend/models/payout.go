@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sources of an Earning.
+const (
+	EarningPurchase = "purchase"
+	EarningMessage  = "message" // unlocked paid message
+	EarningTip      = "tip"
+)
+
+// Earning is a creator's share of one sale. Gross is what the buyer paid,
+// Fee the platform's cut and Net what the creator can withdraw once
+// AvailableAt has passed. Earnings are never updated.
+type Earning struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CreatorID   uuid.UUID  `gorm:"type:uuid;index;not null" json:"creator_id"`
+	ModelID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"model_id"`
+	Source      string     `gorm:"type:varchar(16);not null;uniqueIndex:idx_earning_source" json:"source"`
+	SourceID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_earning_source" json:"source_id"`
+	PostID      *uuid.UUID `gorm:"type:uuid;index" json:"post_id"`
+	Gross       int        `gorm:"not null" json:"gross"`
+	Fee         int        `gorm:"not null" json:"fee"`
+	Net         int        `gorm:"not null" json:"net"`
+	AvailableAt time.Time  `gorm:"index;not null" json:"available_at"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (e *Earning) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// States of a Payout. Pending and processing payouts reserve their amount;
+// rejected and failed ones release it again.
+const (
+	PayoutPending    = "pending"
+	PayoutProcessing = "processing" // approved, transfer in flight
+	PayoutPaid       = "paid"
+	PayoutRejected   = "rejected"
+	PayoutFailed     = "failed"
+)
+
+// Payout is a creator's request to withdraw earnings to a crypto address.
+type Payout struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	Amount      int        `gorm:"not null" json:"amount"`
+	Currency    string     `gorm:"type:varchar(16);not null" json:"currency"`
+	Address     string     `gorm:"not null" json:"address"`
+	Status      string     `gorm:"type:varchar(16);index;not null" json:"status"`
+	Provider    string     `gorm:"type:varchar(16)" json:"provider"`
+	ProviderRef string     `json:"provider_ref"` // transaction ID or hash of the transfer
+	ReviewerID  *uuid.UUID `gorm:"type:uuid" json:"reviewer_id"`
+	Reason      string     `json:"reason"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (p *Payout) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ type Post struct {
 	IsPremium    bool         `json:"isPremium"`
 	PublishedAt  time.Time    `json:"published_time"`
 	LikesCount   int          `json:"likes_count"`
+	ViewsCount   int          `json:"views_count"`
 	Price        int          `json:"price"`
 	UserID       uuid.UUID    `json:"-"`
 	User         User         `json:"user"`
@@ -21,8 +22,19 @@ type Post struct {
 	Media        []Media      `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"media"`
 	Comments     []Comment    `gorm:"foreignKey:PostID" json:"comments"`
 	IsPurchased  bool         `gorm:"-" json:"isPurchased"`
+	HiddenAt     *time.Time   `json:"hidden_at,omitempty"` // set while hidden by moderation
+	// Deleted posts stay until the purge job removes them; see DeletedBy.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedBy string         `gorm:"type:varchar(16)" json:"-"`
 }
 
+// Who deleted a post. Buyers keep access to posts their author deleted but
+// not to posts removed by moderation.
+const (
+	DeletedByOwner      = "owner"
+	DeletedByModeration = "moderation"
+)
+
 // BeforeCreate sets a UUID for the post before inserting into the database.
 func (p *Post) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
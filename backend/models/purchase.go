@@ -1,14 +1,18 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Purchase struct {
 	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
-	UserID    uuid.UUID  `gorm:"not null;index:idx_unique_purchase,unique"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_unique_purchase,unique"`
 	PostID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_unique_purchase,unique"`
 	Completed bool       `gorm:"default:false" json:"completed"`
-	PhotoID   *uuid.UUID // nullable, for per-photo purchase
-	VideoID   *uuid.UUID // nullable, for per-video purchase
+	Price     int        `gorm:"default:0" json:"price"` // what the buyer paid
+	PhotoID   *uuid.UUID `gorm:"type:uuid"`              // nullable, for per-photo purchase
+	VideoID   *uuid.UUID `gorm:"type:uuid"`              // nullable, for per-video purchase
+	CreatedAt time.Time  `json:"created_at"`
 }
@@ -9,8 +9,8 @@ import (
 // Referral tracks users invited through a referral code.
 type Referral struct {
 	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID        uuid.UUID
+	UserID        uuid.UUID `gorm:"type:uuid"`
 	ReferralCode  string
-	InvitedUserID uuid.UUID
+	InvitedUserID uuid.UUID `gorm:"type:uuid"`
 	CreatedAt     time.Time
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a long-lived session credential. Only the SHA-256 hash of
+// the token is stored. Every refresh replaces the token with a new one in the
+// same family; presenting a replaced token revokes the whole family.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	FamilyID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"family_id"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"replaced_by_id"`
+	UserAgent    string     `json:"user_agent"`
+	IP           string     `json:"ip"`
+	MFA          bool       `gorm:"default:false" json:"mfa"` // session passed two-factor verification
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
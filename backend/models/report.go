@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of content a Report can be about.
+const (
+	ReportPost    = "post"
+	ReportComment = "comment"
+	ReportProfile = "profile" // a model profile
+	ReportMessage = "message"
+)
+
+// ReportTargets lists every reportable kind of content.
+var ReportTargets = []string{ReportPost, ReportComment, ReportProfile, ReportMessage}
+
+// Reason categories of a Report.
+const (
+	ReasonSpam          = "spam"
+	ReasonHarassment    = "harassment"
+	ReasonHate          = "hate"
+	ReasonViolence      = "violence"
+	ReasonMinorSafety   = "minor_safety"
+	ReasonIllegal       = "illegal"
+	ReasonImpersonation = "impersonation"
+	ReasonCopyright     = "copyright"
+	ReasonOther         = "other"
+)
+
+// ReportReasons lists every reason category, in the order clients show them.
+var ReportReasons = []string{
+	ReasonSpam, ReasonHarassment, ReasonHate, ReasonViolence, ReasonMinorSafety,
+	ReasonIllegal, ReasonImpersonation, ReasonCopyright, ReasonOther,
+}
+
+// States of a ModerationCase.
+const (
+	CaseOpen     = "open"
+	CaseClaimed  = "claimed"
+	CaseResolved = "resolved"
+)
+
+// Actions a moderator resolves a ModerationCase with.
+const (
+	ActionDismiss = "dismiss" // nothing wrong; hidden content is shown again
+	ActionHide    = "hide"    // the content stays hidden
+	ActionWarn    = "warn"    // the author is warned, the content stays up
+	ActionSuspend = "suspend" // the content is hidden and the author suspended
+)
+
+// ModerationActions lists every resolution action.
+var ModerationActions = []string{ActionDismiss, ActionHide, ActionWarn, ActionSuspend}
+
+// ModerationCase collects the reports about one piece of content until a
+// moderator resolves it. A target has at most one unresolved case; reports
+// after the resolution open a new one, so resolved cases form its history.
+// TargetUserID is the author of the content.
+type ModerationCase struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	TargetType     string     `gorm:"type:varchar(16);index:idx_moderation_cases_target,priority:1;not null" json:"target_type"`
+	TargetID       uuid.UUID  `gorm:"type:uuid;index:idx_moderation_cases_target,priority:2;not null" json:"target_id"`
+	TargetUserID   uuid.UUID  `gorm:"type:uuid;index;not null" json:"target_user_id"`
+	Status         string     `gorm:"type:varchar(16);index;not null" json:"status"`
+	ReportCount    int        `gorm:"not null;default:0" json:"report_count"`
+	AutoHidden     bool       `gorm:"not null;default:false" json:"auto_hidden"` // hidden on reaching the report threshold
+	AssigneeID     *uuid.UUID `gorm:"type:uuid;index" json:"assignee_id"`
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	Action         string     `gorm:"type:varchar(16)" json:"action"`
+	ResolutionNote string     `json:"resolution_note"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	ResolvedBy     *uuid.UUID `gorm:"type:uuid" json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	Reports        []Report   `gorm:"foreignKey:CaseID;constraint:OnDelete:CASCADE" json:"reports,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (c *ModerationCase) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Report is one user's flag on a piece of content. A user reports a target
+// once per case.
+type Report struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	CaseID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_report_case_reporter,priority:1;not null" json:"case_id"`
+	ReporterID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_report_case_reporter,priority:2;index;not null" json:"reporter_id"`
+	Reason     string    `gorm:"type:varchar(32);not null" json:"reason"`
+	Details    string    `gorm:"type:varchar(1000)" json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (r *Report) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
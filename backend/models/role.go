@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role is a named set of permissions (see policy.RolePermissions).
+type Role string
+
+const (
+	// RoleUser is implicit: every account has it and it is never stored.
+	RoleUser      Role = "user"
+	RoleCreator   Role = "creator"
+	RoleModerator Role = "moderator"
+	RoleSupport   Role = "support"
+	RoleFinance   Role = "finance"
+	RoleAdmin     Role = "admin"
+)
+
+// Roles lists every role in order of increasing power.
+var Roles = []Role{RoleUser, RoleCreator, RoleModerator, RoleSupport, RoleFinance, RoleAdmin}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	for _, known := range Roles {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRole grants a role to a user.
+type UserRole struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"-"`
+	Role      Role       `gorm:"type:varchar(32);primaryKey" json:"role"`
+	GrantedBy *uuid.UUID `gorm:"type:uuid" json:"granted_by"`
+	CreatedAt time.Time  `json:"granted_at"`
+}
+
+// Actions recorded in RoleChange.
+const (
+	RoleGranted = "grant"
+	RoleRevoked = "revoke"
+)
+
+// RoleChange is the audit trail of role grants and revocations.
+type RoleChange struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	ActorID   *uuid.UUID `gorm:"type:uuid;index" json:"actor_id"` // nil for system changes (migrations, CLI)
+	Role      Role       `gorm:"type:varchar(32);not null" json:"role"`
+	Action    string     `gorm:"type:varchar(16);not null" json:"action"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (c *RoleChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -14,5 +14,7 @@ type Stream struct {
 	StreamKey     string    `json:"stream_key"`
 	PlaybackUrl   string    `json:"playback_url"`
 	Status        string    `json:"status"`
-	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// ModelID is the creator streaming; tips may name only their own streams.
+	ModelID   *uuid.UUID `gorm:"type:uuid;index" json:"model_id"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tip is money a fan gave a creator on top of any price. At most one of
+// PostID, StreamID and MessageID says what the tip was for.
+type Tip struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	User      User       `gorm:"foreignKey:UserID" json:"-"`
+	ModelID   uuid.UUID  `gorm:"type:uuid;index:idx_tips_model,priority:1;not null" json:"model_id"`
+	Amount    int        `gorm:"not null" json:"amount"`
+	PostID    *uuid.UUID `gorm:"type:uuid;index" json:"post_id"`
+	StreamID  *uuid.UUID `gorm:"type:uuid;index" json:"stream_id"`
+	MessageID *uuid.UUID `gorm:"type:uuid" json:"message_id"`
+	Note      string     `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt time.Time  `gorm:"index:idx_tips_model,priority:2" json:"created_at"`
+}
+
+func (t *Tip) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
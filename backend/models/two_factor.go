@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TwoFactor holds a user's TOTP enrollment. The row exists from setup on;
+// EnabledAt is set once the user has confirmed a code from their app.
+type TwoFactor struct {
+	UserID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Secret         string     `gorm:"not null" json:"-"`
+	EnabledAt      *time.Time `json:"enabled_at"`
+	LastUsedStep   int64      `json:"-"` // last accepted time step, codes can't be replayed
+	FailedAttempts int        `json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Enabled reports whether the enrollment has been confirmed.
+func (t *TwoFactor) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use backup code for a user with 2FA. Only the
+// SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
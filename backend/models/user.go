@@ -1,25 +1,80 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
-	Email        string     `gorm:"unique" json:"email" validate:"required,email"`
-	Nickname     string     `gorm:"unique" json:"nickname" validate:"required,min=3,max=32"`
-	Password     string     `json:"-" validate:"required,min=8"`
-	Balance      int        `json:"balance"`
-	AvatarURL    string     `json:"avatarUrl"`
-	IsAdmin      bool       `gorm:"default:false" json:"isAdmin"`
-	ReferralCode *string    `gorm:"type:varchar(20);unique" json:"referral_code"`
-	ReferredBy   *uuid.UUID `gorm:"index" json:"referred_by"` // FK to User.ID
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	Email           string         `gorm:"unique" json:"email" validate:"required,email"`
+	Nickname        string         `gorm:"unique" json:"nickname" validate:"required,min=3,max=32"`
+	Password        string         `json:"-" validate:"required,min=8"`
+	Balance         int            `json:"balance"`
+	AvatarURL       string         `json:"avatarUrl"`
+	ReferralCode    *string        `gorm:"type:varchar(20);unique" json:"referral_code"`
+	ReferredBy      *uuid.UUID     `gorm:"type:uuid;index" json:"referred_by"` // FK to User.ID
+	TokenVersion    int            `gorm:"default:0" json:"-"`                 // bumped to invalidate issued access tokens
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	Status          string         `gorm:"type:varchar(16);not null;default:active;index" json:"status"` // one of AccountStates
+	StatusReason    string         `json:"-"`                                                            // why staff set Status, see AccountStatusChange
+	SuspendedUntil  *time.Time     `json:"suspended_until"`                                              // end of the current suspension
+	Roles           []UserRole     `gorm:"foreignKey:UserID" json:"roles"`                               // granted roles, RoleUser is implicit
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`                                               // deleted accounts can be restored by staff
+	AnonymizedAt    *time.Time     `json:"-"`                                                            // personal data erased, see AccountDeletion
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Status == "" {
+		u.Status = AccountActive
+	}
 	return nil
 }
+
+// Suspended reports whether the account is suspended at now. A suspension
+// that has run out no longer counts, even before the sweeper reactivates
+// the account.
+func (u *User) Suspended(now time.Time) bool {
+	return u.Status == AccountSuspended && u.SuspendedUntil != nil && now.Before(*u.SuspendedUntil)
+}
+
+// Withdrawn reports whether the account is banned, deactivated or deleted,
+// which takes its posts and model profile out of feeds and search.
+func (u *User) Withdrawn() bool {
+	if u.DeletedAt.Valid {
+		return true
+	}
+	for _, s := range WithdrawnStates {
+		if u.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the user holds the role. Roles must be preloaded.
+func (u *User) HasRole(role Role) bool {
+	if role == RoleUser {
+		return true
+	}
+	for _, r := range u.Roles {
+		if r.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleNames lists the user's roles including the implicit RoleUser.
+func (u *User) RoleNames() []string {
+	names := []string{string(RoleUser)}
+	for _, r := range u.Roles {
+		names = append(names, string(r.Role))
+	}
+	return names
+}
@@ -4,12 +4,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Video struct {
 	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;index" json:"user_id"` // uploader
 	BunnyVideoID string    `json:"bunny_video_id"`
 	Title        string    `json:"title"`
 	CDNUrl       string    `json:"cdn_url"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// The Bunny video is only removed once the purge job drops the row.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostView is one counted view of a post, or of one of its media items when
+// MediaID is set. Repeated views by the same viewer within the dedup window
+// are not recorded. Viewer is "u:<user id>" for signed-in users and a hash
+// of the session otherwise.
+type PostView struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PostID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"post_id"`
+	MediaID   *uuid.UUID `gorm:"type:uuid;index" json:"media_id"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Viewer    string     `gorm:"type:varchar(66);not null" json:"-"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (v *PostView) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// VideoWatch is how far one viewer got in a video of a post. Position only
+// moves forward; Completed stays set once the viewer reached the end.
+type VideoWatch struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	MediaID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_video_watch_viewer" json:"media_id"`
+	Viewer    string     `gorm:"type:varchar(66);not null;uniqueIndex:idx_video_watch_viewer" json:"-"`
+	PostID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"post_id"`
+	UserID    *uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	Position  int        `gorm:"not null" json:"position"` // seconds
+	Duration  int        `gorm:"not null" json:"duration"` // seconds
+	Completed bool       `gorm:"not null;default:false" json:"completed"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"` // first progress event
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (w *VideoWatch) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
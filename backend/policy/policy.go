@@ -0,0 +1,119 @@
+// Package policy decides whether an authenticated user may mutate a resource.
+// Handlers load the resource, ask the policy, and translate the returned error
+// into an HTTP response with utils.Authorize. Staff powers come from roles
+// and their permissions (rbac.go); everyone may act on what they own.
+package policy
+
+import (
+	"errors"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnauthenticated is returned when there is no authenticated user.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned when the user is neither the owner nor holds
+	// the permission.
+	ErrForbidden = errors.New("not allowed to modify this resource")
+	// ErrTwoFactorRequired is returned when the session has not passed 2FA.
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+	// ErrCreatorNotVerified is returned when a creator who has not passed
+	// identity verification publishes premium content or asks for money.
+	ErrCreatorNotVerified = errors.New("creator identity is not verified")
+)
+
+// IsAdmin reports whether the user has the admin role.
+func IsAdmin(actor *models.User) bool {
+	return actor != nil && actor.HasRole(models.RoleAdmin)
+}
+
+// RequireUser fails when there is no authenticated user.
+func RequireUser(actor *models.User) error {
+	if actor == nil {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+// RequireTwoFactor allows only sessions that passed two-factor verification.
+// Applied to admin routes and, for creators, to moving money out.
+func RequireTwoFactor(actor *models.User, passed bool) error {
+	if actor == nil {
+		return ErrUnauthenticated
+	}
+	if !passed {
+		return ErrTwoFactorRequired
+	}
+	return nil
+}
+
+// RequireVerifiedCreator allows only model profiles whose creator
+// application was approved. Gates premium posts and payouts.
+func RequireVerifiedCreator(profile *models.ModelProfile) error {
+	if profile == nil || !profile.Verified() {
+		return ErrCreatorNotVerified
+	}
+	return nil
+}
+
+// OwnerOr allows the user whose ID matches ownerID, or anyone holding perm.
+// Using perm on someone else's resource is a staff action and, as on the
+// staff routes, needs twoFactor: a session that passed 2FA or a deployment
+// that does not require it (utils.StaffTwoFactor).
+func OwnerOr(actor *models.User, ownerID uuid.UUID, perm Permission, twoFactor bool) error {
+	if actor == nil {
+		return ErrUnauthenticated
+	}
+	if ownerID != uuid.Nil && actor.ID == ownerID {
+		return nil
+	}
+	if !Can(actor, perm) {
+		return ErrForbidden
+	}
+	if !twoFactor {
+		return ErrTwoFactorRequired
+	}
+	return nil
+}
+
+// CanModifyUser allows users to change their own account and user managers to change any.
+func CanModifyUser(actor *models.User, userID uuid.UUID, twoFactor bool) error {
+	return OwnerOr(actor, userID, PermManageUsers, twoFactor)
+}
+
+// CanModifyModelProfile allows the profile owner or a moderator.
+func CanModifyModelProfile(actor *models.User, profile *models.ModelProfile, twoFactor bool) error {
+	return OwnerOr(actor, profile.UserID, PermModerateContent, twoFactor)
+}
+
+// CanModifyPost allows the post author, the user behind the post's model
+// profile, or a moderator.
+func CanModifyPost(actor *models.User, post *models.Post, twoFactor bool) error {
+	if err := OwnerOr(actor, post.UserID, PermModerateContent, twoFactor); err == nil || errors.Is(err, ErrUnauthenticated) {
+		return err
+	}
+	return OwnerOr(actor, post.ModelProfile.UserID, PermModerateContent, twoFactor)
+}
+
+// CanModifyOrder allows the order owner or an order manager.
+func CanModifyOrder(actor *models.User, order *models.Order, twoFactor bool) error {
+	return OwnerOr(actor, order.UserID, PermManageOrders, twoFactor)
+}
+
+// CanModifyVideo allows the uploader or a moderator.
+func CanModifyVideo(actor *models.User, video *models.Video, twoFactor bool) error {
+	return OwnerOr(actor, video.UserID, PermModerateContent, twoFactor)
+}
+
+// CanModifyImage allows the uploader or a moderator.
+func CanModifyImage(actor *models.User, image *models.Image, twoFactor bool) error {
+	return OwnerOr(actor, image.UserID, PermModerateContent, twoFactor)
+}
+
+// CanViewModelAnalytics allows the profile owner or payout managers.
+func CanViewModelAnalytics(actor *models.User, profile *models.ModelProfile, twoFactor bool) error {
+	return OwnerOr(actor, profile.UserID, PermManagePayouts, twoFactor)
+}
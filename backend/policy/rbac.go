@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"go-backend/models"
+)
+
+// Permission is a single capability checked by RequirePermission.
+type Permission string
+
+const (
+	// PermModerateContent: edit, hide or delete anyone's posts, media and profiles.
+	PermModerateContent Permission = "content:moderate"
+	// PermReviewCreators: work the creator verification queue.
+	PermReviewCreators Permission = "creators:review"
+	// PermReadUsers: list users and view private account data.
+	PermReadUsers Permission = "users:read"
+	// PermManageUsers: create, change and delete any account.
+	PermManageUsers Permission = "users:manage"
+	// PermReadOrders: view anyone's orders and purchases.
+	PermReadOrders Permission = "orders:read"
+	// PermManageOrders: change anyone's orders.
+	PermManageOrders Permission = "orders:manage"
+	// PermManagePayouts: approve creator payouts and view revenue.
+	PermManagePayouts Permission = "payouts:manage"
+	// PermManageRoles: grant and revoke roles, read the role audit trail.
+	PermManageRoles Permission = "roles:manage"
+	// PermReadAudit: read the admin audit log.
+	PermReadAudit Permission = "audit:read"
+	// PermRunOperations: migrations, seeding and other maintenance.
+	PermRunOperations Permission = "ops:run"
+)
+
+// AllPermissions lists every permission; admins hold all of them.
+var AllPermissions = []Permission{
+	PermModerateContent,
+	PermReviewCreators,
+	PermReadUsers,
+	PermManageUsers,
+	PermReadOrders,
+	PermManageOrders,
+	PermManagePayouts,
+	PermManageRoles,
+	PermReadAudit,
+	PermRunOperations,
+}
+
+// rolePermissions is the single source of truth for what each role may do.
+// RoleUser has no extra permissions; ownership checks cover a user's own data.
+// RoleCreator has none either: creators publish under their own model
+// profile, which ownership covers.
+var rolePermissions = map[models.Role][]Permission{
+	models.RoleUser:      {},
+	models.RoleCreator:   {},
+	models.RoleModerator: {PermModerateContent, PermReviewCreators, PermReadUsers},
+	models.RoleSupport:   {PermReadUsers, PermReadOrders},
+	models.RoleFinance:   {PermReadOrders, PermManageOrders, PermManagePayouts},
+	models.RoleAdmin:     AllPermissions,
+}
+
+// RolePermissions returns the permissions granted by a role.
+func RolePermissions(role models.Role) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+// Can reports whether the user holds the permission through any role.
+func Can(actor *models.User, perm Permission) bool {
+	if actor == nil {
+		return false
+	}
+	for _, role := range actor.Roles {
+		for _, p := range rolePermissions[role.Role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PermissionsOf lists the permissions the user holds.
+func PermissionsOf(actor *models.User) []Permission {
+	var perms []Permission
+	for _, p := range AllPermissions {
+		if Can(actor, p) {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+// IsStaff reports whether the user holds any permission, which makes their
+// changes subject to the audit log.
+func IsStaff(actor *models.User) bool {
+	return len(PermissionsOf(actor)) > 0
+}
+
+// RequirePermission fails unless the user holds the permission.
+func RequirePermission(actor *models.User, perm Permission) error {
+	if actor == nil {
+		return ErrUnauthenticated
+	}
+	if !Can(actor, perm) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package realtime
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultReplaySize is how many recent events per user a Hub keeps for
+	// replay.
+	DefaultReplaySize = 100
+	// DefaultReplayTTL is how long a Hub keeps the events of a user nobody
+	// is subscribed for.
+	DefaultReplayTTL = 10 * time.Minute
+	subscriberBuffer = 64
+)
+
+// Hub is the in-process Broker. Event IDs are a sequence of the Hub, so
+// replay only works against the instance that published the events.
+// Users without subscribers are forgotten once their latest event is older
+// than ReplayTTL, which keeps memory in line with recent activity rather than
+// with every user ever notified.
+type Hub struct {
+	ReplaySize int
+	ReplayTTL  time.Duration
+	Now        func() time.Time
+
+	mu    sync.Mutex
+	seq   uint64
+	swept time.Time
+	users map[uuid.UUID]*userStream
+}
+
+type userStream struct {
+	recent      []Event // oldest first, at most ReplaySize
+	published   time.Time
+	subscribers map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		ReplaySize: DefaultReplaySize,
+		ReplayTTL:  DefaultReplayTTL,
+		Now:        time.Now,
+		users:      map[uuid.UUID]*userStream{},
+	}
+}
+
+func (h *Hub) stream(userID uuid.UUID) *userStream {
+	s, ok := h.users[userID]
+	if !ok {
+		s = &userStream{subscribers: map[chan Event]struct{}{}}
+		h.users[userID] = s
+	}
+	return s
+}
+
+// sweep forgets the users nobody is subscribed for whose latest event is
+// older than ReplayTTL. It walks all users at most once per ReplayTTL.
+func (h *Hub) sweep(now time.Time) {
+	if now.Sub(h.swept) < h.ReplayTTL {
+		return
+	}
+	h.swept = now
+	for id, s := range h.users {
+		if len(s.subscribers) == 0 && now.Sub(s.published) >= h.ReplayTTL {
+			delete(h.users, id)
+		}
+	}
+}
+
+func (h *Hub) Publish(userID uuid.UUID, e Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.Now()
+	h.sweep(now)
+	s := h.stream(userID)
+	h.seq++
+	e.ID = strconv.FormatUint(h.seq, 10)
+	s.published = now
+	s.recent = append(s.recent, e)
+	if len(s.recent) > h.ReplaySize {
+		s.recent = s.recent[len(s.recent)-h.ReplaySize:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// too slow: drop it, the client reconnects and replays
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (h *Hub) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (<-chan Event, error) {
+	h.mu.Lock()
+	s := h.stream(userID)
+	var missed []Event
+	if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		for _, e := range s.recent {
+			if id, _ := strconv.ParseUint(e.ID, 10, 64); id > last {
+				missed = append(missed, e)
+			}
+		}
+	}
+	ch := make(chan Event, subscriberBuffer+len(missed))
+	for _, e := range missed {
+		ch <- e
+	}
+	s.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		// nothing to replay: no need to wait for the sweep
+		if len(s.subscribers) == 0 && len(s.recent) == 0 && h.users[userID] == s {
+			delete(h.users, userID)
+		}
+	}()
+	return ch, nil
+}
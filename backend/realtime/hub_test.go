@@ -0,0 +1,51 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHubForgetsIdleUsers(t *testing.T) {
+	now := time.Now()
+	h := NewHub()
+	h.Now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := uuid.New()
+	events, _ := h.Subscribe(ctx, listener, "")
+	for i := 0; i < 1000; i++ {
+		h.Publish(uuid.New(), Event{Type: EventNotification})
+	}
+	h.Publish(listener, Event{Type: EventNotification})
+	last := (<-events).ID
+	if len(h.users) != 1001 {
+		t.Fatalf("expected recent users kept for replay, got %d", len(h.users))
+	}
+
+	// once their events are older than the TTL only subscribed users remain
+	now = now.Add(DefaultReplayTTL)
+	h.Publish(uuid.New(), Event{Type: EventNotification})
+	if len(h.users) != 2 {
+		t.Fatalf("expected idle users forgotten, %d left", len(h.users))
+	}
+
+	// IDs keep growing after a user was forgotten, so Last-Event-ID still
+	// replays what came after it
+	cancel()
+	for range events {
+	}
+	now = now.Add(DefaultReplayTTL)
+	h.Publish(uuid.New(), Event{Type: EventNotification})
+	if _, ok := h.users[listener]; ok {
+		t.Fatal("expected the unsubscribed user forgotten")
+	}
+	h.Publish(listener, Event{Type: EventBalance})
+	replay, _ := h.Subscribe(context.Background(), listener, last)
+	if e := <-replay; e.Type != EventBalance {
+		t.Fatalf("expected the balance event replayed, got %+v", e)
+	}
+}
@@ -0,0 +1,92 @@
+// Package realtime pushes events such as notifications and balance changes to
+// the connected clients of a user, and tips to the viewers of a live stream. The Broker interface hides where events
+// travel: Hub delivers them within one process, a broker backed by Redis or
+// NATS can implement the same interface to fan out across instances.
+package realtime
+
+import (
+	"context"
+	"sync"
+
+	"go-backend/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Event types.
+const (
+	EventNotification = "notification"
+	EventBalance      = "balance"
+	EventPurchase     = "purchase"
+	EventMessage      = "message"
+	EventMessageRead  = "message_read"
+	EventTip          = "tip"
+)
+
+// Event is one message to a user. ID orders the user's events; clients send
+// the last one they saw as Last-Event-ID when they reconnect.
+type Event struct {
+	ID   string      `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Broker delivers events to the subscribers of a user. The audience of a
+// live stream subscribes under the stream's ID; user and stream IDs are both
+// random UUIDs and do not collide.
+type Broker interface {
+	// Publish assigns the event an ID and sends it to every subscriber of
+	// the user.
+	Publish(userID uuid.UUID, e Event) error
+	// Subscribe streams the user's events until ctx is done, first replaying
+	// those published after lastEventID when it is set and still retained.
+	// The channel is closed when the subscription ends, also when the
+	// subscriber falls too far behind; it should then reconnect.
+	Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (<-chan Event, error)
+}
+
+var (
+	mu     sync.RWMutex
+	broker Broker
+)
+
+// SetBroker overrides the broker returned by Default.
+func SetBroker(b Broker) {
+	mu.Lock()
+	defer mu.Unlock()
+	broker = b
+}
+
+// Default returns the process-wide broker, an in-process Hub unless
+// SetBroker installed another.
+func Default() Broker {
+	mu.RLock()
+	b := broker
+	mu.RUnlock()
+	if b != nil {
+		return b
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if broker == nil {
+		broker = NewHub()
+	}
+	return broker
+}
+
+// Publish sends an event to the user through the default broker. Delivery is
+// best effort: failures are logged, never returned.
+func Publish(userID uuid.UUID, eventType string, data interface{}) {
+	if err := Default().Publish(userID, Event{Type: eventType, Data: data}); err != nil {
+		logging.GetLogger().Warn("Publish event failed", zap.String("user_id", userID.String()), zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// PublishStream sends an event to everyone watching the live stream, best
+// effort like Publish.
+func PublishStream(streamID uuid.UUID, eventType string, data interface{}) {
+	if err := Default().Publish(streamID, Event{Type: eventType, Data: data}); err != nil {
+		logging.GetLogger().Warn("Publish stream event failed", zap.String("stream_id", streamID.String()), zap.String("type", eventType), zap.Error(err))
+	}
+}
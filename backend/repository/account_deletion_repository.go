@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnonymizedText replaces comments and messages of erased accounts.
+const AnonymizedText = "[deleted]"
+
+type AccountDeletionRepository interface {
+	Create(deletion *models.AccountDeletion) error
+	// Pending returns the user's deletion request that can still be
+	// cancelled.
+	Pending(userID uuid.UUID) (*models.AccountDeletion, error)
+	Cancel(id uuid.UUID, now time.Time) error
+	// Due lists pending requests whose grace period ended by now.
+	Due(now time.Time) ([]models.AccountDeletion, error)
+	Complete(id uuid.UUID, now time.Time) error
+	// Deleted lists accounts deleted before cutoff that still hold
+	// personal data.
+	Deleted(cutoff time.Time) ([]uuid.UUID, error)
+	// Anonymize erases the personal data of the user in one transaction and
+	// returns the keys of the private objects that belonged to them.
+	Anonymize(userID uuid.UUID, now time.Time) ([]string, error)
+}
+
+type GormAccountDeletionRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormAccountDeletionRepository) Create(deletion *models.AccountDeletion) error {
+	return r.DB.Create(deletion).Error
+}
+
+func (r *GormAccountDeletionRepository) Pending(userID uuid.UUID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.DB.Where("user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL", userID).First(&deletion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+func (r *GormAccountDeletionRepository) Cancel(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&models.AccountDeletion{}).Where("id = ?", id).Update("cancelled_at", now).Error
+}
+
+func (r *GormAccountDeletionRepository) Due(now time.Time) ([]models.AccountDeletion, error) {
+	var deletions []models.AccountDeletion
+	err := r.DB.Where("cancelled_at IS NULL AND completed_at IS NULL AND due_at <= ?", now).
+		Order("due_at").Find(&deletions).Error
+	return deletions, err
+}
+
+func (r *GormAccountDeletionRepository) Complete(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&models.AccountDeletion{}).Where("id = ?", id).Update("completed_at", now).Error
+}
+
+func (r *GormAccountDeletionRepository) Deleted(cutoff time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.DB.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND anonymized_at IS NULL", cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Anonymize keeps the user row, so purchases, orders, payments, tips,
+// earnings and payouts stay consistent, but strips it of everything that
+// identifies the person. Content is deleted the way its owner would delete
+// it, so buyers keep bought posts and the purge job removes the rest.
+func (r *GormAccountDeletionRepository) Anonymize(userID uuid.UUID, now time.Time) ([]string, error) {
+	var keys []string
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		id := userID.String()
+		err := tx.Unscoped().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email":             "deleted-" + id + "@deleted.invalid",
+			"nickname":          "deleted-" + id,
+			"password":          "",
+			"avatar_url":        "",
+			"referral_code":     nil,
+			"referred_by":       nil,
+			"email_verified_at": nil,
+			"status":            models.AccountDeactivated,
+			"status_reason":     "",
+			"suspended_until":   nil,
+			"token_version":     gorm.Expr("token_version + 1"),
+			"deleted_at":        gorm.Expr("COALESCE(deleted_at, ?)", now),
+			"anonymized_at":     now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Logs mention users by ID and email.
+		for _, s := range []string{user.Email, id} {
+			if s == "" {
+				continue
+			}
+			err := tx.Model(&models.Log{}).Where("message LIKE ?", "%"+s+"%").
+				Update("message", gorm.Expr("REPLACE(message, ?, ?)", s, AnonymizedText)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		updates := []struct {
+			model  interface{}
+			where  string
+			values map[string]interface{}
+		}{
+			{&models.Comment{}, "user_id = @id", map[string]interface{}{"text": AnonymizedText}},
+			{&models.Message{}, "sender_id = @id", map[string]interface{}{"text": AnonymizedText}},
+			{&models.Referral{}, "user_id = @id", map[string]interface{}{"referral_code": ""}},
+			{&models.Tip{}, "user_id = @id", map[string]interface{}{"note": ""}},
+			{&models.Payout{}, "user_id = @id", map[string]interface{}{"address": ""}},
+			{&models.Notification{}, "actor_id = @id", map[string]interface{}{"actor_id": nil}},
+			{&models.PostView{}, "user_id = @id", map[string]interface{}{"user_id": nil}},
+			{&models.VideoWatch{}, "user_id = @id", map[string]interface{}{"user_id": nil}},
+			{&models.ModelProfile{}, "user_id = @id", map[string]interface{}{"name": "Deleted creator", "bio": "", "banner": ""}},
+		}
+		for _, u := range updates {
+			if err := tx.Model(u.model).Where(u.where, sql.Named("id", userID)).Updates(u.values).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.Post{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"deleted_at": now, "deleted_by": models.DeletedByOwner}).Error
+		if err != nil {
+			return err
+		}
+		for _, upload := range []interface{}{&models.Video{}, &models.Image{}} {
+			if err := tx.Model(upload).Where("user_id = ?", userID).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		// Identity documents and data exports live in private storage.
+		err = tx.Model(&models.CreatorDocument{}).
+			Where("application_id IN (?)", tx.Model(&models.CreatorApplication{}).Select("id").Where("user_id = ?", userID)).
+			Pluck("storage_key", &keys).Error
+		if err != nil {
+			return err
+		}
+		var exportKeys []string
+		err = tx.Model(&models.DataExport{}).Where("user_id = ? AND object_key <> ''", userID).Pluck("object_key", &exportKeys).Error
+		if err != nil {
+			return err
+		}
+		keys = append(keys, exportKeys...)
+		err = tx.Where("application_id IN (?)", tx.Model(&models.CreatorApplication{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&models.CreatorDocument{}).Error
+		if err != nil {
+			return err
+		}
+
+		deletes := []struct {
+			model interface{}
+			where string
+		}{
+			{&models.CreatorApplication{}, "user_id = @id"},
+			{&models.DataExport{}, "user_id = @id"},
+			{&models.RefreshToken{}, "user_id = @id"},
+			{&models.EmailToken{}, "user_id = @id"},
+			{&models.TwoFactor{}, "user_id = @id"},
+			{&models.RecoveryCode{}, "user_id = @id"},
+			{&models.UserRole{}, "user_id = @id"},
+			{&models.NotificationActor{}, "actor_id = @id OR notification_id IN (SELECT id FROM notifications WHERE user_id = @id)"},
+			{&models.Notification{}, "user_id = @id"},
+			{&models.NotificationPreference{}, "user_id = @id"},
+			{&models.Follow{}, "follower_id = @id OR followed_id = @id"},
+			{&models.SavedPost{}, "user_id = @id"},
+			{&models.UserBlock{}, "blocker_id = @id OR blocked_id = @id"},
+			{&models.UserMute{}, "muter_id = @id OR muted_id = @id"},
+		}
+		for _, d := range deletes {
+			if err := tx.Where(d.where, sql.Named("id", userID)).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AccountStatusRepository interface {
+	// SetStatus moves the user to change.To, filling in change.From, and
+	// records the change, in one transaction.
+	SetStatus(change *models.AccountStatusChange) error
+	// History returns the user's state changes, newest first.
+	History(userID uuid.UUID, limit, offset int) ([]models.AccountStatusChange, error)
+	// ExpiredSuspensions lists suspended users whose suspension ended by now.
+	ExpiredSuspensions(now time.Time) ([]uuid.UUID, error)
+}
+
+type GormAccountStatusRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormAccountStatusRepository) SetStatus(change *models.AccountStatusChange) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return setAccountStatus(tx, change)
+	})
+}
+
+// setAccountStatus applies change inside tx. Only the state columns are
+// written so a concurrent profile update cannot undo it.
+func setAccountStatus(tx *gorm.DB, change *models.AccountStatusChange) error {
+	var user models.User
+	if err := tx.Select("id", "status").First(&user, "id = ?", change.UserID).Error; err != nil {
+		return err
+	}
+	change.From = user.Status
+	err := tx.Model(&models.User{}).Where("id = ?", change.UserID).Updates(map[string]interface{}{
+		"status":          change.To,
+		"status_reason":   change.Reason,
+		"suspended_until": change.Until,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(change).Error
+}
+
+func (r *GormAccountStatusRepository) History(userID uuid.UUID, limit, offset int) ([]models.AccountStatusChange, error) {
+	var changes []models.AccountStatusChange
+	err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").
+		Limit(limit).Offset(offset).Find(&changes).Error
+	return changes, err
+}
+
+func (r *GormAccountStatusRepository) ExpiredSuspensions(now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.DB.Model(&models.User{}).
+		Where("status = ? AND (suspended_until IS NULL OR suspended_until <= ?)", models.AccountSuspended, now).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DailyRevenue is the revenue of one model on one day (UTC).
+type DailyRevenue struct {
+	Day       string // YYYY-MM-DD
+	Gross     int
+	Net       int
+	Purchases int
+	Tips      int
+	TipGross  int
+}
+
+// DailyCount counts events on one day (UTC).
+type DailyCount struct {
+	Day   string // YYYY-MM-DD
+	Count int
+}
+
+// PostRevenue is what a single post earned from purchases and tips.
+type PostRevenue struct {
+	PostID    uuid.UUID
+	Text      string
+	Purchases int
+	Gross     int
+	Net       int
+}
+
+// VideoCompletion counts viewers who started and finished the videos of a
+// model's posts, by when they started.
+type VideoCompletion struct {
+	Started   int
+	Completed int
+}
+
+// AnalyticsRepository aggregates creator activity per day within [from, to).
+// Rows exist only for days with activity.
+type AnalyticsRepository interface {
+	DailyRevenue(modelID uuid.UUID, from, to time.Time) ([]DailyRevenue, error)
+	DailyFollowers(userID uuid.UUID, from, to time.Time) ([]DailyCount, error)
+	DailyLikes(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error)
+	DailySaves(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error)
+	DailyViews(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error)
+	VideoCompletion(modelID uuid.UUID, from, to time.Time) (VideoCompletion, error)
+	TopPosts(modelID uuid.UUID, from, to time.Time, limit int) ([]PostRevenue, error)
+	Buyers(modelID uuid.UUID, from, to time.Time) (int, error)
+}
+
+// GormAnalyticsRepository groups by DATE(), which truncates timestamps to
+// the day in both Postgres and SQLite.
+type GormAnalyticsRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormAnalyticsRepository) DailyRevenue(modelID uuid.UUID, from, to time.Time) ([]DailyRevenue, error) {
+	var rows []DailyRevenue
+	err := r.DB.Model(&models.Earning{}).
+		Select("DATE(created_at) AS day, SUM(gross) AS gross, SUM(net) AS net, "+
+			"SUM(CASE WHEN source = ? THEN 1 ELSE 0 END) AS purchases, "+
+			"SUM(CASE WHEN source = ? THEN 1 ELSE 0 END) AS tips, "+
+			"SUM(CASE WHEN source = ? THEN gross ELSE 0 END) AS tip_gross",
+			models.EarningPurchase, models.EarningTip, models.EarningTip).
+		Where("model_id = ? AND created_at >= ? AND created_at < ?", modelID, from, to).
+		Group("DATE(created_at)").Order("day").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormAnalyticsRepository) DailyFollowers(userID uuid.UUID, from, to time.Time) ([]DailyCount, error) {
+	var rows []DailyCount
+	err := r.DB.Model(&models.Follow{}).
+		Select("DATE(created_at) AS day, COUNT(*) AS count").
+		Where("followed_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Group("DATE(created_at)").Order("day").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormAnalyticsRepository) DailyLikes(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error) {
+	return r.dailyPostEvents("likes", modelID, from, to)
+}
+
+func (r *GormAnalyticsRepository) DailySaves(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error) {
+	return r.dailyPostEvents("saved_posts", modelID, from, to)
+}
+
+// DailyViews counts views of whole posts; media views are not included.
+func (r *GormAnalyticsRepository) DailyViews(modelID uuid.UUID, from, to time.Time) ([]DailyCount, error) {
+	var rows []DailyCount
+	err := r.DB.Table("post_views AS e").
+		Select("DATE(e.created_at) AS day, COUNT(*) AS count").
+		Joins("JOIN posts p ON p.id = e.post_id").
+		Where("p.model_id = ? AND e.media_id IS NULL AND e.created_at >= ? AND e.created_at < ?", modelID, from, to).
+		Group("DATE(e.created_at)").Order("day").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormAnalyticsRepository) VideoCompletion(modelID uuid.UUID, from, to time.Time) (VideoCompletion, error) {
+	var c VideoCompletion
+	err := r.DB.Table("video_watches AS e").
+		Select("COUNT(*) AS started, COALESCE(SUM(CASE WHEN e.completed THEN 1 ELSE 0 END), 0) AS completed").
+		Joins("JOIN posts p ON p.id = e.post_id").
+		Where("p.model_id = ? AND e.created_at >= ? AND e.created_at < ?", modelID, from, to).
+		Scan(&c).Error
+	return c, err
+}
+
+// dailyPostEvents counts rows of a per-post table (likes, saved_posts) on
+// the model's posts.
+func (r *GormAnalyticsRepository) dailyPostEvents(table string, modelID uuid.UUID, from, to time.Time) ([]DailyCount, error) {
+	var rows []DailyCount
+	err := r.DB.Table(table+" AS e").
+		Select("DATE(e.created_at) AS day, COUNT(*) AS count").
+		Joins("JOIN posts p ON p.id = e.post_id").
+		Where("p.model_id = ? AND e.created_at >= ? AND e.created_at < ?", modelID, from, to).
+		Group("DATE(e.created_at)").Order("day").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormAnalyticsRepository) TopPosts(modelID uuid.UUID, from, to time.Time, limit int) ([]PostRevenue, error) {
+	var rows []PostRevenue
+	err := r.DB.Table("earnings AS e").
+		Select("e.post_id, p.text, SUM(CASE WHEN e.source = ? THEN 1 ELSE 0 END) AS purchases, "+
+			"SUM(e.gross) AS gross, SUM(e.net) AS net", models.EarningPurchase).
+		Joins("JOIN posts p ON p.id = e.post_id").
+		Where("e.model_id = ? AND e.created_at >= ? AND e.created_at < ?", modelID, from, to).
+		Group("e.post_id, p.text").Order("net DESC").Limit(limit).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormAnalyticsRepository) Buyers(modelID uuid.UUID, from, to time.Time) (int, error) {
+	var n int64
+	err := r.DB.Table("purchases AS pu").
+		Joins("JOIN posts p ON p.id = pu.post_id").
+		Where("p.model_id = ? AND pu.created_at >= ? AND pu.created_at < ?", modelID, from, to).
+		Distinct("pu.user_id").Count(&n).Error
+	return int(n), err
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// auditLockKey serializes appends to the audit chain across instances on
+// Postgres.
+const auditLockKey = 0x61756469
+
+// AuditFilter narrows the audit log; zero fields match everything.
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+type AuditRepository interface {
+	// Append links entry to the end of the chain: it sets Seq and PrevHash
+	// from the last entry, lets seal compute the hash and stores the entry,
+	// all while holding the chain lock.
+	Append(entry *models.AuditEntry, seal func(*models.AuditEntry)) error
+	// List returns matching entries, newest first.
+	List(filter AuditFilter, limit, offset int) ([]models.AuditEntry, error)
+	// Chain returns up to limit entries after seq in chain order.
+	Chain(afterSeq int64, limit int) ([]models.AuditEntry, error)
+	// Snapshot loads the rows of table whose key column equals value.
+	Snapshot(table, key, value string) ([]map[string]interface{}, error)
+}
+
+type GormAuditRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormAuditRepository) Append(entry *models.AuditEntry, seal func(*models.AuditEntry)) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLockKey).Error; err != nil {
+				return err
+			}
+		}
+		var last models.AuditEntry
+		err := tx.Order("seq DESC").Take(&last).Error
+		switch {
+		case err == nil:
+			entry.Seq = last.Seq + 1
+			entry.PrevHash = last.Hash
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			entry.Seq = 1
+			entry.PrevHash = ""
+		default:
+			return err
+		}
+		seal(entry)
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *GormAuditRepository) List(filter AuditFilter, limit, offset int) ([]models.AuditEntry, error) {
+	q := r.DB.Model(&models.AuditEntry{})
+	if filter.ActorID != nil {
+		q = q.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		q = q.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		q = q.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Since != nil {
+		q = q.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		q = q.Where("created_at < ?", *filter.Until)
+	}
+	var entries []models.AuditEntry
+	err := q.Order("seq DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, err
+}
+
+func (r *GormAuditRepository) Chain(afterSeq int64, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := r.DB.Where("seq > ?", afterSeq).Order("seq").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (r *GormAuditRepository) Snapshot(table, key, value string) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	err := r.DB.Table(table).Where(key+" = ?", value).Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RelatedUser is a user on a block or mute list with when it started.
+type RelatedUser struct {
+	models.User
+	Since time.Time
+}
+
+type BlockRepository interface {
+	// Block records the block and removes follows in both directions;
+	// created is false when it already existed.
+	Block(blockerID, blockedID uuid.UUID) (created bool, err error)
+	Unblock(blockerID, blockedID uuid.UUID) (deleted bool, err error)
+	Mute(muterID, mutedID uuid.UUID) (created bool, err error)
+	Unmute(muterID, mutedID uuid.UUID) (deleted bool, err error)
+	// Blocked and Muted list whom userID blocked or muted, newest first.
+	Blocked(userID uuid.UUID, limit, offset int) ([]RelatedUser, error)
+	Muted(userID uuid.UUID, limit, offset int) ([]RelatedUser, error)
+	// Between reports whether either user blocks the other.
+	Between(a, b uuid.UUID) (bool, error)
+	// Hides reports whether viewerID must not see userID's content: either
+	// blocks the other or viewerID muted userID.
+	Hides(viewerID, userID uuid.UUID) (bool, error)
+	// BlockSets returns whom userID blocks and who blocks userID.
+	BlockSets(userID uuid.UUID) (blocked, blockedBy map[uuid.UUID]bool, err error)
+	// HiddenFrom lists the users viewerID is blocked with, plus those
+	// viewerID muted when withMuted is set.
+	HiddenFrom(viewerID uuid.UUID, withMuted bool) ([]uuid.UUID, error)
+}
+
+type GormBlockRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormBlockRepository) Block(blockerID, blockedID uuid.UUID) (bool, error) {
+	created := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserBlock{BlockerID: blockerID, BlockedID: blockedID})
+		if res.Error != nil {
+			return res.Error
+		}
+		created = res.RowsAffected > 0
+		return tx.Where("(follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).Delete(&models.Follow{}).Error
+	})
+	return created, err
+}
+
+func (r *GormBlockRepository) Unblock(blockerID, blockedID uuid.UUID) (bool, error) {
+	res := r.DB.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.UserBlock{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormBlockRepository) Mute(muterID, mutedID uuid.UUID) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserMute{MuterID: muterID, MutedID: mutedID})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormBlockRepository) Unmute(muterID, mutedID uuid.UUID) (bool, error) {
+	res := r.DB.Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&models.UserMute{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormBlockRepository) Blocked(userID uuid.UUID, limit, offset int) ([]RelatedUser, error) {
+	return r.related("user_blocks", "blocker_id", "blocked_id", userID, limit, offset)
+}
+
+func (r *GormBlockRepository) Muted(userID uuid.UUID, limit, offset int) ([]RelatedUser, error) {
+	return r.related("user_mutes", "muter_id", "muted_id", userID, limit, offset)
+}
+
+func (r *GormBlockRepository) related(table, ownerColumn, userColumn string, userID uuid.UUID, limit, offset int) ([]RelatedUser, error) {
+	var rows []RelatedUser
+	err := r.DB.Table("users").
+		Select("users.*, rel.created_at AS since").
+		Joins("JOIN "+table+" rel ON rel."+userColumn+" = users.id").
+		Where("rel."+ownerColumn+" = ?", userID).
+		Order("rel.created_at DESC").Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormBlockRepository) Between(a, b uuid.UUID) (bool, error) {
+	var n int64
+	err := r.DB.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormBlockRepository) Hides(viewerID, userID uuid.UUID) (bool, error) {
+	blocked, err := r.Between(viewerID, userID)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	var n int64
+	err = r.DB.Model(&models.UserMute{}).Where("muter_id = ? AND muted_id = ?", viewerID, userID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormBlockRepository) BlockSets(userID uuid.UUID) (map[uuid.UUID]bool, map[uuid.UUID]bool, error) {
+	var rows []models.UserBlock
+	if err := r.DB.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	blocked, blockedBy := map[uuid.UUID]bool{}, map[uuid.UUID]bool{}
+	for _, b := range rows {
+		if b.BlockerID == userID {
+			blocked[b.BlockedID] = true
+		} else {
+			blockedBy[b.BlockerID] = true
+		}
+	}
+	return blocked, blockedBy, nil
+}
+
+func (r *GormBlockRepository) HiddenFrom(viewerID uuid.UUID, withMuted bool) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.DB.Model(&models.UserBlock{}).
+		Select("CASE WHEN blocker_id = ? THEN blocked_id ELSE blocker_id END", viewerID).
+		Where("blocker_id = ? OR blocked_id = ?", viewerID, viewerID).
+		Scan(&ids).Error
+	if err != nil || !withMuted {
+		return ids, err
+	}
+	var muted []uuid.UUID
+	if err := r.DB.Model(&models.UserMute{}).Where("muter_id = ?", viewerID).Pluck("muted_id", &muted).Error; err != nil {
+		return nil, err
+	}
+	return append(ids, muted...), nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CreatorApplicationRepository interface {
+	Create(app *models.CreatorApplication) error
+	FindByID(id uuid.UUID) (*models.CreatorApplication, error)
+	// FindLatestForUser returns the user's most recent application.
+	FindLatestForUser(userID uuid.UUID) (*models.CreatorApplication, error)
+	// ListByStatus returns applications oldest first, so the queue is worked FIFO.
+	ListByStatus(status string, limit, offset int) ([]models.CreatorApplication, error)
+	// Approve marks the application approved, creates or updates the
+	// applicant's model profile as verified and grants the creator role, all
+	// in one transaction. grant and change are nil when the role is already held.
+	Approve(app *models.CreatorApplication, grant *models.UserRole, change *models.RoleChange) (*models.ModelProfile, error)
+	Reject(app *models.CreatorApplication) error
+}
+
+type GormCreatorApplicationRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormCreatorApplicationRepository) Create(app *models.CreatorApplication) error {
+	return r.DB.Create(app).Error
+}
+
+func (r *GormCreatorApplicationRepository) FindByID(id uuid.UUID) (*models.CreatorApplication, error) {
+	var app models.CreatorApplication
+	if err := r.DB.Preload("Documents").First(&app, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *GormCreatorApplicationRepository) FindLatestForUser(userID uuid.UUID) (*models.CreatorApplication, error) {
+	var app models.CreatorApplication
+	if err := r.DB.Preload("Documents").Where("user_id = ?", userID).Order("created_at DESC").First(&app).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *GormCreatorApplicationRepository) ListByStatus(status string, limit, offset int) ([]models.CreatorApplication, error) {
+	q := r.DB.Preload("Documents")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	var apps []models.CreatorApplication
+	if err := q.Order("created_at").Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+func (r *GormCreatorApplicationRepository) Approve(app *models.CreatorApplication, grant *models.UserRole, change *models.RoleChange) (*models.ModelProfile, error) {
+	var profile models.ModelProfile
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Documents").Save(app).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		err := tx.Where("user_id = ?", app.UserID).First(&profile).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			profile = models.ModelProfile{UserID: app.UserID, Name: app.Name, Bio: app.Bio, Banner: app.Banner, VerifiedAt: &now}
+			if err := tx.Create(&profile).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			profile.VerifiedAt = &now
+			if err := tx.Omit("User").Save(&profile).Error; err != nil {
+				return err
+			}
+		}
+		if grant == nil {
+			return nil
+		}
+		if err := tx.Create(grant).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *GormCreatorApplicationRepository) Reject(app *models.CreatorApplication) error {
+	return r.DB.Omit("Documents").Save(app).Error
+}
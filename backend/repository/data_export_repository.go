@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserData is everything kept about one user, as collected for an export.
+type UserData struct {
+	Profile    models.User
+	Models     []models.ModelProfile
+	Posts      []models.Post
+	Videos     []models.Video
+	Images     []models.Image
+	Purchases  []models.Purchase
+	Orders     []models.Order
+	Payments   []models.Payment
+	Tips       []models.Tip
+	Earnings   []models.Earning
+	Payouts    []models.Payout
+	Following  []models.Follow
+	Followers  []models.Follow
+	Likes      []models.Like
+	SavedPosts []models.SavedPost
+	Comments   []models.Comment
+	Referrals  []models.Referral
+	ReferredBy []models.Referral
+}
+
+type DataExportRepository interface {
+	Create(export *models.DataExport) error
+	FindByID(id uuid.UUID) (*models.DataExport, error)
+	// Pending returns the user's export still being built, if any. Exports
+	// requested before since are not counted: their build was lost.
+	Pending(userID uuid.UUID, since time.Time) (*models.DataExport, error)
+	// FailStale marks the pending exports requested before cutoff failed
+	// with reason and returns how many there were.
+	FailStale(cutoff time.Time, reason string, now time.Time) (int64, error)
+	// ListByUser returns the user's exports, newest first.
+	ListByUser(userID uuid.UUID) ([]models.DataExport, error)
+	Update(export *models.DataExport) error
+	// Expired lists ready exports whose download link ran out before now.
+	Expired(now time.Time) ([]models.DataExport, error)
+	Delete(id uuid.UUID) error
+	// Collect loads the user's data. Deleted posts and uploads are
+	// included: they are still kept until purged.
+	Collect(userID uuid.UUID) (*UserData, error)
+}
+
+type GormDataExportRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormDataExportRepository) Create(export *models.DataExport) error {
+	return r.DB.Create(export).Error
+}
+
+func (r *GormDataExportRepository) FindByID(id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.DB.First(&export, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *GormDataExportRepository) Pending(userID uuid.UUID, since time.Time) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.DB.Where("user_id = ? AND status = ? AND created_at >= ?", userID, models.ExportPending, since).First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *GormDataExportRepository) FailStale(cutoff time.Time, reason string, now time.Time) (int64, error) {
+	res := r.DB.Model(&models.DataExport{}).Where("status = ? AND created_at < ?", models.ExportPending, cutoff).
+		Updates(map[string]interface{}{"status": models.ExportFailed, "error": reason, "completed_at": now})
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormDataExportRepository) ListByUser(userID uuid.UUID) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error
+	return exports, err
+}
+
+func (r *GormDataExportRepository) Update(export *models.DataExport) error {
+	return r.DB.Save(export).Error
+}
+
+func (r *GormDataExportRepository) Expired(now time.Time) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.DB.Where("status = ? AND expires_at < ?", models.ExportReady, now).Find(&exports).Error
+	return exports, err
+}
+
+func (r *GormDataExportRepository) Delete(id uuid.UUID) error {
+	return r.DB.Delete(&models.DataExport{}, "id = ?", id).Error
+}
+
+func (r *GormDataExportRepository) Collect(userID uuid.UUID) (*UserData, error) {
+	data := &UserData{}
+	if err := r.DB.Unscoped().First(&data.Profile, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	queries := []struct {
+		dest interface{}
+		db   *gorm.DB
+	}{
+		{&data.Models, r.DB.Where("user_id = ?", userID)},
+		{&data.Posts, r.DB.Unscoped().Preload("Media").Where("user_id = ?", userID).Order("published_at")},
+		{&data.Videos, r.DB.Unscoped().Where("user_id = ?", userID)},
+		{&data.Images, r.DB.Unscoped().Where("user_id = ?", userID)},
+		{&data.Purchases, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.Orders, r.DB.Where("user_id = ?", userID)},
+		{&data.Tips, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.Earnings, r.DB.Where("creator_id = ?", userID).Order("created_at")},
+		{&data.Payouts, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.Following, r.DB.Where("follower_id = ?", userID).Order("created_at")},
+		{&data.Followers, r.DB.Where("followed_id = ?", userID).Order("created_at")},
+		{&data.Likes, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.SavedPosts, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.Comments, r.DB.Where("user_id = ?", userID).Order("time")},
+		{&data.Referrals, r.DB.Where("user_id = ?", userID).Order("created_at")},
+		{&data.ReferredBy, r.DB.Where("invited_user_id = ?", userID)},
+	}
+	for _, q := range queries {
+		if err := q.db.Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	// Payments reference the order they paid for by its ID.
+	var orderIDs []string
+	for _, o := range data.Orders {
+		orderIDs = append(orderIDs, o.ID.String())
+	}
+	if len(orderIDs) > 0 {
+		if err := r.DB.Where("order_number IN ?", orderIDs).Order("created_at").Find(&data.Payments).Error; err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailTokenRepository interface {
+	Create(token *models.EmailToken) error
+	FindByHash(hash, purpose string) (models.EmailToken, error)
+	// MarkUsed consumes the token; it reports false if it was already used.
+	MarkUsed(id uuid.UUID, at time.Time) (bool, error)
+	// InvalidateForUser consumes every outstanding token of the purpose.
+	InvalidateForUser(userID uuid.UUID, purpose string, at time.Time) error
+	CountSentSince(email, purpose string, since time.Time) (int64, error)
+}
+
+type GormEmailTokenRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormEmailTokenRepository) Create(token *models.EmailToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *GormEmailTokenRepository) FindByHash(hash, purpose string) (models.EmailToken, error) {
+	var token models.EmailToken
+	if err := r.DB.Where("token_hash = ? AND purpose = ?", hash, purpose).First(&token).Error; err != nil {
+		return token, err
+	}
+	return token, nil
+}
+
+func (r *GormEmailTokenRepository) MarkUsed(id uuid.UUID, at time.Time) (bool, error) {
+	res := r.DB.Model(&models.EmailToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *GormEmailTokenRepository) InvalidateForUser(userID uuid.UUID, purpose string, at time.Time) error {
+	return r.DB.Model(&models.EmailToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+func (r *GormEmailTokenRepository) CountSentSince(email, purpose string, since time.Time) (int64, error) {
+	var n int64
+	err := r.DB.Model(&models.EmailToken{}).
+		Where("email = ? AND purpose = ? AND created_at >= ?", email, purpose, since).
+		Count(&n).Error
+	return n, err
+}
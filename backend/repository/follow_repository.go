@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowCounts are how many users follow a user and how many it follows.
+type FollowCounts struct {
+	Followers int
+	Following int
+}
+
+// FollowEdge is a user on a followers or following list with when the
+// follow started.
+type FollowEdge struct {
+	models.User
+	FollowedAt time.Time
+}
+
+type FollowRepository interface {
+	// Follow records the follow; created is false when it already existed.
+	Follow(followerID, followedID uuid.UUID) (created bool, err error)
+	// Unfollow removes the follow; deleted is false when there was none.
+	Unfollow(followerID, followedID uuid.UUID) (deleted bool, err error)
+	// Followers lists who follows userID, most recent first, leaving out
+	// withdrawn users and excludeUserIDs.
+	Followers(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]FollowEdge, error)
+	// Following lists whom userID follows, most recent first, leaving out
+	// withdrawn users and excludeUserIDs.
+	Following(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]FollowEdge, error)
+	Counts(userIDs []uuid.UUID) (map[uuid.UUID]FollowCounts, error)
+	// FollowedBy reports which of userIDs followerID follows.
+	FollowedBy(followerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// GormFollowRepository counts follows on demand; follows(follower_id) and
+// follows(followed_id) are indexed, so counts stay cheap and never drift.
+type GormFollowRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormFollowRepository) Follow(followerID, followedID uuid.UUID) (bool, error) {
+	f := models.Follow{ID: uuid.New(), FollowerID: followerID, FollowedID: followedID}
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&f)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormFollowRepository) Unfollow(followerID, followedID uuid.UUID) (bool, error) {
+	res := r.DB.Where("follower_id = ? AND followed_id = ?", followerID, followedID).Delete(&models.Follow{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormFollowRepository) Followers(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]FollowEdge, error) {
+	return r.edges("follows.follower_id", "follows.followed_id", userID, excludeUserIDs, limit, offset)
+}
+
+func (r *GormFollowRepository) Following(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]FollowEdge, error) {
+	return r.edges("follows.followed_id", "follows.follower_id", userID, excludeUserIDs, limit, offset)
+}
+
+// edges joins the users on the other column of the follows of userID.
+// Banned, deactivated and deleted users are left out like in feeds.
+func (r *GormFollowRepository) edges(userColumn, ownerColumn string, userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]FollowEdge, error) {
+	var rows []FollowEdge
+	q := r.DB.Table("users").
+		Select("users.*, follows.created_at AS followed_at").
+		Joins("JOIN follows ON "+userColumn+" = users.id").
+		Where(ownerColumn+" = ?", userID).
+		Where("users.id NOT IN (?)", withdrawnUsers(r.DB))
+	if len(excludeUserIDs) > 0 {
+		q = q.Where("users.id NOT IN ?", excludeUserIDs)
+	}
+	err := q.Order("follows.created_at DESC").Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormFollowRepository) Counts(userIDs []uuid.UUID) (map[uuid.UUID]FollowCounts, error) {
+	counts := map[uuid.UUID]FollowCounts{}
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		UserID uuid.UUID
+		Count  int
+	}
+	if err := r.DB.Model(&models.Follow{}).Select("followed_id AS user_id, COUNT(*) AS count").
+		Where("followed_id IN ?", userIDs).Group("followed_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		c := counts[row.UserID]
+		c.Followers = row.Count
+		counts[row.UserID] = c
+	}
+	rows = nil
+	if err := r.DB.Model(&models.Follow{}).Select("follower_id AS user_id, COUNT(*) AS count").
+		Where("follower_id IN ?", userIDs).Group("follower_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		c := counts[row.UserID]
+		c.Following = row.Count
+		counts[row.UserID] = c
+	}
+	return counts, nil
+}
+
+func (r *GormFollowRepository) FollowedBy(followerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	followed := map[uuid.UUID]bool{}
+	if followerID == uuid.Nil || len(userIDs) == 0 {
+		return followed, nil
+	}
+	var ids []uuid.UUID
+	err := r.DB.Model(&models.Follow{}).Where("follower_id = ? AND followed_id IN ?", followerID, userIDs).
+		Pluck("followed_id", &ids).Error
+	for _, id := range ids {
+		followed[id] = true
+	}
+	return followed, err
+}
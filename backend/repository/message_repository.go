@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MessageRepository interface {
+	// FindConversation loads the conversation with its members and their users.
+	FindConversation(id uuid.UUID) (*models.Conversation, error)
+	// FindOrCreateConversation returns the conversation between a and b,
+	// creating it when there is none; created reports which happened.
+	FindOrCreateConversation(a, b uuid.UUID) (conv *models.Conversation, created bool, err error)
+	// ListConversations returns the user's conversations with messages, most
+	// recent activity first.
+	ListConversations(userID uuid.UUID, limit, offset int) ([]models.Conversation, error)
+	// LastMessages returns the newest message of each conversation.
+	LastMessages(conversationIDs []uuid.UUID) (map[uuid.UUID]models.Message, error)
+	// UnreadCounts counts messages to userID newer than their LastReadAt.
+	UnreadCounts(userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	// CreateMessage stores the message with its attachments and bumps the
+	// conversation's LastMessageAt.
+	CreateMessage(m *models.Message) error
+	// ListMessages returns a conversation's messages, newest first.
+	ListMessages(conversationID uuid.UUID, limit, offset int) ([]models.Message, error)
+	FindMessage(id uuid.UUID) (*models.Message, error)
+	UpdateMember(member *models.ConversationMember) error
+	// UnlockedIDs reports which of the messages the user has unlocked.
+	UnlockedIDs(userID uuid.UUID, messageIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// Unlock charges the user unlock.Price and stores the unlock and the
+	// sender's earning in one transaction.
+	Unlock(unlock *models.MessageUnlock, earning *models.Earning) error
+}
+
+type GormMessageRepository struct {
+	DB *gorm.DB
+}
+
+// PairKey orders two user IDs so either side finds the same conversation.
+func PairKey(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+func (r *GormMessageRepository) FindConversation(id uuid.UUID) (*models.Conversation, error) {
+	var conv models.Conversation
+	if err := r.DB.Preload("Members.User").First(&conv, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+func (r *GormMessageRepository) FindOrCreateConversation(a, b uuid.UUID) (*models.Conversation, bool, error) {
+	key := PairKey(a, b)
+	var conv models.Conversation
+	err := r.DB.Where("pair_key = ?", key).First(&conv).Error
+	if err == nil {
+		found, err := r.FindConversation(conv.ID)
+		return found, false, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	conv = models.Conversation{
+		PairKey: key,
+		Members: []models.ConversationMember{{UserID: a}, {UserID: b}},
+	}
+	if err := r.DB.Create(&conv).Error; err != nil {
+		// lost a race with the other side opening the same conversation
+		if r.DB.Where("pair_key = ?", key).First(&models.Conversation{}).Error == nil {
+			return r.FindOrCreateConversation(a, b)
+		}
+		return nil, false, err
+	}
+	found, err := r.FindConversation(conv.ID)
+	return found, true, err
+}
+
+func (r *GormMessageRepository) ListConversations(userID uuid.UUID, limit, offset int) ([]models.Conversation, error) {
+	var convs []models.Conversation
+	mine := r.DB.Model(&models.ConversationMember{}).Select("conversation_id").Where("user_id = ?", userID)
+	err := r.DB.Preload("Members.User").
+		Where("id IN (?)", mine).
+		Where("EXISTS (SELECT 1 FROM messages m WHERE m.conversation_id = conversations.id)").
+		Order("last_message_at DESC").Limit(limit).Offset(offset).
+		Find(&convs).Error
+	return convs, err
+}
+
+func (r *GormMessageRepository) LastMessages(conversationIDs []uuid.UUID) (map[uuid.UUID]models.Message, error) {
+	last := map[uuid.UUID]models.Message{}
+	if len(conversationIDs) == 0 {
+		return last, nil
+	}
+	var messages []models.Message
+	latest := r.DB.Model(&models.Message{}).
+		Select("conversation_id, MAX(created_at) AS at").
+		Where("conversation_id IN ? AND hidden_at IS NULL", conversationIDs).
+		Group("conversation_id")
+	err := r.DB.Preload("Attachments").
+		Joins("JOIN (?) l ON l.conversation_id = messages.conversation_id AND l.at = messages.created_at", latest).
+		Where("messages.hidden_at IS NULL").
+		Find(&messages).Error
+	for _, m := range messages {
+		last[m.ConversationID] = m
+	}
+	return last, err
+}
+
+func (r *GormMessageRepository) UnreadCounts(userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := map[uuid.UUID]int{}
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ConversationID uuid.UUID
+		Count          int
+	}
+	err := r.DB.Table("messages AS m").
+		Select("m.conversation_id, COUNT(*) AS count").
+		Joins("JOIN conversation_members cm ON cm.conversation_id = m.conversation_id AND cm.user_id = ?", userID).
+		Where("m.conversation_id IN ? AND m.sender_id <> ? AND m.hidden_at IS NULL", conversationIDs, userID).
+		Where("cm.last_read_at IS NULL OR m.created_at > cm.last_read_at").
+		Group("m.conversation_id").Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, err
+}
+
+func (r *GormMessageRepository) CreateMessage(m *models.Message) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Conversation{}).Where("id = ?", m.ConversationID).
+			Update("last_message_at", m.CreatedAt).Error
+	})
+}
+
+func (r *GormMessageRepository) ListMessages(conversationID uuid.UUID, limit, offset int) ([]models.Message, error) {
+	var messages []models.Message
+	err := r.DB.Preload("Attachments").Where("conversation_id = ? AND hidden_at IS NULL", conversationID).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormMessageRepository) FindMessage(id uuid.UUID) (*models.Message, error) {
+	var m models.Message
+	if err := r.DB.Preload("Attachments").First(&m, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *GormMessageRepository) UpdateMember(member *models.ConversationMember) error {
+	return r.DB.Model(member).Select("last_read_at", "blocked_at").Updates(member).Error
+}
+
+func (r *GormMessageRepository) UnlockedIDs(userID uuid.UUID, messageIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	unlocked := map[uuid.UUID]bool{}
+	if len(messageIDs) == 0 {
+		return unlocked, nil
+	}
+	var ids []uuid.UUID
+	err := r.DB.Model(&models.MessageUnlock{}).Where("user_id = ? AND message_id IN ?", userID, messageIDs).
+		Pluck("message_id", &ids).Error
+	for _, id := range ids {
+		unlocked[id] = true
+	}
+	return unlocked, err
+}
+
+func (r *GormMessageRepository) Unlock(unlock *models.MessageUnlock, earning *models.Earning) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := chargeBalance(tx, unlock.UserID, unlock.Price); err != nil {
+			return err
+		}
+		if err := tx.Create(unlock).Error; err != nil {
+			return err
+		}
+		return tx.Create(earning).Error
+	})
+}
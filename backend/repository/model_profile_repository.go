@@ -8,8 +8,10 @@ import (
 )
 
 type ModelProfileRepository interface {
-	FindAll(limit, offset int) ([]models.ModelProfile, error)
+	// FindAll lists profiles, leaving out hidden ones and those of excludeUserIDs.
+	FindAll(limit, offset int, excludeUserIDs []uuid.UUID) ([]models.ModelProfile, error)
 	Create(profile *models.ModelProfile) error
+	FindByID(id uuid.UUID) (models.ModelProfile, error)
 	FindByUserID(userID uuid.UUID) (models.ModelProfile, error)
 }
 
@@ -17,9 +19,13 @@ type GormModelProfileRepository struct {
 	DB *gorm.DB
 }
 
-func (r *GormModelProfileRepository) FindAll(limit, offset int) ([]models.ModelProfile, error) {
+func (r *GormModelProfileRepository) FindAll(limit, offset int, excludeUserIDs []uuid.UUID) ([]models.ModelProfile, error) {
 	var profiles []models.ModelProfile
-	q := r.DB.Preload("User")
+	q := r.DB.Preload("User").Where("hidden_at IS NULL").
+		Where("user_id NOT IN (?)", withdrawnUsers(r.DB))
+	if len(excludeUserIDs) > 0 {
+		q = q.Where("user_id NOT IN ?", excludeUserIDs)
+	}
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -43,3 +49,11 @@ func (r *GormModelProfileRepository) FindByUserID(userID uuid.UUID) (models.Mode
 	}
 	return profile, nil
 }
+
+func (r *GormModelProfileRepository) FindByID(id uuid.UUID) (models.ModelProfile, error) {
+	var profile models.ModelProfile
+	if err := r.DB.First(&profile, "id = ?", id).Error; err != nil {
+		return profile, err
+	}
+	return profile, nil
+}
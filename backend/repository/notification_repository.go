@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	// Merge folds n into the recipient's unread notification of the same type
+	// and subject last updated at or after since, or creates n when there is
+	// none. n is updated to the stored notification.
+	Merge(n *models.Notification, since time.Time) error
+	CreateMany(notifications []models.Notification) error
+	// List returns the user's notifications, most recently updated first,
+	// with the actor preloaded.
+	List(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, error)
+	CountUnread(userID uuid.UUID) (int64, error)
+	// MarkRead returns gorm.ErrRecordNotFound unless the notification
+	// belongs to the user.
+	MarkRead(userID, id uuid.UUID, at time.Time) error
+	MarkAllRead(userID uuid.UUID, at time.Time) (int64, error)
+	Preferences(userID uuid.UUID) ([]models.NotificationPreference, error)
+	SavePreferences(prefs []models.NotificationPreference) error
+	// Enabled reports whether the user receives notifications of the type.
+	Enabled(userID uuid.UUID, notificationType string) (bool, error)
+	// FollowersToNotify lists followers of userID who have the type enabled
+	// and have not muted userID.
+	FollowersToNotify(userID uuid.UUID, notificationType string) ([]uuid.UUID, error)
+}
+
+type GormNotificationRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormNotificationRepository) Merge(n *models.Notification, since time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.Notification
+		q := tx.Where("user_id = ? AND type = ? AND read_at IS NULL AND updated_at >= ?", n.UserID, n.Type, since)
+		if n.SubjectID != nil {
+			q = q.Where("subject_id = ?", *n.SubjectID)
+		} else {
+			q = q.Where("subject_id IS NULL")
+		}
+		err := q.Order("updated_at DESC").First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Create(n).Error; err != nil {
+				return err
+			}
+			return addNotificationActors(tx, n.ID, n.ActorID)
+		}
+		if err != nil {
+			return err
+		}
+		// the same actor repeating an action (like, unlike, like, or A, B, A)
+		// counts once; rows created without actors keep their latest one
+		if err := addNotificationActors(tx, existing.ID, existing.ActorID, n.ActorID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.NotificationActor{}).Where("notification_id = ?", existing.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		existing.ActorID = n.ActorID
+		existing.ActorCount = int(count)
+		existing.UpdatedAt = n.UpdatedAt
+		if err := tx.Model(&existing).Select("actor_id", "actor_count", "updated_at").Updates(&existing).Error; err != nil {
+			return err
+		}
+		*n = existing
+		return nil
+	})
+}
+
+// addNotificationActors records the actors of a notification, skipping nil
+// ones and those already recorded.
+func addNotificationActors(tx *gorm.DB, notificationID uuid.UUID, actorIDs ...*uuid.UUID) error {
+	for _, id := range actorIDs {
+		if id == nil {
+			continue
+		}
+		actor := models.NotificationActor{NotificationID: notificationID, ActorID: *id}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&actor).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GormNotificationRepository) CreateMany(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.DB.CreateInBatches(&notifications, 500).Error
+}
+
+func (r *GormNotificationRepository) List(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	q := r.DB.Preload("Actor").Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	err := q.Order("updated_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *GormNotificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var n int64
+	err := r.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&n).Error
+	return n, err
+}
+
+func (r *GormNotificationRepository) MarkRead(userID, id uuid.UUID, at time.Time) error {
+	var n models.Notification
+	if err := r.DB.First(&n, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return err
+	}
+	if n.ReadAt != nil {
+		return nil
+	}
+	return r.DB.Model(&n).Update("read_at", at).Error
+}
+
+func (r *GormNotificationRepository) MarkAllRead(userID uuid.UUID, at time.Time) (int64, error) {
+	res := r.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormNotificationRepository) Preferences(userID uuid.UUID) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.DB.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *GormNotificationRepository) SavePreferences(prefs []models.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&prefs).Error
+}
+
+func (r *GormNotificationRepository) Enabled(userID uuid.UUID, notificationType string) (bool, error) {
+	var pref models.NotificationPreference
+	err := r.DB.Where("user_id = ? AND type = ?", userID, notificationType).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	return pref.Enabled, err
+}
+
+func (r *GormNotificationRepository) FollowersToNotify(userID uuid.UUID, notificationType string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	disabled := r.DB.Model(&models.NotificationPreference{}).Select("user_id").
+		Where("type = ? AND enabled = ?", notificationType, false)
+	muted := r.DB.Model(&models.UserMute{}).Select("muter_id").Where("muted_id = ?", userID)
+	err := r.DB.Model(&models.Follow{}).
+		Where("followed_id = ? AND follower_id NOT IN (?) AND follower_id NOT IN (?)", userID, disabled, muted).
+		Distinct().Pluck("follower_id", &ids).Error
+	return ids, err
+}
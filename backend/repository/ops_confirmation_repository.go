@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"go-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OpsConfirmationRepository interface {
+	// Use records the confirmation; used is false when its nonce was
+	// recorded before.
+	Use(c *models.UsedOpsConfirmation) (used bool, err error)
+}
+
+type GormOpsConfirmationRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormOpsConfirmationRepository) Use(c *models.UsedOpsConfirmation) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(c)
+	return res.RowsAffected > 0, res.Error
+}
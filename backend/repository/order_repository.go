@@ -3,11 +3,13 @@ package repository
 import (
 	"go-backend/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
-	FindAll(limit, offset int) ([]models.Order, error)
+	// FindAll lists the orders of userID, or of everyone when it is nil.
+	FindAll(userID *uuid.UUID, limit, offset int) ([]models.Order, error)
 	Create(order *models.Order) error
 }
 
@@ -15,9 +17,12 @@ type GormOrderRepository struct {
 	DB *gorm.DB
 }
 
-func (r *GormOrderRepository) FindAll(limit, offset int) ([]models.Order, error) {
+func (r *GormOrderRepository) FindAll(userID *uuid.UUID, limit, offset int) ([]models.Order, error) {
 	var orders []models.Order
 	q := r.DB
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
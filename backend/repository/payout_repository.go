@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientFunds is returned when a payout exceeds the available balance.
+var ErrInsufficientFunds = errors.New("amount exceeds the available balance")
+
+// PayoutTotals sums a creator's earnings and payouts.
+type PayoutTotals struct {
+	Held      int // net earnings still in the holding period
+	Cleared   int // net earnings past the holding period
+	Reserved  int // pending and processing payouts
+	PaidOut   int
+	Available int // Cleared minus Reserved and PaidOut
+}
+
+// PayoutFilter narrows payout listings. Zero values match everything.
+type PayoutFilter struct {
+	UserID *uuid.UUID
+	Status string
+	Limit  int
+	Offset int
+}
+
+type PayoutRepository interface {
+	Totals(userID uuid.UUID, now time.Time) (PayoutTotals, error)
+	ListEarnings(userID uuid.UUID, limit, offset int) ([]models.Earning, error)
+	ListPayouts(filter PayoutFilter) ([]models.Payout, error)
+	FindPayout(id uuid.UUID) (*models.Payout, error)
+	// CreatePayout stores the request if the available balance covers it.
+	// The user row is locked so concurrent requests cannot overdraw.
+	CreatePayout(payout *models.Payout, now time.Time) error
+	// UpdatePayout saves the payout's new state if it is still in state
+	// from, and fails with gorm.ErrRecordNotFound otherwise. This keeps two
+	// reviewers from acting on the same payout.
+	UpdatePayout(payout *models.Payout, from string) error
+}
+
+type GormPayoutRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormPayoutRepository) Totals(userID uuid.UUID, now time.Time) (PayoutTotals, error) {
+	return totals(r.DB, userID, now)
+}
+
+func totals(db *gorm.DB, userID uuid.UUID, now time.Time) (PayoutTotals, error) {
+	var t PayoutTotals
+	var earned struct{ Held, Cleared int }
+	err := db.Model(&models.Earning{}).
+		Select("COALESCE(SUM(CASE WHEN available_at > ? THEN net ELSE 0 END), 0) AS held, "+
+			"COALESCE(SUM(CASE WHEN available_at <= ? THEN net ELSE 0 END), 0) AS cleared", now, now).
+		Where("creator_id = ?", userID).Scan(&earned).Error
+	if err != nil {
+		return t, err
+	}
+	var paid struct{ Reserved, PaidOut int }
+	err = db.Model(&models.Payout{}).
+		Select("COALESCE(SUM(CASE WHEN status IN ? THEN amount ELSE 0 END), 0) AS reserved, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN amount ELSE 0 END), 0) AS paid_out",
+			[]string{models.PayoutPending, models.PayoutProcessing}, models.PayoutPaid).
+		Where("user_id = ?", userID).Scan(&paid).Error
+	if err != nil {
+		return t, err
+	}
+	t.Held, t.Cleared = earned.Held, earned.Cleared
+	t.Reserved, t.PaidOut = paid.Reserved, paid.PaidOut
+	t.Available = t.Cleared - t.Reserved - t.PaidOut
+	return t, nil
+}
+
+func (r *GormPayoutRepository) ListEarnings(userID uuid.UUID, limit, offset int) ([]models.Earning, error) {
+	q := r.DB.Where("creator_id = ?", userID)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	var earnings []models.Earning
+	if err := q.Order("created_at DESC").Find(&earnings).Error; err != nil {
+		return nil, err
+	}
+	return earnings, nil
+}
+
+func (r *GormPayoutRepository) ListPayouts(filter PayoutFilter) ([]models.Payout, error) {
+	q := r.DB.Model(&models.Payout{})
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	order := "created_at DESC"
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+		if filter.Status == models.PayoutPending {
+			// the review queue is worked oldest first
+			order = "created_at"
+		}
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+	var payouts []models.Payout
+	if err := q.Order(order).Find(&payouts).Error; err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+func (r *GormPayoutRepository) FindPayout(id uuid.UUID) (*models.Payout, error) {
+	var payout models.Payout
+	if err := r.DB.First(&payout, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+func (r *GormPayoutRepository) CreatePayout(payout *models.Payout, now time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, "id = ?", payout.UserID).Error; err != nil {
+			return err
+		}
+		t, err := totals(tx, payout.UserID, now)
+		if err != nil {
+			return err
+		}
+		if payout.Amount > t.Available {
+			return ErrInsufficientFunds
+		}
+		return tx.Create(payout).Error
+	})
+}
+
+func (r *GormPayoutRepository) UpdatePayout(payout *models.Payout, from string) error {
+	res := r.DB.Model(&models.Payout{}).
+		Where("id = ? AND status = ?", payout.ID, from).
+		Updates(map[string]interface{}{
+			"status":       payout.Status,
+			"provider":     payout.Provider,
+			"provider_ref": payout.ProviderRef,
+			"reviewer_id":  payout.ReviewerID,
+			"reason":       payout.Reason,
+			"reviewed_at":  payout.ReviewedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"go-backend/models"
 
 	"github.com/google/uuid"
@@ -8,18 +10,26 @@ import (
 )
 
 type PostRepository interface {
-	FindAll(limit, offset int) ([]models.Post, error)
+	// FindAll lists posts, leaving out hidden ones and those by excludeUserIDs.
+	FindAll(limit, offset int, excludeUserIDs []uuid.UUID) ([]models.Post, error)
 	FindByID(id uuid.UUID) (models.Post, error)
 	Create(post *models.Post) error
+	// Delete soft-deletes the post, recording who deleted it
+	// (DeletedByOwner or DeletedByModeration).
+	Delete(id uuid.UUID, by string) error
 }
 
 type GormPostRepository struct {
 	DB *gorm.DB
 }
 
-func (r *GormPostRepository) FindAll(limit, offset int) ([]models.Post, error) {
+func (r *GormPostRepository) FindAll(limit, offset int, excludeUserIDs []uuid.UUID) ([]models.Post, error) {
 	var posts []models.Post
-	q := r.DB.Preload("User").Preload("Media").Preload("ModelProfile")
+	q := r.DB.Preload("User").Preload("Media").Preload("ModelProfile").Where("hidden_at IS NULL").
+		Where("user_id NOT IN (?)", withdrawnUsers(r.DB))
+	if len(excludeUserIDs) > 0 {
+		q = q.Where("user_id NOT IN ?", excludeUserIDs)
+	}
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -43,3 +53,8 @@ func (r *GormPostRepository) FindByID(id uuid.UUID) (models.Post, error) {
 func (r *GormPostRepository) Create(post *models.Post) error {
 	return r.DB.Create(post).Error
 }
+
+func (r *GormPostRepository) Delete(id uuid.UUID, by string) error {
+	return r.DB.Model(&models.Post{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": by}).Error
+}
@@ -1,16 +1,27 @@
 package repository
 
 import (
+	"errors"
+
 	"go-backend/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// ErrInsufficientBalance is returned when the buyer cannot pay for a purchase.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
 type PurchaseRepository interface {
 	FindByUserID(userID uuid.UUID, limit, offset int) ([]models.Purchase, error)
 	Create(purchase *models.Purchase) error
 	FindByUserAndPost(userID uuid.UUID, postID uuid.UUID) (models.Purchase, error)
+	// Buy charges the buyer purchase.Price and stores the purchase and the
+	// creator's earning in one transaction.
+	Buy(purchase *models.Purchase, earning *models.Earning) error
+	// PurchasedPosts lists the posts userID bought that are still visible to
+	// the buyer, most recent purchase first, leaving out posts by excludeUserIDs.
+	PurchasedPosts(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]models.Post, error)
 }
 
 type GormPurchaseRepository struct {
@@ -43,3 +54,60 @@ func (r *GormPurchaseRepository) FindByUserAndPost(userID uuid.UUID, postID uuid
 	}
 	return purchase, nil
 }
+
+func (r *GormPurchaseRepository) Buy(purchase *models.Purchase, earning *models.Earning) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := chargeBalance(tx, purchase.UserID, purchase.Price); err != nil {
+			return err
+		}
+		if err := tx.Create(purchase).Error; err != nil {
+			return err
+		}
+		return tx.Create(earning).Error
+	})
+}
+
+// chargeBalance takes amount from the user's balance within tx, failing with
+// ErrInsufficientBalance rather than going negative.
+func chargeBalance(tx *gorm.DB, userID uuid.UUID, amount int) error {
+	res := tx.Model(&models.User{}).
+		Where("id = ? AND balance >= ?", userID, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// PurchasedPosts leaves out posts hidden by moderation and posts of banned
+// authors. Buyers keep the posts an author deleted before leaving; other
+// posts of deactivated or deleted authors are gone, as in GET /posts/:id.
+func (r *GormPurchaseRepository) PurchasedPosts(userID uuid.UUID, excludeUserIDs []uuid.UUID, limit, offset int) ([]models.Post, error) {
+	banned := r.DB.Unscoped().Model(&models.User{}).Select("id").Where("status = ?", models.AccountBanned)
+	q := r.DB.Unscoped().
+		Joins("JOIN purchases ON purchases.post_id = posts.id AND purchases.user_id = ?", userID).
+		Where("posts.hidden_at IS NULL").
+		Where("posts.user_id NOT IN (?)", banned).
+		Where("(posts.deleted_at IS NULL AND posts.user_id NOT IN (?)) OR (posts.deleted_by = ? AND purchases.completed = ?)",
+			withdrawnUsers(r.DB), models.DeletedByOwner, true).
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Media").Preload("ModelProfile").
+		Order("purchases.created_at DESC")
+	if len(excludeUserIDs) > 0 {
+		q = q.Where("posts.user_id NOT IN ?", excludeUserIDs)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	var posts []models.Post
+	if err := q.Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
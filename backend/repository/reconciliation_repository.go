@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sale is a paid purchase, tip or message unlock as the earnings ledger
+// should record it.
+type Sale struct {
+	Source    string     `json:"source"`
+	SourceID  uuid.UUID  `json:"source_id"`
+	CreatorID uuid.UUID  `json:"creator_id"`
+	ModelID   uuid.UUID  `json:"model_id"`
+	PostID    *uuid.UUID `json:"post_id"`
+	Gross     int        `json:"gross"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// OverdrawnCreator has more paid out or reserved than they ever earned.
+type OverdrawnCreator struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Earned  int       `json:"earned"`
+	Payouts int       `json:"payouts"`
+}
+
+// NegativeBalance is a user whose wallet went below zero.
+type NegativeBalance struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Balance int       `json:"balance"`
+}
+
+// ReconciliationRepository cross-checks wallets, sales and the earnings
+// ledger.
+type ReconciliationRepository interface {
+	// UnrecordedSales lists paid sales without an earning.
+	UnrecordedSales() ([]Sale, error)
+	// AddEarnings stores earnings, skipping those already recorded.
+	AddEarnings(earnings []*models.Earning) error
+	// Overdrawn lists creators whose pending, processing and paid payouts
+	// exceed their net earnings.
+	Overdrawn() ([]OverdrawnCreator, error)
+	NegativeBalances() ([]NegativeBalance, error)
+}
+
+type GormReconciliationRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormReconciliationRepository) UnrecordedSales() ([]Sale, error) {
+	queries := []*gorm.DB{
+		r.DB.Table("purchases p").
+			Select("? AS source, p.id AS source_id, posts.user_id AS creator_id, posts.model_id AS model_id, posts.id AS post_id, p.price AS gross, p.created_at AS created_at", models.EarningPurchase).
+			Joins("JOIN posts ON posts.id = p.post_id").
+			Joins("LEFT JOIN earnings e ON e.source = ? AND e.source_id = p.id", models.EarningPurchase).
+			Where("p.price > 0 AND e.id IS NULL"),
+		r.DB.Table("tips t").
+			Select("? AS source, t.id AS source_id, mp.user_id AS creator_id, mp.id AS model_id, t.post_id AS post_id, t.amount AS gross, t.created_at AS created_at", models.EarningTip).
+			Joins("JOIN model_profiles mp ON mp.id = t.model_id").
+			Joins("LEFT JOIN earnings e ON e.source = ? AND e.source_id = t.id", models.EarningTip).
+			Where("t.amount > 0 AND e.id IS NULL"),
+		r.DB.Table("message_unlocks u").
+			Select("? AS source, u.id AS source_id, m.sender_id AS creator_id, mp.id AS model_id, NULL AS post_id, u.price AS gross, u.created_at AS created_at", models.EarningMessage).
+			Joins("JOIN messages m ON m.id = u.message_id").
+			Joins("JOIN model_profiles mp ON mp.user_id = m.sender_id").
+			Joins("LEFT JOIN earnings e ON e.source = ? AND e.source_id = u.id", models.EarningMessage).
+			Where("u.price > 0 AND e.id IS NULL"),
+	}
+	var sales []Sale
+	for _, q := range queries {
+		var batch []Sale
+		if err := q.Scan(&batch).Error; err != nil {
+			return nil, err
+		}
+		sales = append(sales, batch...)
+	}
+	return sales, nil
+}
+
+func (r *GormReconciliationRepository) AddEarnings(earnings []*models.Earning) error {
+	if len(earnings) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(earnings).Error
+}
+
+func (r *GormReconciliationRepository) Overdrawn() ([]OverdrawnCreator, error) {
+	earned := r.DB.Model(&models.Earning{}).Select("COALESCE(SUM(net), 0)").Where("creator_id = p.user_id")
+	var creators []OverdrawnCreator
+	err := r.DB.Table("payouts p").
+		Select("p.user_id AS user_id, SUM(p.amount) AS payouts, (?) AS earned", earned).
+		Where("p.status IN ?", []string{models.PayoutPending, models.PayoutProcessing, models.PayoutPaid}).
+		Group("p.user_id").
+		Having("SUM(p.amount) > (?)", earned).
+		Scan(&creators).Error
+	return creators, err
+}
+
+func (r *GormReconciliationRepository) NegativeBalances() ([]NegativeBalance, error) {
+	var users []NegativeBalance
+	err := r.DB.Unscoped().Model(&models.User{}).Select("id AS user_id, balance").Where("balance < 0").Scan(&users).Error
+	return users, err
+}
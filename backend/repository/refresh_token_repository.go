@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(hash string) (models.RefreshToken, error)
+	// Rotate revokes the token in favour of nextID unless it was already
+	// revoked, and reports whether it did.
+	Rotate(id, nextID uuid.UUID, at time.Time) (bool, error)
+	RevokeFamily(familyID uuid.UUID, at time.Time) error
+	RevokeAllForUser(userID uuid.UUID, at time.Time) error
+}
+
+type GormRefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *GormRefreshTokenRepository) FindByHash(hash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.DB.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return token, err
+	}
+	return token, nil
+}
+
+func (r *GormRefreshTokenRepository) Rotate(id, nextID uuid.UUID, at time.Time) (bool, error) {
+	res := r.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "replaced_by_id": nextID})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamily(familyID uuid.UUID, at time.Time) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *GormRefreshTokenRepository) RevokeAllForUser(userID uuid.UUID, at time.Time) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDuplicateReport = errors.New("already reported")
+
+// reportTables maps a report target type to its table and author column.
+var reportTables = map[string]struct{ table, owner string }{
+	models.ReportPost:    {"posts", "user_id"},
+	models.ReportComment: {"comments", "user_id"},
+	models.ReportProfile: {"model_profiles", "user_id"},
+	models.ReportMessage: {"messages", "sender_id"},
+}
+
+// HistoryFilter narrows resolved moderation cases to one target or author.
+type HistoryFilter struct {
+	TargetType   string
+	TargetID     *uuid.UUID
+	TargetUserID *uuid.UUID
+}
+
+type ReportRepository interface {
+	// TargetOwner returns the author of the content, or
+	// gorm.ErrRecordNotFound when it does not exist.
+	TargetOwner(targetType string, targetID uuid.UUID) (uuid.UUID, error)
+	// AddReport files the report on the target's unresolved case, opening one
+	// if needed, and hides the target once the case reaches hideThreshold
+	// reports (never when hideThreshold is 0). It fails with
+	// ErrDuplicateReport when the reporter already reported this case.
+	AddReport(open *models.ModerationCase, report *models.Report, hideThreshold int) (*models.ModerationCase, error)
+	// ListByReporter returns a user's reports, newest first.
+	ListByReporter(reporterID uuid.UUID, limit, offset int) ([]models.Report, error)
+	FindCase(id uuid.UUID) (*models.ModerationCase, error)
+	// ListCases returns cases in the given status (all when empty), oldest
+	// first, so the queue is worked FIFO.
+	ListCases(status, targetType string, limit, offset int) ([]models.ModerationCase, error)
+	// History returns resolved cases, most recently resolved first.
+	History(filter HistoryFilter, limit, offset int) ([]models.ModerationCase, error)
+	// Claim assigns the case to moderatorID unless it is resolved or claimed
+	// by someone else; claimed is false then.
+	Claim(caseID, moderatorID uuid.UUID, at time.Time) (claimed bool, err error)
+	// Resolve saves the resolution on c and applies it in one transaction,
+	// unless the case was resolved meanwhile or is claimed by someone other
+	// than c.ResolvedBy; resolved is false then. The target is hidden when
+	// hide is set, and shown again otherwise if this case hid it on reaching
+	// the report threshold. The author is suspended until c.SuspendedUntil
+	// when set, unless banned.
+	Resolve(c *models.ModerationCase, hide bool) (resolved bool, err error)
+}
+
+type GormReportRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormReportRepository) TargetOwner(targetType string, targetID uuid.UUID) (uuid.UUID, error) {
+	t, ok := reportTables[targetType]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("unknown report target %q", targetType)
+	}
+	var owners []uuid.UUID
+	if err := r.DB.Table(t.table).Where("id = ?", targetID).Limit(1).Pluck(t.owner, &owners).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(owners) == 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return owners[0], nil
+}
+
+func (r *GormReportRepository) AddReport(open *models.ModerationCase, report *models.Report, hideThreshold int) (*models.ModerationCase, error) {
+	var result models.ModerationCase
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("target_type = ? AND target_id = ? AND status <> ?", open.TargetType, open.TargetID, models.CaseResolved).
+			First(&result).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			result = *open
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		}
+		var dup int64
+		if err := tx.Model(&models.Report{}).Where("case_id = ? AND reporter_id = ?", result.ID, report.ReporterID).Count(&dup).Error; err != nil {
+			return err
+		}
+		if dup > 0 {
+			return ErrDuplicateReport
+		}
+		report.CaseID = result.ID
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		result.ReportCount++
+		updates := map[string]interface{}{"report_count": result.ReportCount, "updated_at": report.CreatedAt}
+		if hideThreshold > 0 && result.ReportCount >= hideThreshold && !result.AutoHidden {
+			result.AutoHidden = true
+			updates["auto_hidden"] = true
+			if err := setHidden(tx, result.TargetType, result.TargetID, &report.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.ModerationCase{}).Where("id = ?", result.ID).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// setHidden hides the target at the given time, or shows it again when at
+// is nil. Content hidden earlier keeps its original time.
+func setHidden(tx *gorm.DB, targetType string, targetID uuid.UUID, at *time.Time) error {
+	t, ok := reportTables[targetType]
+	if !ok {
+		return fmt.Errorf("unknown report target %q", targetType)
+	}
+	q := tx.Table(t.table).Where("id = ?", targetID)
+	if at != nil {
+		q = q.Where("hidden_at IS NULL")
+	}
+	return q.Update("hidden_at", at).Error
+}
+
+func (r *GormReportRepository) ListByReporter(reporterID uuid.UUID, limit, offset int) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.DB.Where("reporter_id = ?", reporterID).Order("created_at DESC").
+		Limit(limit).Offset(offset).Find(&reports).Error
+	return reports, err
+}
+
+func (r *GormReportRepository) FindCase(id uuid.UUID) (*models.ModerationCase, error) {
+	var c models.ModerationCase
+	err := r.DB.Preload("Reports", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&c, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormReportRepository) ListCases(status, targetType string, limit, offset int) ([]models.ModerationCase, error) {
+	q := r.DB.Model(&models.ModerationCase{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if targetType != "" {
+		q = q.Where("target_type = ?", targetType)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	var cases []models.ModerationCase
+	err := q.Order("created_at").Find(&cases).Error
+	return cases, err
+}
+
+func (r *GormReportRepository) History(filter HistoryFilter, limit, offset int) ([]models.ModerationCase, error) {
+	q := r.DB.Where("status = ?", models.CaseResolved)
+	if filter.TargetType != "" {
+		q = q.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		q = q.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.TargetUserID != nil {
+		q = q.Where("target_user_id = ?", *filter.TargetUserID)
+	}
+	var cases []models.ModerationCase
+	err := q.Order("resolved_at DESC").Limit(limit).Offset(offset).Find(&cases).Error
+	return cases, err
+}
+
+func (r *GormReportRepository) Claim(caseID, moderatorID uuid.UUID, at time.Time) (bool, error) {
+	res := r.DB.Model(&models.ModerationCase{}).
+		Where("id = ? AND status <> ? AND (assignee_id IS NULL OR assignee_id = ?)", caseID, models.CaseResolved, moderatorID).
+		Updates(map[string]interface{}{"status": models.CaseClaimed, "assignee_id": moderatorID, "claimed_at": at, "updated_at": at})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormReportRepository) Resolve(c *models.ModerationCase, hide bool) (bool, error) {
+	resolved := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.ModerationCase{}).
+			Where("id = ? AND status <> ? AND (assignee_id IS NULL OR assignee_id = ?)", c.ID, models.CaseResolved, *c.ResolvedBy).
+			Updates(map[string]interface{}{
+				"status":          c.Status,
+				"action":          c.Action,
+				"resolution_note": c.ResolutionNote,
+				"assignee_id":     c.AssigneeID,
+				"claimed_at":      c.ClaimedAt,
+				"suspended_until": c.SuspendedUntil,
+				"resolved_by":     c.ResolvedBy,
+				"resolved_at":     c.ResolvedAt,
+				"updated_at":      c.UpdatedAt,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		resolved = true
+		// Reports filed since c was loaded may have hidden the target.
+		if err := tx.Omit("Reports").First(c, "id = ?", c.ID).Error; err != nil {
+			return err
+		}
+		if hide {
+			if err := setHidden(tx, c.TargetType, c.TargetID, c.ResolvedAt); err != nil {
+				return err
+			}
+		} else if c.AutoHidden {
+			if err := setHidden(tx, c.TargetType, c.TargetID, nil); err != nil {
+				return err
+			}
+		}
+		if c.SuspendedUntil == nil {
+			return nil
+		}
+		var banned int64
+		err := tx.Model(&models.User{}).Where("id = ? AND status = ?", c.TargetUserID, models.AccountBanned).Count(&banned).Error
+		if err != nil || banned > 0 {
+			return err
+		}
+		reason := "moderation case " + c.ID.String()
+		if c.ResolutionNote != "" {
+			reason += ": " + c.ResolutionNote
+		}
+		return setAccountStatus(tx, &models.AccountStatusChange{
+			UserID:    c.TargetUserID,
+			ActorID:   c.ResolvedBy,
+			To:        models.AccountSuspended,
+			Reason:    reason,
+			Until:     c.SuspendedUntil,
+			CreatedAt: *c.ResolvedAt,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return resolved, nil
+}
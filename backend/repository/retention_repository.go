@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrNotDeleted = errors.New("not deleted")
+
+// RetentionRepository restores soft-deleted rows and finds and removes those
+// whose retention period is over.
+type RetentionRepository interface {
+	// Restore undeletes the row of model (a pointer to a Post, User, Video or
+	// Image). It fails with gorm.ErrRecordNotFound when the row does not
+	// exist, was purged or is an anonymized account, and with ErrNotDeleted
+	// when it is not deleted.
+	Restore(model interface{}, id uuid.UUID) error
+	// ExpiredPosts lists posts deleted before cutoff with their media,
+	// leaving out those nothing is left to purge of: bought posts their
+	// author deleted stay for the buyers, and bought posts removed by
+	// moderation keep their row for the purchase history.
+	ExpiredPosts(cutoff time.Time) ([]models.Post, error)
+	// Purchased reports whether anyone bought the post.
+	Purchased(postID uuid.UUID) (bool, error)
+	// Uploads returns the videos and images, deleted or not, behind the
+	// given CDN URLs.
+	Uploads(urls []string) ([]models.Video, []models.Image, error)
+	// ExpiredVideos and ExpiredImages list uploads deleted before cutoff or
+	// belonging to users deleted before cutoff, except those used by posts
+	// buyers still have access to.
+	ExpiredVideos(cutoff time.Time) ([]models.Video, error)
+	ExpiredImages(cutoff time.Time) ([]models.Image, error)
+	// PurgePost removes the post's media and, unless keepRow is set, the
+	// post itself with everything that cascades from it.
+	PurgePost(postID uuid.UUID, keepRow bool) error
+	PurgeVideo(id uuid.UUID) error
+	PurgeImage(id uuid.UUID) error
+}
+
+type GormRetentionRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormRetentionRepository) Restore(model interface{}, id uuid.UUID) error {
+	updates := map[string]interface{}{"deleted_at": nil}
+	db := r.DB.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id)
+	switch model.(type) {
+	case *models.Post:
+		updates["deleted_by"] = ""
+	case *models.User:
+		// Anonymized accounts are gone for good.
+		db = db.Where("anonymized_at IS NULL")
+	}
+	res := db.Updates(updates)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	var n int64
+	if err := r.DB.Model(model).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrNotDeleted
+}
+
+// purchasedPosts selects the IDs of posts someone bought.
+func purchasedPosts(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Purchase{}).Select("post_id")
+}
+
+// boughtMediaURLs selects the media URLs of bought posts that were not
+// removed by moderation; their files must outlive any deletion.
+func boughtMediaURLs(db *gorm.DB) *gorm.DB {
+	return db.Table("media").Select("media.url").
+		Joins("JOIN posts ON posts.id = media.post_id").
+		Where("media.url IS NOT NULL AND media.post_id IN (?)", purchasedPosts(db)).
+		Where("COALESCE(posts.deleted_by, '') <> ?", models.DeletedByModeration)
+}
+
+func (r *GormRetentionRepository) ExpiredPosts(cutoff time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.DB.Unscoped().Preload("Media").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Where("NOT (id IN (?) AND (deleted_by = ? OR id NOT IN (?)))",
+			purchasedPosts(r.DB), models.DeletedByOwner, r.DB.Model(&models.Media{}).Select("post_id").Where("post_id IS NOT NULL")).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *GormRetentionRepository) Purchased(postID uuid.UUID) (bool, error) {
+	var n int64
+	err := r.DB.Model(&models.Purchase{}).Where("post_id = ?", postID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRetentionRepository) Uploads(urls []string) ([]models.Video, []models.Image, error) {
+	var videos []models.Video
+	var images []models.Image
+	if len(urls) == 0 {
+		return videos, images, nil
+	}
+	if err := r.DB.Unscoped().Where("cdn_url IN ?", urls).Find(&videos).Error; err != nil {
+		return nil, nil, err
+	}
+	err := r.DB.Unscoped().Where("cdn_url IN ?", urls).Find(&images).Error
+	return videos, images, err
+}
+
+// expiredUploads narrows videos or images to those due for purging.
+func (r *GormRetentionRepository) expiredUploads(cutoff time.Time) *gorm.DB {
+	deletedUsers := r.DB.Unscoped().Model(&models.User{}).Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+	return r.DB.Unscoped().
+		Where("(deleted_at IS NOT NULL AND deleted_at < ?) OR user_id IN (?)", cutoff, deletedUsers).
+		Where("cdn_url NOT IN (?)", boughtMediaURLs(r.DB))
+}
+
+func (r *GormRetentionRepository) ExpiredVideos(cutoff time.Time) ([]models.Video, error) {
+	var videos []models.Video
+	err := r.expiredUploads(cutoff).Find(&videos).Error
+	return videos, err
+}
+
+func (r *GormRetentionRepository) ExpiredImages(cutoff time.Time) ([]models.Image, error) {
+	var images []models.Image
+	err := r.expiredUploads(cutoff).Find(&images).Error
+	return images, err
+}
+
+func (r *GormRetentionRepository) PurgePost(postID uuid.UUID, keepRow bool) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&models.Media{}).Error; err != nil {
+			return err
+		}
+		if keepRow {
+			return nil
+		}
+		return tx.Unscoped().Delete(&models.Post{}, "id = ?", postID).Error
+	})
+}
+
+func (r *GormRetentionRepository) PurgeVideo(id uuid.UUID) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// Purchases of the video stay in the history without it.
+		if err := tx.Model(&models.Purchase{}).Where("video_id = ?", id).Update("video_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Video{}, "id = ?", id).Error
+	})
+}
+
+func (r *GormRetentionRepository) PurgeImage(id uuid.UUID) error {
+	return r.DB.Unscoped().Delete(&models.Image{}, "id = ?", id).Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrLastHolder = errors.New("last holder of the role")
+
+// RoleChangeFilter narrows the role audit trail. Zero values match everything.
+type RoleChangeFilter struct {
+	UserID  *uuid.UUID
+	ActorID *uuid.UUID
+	Role    models.Role
+	Limit   int
+	Offset  int
+}
+
+type RoleRepository interface {
+	FindForUser(userID uuid.UUID) ([]models.UserRole, error)
+	// Grant stores the role and its audit record in one transaction.
+	Grant(role *models.UserRole, change *models.RoleChange) error
+	// Revoke removes the role and stores its audit record in one transaction.
+	// It fails with gorm.ErrRecordNotFound when the user does not hold the
+	// role and, when keepLast is set, with ErrLastHolder when the user is its
+	// only holder; the holders are locked while they are counted.
+	Revoke(userID uuid.UUID, role models.Role, change *models.RoleChange, keepLast bool) error
+	ListChanges(filter RoleChangeFilter) ([]models.RoleChange, error)
+}
+
+type GormRoleRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormRoleRepository) FindForUser(userID uuid.UUID) ([]models.UserRole, error) {
+	var roles []models.UserRole
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *GormRoleRepository) Grant(role *models.UserRole, change *models.RoleChange) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *GormRoleRepository) Revoke(userID uuid.UUID, role models.Role, change *models.RoleChange, keepLast bool) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if keepLast {
+			// concurrent revokes wait here, so two admins cannot remove
+			// each other at once
+			var holders []uuid.UUID
+			err := tx.Model(&models.UserRole{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("role = ?", role).Pluck("user_id", &holders).Error
+			if err != nil {
+				return err
+			}
+			held := false
+			for _, id := range holders {
+				if id == userID {
+					held = true
+				}
+			}
+			if !held {
+				return gorm.ErrRecordNotFound
+			}
+			if len(holders) <= 1 {
+				return ErrLastHolder
+			}
+		}
+		res := tx.Where("user_id = ? AND role = ?", userID, role).Delete(&models.UserRole{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *GormRoleRepository) ListChanges(filter RoleChangeFilter) ([]models.RoleChange, error) {
+	q := r.DB.Model(&models.RoleChange{})
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		q = q.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Role != "" {
+		q = q.Where("role = ?", filter.Role)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+	var changes []models.RoleChange
+	if err := q.Order("created_at DESC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
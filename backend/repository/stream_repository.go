@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StreamRepository interface {
+	FindByID(id uuid.UUID) (*models.Stream, error)
+}
+
+type GormStreamRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormStreamRepository) FindByID(id uuid.UUID) (*models.Stream, error) {
+	var stream models.Stream
+	if err := r.DB.First(&stream, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TipRepository interface {
+	// Create charges the tipper tip.Amount and stores the tip and the
+	// creator's earning in one transaction.
+	Create(tip *models.Tip, earning *models.Earning) error
+	// ListSent returns the user's tips, newest first.
+	ListSent(userID uuid.UUID, limit, offset int) ([]models.Tip, error)
+	// ListReceived returns the tips to a model profile with their tippers,
+	// newest first.
+	ListReceived(modelID uuid.UUID, limit, offset int) ([]models.Tip, error)
+}
+
+type GormTipRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormTipRepository) Create(tip *models.Tip, earning *models.Earning) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := chargeBalance(tx, tip.UserID, tip.Amount); err != nil {
+			return err
+		}
+		if err := tx.Create(tip).Error; err != nil {
+			return err
+		}
+		return tx.Create(earning).Error
+	})
+}
+
+func (r *GormTipRepository) ListSent(userID uuid.UUID, limit, offset int) ([]models.Tip, error) {
+	var tips []models.Tip
+	err := r.DB.Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tips).Error
+	return tips, err
+}
+
+func (r *GormTipRepository) ListReceived(modelID uuid.UUID, limit, offset int) ([]models.Tip, error) {
+	var tips []models.Tip
+	err := r.DB.Preload("User").Where("model_id = ?", modelID).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tips).Error
+	return tips, err
+}
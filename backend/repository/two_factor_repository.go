@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	// FindByUserID returns nil without error when the user never set up 2FA.
+	FindByUserID(userID uuid.UUID) (*models.TwoFactor, error)
+	Save(tf *models.TwoFactor) error
+	Delete(userID uuid.UUID) error
+	ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error
+	// UseRecoveryCode consumes an unused code; it reports false if there is none.
+	UseRecoveryCode(userID uuid.UUID, hash string, at time.Time) (bool, error)
+	CountRecoveryCodes(userID uuid.UUID) (int64, error)
+}
+
+type GormTwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormTwoFactorRepository) FindByUserID(userID uuid.UUID) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+	if err := r.DB.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tf, nil
+}
+
+func (r *GormTwoFactorRepository) Save(tf *models.TwoFactor) error {
+	return r.DB.Save(tf).Error
+}
+
+func (r *GormTwoFactorRepository) Delete(userID uuid.UUID) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+func (r *GormTwoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(hashes))
+		for i, h := range hashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: h}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *GormTwoFactorRepository) UseRecoveryCode(userID uuid.UUID, hash string, at time.Time) (bool, error) {
+	res := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *GormTwoFactorRepository) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var n int64
+	err := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
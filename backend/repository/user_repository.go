@@ -6,14 +6,23 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserDeleted: the only account with the address is deleted. The
+	// address stays taken so staff can restore the account.
+	ErrUserDeleted = errors.New("user deleted")
+	// ErrUserConflict: another account holds the email or nickname.
+	ErrUserConflict = errors.New("email or nickname belongs to another account")
+)
 
 type UserRepository interface {
 	FindAll(limit, offset int) ([]models.User, error)
 	FindByID(id uuid.UUID) (models.User, error)
 	FindByEmail(email string) (models.User, error)
+	FindByReferralCode(code string) (models.User, error)
 	Create(user *models.User) error
 	Update(user *models.User) error
 	Delete(id uuid.UUID) error
@@ -33,7 +42,7 @@ func (r *GormUserRepository) FindAll(limit, offset int) ([]models.User, error) {
 	if offset > 0 {
 		q = q.Offset(offset)
 	}
-	if err := q.Find(&users).Error; err != nil {
+	if err := q.Preload("Roles").Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -41,7 +50,7 @@ func (r *GormUserRepository) FindAll(limit, offset int) ([]models.User, error) {
 
 func (r *GormUserRepository) FindByID(id uuid.UUID) (models.User, error) {
 	var user models.User
-	if err := r.DB.First(&user, "id = ?", id).Error; err != nil {
+	if err := r.DB.Preload("Roles").First(&user, "id = ?", id).Error; err != nil {
 		return user, err
 	}
 	return user, nil
@@ -49,7 +58,25 @@ func (r *GormUserRepository) FindByID(id uuid.UUID) (models.User, error) {
 
 func (r *GormUserRepository) FindByEmail(email string) (models.User, error) {
 	var user models.User
-	if err := r.DB.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.DB.Preload("Roles").Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			var deleted int64
+			if err := r.DB.Unscoped().Model(&models.User{}).Where("email = ? AND deleted_at IS NOT NULL", email).Count(&deleted).Error; err != nil {
+				return user, err
+			}
+			if deleted > 0 {
+				return user, ErrUserDeleted
+			}
+			return user, ErrUserNotFound
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+func (r *GormUserRepository) FindByReferralCode(code string) (models.User, error) {
+	var user models.User
+	if err := r.DB.Preload("Roles").Where("referral_code = ?", code).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return user, ErrUserNotFound
 		}
@@ -62,8 +89,27 @@ func (r *GormUserRepository) Create(user *models.User) error {
 	return r.DB.Create(user).Error
 }
 
+// Update saves the user's own columns. Roles are changed only through
+// RoleRepository, so a stale copy cannot bring back a revoked role. It fails
+// with ErrUserConflict when another account holds the email or nickname.
 func (r *GormUserRepository) Update(user *models.User) error {
-	return r.DB.Save(user).Error
+	err := r.DB.Omit(clause.Associations).Save(user).Error
+	if isUniqueViolation(r.DB, err) {
+		return ErrUserConflict
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation of
+// the database behind db.
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	if err == nil {
+		return false
+	}
+	if t, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = t.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
 }
 
 func (r *GormUserRepository) Delete(id uuid.UUID) error {
@@ -82,3 +128,10 @@ func (r *GormUserRepository) CreateUser(email, avatar, password, refCode string)
 	}
 	return user, nil
 }
+
+// withdrawnUsers selects the IDs of banned, deactivated and deleted users,
+// whose content is left out of feeds and search.
+func withdrawnUsers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(&models.User{}).Select("id").
+		Where("status IN ? OR deleted_at IS NOT NULL", models.WithdrawnStates)
+}
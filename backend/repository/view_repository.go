@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"go-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ViewRepository stores batches of view and watch-progress events.
+type ViewRepository interface {
+	// SaveViews inserts the views and adds views of whole posts to the
+	// posts' view counters.
+	SaveViews(views []models.PostView) error
+	// SaveWatches merges progress into each viewer's watch of a video,
+	// keeping the furthest position.
+	SaveWatches(watches []models.VideoWatch) error
+}
+
+type GormViewRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormViewRepository) SaveViews(views []models.PostView) error {
+	if len(views) == 0 {
+		return nil
+	}
+	counts := map[uuid.UUID]int{}
+	for _, v := range views {
+		if v.MediaID == nil {
+			counts[v.PostID]++
+		}
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&views).Error; err != nil {
+			return err
+		}
+		for postID, n := range counts {
+			if err := tx.Model(&models.Post{}).Where("id = ?", postID).
+				UpdateColumn("views_count", gorm.Expr("views_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormViewRepository) SaveWatches(watches []models.VideoWatch) error {
+	if len(watches) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "media_id"}, {Name: "viewer"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"position":   gorm.Expr("CASE WHEN excluded.position > video_watches.position THEN excluded.position ELSE video_watches.position END"),
+			"duration":   gorm.Expr("excluded.duration"),
+			"completed":  gorm.Expr("video_watches.completed OR excluded.completed"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&watches).Error
+}
@@ -4,6 +4,7 @@ import (
 	"go-backend/database"
 	"go-backend/handlers"
 	"go-backend/middleware"
+	"go-backend/policy"
 	"go-backend/services"
 
 	"go.uber.org/zap"
@@ -22,84 +23,200 @@ func InitRoutes(r *gin.Engine, logger *zap.Logger) {
 
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	// Users (protected)
-	users := r.Group("/users", middleware.UserMiddleware(logger))
+
+	// Route groups declare whether they need a logged-in user.
+	requireAuth := middleware.UserMiddleware(logger, middleware.RequireAuth)
+	optionalAuth := middleware.UserMiddleware(logger, middleware.OptionalAuth)
+
+	// Native email/password authentication
+	auth := r.Group("/auth")
+	{
+		auth.POST("/register", handlers.Register)
+		auth.POST("/login", handlers.Login)
+		auth.POST("/refresh", handlers.RefreshToken)
+		auth.POST("/logout", handlers.Logout)
+		auth.POST("/logout-all", requireAuth, handlers.LogoutAll)
+		auth.POST("/password", requireAuth, handlers.ChangePassword)
+		auth.POST("/password/forgot", handlers.ForgotPassword)
+		auth.POST("/password/reset", handlers.ResetPassword)
+		auth.POST("/verify-email", handlers.VerifyEmail)
+		auth.POST("/verify-email/send", requireAuth, handlers.SendVerificationEmail)
+
+		// Two-factor authentication (TOTP)
+		auth.GET("/2fa", requireAuth, handlers.GetTwoFactorStatus)
+		auth.POST("/2fa/setup", requireAuth, handlers.SetupTwoFactor)
+		auth.POST("/2fa/enable", requireAuth, handlers.EnableTwoFactor)
+		auth.POST("/2fa/verify", optionalAuth, handlers.VerifyTwoFactor)
+		auth.POST("/2fa/disable", requireAuth, handlers.DisableTwoFactor)
+		auth.POST("/2fa/recovery-codes", requireAuth, handlers.RegenerateRecoveryCodes)
+	}
+
+	// Users (public profiles, everything else protected)
+	users := r.Group("/users")
 	{
-		users.GET("", handlers.GetUsers)
-		users.GET("/:id", handlers.GetUserByID)
-		users.GET("/:id/model-profile", handlers.GetModelProfileByUserID)
-		users.GET("/:id/saved-posts", handlers.GetSavedPosts)
-		users.GET("/:id/purchased-posts", handlers.GetPurchasedPosts)
-		users.POST("", handlers.CreateUser)
-		users.PUT("/:id", handlers.UpdateUser)
-		users.DELETE("/:id", handlers.DeleteUser)
+		users.GET("", requireAuth, middleware.RequirePermission(policy.PermReadUsers), handlers.GetUsers)
+		users.GET("/:id", optionalAuth, handlers.GetUserByID)
+		users.GET("/:id/model-profile", optionalAuth, handlers.GetModelProfileByUserID)
+		users.GET("/:id/followers", optionalAuth, handlers.GetUserFollowers)
+		users.GET("/:id/following", optionalAuth, handlers.GetUserFollowing)
+		users.GET("/:id/saved-posts", requireAuth, handlers.GetSavedPosts)
+		users.GET("/:id/purchased-posts", requireAuth, handlers.GetPurchasedPosts)
+		// Saved videos for any user (admin or self)
+		users.GET("/:id/saved-videos", requireAuth, handlers.GetSavedVideosByUserID)
+		users.POST("", requireAuth, middleware.Audit("user.create", services.AuditUser), handlers.CreateUser)
+		users.PUT("/:id", requireAuth, middleware.Audit("user.update", services.AuditUser), handlers.UpdateUser)
+		users.DELETE("/:id", requireAuth, middleware.Audit("user.delete", services.AuditUser), handlers.DeleteUser)
+	}
+
+	// The current user's own data; download links are signed, so they need
+	// no session
+	me := r.Group("/me")
+	{
+		me.POST("/export", requireAuth, handlers.RequestDataExport)
+		me.GET("/exports", requireAuth, handlers.GetDataExports)
+		me.GET("/exports/:id/download", handlers.DownloadDataExport)
+		me.POST("/deletion", requireAuth, handlers.RequestAccountDeletion)
+		me.GET("/deletion", requireAuth, handlers.GetAccountDeletion)
+		me.DELETE("/deletion", requireAuth, handlers.CancelAccountDeletion)
 	}
 
 	// Posts (GET public, others protected)
 	posts := r.Group("/posts")
 	{
-		posts.GET("", handlers.GetPosts)
-		posts.GET("/:id", handlers.GetPostByID)
-		posts.POST("", middleware.UserMiddleware(logger), handlers.CreatePost)
-		posts.PUT("/:id", middleware.UserMiddleware(logger), handlers.UpdatePost)
-		posts.DELETE("/:id", middleware.UserMiddleware(logger), handlers.DeletePost)
+		posts.GET("", optionalAuth, handlers.GetPosts)
+		posts.GET("/:id", optionalAuth, handlers.GetPostByID)
+		posts.POST("", requireAuth, handlers.CreatePost)
+		posts.PUT("/:id", requireAuth, middleware.Audit("post.update", services.AuditPost), handlers.UpdatePost)
+		posts.DELETE("/:id", requireAuth, middleware.Audit("post.delete", services.AuditPost), handlers.DeletePost)
 		// Лайки для постов
-		posts.POST("/:id/like", middleware.UserMiddleware(logger), handlers.ToggleLikePost)
-		posts.POST("/:id/save", middleware.UserMiddleware(logger), handlers.ToggleSavePost)
+		posts.POST("/:id/like", requireAuth, handlers.ToggleLikePost)
+		posts.POST("/:id/save", requireAuth, handlers.ToggleSavePost)
+		posts.POST("/:id/comments", requireAuth, handlers.CreateComment)
+		posts.POST("/:id/view", optionalAuth, handlers.RecordPostView)
+		posts.POST("/:id/media/:mediaId/progress", optionalAuth, handlers.RecordWatchProgress)
 	}
 
+	// Creator onboarding: apply with identity documents, staff reviews below
+	creators := r.Group("/creators", requireAuth)
+	{
+		creators.POST("/applications", handlers.SubmitCreatorApplication)
+		creators.GET("/applications/me", handlers.GetMyCreatorApplication)
+	}
+
+	// Creator earnings and withdrawals
+	payouts := r.Group("/payouts", requireAuth)
+	{
+		payouts.GET("", handlers.GetPayouts)
+		payouts.POST("", handlers.RequestPayout)
+		payouts.GET("/balance", handlers.GetPayoutBalance)
+		payouts.GET("/earnings", handlers.GetEarnings)
+	}
+
+	// Server-Sent Events of the current user (EventSource may pass ?access_token=)
+	r.GET("/events", middleware.TokenFromQuery(), requireAuth, handlers.StreamEvents)
+	// and of a live stream's audience
+	r.GET("/streams/:id/events", handlers.LiveStreamEvents)
+
+	// In-app notification inbox
+	notifications := r.Group("/notifications", requireAuth)
+	{
+		notifications.GET("", handlers.GetNotifications)
+		notifications.GET("/unread-count", handlers.GetUnreadNotificationCount)
+		notifications.POST("/read-all", handlers.MarkAllNotificationsRead)
+		notifications.POST("/:id/read", handlers.MarkNotificationRead)
+		notifications.GET("/preferences", handlers.GetNotificationPreferences)
+		notifications.PUT("/preferences", handlers.UpdateNotificationPreferences)
+	}
+
+	// Direct messages between fans and creators
+	conversations := r.Group("/conversations", requireAuth)
+	{
+		conversations.GET("", handlers.GetConversations)
+		conversations.POST("", handlers.OpenConversation)
+		conversations.GET("/:id/messages", handlers.GetMessages)
+		conversations.POST("/:id/messages", handlers.SendMessage)
+		conversations.POST("/:id/read", handlers.MarkConversationRead)
+		conversations.POST("/:id/block", handlers.BlockConversation)
+		conversations.DELETE("/:id/block", handlers.UnblockConversation)
+	}
+	r.POST("/messages/:id/unlock", requireAuth, handlers.UnlockMessage)
+	r.GET("/tips", requireAuth, handlers.GetTips)
+
 	// Orders (protected)
-	orders := r.Group("/orders", middleware.UserMiddleware(logger))
+	orders := r.Group("/orders", requireAuth)
 	{
 		orders.GET("", handlers.GetOrders)
 		orders.GET("/:id", handlers.GetOrderByID)
 		orders.POST("", handlers.CreateOrder)
-		orders.PUT("/:id", handlers.UpdateOrder)
-		orders.DELETE("/:id", handlers.DeleteOrder)
+		orders.PUT("/:id", middleware.Audit("order.update", services.AuditOrder), handlers.UpdateOrder)
+		orders.DELETE("/:id", middleware.Audit("order.delete", services.AuditOrder), handlers.DeleteOrder)
 	}
 
-	// Models (protected)
-	models := r.Group("/models", middleware.UserMiddleware(logger))
+	// Models (GET public, others protected)
+	models := r.Group("/models")
 	{
-		models.GET("", handlers.GetModelProfiles)
-		models.GET("/:id", handlers.GetModelProfileByID)
-		models.POST("", handlers.CreateModelProfile)
-		models.PUT("/:id", handlers.UpdateModelProfile)
-		models.DELETE("/:id", handlers.DeleteModelProfile)
+		models.GET("", optionalAuth, handlers.GetModelProfiles)
+		models.GET("/:id", optionalAuth, handlers.GetModelProfileByID)
+		models.POST("", requireAuth, middleware.RequirePermission(policy.PermReviewCreators), middleware.Audit("model.create", services.AuditModel), handlers.CreateModelProfile)
+		models.PUT("/:id", requireAuth, middleware.Audit("model.update", services.AuditModel), handlers.UpdateModelProfile)
+		models.DELETE("/:id", requireAuth, middleware.Audit("model.delete", services.AuditModel), handlers.DeleteModelProfile)
+		models.GET("/:id/analytics", requireAuth, handlers.GetModelAnalytics)
+		models.POST("/:id/tips", requireAuth, handlers.TipModel)
+		models.GET("/:id/tips", requireAuth, handlers.GetModelTips)
+		models.GET("/:id/photos/:photoId/url", requireAuth, handlers.GetPhotoURL)
+		models.GET("/:id/videos/:videoId/url", requireAuth, handlers.GetVideoURL)
 	}
 
-	// Media / Videos (protected)
-	videos := r.Group("/videos", middleware.UserMiddleware(logger))
+	// Media / Videos (GET public, others protected)
+	videos := r.Group("/videos")
 	{
-		videos.POST("/upload", handlers.UploadVideo)
-		videos.GET("/:id", handlers.GetVideo)
-		videos.DELETE("/:id", handlers.DeleteVideo)
+		videos.POST("/upload", requireAuth, handlers.UploadVideo)
+		videos.GET("/:id", optionalAuth, handlers.GetVideo)
+		videos.DELETE("/:id", requireAuth, middleware.Audit("video.delete", services.AuditVideo), handlers.DeleteVideo)
 	}
 
-	images := r.Group("/images", middleware.UserMiddleware(logger))
+	images := r.Group("/images")
 	{
-		images.POST("/upload", handlers.UploadImage)
-		images.GET("/:id", handlers.GetImage)
-		images.DELETE("/:id", handlers.DeleteImage)
+		images.POST("/upload", requireAuth, handlers.UploadImage)
+		images.GET("/:id", optionalAuth, handlers.GetImage)
+		images.DELETE("/:id", requireAuth, middleware.Audit("image.delete", services.AuditImage), handlers.DeleteImage)
 	}
 
-	// Saved videos for any user (admin or self)
-	r.GET("/users/:id/saved-videos", handlers.GetSavedVideosByUserID)
-
 	// Покупка контента (protected)
-	purchases := r.Group("/purchases", middleware.UserMiddleware(logger))
+	purchases := r.Group("/purchases", requireAuth)
 	{
 		purchases.POST("", handlers.BuyContent)                   // Покупка
 		purchases.GET("", handlers.GetPurchases)                  // История покупок
 		purchases.PUT("/:id/complete", handlers.CompletePurchase) // Завершить покупку
 	}
 
-	r.POST("/follow/:id", middleware.UserMiddleware(logger), handlers.FollowUser)
-	r.DELETE("/follow/:id", middleware.UserMiddleware(logger), handlers.UnfollowUser)
-	r.GET("/followers", middleware.UserMiddleware(logger), handlers.GetFollowers)
-	r.GET("/referrals", middleware.UserMiddleware(logger), handlers.GetReferrals)
-	r.GET("/models/:modelId/photos/:photoId/url", handlers.GetPhotoURL)
-	r.GET("/models/:modelId/videos/:videoId/url", handlers.GetVideoURL)
+	r.POST("/follow/:id", requireAuth, handlers.FollowUser)
+	r.DELETE("/follow/:id", requireAuth, handlers.UnfollowUser)
+	r.GET("/followers", requireAuth, handlers.GetFollowers)
+	r.GET("/following", requireAuth, handlers.GetFollowing)
+
+	// Blocks cut contact both ways; mutes only hide content from the muter
+	blocks := r.Group("/blocks", requireAuth)
+	{
+		blocks.GET("", handlers.GetBlockedUsers)
+		blocks.POST("/:id", handlers.BlockUser)
+		blocks.DELETE("/:id", handlers.UnblockUser)
+	}
+	mutes := r.Group("/mutes", requireAuth)
+	{
+		mutes.GET("", handlers.GetMutedUsers)
+		mutes.POST("/:id", handlers.MuteUser)
+		mutes.DELETE("/:id", handlers.UnmuteUser)
+	}
+
+	// Reports feed the moderation queue under /admin/moderation
+	reports := r.Group("/reports", requireAuth)
+	{
+		reports.POST("", handlers.CreateReport)
+		reports.GET("", handlers.GetMyReports)
+	}
+
+	r.GET("/referrals", requireAuth, handlers.GetReferrals)
 
 	// Webhook Bunny
 	r.POST("/webhook/bunny", handlers.BunnyWebhook)
@@ -109,10 +226,56 @@ func InitRoutes(r *gin.Engine, logger *zap.Logger) {
 	r.POST("/payments/plisio/callback", handlers.PlisioCallback)
 
 	// Технические маршруты
-	r.GET("/migrate", handlers.MigrateHandler)
-	r.GET("/seed", handlers.SeedHandler)
 	r.GET("/metrics", handlers.GetMetrics)
 
-	admin := r.Group("/admin", middleware.AdminMiddleware())
-	admin.POST("/models/:modelId/portfolio/batch", handlers.BatchUploadPortfolio)
+	// Staff routes: every route names the permission it needs; mutations are
+	// recorded in the audit log.
+	admin := r.Group("/admin", requireAuth)
+	{
+		admin.POST("/models/:modelId/portfolio/batch", middleware.RequirePermission(policy.PermModerateContent), middleware.Audit("portfolio.upload", services.AuditPortfolio), handlers.BatchUploadPortfolio)
+
+		moderate := middleware.RequirePermission(policy.PermModerateContent)
+		admin.GET("/moderation/cases", moderate, handlers.GetModerationQueue)
+		admin.GET("/moderation/cases/:id", moderate, handlers.GetModerationCase)
+		admin.POST("/moderation/cases/:id/claim", moderate, middleware.Audit("moderation_case.claim", services.AuditModerationCase), handlers.ClaimModerationCase)
+		admin.POST("/moderation/cases/:id/resolve", moderate, middleware.Audit("moderation_case.resolve", services.AuditModerationCase), handlers.ResolveModerationCase)
+		admin.GET("/moderation/history", moderate, handlers.GetModerationHistory)
+		admin.POST("/posts/:id/restore", moderate, middleware.Audit("post.restore", services.AuditPost), handlers.RestorePost)
+		admin.POST("/videos/:id/restore", moderate, middleware.Audit("video.restore", services.AuditVideo), handlers.RestoreVideo)
+		admin.POST("/images/:id/restore", moderate, middleware.Audit("image.restore", services.AuditImage), handlers.RestoreImage)
+
+		reviewCreators := middleware.RequirePermission(policy.PermReviewCreators)
+		admin.GET("/creator-applications", reviewCreators, handlers.GetCreatorApplications)
+		admin.GET("/creator-applications/:id", reviewCreators, handlers.GetCreatorApplication)
+		admin.GET("/creator-applications/:id/documents/:docId", reviewCreators, handlers.GetCreatorDocument)
+		admin.POST("/creator-applications/:id/approve", reviewCreators, middleware.Audit("creator_application.approve", services.AuditCreatorApplication), handlers.ApproveCreatorApplication)
+		admin.POST("/creator-applications/:id/reject", reviewCreators, middleware.Audit("creator_application.reject", services.AuditCreatorApplication), handlers.RejectCreatorApplication)
+
+		managePayouts := middleware.RequirePermission(policy.PermManagePayouts)
+		admin.GET("/payouts", managePayouts, handlers.GetPayoutQueue)
+		admin.POST("/payouts/:id/approve", managePayouts, middleware.Audit("payout.approve", services.AuditPayout), handlers.ApprovePayout)
+		admin.POST("/payouts/:id/reject", managePayouts, middleware.Audit("payout.reject", services.AuditPayout), handlers.RejectPayout)
+
+		manageUsers := middleware.RequirePermission(policy.PermManageUsers)
+		admin.GET("/users/:id/status", manageUsers, handlers.GetAccountStatus)
+		admin.PUT("/users/:id/status", manageUsers, middleware.Audit("account_status.change", services.AuditUser), handlers.ChangeAccountStatus)
+		admin.GET("/users/:id/status/history", manageUsers, handlers.GetAccountStatusHistory)
+		admin.POST("/users/:id/restore", manageUsers, middleware.Audit("user.restore", services.AuditUser), handlers.RestoreUser)
+
+		manageRoles := middleware.RequirePermission(policy.PermManageRoles)
+		admin.GET("/roles", manageRoles, handlers.GetRoles)
+		admin.GET("/roles/changes", manageRoles, handlers.GetRoleChanges)
+		admin.GET("/users/:id/roles", manageRoles, handlers.GetUserRoles)
+		admin.POST("/users/:id/roles", manageRoles, middleware.Audit("role.grant", services.AuditUserRoles), handlers.GrantRole)
+		admin.DELETE("/users/:id/roles/:role", manageRoles, middleware.Audit("role.revoke", services.AuditUserRoles), handlers.RevokeRole)
+
+		readAudit := middleware.RequirePermission(policy.PermReadAudit)
+		admin.GET("/audit", readAudit, handlers.GetAuditLog)
+		admin.GET("/audit/verify", readAudit, handlers.VerifyAuditLog)
+
+		// Maintenance operations need a fresh confirmation token per run
+		runOps := middleware.RequirePermission(policy.PermRunOperations)
+		admin.POST("/ops/:operation/confirmation", runOps, handlers.ConfirmOperation)
+		admin.POST("/ops/:operation", runOps, middleware.Audit("operation.run", services.AuditOperation), handlers.RunOperation)
+	}
 }
@@ -5,7 +5,6 @@ import (
 	"math/rand"
 	"time"
 
-	"go-backend/config"
 	"go-backend/database"
 	"go-backend/models"
 	"go-backend/utils"
@@ -42,7 +41,7 @@ func RunUsers() ([]models.User, error) {
 		Password:  hashedAdminPassword, // hashed
 		Balance:   1000,
 		AvatarURL: faker.URL(),
-		IsAdmin:   true,
+		Roles:     []models.UserRole{{Role: models.RoleAdmin}},
 	})
 
 	// Генерируем остальных пользователей
@@ -75,13 +74,16 @@ func RunUsers() ([]models.User, error) {
 
 func RunModelProfiles(users []models.User) ([]models.ModelProfile, error) {
 	var profiles []models.ModelProfile
+	// Сидированные модели считаются верифицированными, иначе премиум-посты невозможны
+	verifiedAt := time.Now()
 	for _, user := range users {
 		profiles = append(profiles, models.ModelProfile{
-			ID:     uuid.New(),
-			UserID: user.ID,
-			Name:   faker.Name(),
-			Bio:    faker.Sentence(),
-			Banner: faker.URL(),
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			Name:       faker.Name(),
+			Bio:        faker.Sentence(),
+			Banner:     faker.URL(),
+			VerifiedAt: &verifiedAt,
 		})
 	}
 	if err := database.DB.Create(&profiles).Error; err != nil {
@@ -276,10 +278,8 @@ func RunReferrals(users []models.User) error {
 	return database.DB.Create(&refs).Error
 }
 
+// SeedData replaces the contents of the main tables with sample data.
 func SeedData() error {
-	config.LoadConfig()
-	database.InitDB()
-
 	if err := truncateAllTables(); err != nil {
 		return err
 	}
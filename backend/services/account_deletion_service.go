@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend/config"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrDeletionPending = errors.New("account deletion already requested")
+
+// AccountDeletionService erases accounts. Users request the deletion of
+// their account and can cancel it during Grace; accounts deleted by their
+// owner or staff can be restored for Retention. After that the account is
+// anonymized and its private objects are removed from Store.
+type AccountDeletionService struct {
+	Repo      repository.AccountDeletionRepository
+	Store     storage.Store
+	Grace     time.Duration
+	Retention time.Duration
+	Now       func() time.Time
+}
+
+func NewAccountDeletionService(repo repository.AccountDeletionRepository, store storage.Store) *AccountDeletionService {
+	return &AccountDeletionService{
+		Repo:      repo,
+		Store:     store,
+		Grace:     config.AppConfig.AccountDeletionGrace,
+		Retention: config.AppConfig.DeletedRetention,
+		Now:       time.Now,
+	}
+}
+
+// Request schedules the erasure of the user's account.
+func (s *AccountDeletionService) Request(user *models.User, reason string) (*models.AccountDeletion, error) {
+	if _, err := s.Repo.Pending(user.ID); err == nil {
+		return nil, ErrDeletionPending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	now := s.Now()
+	deletion := &models.AccountDeletion{
+		UserID:    user.ID,
+		Reason:    strings.TrimSpace(reason),
+		DueAt:     now.Add(s.Grace),
+		CreatedAt: now,
+	}
+	if err := s.Repo.Create(deletion); err != nil {
+		return nil, err
+	}
+	logging.GetLogger().Info("Account deletion requested", zap.String("user_id", user.ID.String()), zap.Time("due_at", deletion.DueAt))
+	return deletion, nil
+}
+
+// Pending returns the user's deletion request that can still be cancelled.
+func (s *AccountDeletionService) Pending(userID uuid.UUID) (*models.AccountDeletion, error) {
+	return s.Repo.Pending(userID)
+}
+
+// Cancel withdraws the user's pending deletion request.
+func (s *AccountDeletionService) Cancel(userID uuid.UUID) error {
+	deletion, err := s.Repo.Pending(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.Cancel(deletion.ID, s.Now()); err != nil {
+		return err
+	}
+	logging.GetLogger().Info("Account deletion cancelled", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// Erase anonymizes the account and removes its private objects. Objects
+// that cannot be removed are logged; the account is erased regardless.
+func (s *AccountDeletionService) Erase(userID uuid.UUID) error {
+	logger := logging.GetLogger()
+	keys, err := s.Repo.Anonymize(userID, s.Now())
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if s.Store == nil {
+			break
+		}
+		if err := s.Store.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Error("Delete private object of erased account failed", zap.String("user_id", userID.String()), zap.String("key", key), zap.Error(err))
+		}
+	}
+	logger.Info("Account erased", zap.String("user_id", userID.String()), zap.Int("objects", len(keys)))
+	return nil
+}
+
+// EraseDue erases the accounts whose deletion request is due and those
+// deleted longer than Retention ago, and returns how many it erased.
+func (s *AccountDeletionService) EraseDue() (int, error) {
+	logger := logging.GetLogger()
+	now := s.Now()
+	erased := 0
+	due, err := s.Repo.Due(now)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range due {
+		if err := s.Erase(d.UserID); err != nil {
+			logger.Error("Account erasure failed", zap.String("user_id", d.UserID.String()), zap.Error(err))
+			continue
+		}
+		if err := s.Repo.Complete(d.ID, now); err != nil {
+			logger.Error("Complete account deletion failed", zap.String("deletion_id", d.ID.String()), zap.Error(err))
+		}
+		erased++
+	}
+	deleted, err := s.Repo.Deleted(now.Add(-s.Retention))
+	if err != nil {
+		return erased, err
+	}
+	for _, id := range deleted {
+		if err := s.Erase(id); err != nil {
+			logger.Error("Account erasure failed", zap.String("user_id", id.String()), zap.Error(err))
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// StartEraser runs EraseDue every interval in the background until the
+// returned stop function is called.
+func (s *AccountDeletionService) StartEraser(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.EraseDue(); err != nil {
+					logging.GetLogger().Error("Account erasure run failed", zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/logging"
+	"go-backend/mailer"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEmailToken = errors.New("invalid or expired token")
+	ErrAlreadyVerified   = errors.New("email is already verified")
+	ErrMailRateLimited   = errors.New("too many emails sent to this address, try again later")
+)
+
+// AccountEmailService runs the flows that prove control of an email address:
+// address verification and password reset. Both mail a single-use, expiring
+// link and are rate limited per address.
+type AccountEmailService struct {
+	Users      repository.UserRepository
+	Tokens     repository.EmailTokenRepository
+	Sessions   repository.RefreshTokenRepository
+	Mailer     mailer.Mailer
+	BaseURL    string
+	VerifyTTL  time.Duration
+	ResetTTL   time.Duration
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+func NewAccountEmailService(users repository.UserRepository, tokens repository.EmailTokenRepository, sessions repository.RefreshTokenRepository, m mailer.Mailer) *AccountEmailService {
+	cfg := config.AppConfig
+	return &AccountEmailService{
+		Users:      users,
+		Tokens:     tokens,
+		Sessions:   sessions,
+		Mailer:     m,
+		BaseURL:    strings.TrimRight(cfg.FrontendURL, "/"),
+		VerifyTTL:  cfg.VerifyEmailTTL,
+		ResetTTL:   cfg.PasswordResetTTL,
+		RateLimit:  cfg.MailRateLimit,
+		RateWindow: cfg.MailRateWindow,
+	}
+}
+
+// SendVerification mails a verification link to the user's current address.
+// Earlier unused links stop working.
+func (s *AccountEmailService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+	return s.send(ctx, user, models.EmailTokenVerify, mailer.TemplateVerifyEmail, "/verify-email", s.VerifyTTL)
+}
+
+// VerifyEmail consumes a verification token and marks the address verified.
+// A token sent to an address the user has since changed is rejected.
+func (s *AccountEmailService) VerifyEmail(raw string) (*models.User, error) {
+	token, user, err := s.consume(raw, models.EmailTokenVerify)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(token.Email, user.Email) {
+		return nil, ErrInvalidEmailToken
+	}
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.Users.Update(&user); err != nil {
+			return nil, err
+		}
+	}
+	logging.GetLogger().Info("VerifyEmail success", zap.String("user_id", user.ID.String()))
+	return &user, nil
+}
+
+// RequestPasswordReset mails a reset link if an account with the address
+// exists. Unknown addresses and rate-limited requests are not reported to
+// the caller, so the endpoint cannot be used to probe for accounts.
+func (s *AccountEmailService) RequestPasswordReset(ctx context.Context, email string) error {
+	logger := logging.GetLogger()
+	user, err := s.Users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			logger.Info("Password reset for unknown address", zap.String("email", email))
+			return nil
+		}
+		return err
+	}
+	err = s.send(ctx, &user, models.EmailTokenReset, mailer.TemplatePasswordReset, "/reset-password", s.ResetTTL)
+	if errors.Is(err, ErrMailRateLimited) {
+		logger.Warn("Password reset rate limited", zap.String("user_id", user.ID.String()))
+		return nil
+	}
+	return err
+}
+
+// ResetPassword consumes a reset token, sets the new password and ends all
+// sessions. Completing a reset also proves control of the address.
+func (s *AccountEmailService) ResetPassword(raw, password string) error {
+	token, user, err := s.consume(raw, models.EmailTokenReset)
+	if err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.Password = hashed
+	user.TokenVersion++
+	if user.EmailVerifiedAt == nil && strings.EqualFold(token.Email, user.Email) {
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.Users.Update(&user); err != nil {
+		return err
+	}
+	if err := s.Sessions.RevokeAllForUser(user.ID, now); err != nil {
+		return err
+	}
+	// Other reset links mailed before this one must not work any more.
+	if err := s.Tokens.InvalidateForUser(user.ID, models.EmailTokenReset, now); err != nil {
+		return err
+	}
+	logging.GetLogger().Info("ResetPassword success", zap.String("user_id", user.ID.String()))
+	return nil
+}
+
+func (s *AccountEmailService) send(ctx context.Context, user *models.User, purpose, template, path string, ttl time.Duration) error {
+	logger := logging.GetLogger()
+	now := time.Now()
+	sent, err := s.Tokens.CountSentSince(user.Email, purpose, now.Add(-s.RateWindow))
+	if err != nil {
+		return err
+	}
+	if s.RateLimit > 0 && sent >= int64(s.RateLimit) {
+		return ErrMailRateLimited
+	}
+
+	raw, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	if err := s.Tokens.InvalidateForUser(user.ID, purpose, now); err != nil {
+		return err
+	}
+	token := &models.EmailToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.Tokens.Create(token); err != nil {
+		return err
+	}
+
+	msg, err := mailer.Render(template, user.Email, map[string]any{
+		"Nickname":  user.Nickname,
+		"Link":      s.BaseURL + path + "?token=" + url.QueryEscape(raw),
+		"ExpiresIn": ttl.String(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil {
+		logger.Error("Send email failed", zap.String("user_id", user.ID.String()), zap.String("purpose", purpose), zap.Error(err))
+		return err
+	}
+	logger.Info("Email sent", zap.String("user_id", user.ID.String()), zap.String("purpose", purpose))
+	return nil
+}
+
+// consume validates a token and marks it used. Concurrent requests with the
+// same token cannot both succeed.
+func (s *AccountEmailService) consume(raw, purpose string) (models.EmailToken, models.User, error) {
+	token, err := s.Tokens.FindByHash(hashToken(raw), purpose)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return token, models.User{}, ErrInvalidEmailToken
+		}
+		return token, models.User{}, err
+	}
+	now := time.Now()
+	if token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return token, models.User{}, ErrInvalidEmailToken
+	}
+	ok, err := s.Tokens.MarkUsed(token.ID, now)
+	if err != nil {
+		return token, models.User{}, err
+	}
+	if !ok {
+		return token, models.User{}, ErrInvalidEmailToken
+	}
+	user, err := s.Users.FindByID(token.UserID)
+	if err != nil {
+		return token, models.User{}, ErrInvalidEmailToken
+	}
+	return token, user, nil
+}
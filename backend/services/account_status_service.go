@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAccountStatus        = errors.New("unknown account status")
+	ErrStatusReasonRequired = errors.New("a reason is required")
+	ErrSuspensionEnd        = errors.New("a suspension needs an end in the future")
+	ErrStatusSelf           = errors.New("you cannot change the status of your own account")
+	ErrStatusUnchanged      = errors.New("account already has this status")
+)
+
+// AccountStatusService moves accounts between the states in
+// models.AccountStates and records every change.
+type AccountStatusService struct {
+	Repo  repository.AccountStatusRepository
+	Users repository.UserRepository
+	Now   func() time.Time
+}
+
+func NewAccountStatusService(repo repository.AccountStatusRepository, users repository.UserRepository) *AccountStatusService {
+	return &AccountStatusService{Repo: repo, Users: users, Now: time.Now}
+}
+
+// Status returns the user's current account state.
+func (s *AccountStatusService) Status(userID uuid.UUID) (*dto.AccountStatusResponseDTO, error) {
+	user, err := s.Users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AccountStatusResponseDTO{
+		UserID:         user.ID,
+		Status:         user.Status,
+		Reason:         user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+	}, nil
+}
+
+// Change moves the user to input.Status. Suspending an already suspended
+// account moves the end of the suspension.
+func (s *AccountStatusService) Change(actor *models.User, userID uuid.UUID, input dto.AccountStatusDTO) (*dto.AccountStatusResponseDTO, error) {
+	logger := logging.GetLogger()
+	if !contains(models.AccountStates, input.Status) {
+		return nil, ErrAccountStatus
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrStatusReasonRequired
+	}
+	if actor != nil && actor.ID == userID {
+		return nil, ErrStatusSelf
+	}
+	until := input.Until
+	if input.Status == models.AccountSuspended {
+		if until == nil || !until.After(s.Now()) {
+			return nil, ErrSuspensionEnd
+		}
+	} else {
+		until = nil
+	}
+	user, err := s.Users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == input.Status && input.Status != models.AccountSuspended {
+		return nil, ErrStatusUnchanged
+	}
+	actorID := actorIDOf(actor)
+	change := &models.AccountStatusChange{
+		UserID:    userID,
+		ActorID:   actorID,
+		To:        input.Status,
+		Reason:    reason,
+		Until:     until,
+		CreatedAt: s.Now(),
+	}
+	if err := s.Repo.SetStatus(change); err != nil {
+		logger.Error("Change account status failed", zap.String("user_id", userID.String()), zap.String("status", input.Status), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Account status changed", zap.String("user_id", userID.String()), zap.String("from", change.From),
+		zap.String("to", change.To), zap.Stringp("actor_id", uuidString(actorID)))
+	return s.Status(userID)
+}
+
+// History lists the user's state changes, newest first.
+func (s *AccountStatusService) History(userID uuid.UUID, limit, offset int) ([]models.AccountStatusChange, error) {
+	if _, err := s.Users.FindByID(userID); err != nil {
+		return nil, err
+	}
+	return s.Repo.History(userID, limit, offset)
+}
+
+// LiftExpiredSuspensions reactivates every account whose suspension has
+// ended and returns how many it reactivated.
+func (s *AccountStatusService) LiftExpiredSuspensions() (int, error) {
+	logger := logging.GetLogger()
+	now := s.Now()
+	ids, err := s.Repo.ExpiredSuspensions(now)
+	if err != nil {
+		return 0, err
+	}
+	lifted := 0
+	for _, id := range ids {
+		change := &models.AccountStatusChange{
+			UserID:    id,
+			To:        models.AccountActive,
+			Reason:    "suspension expired",
+			CreatedAt: now,
+		}
+		if err := s.Repo.SetStatus(change); err != nil {
+			logger.Error("Lift suspension failed", zap.String("user_id", id.String()), zap.Error(err))
+			continue
+		}
+		lifted++
+	}
+	if lifted > 0 {
+		logger.Info("Expired suspensions lifted", zap.Int("count", lifted))
+	}
+	return lifted, nil
+}
+
+// StartSuspensionSweeper runs LiftExpiredSuspensions every interval in the
+// background until the returned stop function is called.
+func (s *AccountStatusService) StartSuspensionSweeper(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.LiftExpiredSuspensions(); err != nil {
+					logging.GetLogger().Error("Suspension sweep failed", zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+)
+
+// Bucket sizes of an analytics series.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// MaxAnalyticsRange bounds the period of one analytics request.
+const MaxAnalyticsRange = 366 * 24 * time.Hour
+
+// TopPostsLimit is how many top-earning posts analytics returns.
+const TopPostsLimit = 10
+
+var (
+	ErrAnalyticsInterval = errors.New("interval must be day, week or month")
+	ErrAnalyticsRange    = errors.New("from must be before to and the range at most 366 days")
+)
+
+// AnalyticsPoint is one bucket of the series. Period is the first day of
+// the bucket; weeks start on Monday.
+type AnalyticsPoint struct {
+	Period       string `json:"period"`
+	Revenue      int    `json:"revenue"`
+	NetRevenue   int    `json:"net_revenue"`
+	Purchases    int    `json:"purchases"`
+	Tips         int    `json:"tips"`
+	TipRevenue   int    `json:"tip_revenue"`
+	Views        int    `json:"views"`
+	NewFollowers int    `json:"new_followers"`
+	Likes        int    `json:"likes"`
+	Saves        int    `json:"saves"`
+}
+
+// AnalyticsTotals sums the series. ConversionRate is purchases per view and
+// CompletionRate the share of started videos watched to the end; both are 0
+// without views.
+type AnalyticsTotals struct {
+	Revenue        int     `json:"revenue"`
+	NetRevenue     int     `json:"net_revenue"`
+	Purchases      int     `json:"purchases"`
+	Tips           int     `json:"tips"`
+	TipRevenue     int     `json:"tip_revenue"`
+	Buyers         int     `json:"buyers"`
+	Views          int     `json:"views"`
+	ConversionRate float64 `json:"conversion_rate"`
+	NewFollowers   int     `json:"new_followers"`
+	Likes          int     `json:"likes"`
+	Saves          int     `json:"saves"`
+	VideoStarts    int     `json:"video_starts"`
+	VideoCompletes int     `json:"video_completes"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+type TopPost struct {
+	PostID     uuid.UUID `json:"post_id"`
+	Text       string    `json:"text"`
+	Purchases  int       `json:"purchases"`
+	Revenue    int       `json:"revenue"`
+	NetRevenue int       `json:"net_revenue"`
+}
+
+// ModelAnalytics is the creator dashboard for one model profile.
+type ModelAnalytics struct {
+	ModelID  uuid.UUID        `json:"model_id"`
+	From     string           `json:"from"`
+	To       string           `json:"to"` // inclusive
+	Interval string           `json:"interval"`
+	Totals   AnalyticsTotals  `json:"totals"`
+	Series   []AnalyticsPoint `json:"series"`
+	TopPosts []TopPost        `json:"top_posts"`
+}
+
+// AnalyticsService aggregates earnings, views, followers, likes and saves of a
+// model profile into a time series. Revenue is gross (what buyers paid,
+// tips included, TipRevenue being their part); NetRevenue is the creator's
+// share after the platform fee.
+type AnalyticsService struct {
+	Repo repository.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{Repo: repo}
+}
+
+// ModelAnalytics reports on the days from..to (inclusive, UTC) for the
+// model profile modelID owned by userID.
+func (s *AnalyticsService) ModelAnalytics(modelID, userID uuid.UUID, from, to time.Time, interval string) (*ModelAnalytics, error) {
+	if interval != IntervalDay && interval != IntervalWeek && interval != IntervalMonth {
+		return nil, ErrAnalyticsInterval
+	}
+	from, to = truncateDay(from), truncateDay(to)
+	end := to.AddDate(0, 0, 1)
+	if !from.Before(end) || end.Sub(from) > MaxAnalyticsRange {
+		return nil, ErrAnalyticsRange
+	}
+
+	revenue, err := s.Repo.DailyRevenue(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.Repo.DailyFollowers(userID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	likes, err := s.Repo.DailyLikes(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	saves, err := s.Repo.DailySaves(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.Repo.TopPosts(modelID, from, end, TopPostsLimit)
+	if err != nil {
+		return nil, err
+	}
+	buyers, err := s.Repo.Buyers(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.Repo.DailyViews(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	videos, err := s.Repo.VideoCompletion(modelID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// one point per bucket, including empty ones, so charts need no gap filling
+	var series []AnalyticsPoint
+	index := map[string]int{}
+	for day := from; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := bucketStart(day, interval).Format(dateLayout)
+		if _, ok := index[key]; !ok {
+			index[key] = len(series)
+			series = append(series, AnalyticsPoint{Period: key})
+		}
+	}
+	point := func(day string) *AnalyticsPoint {
+		t, err := time.Parse(dateLayout, dayOf(day))
+		if err != nil {
+			return nil
+		}
+		i, ok := index[bucketStart(t, interval).Format(dateLayout)]
+		if !ok {
+			return nil
+		}
+		return &series[i]
+	}
+	for _, r := range revenue {
+		if p := point(r.Day); p != nil {
+			p.Revenue += r.Gross
+			p.NetRevenue += r.Net
+			p.Purchases += r.Purchases
+			p.Tips += r.Tips
+			p.TipRevenue += r.TipGross
+		}
+	}
+	for _, r := range views {
+		if p := point(r.Day); p != nil {
+			p.Views += r.Count
+		}
+	}
+	for _, r := range followers {
+		if p := point(r.Day); p != nil {
+			p.NewFollowers += r.Count
+		}
+	}
+	for _, r := range likes {
+		if p := point(r.Day); p != nil {
+			p.Likes += r.Count
+		}
+	}
+	for _, r := range saves {
+		if p := point(r.Day); p != nil {
+			p.Saves += r.Count
+		}
+	}
+
+	totals := AnalyticsTotals{Buyers: buyers, VideoStarts: videos.Started, VideoCompletes: videos.Completed}
+	for _, p := range series {
+		totals.Revenue += p.Revenue
+		totals.NetRevenue += p.NetRevenue
+		totals.Purchases += p.Purchases
+		totals.Tips += p.Tips
+		totals.TipRevenue += p.TipRevenue
+		totals.Views += p.Views
+		totals.NewFollowers += p.NewFollowers
+		totals.Likes += p.Likes
+		totals.Saves += p.Saves
+	}
+	totals.ConversionRate = ratio(totals.Purchases, totals.Views)
+	totals.CompletionRate = ratio(videos.Completed, videos.Started)
+	topPosts := make([]TopPost, 0, len(top))
+	for _, t := range top {
+		topPosts = append(topPosts, TopPost{PostID: t.PostID, Text: t.Text, Purchases: t.Purchases, Revenue: t.Gross, NetRevenue: t.Net})
+	}
+	return &ModelAnalytics{
+		ModelID:  modelID,
+		From:     from.Format(dateLayout),
+		To:       to.Format(dateLayout),
+		Interval: interval,
+		Totals:   totals,
+		Series:   series,
+		TopPosts: topPosts,
+	}, nil
+}
+
+const dateLayout = "2006-01-02"
+
+func ratio(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
+
+// dayOf cuts a DATE() result to YYYY-MM-DD; Postgres drivers return it as a
+// full timestamp.
+func dayOf(s string) string {
+	if len(s) > len(dateLayout) {
+		return s[:len(dateLayout)]
+	}
+	return s
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func bucketStart(day time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+// AuditTarget says which entity an audited route acts on: the rows of Table
+// whose Key column equals the route parameter Param. Routes that create
+// entities or act on the system as a whole have no Table and record no
+// changes.
+type AuditTarget struct {
+	Entity string
+	Table  string
+	Key    string // "id" when empty
+	Param  string // "id" when empty
+}
+
+// Entities the audit log knows about.
+var (
+	AuditUser               = AuditTarget{Entity: "user", Table: "users"}
+	AuditUserRoles          = AuditTarget{Entity: "user", Table: "user_roles", Key: "user_id"}
+	AuditPost               = AuditTarget{Entity: "post", Table: "posts"}
+	AuditVideo              = AuditTarget{Entity: "video", Table: "videos"}
+	AuditImage              = AuditTarget{Entity: "image", Table: "images"}
+	AuditModel              = AuditTarget{Entity: "model", Table: "model_profiles"}
+	AuditPortfolio          = AuditTarget{Entity: "model", Param: "modelId"}
+	AuditOrder              = AuditTarget{Entity: "order", Table: "orders"}
+	AuditPayout             = AuditTarget{Entity: "payout", Table: "payouts"}
+	AuditModerationCase     = AuditTarget{Entity: "moderation_case", Table: "moderation_cases"}
+	AuditCreatorApplication = AuditTarget{Entity: "creator_application", Table: "creator_applications"}
+	AuditOperation          = AuditTarget{Entity: "operation", Param: "operation"}
+)
+
+// auditRedacted columns never appear in the log; a change to them shows up
+// without its values. Besides secrets this covers personal data, which the
+// log cannot give up on erasure without breaking its hash chain.
+var auditRedacted = map[string]bool{
+	"password": true, "secret": true, "token_hash": true, "code_hash": true,
+	"storage_key": true, "object_key": true,
+	// personal data
+	"email": true, "nickname": true, "referral_code": true, "avatar_url": true,
+	"legal_name": true, "date_of_birth": true, "address": true,
+}
+
+const redacted = "[redacted]"
+
+// AuditChange is the old and new value of one changed field.
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditRecord is a privileged request to be written to the audit log.
+// Before and After are snapshots of the target taken around the request.
+type AuditRecord struct {
+	Actor     *models.User
+	Action    string
+	Target    AuditTarget
+	EntityID  string
+	Before    interface{}
+	After     interface{}
+	RequestID string
+	IP        string
+}
+
+// AuditVerification is the outcome of checking the hash chain. BrokenAt is
+// the Seq of the first entry that does not match.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
+
+// AuditService writes the tamper-evident audit log of staff actions.
+type AuditService struct {
+	Repo repository.AuditRepository
+	Now  func() time.Time
+}
+
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{Repo: repo, Now: time.Now}
+}
+
+// Snapshot returns the current state of the target with the given ID: a
+// row, a list of rows for non-unique keys, or nil when there is nothing to
+// load.
+func (s *AuditService) Snapshot(target AuditTarget, id string) (interface{}, error) {
+	if target.Table == "" || id == "" {
+		return nil, nil
+	}
+	key := target.Key
+	if key == "" {
+		key = "id"
+	}
+	rows, err := s.Repo.Snapshot(target.Table, key, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+	}
+	if key != "id" {
+		return rows, nil
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// Record appends the request to the audit log.
+func (s *AuditService) Record(rec AuditRecord) error {
+	changes, err := json.Marshal(auditDiff(rec.Before, rec.After))
+	if err != nil {
+		return err
+	}
+	entry := &models.AuditEntry{
+		Action:     rec.Action,
+		EntityType: rec.Target.Entity,
+		EntityID:   rec.EntityID,
+		Changes:    string(changes),
+		RequestID:  rec.RequestID,
+		IP:         rec.IP,
+		// Postgres keeps microseconds; the hash must survive a round trip.
+		CreatedAt: s.Now().UTC().Truncate(time.Microsecond),
+	}
+	if rec.Actor != nil {
+		id := rec.Actor.ID
+		entry.ActorID = &id
+	}
+	if err := s.Repo.Append(entry, func(e *models.AuditEntry) { e.Hash = auditHash(e) }); err != nil {
+		logging.GetLogger().Error("Audit entry not recorded", zap.String("action", rec.Action), zap.String("entity_id", rec.EntityID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List returns matching audit entries, newest first.
+func (s *AuditService) List(filter repository.AuditFilter, limit, offset int) ([]dto.AuditEntryResponseDTO, error) {
+	entries, err := s.Repo.List(filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.AuditEntryResponseDTO, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, dto.AuditEntryResponseDTO{AuditEntry: e, Changes: json.RawMessage(e.Changes)})
+	}
+	return out, nil
+}
+
+// Verify recomputes the hash chain from the first entry and reports the
+// first entry that was altered, removed or inserted out of order.
+func (s *AuditService) Verify() (AuditVerification, error) {
+	const batch = 500
+	var result AuditVerification
+	prevHash := ""
+	var seq int64
+	for {
+		entries, err := s.Repo.Chain(seq, batch)
+		if err != nil {
+			return result, err
+		}
+		for i := range entries {
+			e := &entries[i]
+			if e.Seq != seq+1 || e.PrevHash != prevHash || e.Hash != auditHash(e) {
+				broken := e.Seq
+				result.BrokenAt = &broken
+				return result, nil
+			}
+			seq, prevHash = e.Seq, e.Hash
+			result.Entries++
+		}
+		if len(entries) < batch {
+			result.Valid = true
+			return result, nil
+		}
+	}
+}
+
+// auditHash is the SHA-256 of the entry's fields and the previous hash.
+func auditHash(e *models.AuditEntry) string {
+	actor := ""
+	if e.ActorID != nil {
+		actor = e.ActorID.String()
+	}
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.Seq, 10),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		actor,
+		e.Action,
+		e.EntityType,
+		e.EntityID,
+		e.Changes,
+		e.RequestID,
+		e.IP,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// auditDiff lists the fields that differ between two snapshots. Snapshots
+// that are not single rows are compared as a whole under "rows".
+func auditDiff(before, after interface{}) map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	b, bok := before.(map[string]interface{})
+	a, aok := after.(map[string]interface{})
+	if !bok && !aok {
+		if !auditEqual(before, after) {
+			changes["rows"] = AuditChange{From: redactRows(before), To: redactRows(after)}
+		}
+		return changes
+	}
+	keys := map[string]bool{}
+	for k := range b {
+		keys[k] = true
+	}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range keys {
+		from, to := b[k], a[k]
+		if auditEqual(from, to) {
+			continue
+		}
+		if auditRedacted[k] {
+			from, to = redacted, redacted
+		}
+		changes[k] = AuditChange{From: from, To: to}
+	}
+	return changes
+}
+
+// auditEqual compares values as they would be logged.
+func auditEqual(x, y interface{}) bool {
+	bx, errx := json.Marshal(x)
+	by, erry := json.Marshal(y)
+	if errx != nil || erry != nil {
+		return reflect.DeepEqual(x, y)
+	}
+	return string(bx) == string(by)
+}
+
+// redactRows hides redacted columns of a list of rows.
+func redactRows(v interface{}) interface{} {
+	rows, ok := v.([]map[string]interface{})
+	if !ok {
+		return v
+	}
+	for _, row := range rows {
+		for k := range row {
+			if auditRedacted[k] {
+				row[k] = redacted
+			}
+		}
+	}
+	return rows
+}
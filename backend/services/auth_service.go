@@ -0,0 +1,403 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/utils"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAuthNotConfigured   = errors.New("native authentication is not configured")
+	ErrEmailTaken          = errors.New("email is already registered")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+	ErrTwoFactorRequired   = errors.New("two-factor verification required")
+)
+
+// Token purposes. Access tokens carry no purpose; a 2FA challenge token only
+// proves the password step of a login and cannot be used as an access token.
+const purposeTwoFactorChallenge = "2fa_challenge"
+
+// twoFactorChallengeTTL is how long a user has to enter their code after the password.
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// AccessClaims are the claims of an access JWT issued by AuthService.
+type AccessClaims struct {
+	Version int    `json:"ver"`
+	MFA     bool   `json:"mfa,omitempty"` // the session passed two-factor verification
+	Purpose string `json:"pur,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SessionMeta describes the client a session is issued to.
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// AuthService implements first-party email/password authentication with
+// short-lived access JWTs and rotating refresh tokens.
+type AuthService struct {
+	Users      repository.UserRepository
+	Tokens     repository.RefreshTokenRepository
+	TwoFactors repository.TwoFactorRepository
+	Secret     []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+func NewAuthService(users repository.UserRepository, tokens repository.RefreshTokenRepository, twoFactors repository.TwoFactorRepository) *AuthService {
+	cfg := config.AppConfig
+	return &AuthService{
+		Users:      users,
+		Tokens:     tokens,
+		TwoFactors: twoFactors,
+		Secret:     []byte(cfg.JWTSecret),
+		Issuer:     cfg.JWTIssuer,
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	}
+}
+
+func (s *AuthService) configured() bool {
+	return len(s.Secret) > 0
+}
+
+func (s *AuthService) Register(input *dto.RegisterDTO, meta SessionMeta) (*models.User, dto.TokenPairDTO, error) {
+	logger := logging.GetLogger()
+	logger.Debug("Register called", zap.String("email", input.Email))
+	if !s.configured() {
+		return nil, dto.TokenPairDTO{}, ErrAuthNotConfigured
+	}
+	if _, err := s.Users.FindByEmail(input.Email); err == nil || errors.Is(err, repository.ErrUserDeleted) {
+		return nil, dto.TokenPairDTO{}, ErrEmailTaken
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, dto.TokenPairDTO{}, err
+	}
+	userService := NewUserService(s.Users)
+	user := models.User{
+		Email:    input.Email,
+		Nickname: input.Nickname,
+		Password: input.Password,
+	}
+	if err := userService.CreateUser(&user); err != nil {
+		logger.Error("Register create user failed", zap.String("email", input.Email), zap.Error(err))
+		return nil, dto.TokenPairDTO{}, err
+	}
+	if input.ReferralCode != "" {
+		if err := userService.ApplyReferral(&user, input.ReferralCode); err != nil {
+			logger.Warn("Register referral not applied", zap.String("email", input.Email), zap.Error(err))
+		}
+	}
+	tokens, err := s.issue(&user, uuid.New(), false, meta)
+	if err != nil {
+		return nil, dto.TokenPairDTO{}, err
+	}
+	logger.Info("Register success", zap.String("user_id", user.ID.String()))
+	return &user, tokens, nil
+}
+
+// Login checks the password. For users with two-factor authentication it
+// returns the user together with ErrTwoFactorRequired and no tokens; the
+// caller then hands out a challenge with IssueChallenge.
+func (s *AuthService) Login(email, password string, meta SessionMeta) (*models.User, dto.TokenPairDTO, error) {
+	logger := logging.GetLogger()
+	if !s.configured() {
+		return nil, dto.TokenPairDTO{}, ErrAuthNotConfigured
+	}
+	user, err := s.Users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, dto.TokenPairDTO{}, ErrInvalidCredentials
+		}
+		return nil, dto.TokenPairDTO{}, err
+	}
+	// Accounts created through Firebase have no password and cannot log in here.
+	if user.Password == "" || !utils.CheckPasswordHash(password, user.Password) {
+		logger.Warn("Login failed", zap.String("user_id", user.ID.String()))
+		return nil, dto.TokenPairDTO{}, ErrInvalidCredentials
+	}
+	tf, err := s.TwoFactors.FindByUserID(user.ID)
+	if err != nil {
+		return nil, dto.TokenPairDTO{}, err
+	}
+	if tf.Enabled() {
+		logger.Info("Login needs second factor", zap.String("user_id", user.ID.String()))
+		return &user, dto.TokenPairDTO{}, ErrTwoFactorRequired
+	}
+	tokens, err := s.issue(&user, uuid.New(), false, meta)
+	if err != nil {
+		return nil, dto.TokenPairDTO{}, err
+	}
+	logger.Info("Login success", zap.String("user_id", user.ID.String()))
+	return &user, tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting an already rotated token revokes its whole family,
+// and so does losing the race to rotate it against a concurrent refresh.
+func (s *AuthService) Refresh(raw string, meta SessionMeta) (dto.TokenPairDTO, error) {
+	logger := logging.GetLogger()
+	if !s.configured() {
+		return dto.TokenPairDTO{}, ErrAuthNotConfigured
+	}
+	token, err := s.Tokens.FindByHash(hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.TokenPairDTO{}, ErrInvalidRefreshToken
+		}
+		return dto.TokenPairDTO{}, err
+	}
+	now := time.Now()
+	if token.RevokedAt != nil {
+		if token.ReplacedByID != nil {
+			logger.Warn("Refresh token reuse detected", zap.String("user_id", token.UserID.String()), zap.String("family_id", token.FamilyID.String()))
+			if err := s.Tokens.RevokeFamily(token.FamilyID, now); err != nil {
+				return dto.TokenPairDTO{}, err
+			}
+		}
+		return dto.TokenPairDTO{}, ErrInvalidRefreshToken
+	}
+	if now.After(token.ExpiresAt) {
+		return dto.TokenPairDTO{}, ErrInvalidRefreshToken
+	}
+	user, err := s.Users.FindByID(token.UserID)
+	if err != nil {
+		return dto.TokenPairDTO{}, ErrInvalidRefreshToken
+	}
+	pair, next, err := s.issueWithToken(&user, token.FamilyID, token.MFA, meta)
+	if err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	rotated, err := s.Tokens.Rotate(token.ID, next.ID, now)
+	if err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	if !rotated {
+		logger.Warn("Refresh token reuse detected", zap.String("user_id", token.UserID.String()), zap.String("family_id", token.FamilyID.String()))
+		if err := s.Tokens.RevokeFamily(token.FamilyID, now); err != nil {
+			return dto.TokenPairDTO{}, err
+		}
+		return dto.TokenPairDTO{}, ErrInvalidRefreshToken
+	}
+	return pair, nil
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are ignored.
+func (s *AuthService) Logout(raw string) error {
+	token, err := s.Tokens.FindByHash(hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.Tokens.RevokeFamily(token.FamilyID, time.Now())
+}
+
+// LogoutAll revokes every session of the user, including issued access tokens.
+func (s *AuthService) LogoutAll(user *models.User) error {
+	if err := s.Tokens.RevokeAllForUser(user.ID, time.Now()); err != nil {
+		return err
+	}
+	user.TokenVersion++
+	return s.Users.Update(user)
+}
+
+// ChangePassword sets a new password, ends all other sessions and returns a
+// fresh token pair for the caller. Users without a password (Firebase
+// accounts) may set one without providing the current password. mfa carries
+// over the caller's two-factor state to the new session.
+func (s *AuthService) ChangePassword(user *models.User, current, next string, mfa bool, meta SessionMeta) (dto.TokenPairDTO, error) {
+	logger := logging.GetLogger()
+	if !s.configured() {
+		return dto.TokenPairDTO{}, ErrAuthNotConfigured
+	}
+	if user.Password != "" && !utils.CheckPasswordHash(current, user.Password) {
+		return dto.TokenPairDTO{}, ErrInvalidCredentials
+	}
+	hashed, err := utils.HashPassword(next)
+	if err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	user.Password = hashed
+	if err := s.LogoutAll(user); err != nil {
+		logger.Error("ChangePassword failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return dto.TokenPairDTO{}, err
+	}
+	logger.Info("ChangePassword success", zap.String("user_id", user.ID.String()))
+	return s.issue(user, uuid.New(), mfa, meta)
+}
+
+// StartSession issues a new session for the user. Used after
+// a completed two-factor login.
+func (s *AuthService) StartSession(user *models.User, mfa bool, meta SessionMeta) (dto.TokenPairDTO, error) {
+	if !s.configured() {
+		return dto.TokenPairDTO{}, ErrAuthNotConfigured
+	}
+	return s.issue(user, uuid.New(), mfa, meta)
+}
+
+// IssueChallenge returns a short-lived token proving that the user passed the
+// password step of a login. It is exchanged for a session at /auth/2fa/verify.
+func (s *AuthService) IssueChallenge(user *models.User) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(twoFactorChallengeTTL)
+	claims := AccessClaims{
+		Version: user.TokenVersion,
+		Purpose: purposeTwoFactorChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.Issuer,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+	raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.Secret)
+	return raw, exp, err
+}
+
+// ParseChallenge verifies a challenge token and loads its user.
+func (s *AuthService) ParseChallenge(raw string) (*models.User, error) {
+	user, _, err := s.loadUser(raw, purposeTwoFactorChallenge)
+	return user, err
+}
+
+// IsNativeToken reports whether raw looks like an access token issued by this
+// service, as opposed to a Firebase ID token. The signature is not checked.
+func (s *AuthService) IsNativeToken(raw string) bool {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, &claims); err != nil {
+		return false
+	}
+	return claims.Issuer == s.Issuer
+}
+
+// Authenticate verifies an access token and loads its user. The returned
+// bool reports whether the session passed two-factor verification.
+func (s *AuthService) Authenticate(raw string) (*models.User, bool, error) {
+	user, claims, err := s.loadUser(raw, "")
+	if err != nil {
+		return nil, false, err
+	}
+	return user, claims.MFA, nil
+}
+
+// loadUser verifies a token of the given purpose and loads its user.
+func (s *AuthService) loadUser(raw, purpose string) (*models.User, *AccessClaims, error) {
+	claims, err := s.ParseAccessToken(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	user, err := s.Users.FindByID(id)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	if user.TokenVersion != claims.Version {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	return &user, claims, nil
+}
+
+func (s *AuthService) ParseAccessToken(raw string) (*AccessClaims, error) {
+	if !s.configured() {
+		return nil, ErrAuthNotConfigured
+	}
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidAccessToken
+		}
+		return s.Secret, nil
+	})
+	if err != nil || !claims.VerifyIssuer(s.Issuer, true) {
+		return nil, ErrInvalidAccessToken
+	}
+	return claims, nil
+}
+
+func (s *AuthService) issue(user *models.User, familyID uuid.UUID, mfa bool, meta SessionMeta) (dto.TokenPairDTO, error) {
+	pair, _, err := s.issueWithToken(user, familyID, mfa, meta)
+	return pair, err
+}
+
+func (s *AuthService) issueWithToken(user *models.User, familyID uuid.UUID, mfa bool, meta SessionMeta) (dto.TokenPairDTO, *models.RefreshToken, error) {
+	now := time.Now()
+	accessExp := now.Add(s.AccessTTL)
+	claims := AccessClaims{
+		Version: user.TokenVersion,
+		MFA:     mfa,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.Issuer,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(accessExp),
+		},
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.Secret)
+	if err != nil {
+		return dto.TokenPairDTO{}, nil, err
+	}
+	raw, err := newSecretToken()
+	if err != nil {
+		return dto.TokenPairDTO{}, nil, err
+	}
+	refresh := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(s.RefreshTTL),
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+		MFA:       mfa,
+	}
+	if err := s.Tokens.Create(refresh); err != nil {
+		return dto.TokenPairDTO{}, nil, err
+	}
+	return dto.TokenPairDTO{
+		AccessToken:      access,
+		RefreshToken:     raw,
+		TokenType:        "Bearer",
+		ExpiresAt:        accessExp,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, refresh, nil
+}
+
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrBlockSelf   = errors.New("you cannot block or mute yourself")
+	ErrUserBlocked = errors.New("you cannot interact with this user")
+)
+
+// BlockService manages blocks and mutes and answers what they hide. Viewer
+// IDs are uuid.Nil for anonymous requests, for whom nothing is hidden.
+type BlockService struct {
+	Repo  repository.BlockRepository
+	Users repository.UserRepository
+}
+
+func NewBlockService(repo repository.BlockRepository, users repository.UserRepository) *BlockService {
+	return &BlockService{Repo: repo, Users: users}
+}
+
+// Block reports false when userID already blocked targetID.
+func (s *BlockService) Block(userID, targetID uuid.UUID) (bool, error) {
+	if err := s.checkTarget(userID, targetID); err != nil {
+		return false, err
+	}
+	created, err := s.Repo.Block(userID, targetID)
+	if err != nil {
+		logging.GetLogger().Error("Block failed", zap.String("user_id", userID.String()), zap.String("target_id", targetID.String()), zap.Error(err))
+	} else if created {
+		logging.GetLogger().Info("User blocked", zap.String("user_id", userID.String()), zap.String("target_id", targetID.String()))
+	}
+	return created, err
+}
+
+func (s *BlockService) Unblock(userID, targetID uuid.UUID) (bool, error) {
+	return s.Repo.Unblock(userID, targetID)
+}
+
+// Mute reports false when userID already muted targetID.
+func (s *BlockService) Mute(userID, targetID uuid.UUID) (bool, error) {
+	if err := s.checkTarget(userID, targetID); err != nil {
+		return false, err
+	}
+	return s.Repo.Mute(userID, targetID)
+}
+
+func (s *BlockService) Unmute(userID, targetID uuid.UUID) (bool, error) {
+	return s.Repo.Unmute(userID, targetID)
+}
+
+func (s *BlockService) checkTarget(userID, targetID uuid.UUID) error {
+	if userID == targetID {
+		return ErrBlockSelf
+	}
+	_, err := s.Users.FindByID(targetID)
+	return err
+}
+
+func (s *BlockService) Blocked(userID uuid.UUID, limit, offset int) ([]dto.RelatedUserDTO, error) {
+	users, err := s.Repo.Blocked(userID, limit, offset)
+	return relatedUsers(users), err
+}
+
+func (s *BlockService) Muted(userID uuid.UUID, limit, offset int) ([]dto.RelatedUserDTO, error) {
+	users, err := s.Repo.Muted(userID, limit, offset)
+	return relatedUsers(users), err
+}
+
+func relatedUsers(users []repository.RelatedUser) []dto.RelatedUserDTO {
+	resp := make([]dto.RelatedUserDTO, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, dto.RelatedUserDTO{ID: u.ID, Nickname: u.Nickname, AvatarURL: u.AvatarURL, Since: u.Since})
+	}
+	return resp
+}
+
+// CheckInteraction fails with ErrUserBlocked when either user blocks the
+// other.
+func (s *BlockService) CheckInteraction(a, b uuid.UUID) error {
+	blocked, err := s.Repo.Between(a, b)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	return nil
+}
+
+// FeedHidden lists whose posts and comments viewerID must not see.
+func (s *BlockService) FeedHidden(viewerID uuid.UUID) ([]uuid.UUID, error) {
+	if viewerID == uuid.Nil {
+		return nil, nil
+	}
+	return s.Repo.HiddenFrom(viewerID, true)
+}
+
+// SearchHidden lists the users left out of viewerID's search results: those
+// blocked either way. Muted users can still be found.
+func (s *BlockService) SearchHidden(viewerID uuid.UUID) ([]uuid.UUID, error) {
+	if viewerID == uuid.Nil {
+		return nil, nil
+	}
+	return s.Repo.HiddenFrom(viewerID, false)
+}
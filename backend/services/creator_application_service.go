@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrApplicationPending   = errors.New("an application is already waiting for review")
+	ErrAlreadyCreator       = errors.New("user is already a verified creator")
+	ErrApplicationReviewed  = errors.New("application has already been reviewed")
+	ErrApplicantUnderage    = errors.New("creators must be at least 18 years old")
+	ErrDocumentMissing      = errors.New("an ID document and a selfie are required")
+	ErrDocumentType         = errors.New("documents must be JPEG, PNG or PDF")
+	ErrDocumentTooLarge     = errors.New("document is too large")
+	ErrReviewReasonRequired = errors.New("a reason is required to reject an application")
+)
+
+// MinCreatorAge is the minimum age checked against the declared date of birth.
+const MinCreatorAge = 18
+
+// MaxDocumentSize limits a single identity document upload.
+const MaxDocumentSize = 10 << 20
+
+var documentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// CreatorApplicationInput is what the applicant submits besides documents.
+type CreatorApplicationInput struct {
+	LegalName   string
+	DateOfBirth time.Time
+	Country     string
+	Name        string
+	Bio         string
+	Banner      string
+}
+
+// DocumentUpload is an identity document read from the request.
+type DocumentUpload struct {
+	Kind        string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// CreatorApplicationService runs creator onboarding: applicants submit
+// identity data and documents, moderators approve or reject, and approval
+// turns the applicant into a verified creator.
+type CreatorApplicationService struct {
+	Repo  repository.CreatorApplicationRepository
+	Roles repository.RoleRepository
+	Store storage.Store
+	Now   func() time.Time
+}
+
+func NewCreatorApplicationService(repo repository.CreatorApplicationRepository, roles repository.RoleRepository, store storage.Store) *CreatorApplicationService {
+	return &CreatorApplicationService{Repo: repo, Roles: roles, Store: store, Now: time.Now}
+}
+
+// Submit files a new application. Documents are written to the private store
+// before the application row, and removed again if saving it fails.
+func (s *CreatorApplicationService) Submit(ctx context.Context, user *models.User, input CreatorApplicationInput, docs []DocumentUpload) (*models.CreatorApplication, error) {
+	logger := logging.GetLogger()
+	latest, err := s.Repo.FindLatestForUser(user.ID)
+	switch {
+	case err == nil && latest.Status == models.ApplicationPending:
+		return nil, ErrApplicationPending
+	case err == nil && latest.Status == models.ApplicationApproved:
+		return nil, ErrAlreadyCreator
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	if ageOn(input.DateOfBirth, s.Now()) < MinCreatorAge {
+		return nil, ErrApplicantUnderage
+	}
+	if err := validateDocuments(docs); err != nil {
+		return nil, err
+	}
+
+	app := &models.CreatorApplication{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Status:      models.ApplicationPending,
+		LegalName:   strings.TrimSpace(input.LegalName),
+		DateOfBirth: input.DateOfBirth,
+		Country:     strings.ToUpper(input.Country),
+		Name:        input.Name,
+		Bio:         input.Bio,
+		Banner:      input.Banner,
+	}
+	for _, d := range docs {
+		doc := models.CreatorDocument{ID: uuid.New(), ApplicationID: app.ID, Kind: d.Kind, ContentType: d.ContentType, Size: d.Size}
+		doc.StorageKey = fmt.Sprintf("creator-applications/%s/%s", app.ID, doc.ID)
+		if err := s.Store.Put(ctx, doc.StorageKey, d.Body); err != nil {
+			s.removeDocuments(app.Documents)
+			logger.Error("Store creator document failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+			return nil, err
+		}
+		app.Documents = append(app.Documents, doc)
+	}
+	if err := s.Repo.Create(app); err != nil {
+		s.removeDocuments(app.Documents)
+		logger.Error("Create creator application failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Creator application submitted", zap.String("application_id", app.ID.String()), zap.String("user_id", user.ID.String()))
+	return app, nil
+}
+
+// Latest returns the user's most recent application.
+func (s *CreatorApplicationService) Latest(userID uuid.UUID) (*models.CreatorApplication, error) {
+	return s.Repo.FindLatestForUser(userID)
+}
+
+// Queue lists applications in the given status, oldest first.
+func (s *CreatorApplicationService) Queue(status string, limit, offset int) ([]models.CreatorApplication, error) {
+	return s.Repo.ListByStatus(status, limit, offset)
+}
+
+func (s *CreatorApplicationService) Get(id uuid.UUID) (*models.CreatorApplication, error) {
+	return s.Repo.FindByID(id)
+}
+
+// OpenDocument returns a document of the application and its contents.
+// The caller closes the reader.
+func (s *CreatorApplicationService) OpenDocument(ctx context.Context, appID, docID uuid.UUID) (*models.CreatorDocument, io.ReadCloser, error) {
+	app, err := s.Repo.FindByID(appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range app.Documents {
+		doc := &app.Documents[i]
+		if doc.ID != docID {
+			continue
+		}
+		body, err := s.Store.Get(ctx, doc.StorageKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc, body, nil
+	}
+	return nil, nil, gorm.ErrRecordNotFound
+}
+
+// Approve verifies the applicant: their model profile is created (or the
+// existing one updated) with VerifiedAt set and they get the creator role.
+func (s *CreatorApplicationService) Approve(reviewer *models.User, id uuid.UUID, reason string) (*models.CreatorApplication, *models.ModelProfile, error) {
+	logger := logging.GetLogger()
+	app, err := s.pending(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.review(app, reviewer, models.ApplicationApproved, reason)
+
+	var grant *models.UserRole
+	var change *models.RoleChange
+	held, err := s.Roles.FindForUser(app.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hasRole(held, models.RoleCreator) {
+		grant = &models.UserRole{UserID: app.UserID, Role: models.RoleCreator, GrantedBy: app.ReviewerID}
+		change = &models.RoleChange{UserID: app.UserID, ActorID: app.ReviewerID, Role: models.RoleCreator, Action: models.RoleGranted, Reason: "creator application approved"}
+	}
+	profile, err := s.Repo.Approve(app, grant, change)
+	if err != nil {
+		logger.Error("Approve creator application failed", zap.String("application_id", id.String()), zap.Error(err))
+		return nil, nil, err
+	}
+	logger.Info("Creator application approved", zap.String("application_id", id.String()), zap.String("user_id", app.UserID.String()), zap.Stringp("reviewer_id", uuidString(app.ReviewerID)))
+	return app, profile, nil
+}
+
+// Reject declines the application. The applicant sees the reason and may apply again.
+func (s *CreatorApplicationService) Reject(reviewer *models.User, id uuid.UUID, reason string) (*models.CreatorApplication, error) {
+	logger := logging.GetLogger()
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrReviewReasonRequired
+	}
+	app, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	s.review(app, reviewer, models.ApplicationRejected, reason)
+	if err := s.Repo.Reject(app); err != nil {
+		logger.Error("Reject creator application failed", zap.String("application_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Creator application rejected", zap.String("application_id", id.String()), zap.String("user_id", app.UserID.String()), zap.Stringp("reviewer_id", uuidString(app.ReviewerID)))
+	return app, nil
+}
+
+func (s *CreatorApplicationService) pending(id uuid.UUID) (*models.CreatorApplication, error) {
+	app, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if app.Status != models.ApplicationPending {
+		return nil, ErrApplicationReviewed
+	}
+	return app, nil
+}
+
+func (s *CreatorApplicationService) review(app *models.CreatorApplication, reviewer *models.User, status, reason string) {
+	now := s.Now()
+	app.Status = status
+	app.ReviewerID = actorIDOf(reviewer)
+	app.ReviewReason = strings.TrimSpace(reason)
+	app.ReviewedAt = &now
+}
+
+func (s *CreatorApplicationService) removeDocuments(docs []models.CreatorDocument) {
+	for _, d := range docs {
+		if err := s.Store.Delete(context.Background(), d.StorageKey); err != nil {
+			logging.GetLogger().Warn("Remove creator document failed", zap.String("key", d.StorageKey), zap.Error(err))
+		}
+	}
+}
+
+func validateDocuments(docs []DocumentUpload) error {
+	kinds := map[string]bool{}
+	for _, d := range docs {
+		if !documentTypes[d.ContentType] {
+			return ErrDocumentType
+		}
+		if d.Size > MaxDocumentSize {
+			return ErrDocumentTooLarge
+		}
+		kinds[d.Kind] = true
+	}
+	if !kinds[models.DocumentID] || !kinds[models.DocumentSelfie] {
+		return ErrDocumentMissing
+	}
+	return nil
+}
+
+// ageOn returns the age in full years on the given day. Birthdays on 29
+// February come round on 1 March in common years.
+func ageOn(birth, day time.Time) int {
+	years := day.Year() - birth.Year()
+	if day.Month() < birth.Month() || (day.Month() == birth.Month() && day.Day() < birth.Day()) {
+		years--
+	}
+	return years
+}
+
+func hasRole(roles []models.UserRole, role models.Role) bool {
+	for _, r := range roles {
+		if r.Role == role {
+			return true
+		}
+	}
+	return false
+}
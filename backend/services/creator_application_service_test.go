@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgeOn(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		name       string
+		birth, day time.Time
+		want       int
+	}{
+		{"day before birthday", date(2000, time.June, 15), date(2018, time.June, 14), 17},
+		{"on birthday", date(2000, time.June, 15), date(2018, time.June, 15), 18},
+		// 1 March is day 60 in a common year and day 61 in a leap year
+		{"born 1 March, leap year eve", date(2010, time.March, 1), date(2028, time.February, 29), 17},
+		{"born 1 March, leap year birthday", date(2010, time.March, 1), date(2028, time.March, 1), 18},
+		{"born in a leap year, day before", date(2008, time.March, 1), date(2026, time.February, 28), 17},
+		{"born in a leap year, birthday", date(2008, time.March, 1), date(2026, time.March, 1), 18},
+		{"born 31 December", date(2007, time.December, 31), date(2025, time.December, 30), 17},
+		{"born 29 February, common year 28 February", date(2008, time.February, 29), date(2026, time.February, 28), 17},
+		{"born 29 February, common year 1 March", date(2008, time.February, 29), date(2026, time.March, 1), 18},
+		{"born 29 February, leap year birthday", date(2008, time.February, 29), date(2028, time.February, 29), 20},
+		{"born 29 February, leap year eve", date(2008, time.February, 29), date(2028, time.February, 28), 19},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, ageOn(tc.birth, tc.day), tc.name)
+	}
+}
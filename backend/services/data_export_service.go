@@ -0,0 +1,338 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ExportTimeout is how long an export may stay pending. One still pending
+// after that lost its build, to a restart or a crash, and is marked failed.
+const ExportTimeout = time.Hour
+
+// exportFailed is the error shown to the user on a failed export.
+const exportFailed = "Export could not be built, please request a new one"
+
+var (
+	ErrExportPending = errors.New("a data export is already being prepared")
+	ErrExportLink    = errors.New("invalid download link")
+	ErrExportExpired = errors.New("download link expired")
+)
+
+// DataExportService builds ZIP archives of everything kept about a user, one
+// JSON file per kind of data, and hands them out through signed links that
+// expire after TTL. Archives are built in the background and the user is
+// notified once theirs is ready.
+type DataExportService struct {
+	Repo          repository.DataExportRepository
+	Store         storage.Store
+	Notifications *NotificationService
+	Secret        []byte
+	TTL           time.Duration
+	Timeout       time.Duration
+	Now           func() time.Time
+}
+
+func NewDataExportService(repo repository.DataExportRepository, store storage.Store, notifications *NotificationService) *DataExportService {
+	cfg := config.AppConfig
+	return &DataExportService{
+		Repo:          repo,
+		Store:         store,
+		Notifications: notifications,
+		Secret:        []byte(cfg.JWTSecret),
+		TTL:           cfg.DataExportTTL,
+		Timeout:       ExportTimeout,
+		Now:           time.Now,
+	}
+}
+
+// Request starts an export for the user unless one is still being built.
+func (s *DataExportService) Request(user *models.User) (dto.DataExportResponseDTO, error) {
+	if _, err := s.Repo.Pending(user.ID, s.Now().Add(-s.Timeout)); err == nil {
+		return dto.DataExportResponseDTO{}, ErrExportPending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.DataExportResponseDTO{}, err
+	}
+	export := &models.DataExport{UserID: user.ID, Status: models.ExportPending, CreatedAt: s.Now()}
+	if err := s.Repo.Create(export); err != nil {
+		return dto.DataExportResponseDTO{}, err
+	}
+	logging.GetLogger().Info("Data export requested", zap.String("export_id", export.ID.String()), zap.String("user_id", user.ID.String()))
+	go s.Run(*export)
+	return s.exportDTO(export), nil
+}
+
+// List returns the user's exports, newest first.
+func (s *DataExportService) List(userID uuid.UUID) ([]dto.DataExportResponseDTO, error) {
+	exports, err := s.Repo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.DataExportResponseDTO, 0, len(exports))
+	for i := range exports {
+		out = append(out, s.exportDTO(&exports[i]))
+	}
+	return out, nil
+}
+
+// Run builds and stores the archive of a pending export and notifies its
+// user. Failures, panics included, are recorded on the export.
+func (s *DataExportService) Run(export models.DataExport) {
+	logger := logging.GetLogger()
+	key := fmt.Sprintf("exports/%s/%s.zip", export.UserID, export.ID)
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("Data export panicked", zap.String("export_id", export.ID.String()), zap.Any("panic", p))
+			now := s.Now()
+			export.Status = models.ExportFailed
+			export.Error = exportFailed
+			export.CompletedAt = &now
+			if err := s.Repo.Update(&export); err != nil {
+				logger.Error("Record failed data export", zap.String("export_id", export.ID.String()), zap.Error(err))
+			}
+		}
+	}()
+	size, err := s.build(key, export.UserID)
+	now := s.Now()
+	export.CompletedAt = &now
+	if err != nil {
+		logger.Error("Data export failed", zap.String("export_id", export.ID.String()), zap.Error(err))
+		export.Status = models.ExportFailed
+		export.Error = exportFailed
+		if err := s.Repo.Update(&export); err != nil {
+			logger.Error("Record failed data export", zap.String("export_id", export.ID.String()), zap.Error(err))
+		}
+		return
+	}
+	expires := now.Add(s.TTL)
+	export.Status = models.ExportReady
+	export.ObjectKey = key
+	export.Size = size
+	export.ExpiresAt = &expires
+	if err := s.Repo.Update(&export); err != nil {
+		logger.Error("Record data export failed", zap.String("export_id", export.ID.String()), zap.Error(err))
+		return
+	}
+	logger.Info("Data export ready", zap.String("export_id", export.ID.String()), zap.Int64("size", size))
+	if s.Notifications != nil {
+		_ = s.Notifications.ExportReady(export.UserID, export.ID)
+	}
+}
+
+// exportedPost leaves the author, profile and comments embedded in a Post
+// out of the archive; they are exported on their own.
+type exportedPost struct {
+	models.Post
+	User         *struct{}  `json:"user,omitempty"`
+	ModelProfile *struct{}  `json:"model,omitempty"`
+	Comments     *struct{}  `json:"comments,omitempty"`
+	IsPurchased  *struct{}  `json:"isPurchased,omitempty"`
+	ModelID      uuid.UUID  `json:"model_id"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// exportedComment leaves out the author, who is the exporting user.
+type exportedComment struct {
+	models.Comment
+	User *struct{} `json:"user,omitempty"`
+}
+
+// build writes the user's archive to key and returns its size.
+func (s *DataExportService) build(key string, userID uuid.UUID) (int64, error) {
+	data, err := s.Repo.Collect(userID)
+	if err != nil {
+		return 0, err
+	}
+	posts := make([]exportedPost, 0, len(data.Posts))
+	for _, p := range data.Posts {
+		post := exportedPost{Post: p, ModelID: p.ModelID}
+		if p.DeletedAt.Valid {
+			post.DeletedAt = &p.DeletedAt.Time
+		}
+		posts = append(posts, post)
+	}
+	comments := make([]exportedComment, 0, len(data.Comments))
+	for _, c := range data.Comments {
+		comments = append(comments, exportedComment{Comment: c})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"model_profiles.json", data.Models},
+		{"posts.json", posts},
+		{"videos.json", data.Videos},
+		{"images.json", data.Images},
+		{"purchases.json", data.Purchases},
+		{"orders.json", data.Orders},
+		{"payments.json", data.Payments},
+		{"tips.json", data.Tips},
+		{"earnings.json", data.Earnings},
+		{"payouts.json", data.Payouts},
+		{"following.json", data.Following},
+		{"followers.json", data.Followers},
+		{"likes.json", data.Likes},
+		{"saved_posts.json", data.SavedPosts},
+		{"comments.json", comments},
+		{"referrals.json", data.Referrals},
+		{"referred_by.json", data.ReferredBy},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return 0, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.v); err != nil {
+			return 0, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	size := int64(buf.Len())
+	if err := s.Store.Put(context.Background(), key, &buf); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Open checks a download link and opens the archive it points to.
+func (s *DataExportService) Open(ctx context.Context, id uuid.UUID, expires, signature string) (*models.DataExport, io.ReadCloser, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.sign(id, unix))) {
+		return nil, nil, ErrExportLink
+	}
+	if !s.Now().Before(time.Unix(unix, 0)) {
+		return nil, nil, ErrExportExpired
+	}
+	export, err := s.Repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != models.ExportReady {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	body, err := s.Store.Get(ctx, export.ObjectKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return export, body, nil
+}
+
+// sign returns the signature of a download link for the export that is
+// valid until the unix time expires.
+func (s *DataExportService) sign(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "data-export|%s|%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *DataExportService) exportDTO(e *models.DataExport) dto.DataExportResponseDTO {
+	out := dto.DataExportResponseDTO{
+		ID:          e.ID,
+		Status:      e.Status,
+		Size:        e.Size,
+		Error:       e.Error,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+	}
+	if e.Status == models.ExportReady && e.ExpiresAt != nil && s.Now().Before(*e.ExpiresAt) {
+		expires := e.ExpiresAt.Unix()
+		out.DownloadURL = fmt.Sprintf("/me/exports/%s/download?expires=%d&signature=%s", e.ID, expires, s.sign(e.ID, expires))
+	}
+	return out
+}
+
+// RemoveExpired deletes exports whose download link ran out, with their
+// archives, and returns how many went.
+func (s *DataExportService) RemoveExpired() (int, error) {
+	logger := logging.GetLogger()
+	exports, err := s.Repo.Expired(s.Now())
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range exports {
+		if err := s.Store.Delete(context.Background(), e.ObjectKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Error("Delete data export archive failed", zap.String("export_id", e.ID.String()), zap.Error(err))
+			continue
+		}
+		if err := s.Repo.Delete(e.ID); err != nil {
+			logger.Error("Delete data export failed", zap.String("export_id", e.ID.String()), zap.Error(err))
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		logger.Info("Expired data exports removed", zap.Int("count", removed))
+	}
+	return removed, nil
+}
+
+// FailStale marks exports pending for longer than Timeout failed, so their
+// users can request a new one.
+func (s *DataExportService) FailStale() (int64, error) {
+	now := s.Now()
+	n, err := s.Repo.FailStale(now.Add(-s.Timeout), exportFailed, now)
+	if n > 0 {
+		logging.GetLogger().Warn("Stale data exports failed", zap.Int64("count", n))
+	}
+	return n, err
+}
+
+// StartCleaner runs FailStale right away, which catches the exports a
+// restart interrupted, and then FailStale and RemoveExpired every interval
+// in the background until the returned stop function is called.
+func (s *DataExportService) StartCleaner(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.FailStale(); err != nil {
+				logging.GetLogger().Error("Failing stale data exports failed", zap.Error(err))
+			}
+			select {
+			case <-ticker.C:
+				if _, err := s.RemoveExpired(); err != nil {
+					logging.GetLogger().Error("Removing expired data exports failed", zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
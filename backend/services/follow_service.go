@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrFollowSelf = errors.New("you cannot follow yourself")
+
+// FollowService maintains the follow graph. Viewer IDs are uuid.Nil for
+// anonymous requests, who follow nobody.
+type FollowService struct {
+	Repo   repository.FollowRepository
+	Users  repository.UserRepository
+	Blocks repository.BlockRepository
+}
+
+func NewFollowService(repo repository.FollowRepository, users repository.UserRepository, blocks repository.BlockRepository) *FollowService {
+	return &FollowService{Repo: repo, Users: users, Blocks: blocks}
+}
+
+// Follow makes follower follow targetID unless either blocks the other. It
+// reports false when the follow already existed.
+func (s *FollowService) Follow(follower *models.User, targetID uuid.UUID) (bool, error) {
+	if targetID == follower.ID {
+		return false, ErrFollowSelf
+	}
+	if _, err := s.Users.FindByID(targetID); err != nil {
+		return false, err
+	}
+	if blocked, err := s.Blocks.Between(follower.ID, targetID); err != nil || blocked {
+		if blocked {
+			err = ErrUserBlocked
+		}
+		return false, err
+	}
+	created, err := s.Repo.Follow(follower.ID, targetID)
+	if err != nil {
+		logging.GetLogger().Error("Follow failed", zap.String("follower_id", follower.ID.String()), zap.String("followed_id", targetID.String()), zap.Error(err))
+	}
+	return created, err
+}
+
+// Unfollow reports false when followerID did not follow targetID.
+func (s *FollowService) Unfollow(followerID, targetID uuid.UUID) (bool, error) {
+	return s.Repo.Unfollow(followerID, targetID)
+}
+
+// Followers lists who follows userID as viewerID sees it: without withdrawn
+// users and users blocked with the viewer either way, like search.
+func (s *FollowService) Followers(viewerID, userID uuid.UUID, limit, offset int) ([]dto.FollowUserDTO, error) {
+	hidden, err := s.hidden(viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.Repo.Followers(userID, hidden, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.followList(viewerID, edges)
+}
+
+// Following lists whom userID follows, leaving out the same users as
+// Followers.
+func (s *FollowService) Following(viewerID, userID uuid.UUID, limit, offset int) ([]dto.FollowUserDTO, error) {
+	hidden, err := s.hidden(viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.Repo.Following(userID, hidden, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.followList(viewerID, edges)
+}
+
+// hidden checks that userID exists and lists the users viewerID is blocked
+// with; anonymous viewers see everyone who is not withdrawn.
+func (s *FollowService) hidden(viewerID, userID uuid.UUID) ([]uuid.UUID, error) {
+	if _, err := s.Users.FindByID(userID); err != nil {
+		return nil, err
+	}
+	if viewerID == uuid.Nil {
+		return nil, nil
+	}
+	return s.Blocks.HiddenFrom(viewerID, false)
+}
+
+func (s *FollowService) followList(viewerID uuid.UUID, edges []repository.FollowEdge) ([]dto.FollowUserDTO, error) {
+	ids := make([]uuid.UUID, 0, len(edges))
+	for _, e := range edges {
+		ids = append(ids, e.ID)
+	}
+	counts, followed, err := s.stats(viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.FollowUserDTO, 0, len(edges))
+	for _, e := range edges {
+		resp = append(resp, dto.FollowUserDTO{
+			ID:             e.ID,
+			Nickname:       e.Nickname,
+			AvatarURL:      e.AvatarURL,
+			FollowersCount: counts[e.ID].Followers,
+			FollowingCount: counts[e.ID].Following,
+			IsFollowing:    followed[e.ID],
+			FollowedAt:     e.FollowedAt,
+		})
+	}
+	return resp, nil
+}
+
+// DecorateUsers fills in the follow counts of users and whether viewerID
+// follows them.
+func (s *FollowService) DecorateUsers(viewerID uuid.UUID, users []dto.UserResponseDTO) error {
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	counts, followed, err := s.stats(viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		id := users[i].ID
+		users[i].FollowersCount = counts[id].Followers
+		users[i].FollowingCount = counts[id].Following
+		users[i].IsFollowing = followed[id]
+	}
+	return nil
+}
+
+// DecorateProfiles fills in the follow counts of the profiles' users and
+// whether viewerID follows them.
+func (s *FollowService) DecorateProfiles(viewerID uuid.UUID, profiles []dto.ModelProfileResponseDTO) error {
+	ids := make([]uuid.UUID, 0, len(profiles))
+	for _, p := range profiles {
+		ids = append(ids, p.UserID)
+	}
+	counts, followed, err := s.stats(viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		id := profiles[i].UserID
+		profiles[i].FollowersCount = counts[id].Followers
+		profiles[i].FollowingCount = counts[id].Following
+		profiles[i].IsFollowing = followed[id]
+	}
+	return nil
+}
+
+func (s *FollowService) stats(viewerID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]repository.FollowCounts, map[uuid.UUID]bool, error) {
+	counts, err := s.Repo.Counts(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	followed, err := s.Repo.FollowedBy(viewerID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return counts, followed, nil
+}
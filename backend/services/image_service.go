@@ -27,7 +27,8 @@ func NewImageService(db *gorm.DB, logger *zap.Logger) *ImageService {
 	return &ImageService{DB: db, Logger: logger}
 }
 
-func (s *ImageService) UploadImage(file *multipart.FileHeader) (*models.Image, error) {
+// UploadImage uploads the file to Bunny Storage and records ownerID as the uploader.
+func (s *ImageService) UploadImage(ownerID uuid.UUID, file *multipart.FileHeader) (*models.Image, error) {
 	s.Logger.Info("Uploading image to Bunny Storage", zap.String("filename", file.Filename))
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".gif" && ext != ".webp" {
@@ -58,6 +59,7 @@ func (s *ImageService) UploadImage(file *multipart.FileHeader) (*models.Image, e
 	cdnURL := fmt.Sprintf("https://%s/%s", bunnyHost, filename)
 	image := &models.Image{
 		ID:        imgID,
+		UserID:    ownerID,
 		Filename:  filename,
 		CDNUrl:    cdnURL,
 		CreatedAt: time.Now(),
@@ -79,26 +81,14 @@ func (s *ImageService) GetImage(id uuid.UUID) (*models.Image, error) {
 	return &image, nil
 }
 
+// DeleteImage soft-deletes the image. The stored file stays until the
+// retention period is over, see RetentionService.Purge.
 func (s *ImageService) DeleteImage(id uuid.UUID) error {
 	var image models.Image
 	if err := s.DB.First(&image, "id = ?", id).Error; err != nil {
 		s.Logger.Error("Image not found", zap.Error(err))
 		return err
 	}
-	bunnyZone := config.AppConfig.BunnyStorageZone
-	bunnyKey := config.AppConfig.BunnyStorageAPIKey
-	if bunnyZone == "" || bunnyKey == "" {
-		s.Logger.Error("Bunny Storage config missing")
-		return fmt.Errorf("Bunny Storage config missing")
-	}
-	deleteURL := fmt.Sprintf("https://sg.storage.bunnycdn.com/%s/%s", bunnyZone, image.Filename)
-	req, _ := http.NewRequest("DELETE", deleteURL, nil)
-	req.Header.Set("AccessKey", bunnyKey)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil || resp.StatusCode > 299 {
-		s.Logger.Error("Bunny image delete failed", zap.Error(err))
-		return fmt.Errorf("Bunny image delete failed: %w", err)
-	}
 	if err := s.DB.Delete(&image).Error; err != nil {
 		s.Logger.Error("DB delete failed", zap.Error(err))
 		return err
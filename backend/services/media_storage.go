@@ -0,0 +1,54 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-backend/config"
+)
+
+// MediaStorage removes uploaded files from the CDN.
+type MediaStorage interface {
+	DeleteVideo(bunnyVideoID string) error
+	DeleteImage(filename string) error
+}
+
+// BunnyMediaStorage keeps videos in Bunny Stream and images in Bunny Storage.
+type BunnyMediaStorage struct{}
+
+func (BunnyMediaStorage) DeleteVideo(bunnyVideoID string) error {
+	bunnyAPI := config.AppConfig.BunnyStreamAPI
+	bunnyKey := config.AppConfig.BunnyStreamAPIKey
+	if bunnyAPI == "" || bunnyKey == "" {
+		return fmt.Errorf("Bunny Stream config missing")
+	}
+	return bunnyDelete(fmt.Sprintf("%s/library/videos/%s", bunnyAPI, bunnyVideoID), bunnyKey)
+}
+
+func (BunnyMediaStorage) DeleteImage(filename string) error {
+	bunnyZone := config.AppConfig.BunnyStorageZone
+	bunnyKey := config.AppConfig.BunnyStorageAPIKey
+	if bunnyZone == "" || bunnyKey == "" {
+		return fmt.Errorf("Bunny Storage config missing")
+	}
+	return bunnyDelete(fmt.Sprintf("https://sg.storage.bunnycdn.com/%s/%s", bunnyZone, filename), bunnyKey)
+}
+
+// bunnyDelete deletes the object at url. A missing object counts as deleted
+// so an interrupted purge can be retried.
+func bunnyDelete(url, accessKey string) error {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("AccessKey", accessKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("bunny delete failed: %s", resp.Status)
+	}
+	return nil
+}
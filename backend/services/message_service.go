@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/realtime"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMessageSelf            = errors.New("you cannot message yourself")
+	ErrNotCreatorConversation = errors.New("direct messages need a creator on one side")
+	ErrConversationBlocked    = errors.New("this conversation is blocked")
+	ErrEmptyMessage           = errors.New("a message needs text or an attachment")
+	ErrPaidMessageAttachments = errors.New("a paid message needs an attachment to unlock")
+	ErrMessageFree            = errors.New("message is not paid")
+	ErrMessageUnlocked        = errors.New("message is already unlocked")
+)
+
+// MessageService runs one-to-one conversations between fans and creators.
+// Conversations a user is not a member of are reported as not found.
+type MessageService struct {
+	Repo     repository.MessageRepository
+	Users    repository.UserRepository
+	Profiles repository.ModelProfileRepository
+	Blocks   repository.BlockRepository
+	Now      func() time.Time
+}
+
+func NewMessageService(repo repository.MessageRepository, users repository.UserRepository, profiles repository.ModelProfileRepository, blocks repository.BlockRepository) *MessageService {
+	return &MessageService{Repo: repo, Users: users, Profiles: profiles, Blocks: blocks, Now: time.Now}
+}
+
+// Open returns the conversation between user and otherID, starting it if
+// needed. One of them must have a model profile and neither may block the
+// other.
+func (s *MessageService) Open(user *models.User, otherID uuid.UUID) (*dto.ConversationResponseDTO, bool, error) {
+	if otherID == user.ID {
+		return nil, false, ErrMessageSelf
+	}
+	if _, err := s.Users.FindByID(otherID); err != nil {
+		return nil, false, err
+	}
+	if !s.isCreator(user.ID) && !s.isCreator(otherID) {
+		return nil, false, ErrNotCreatorConversation
+	}
+	if blocked, err := s.Blocks.Between(user.ID, otherID); err != nil || blocked {
+		if blocked {
+			err = ErrUserBlocked
+		}
+		return nil, false, err
+	}
+	conv, created, err := s.Repo.FindOrCreateConversation(user.ID, otherID)
+	if err != nil {
+		return nil, false, err
+	}
+	views, err := s.conversationViews(user.ID, []models.Conversation{*conv})
+	if err != nil {
+		return nil, false, err
+	}
+	return &views[0], created, nil
+}
+
+// Conversations lists the user's conversations, most recent activity first.
+func (s *MessageService) Conversations(userID uuid.UUID, limit, offset int) ([]dto.ConversationResponseDTO, error) {
+	convs, err := s.Repo.ListConversations(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.conversationViews(userID, convs)
+}
+
+// Messages lists a conversation's messages, newest first.
+func (s *MessageService) Messages(userID, conversationID uuid.UUID, limit, offset int) ([]dto.MessageResponseDTO, error) {
+	conv, me, other, err := s.membership(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.Repo.ListMessages(conv.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	unlocked, err := s.Repo.UnlockedIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.MessageResponseDTO, 0, len(messages))
+	for i := range messages {
+		resp = append(resp, messageView(&messages[i], me.UserID, other, unlocked[messages[i].ID]))
+	}
+	return resp, nil
+}
+
+// Send posts a message and pushes it to the recipient. Callers check that
+// senders of paid messages are verified creators.
+func (s *MessageService) Send(sender *models.User, conversationID uuid.UUID, input dto.MessageCreateDTO) (*dto.MessageResponseDTO, error) {
+	logger := logging.GetLogger()
+	_, me, other, err := s.membership(sender.ID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if me.BlockedAt != nil || other.BlockedAt != nil {
+		return nil, ErrConversationBlocked
+	}
+	if blocked, err := s.Blocks.Between(sender.ID, other.UserID); err != nil || blocked {
+		if blocked {
+			err = ErrUserBlocked
+		}
+		return nil, err
+	}
+	text := strings.TrimSpace(input.Text)
+	if text == "" && len(input.Attachments) == 0 {
+		return nil, ErrEmptyMessage
+	}
+	if input.Price > 0 && len(input.Attachments) == 0 {
+		return nil, ErrPaidMessageAttachments
+	}
+	msg := &models.Message{
+		ConversationID: conversationID,
+		SenderID:       sender.ID,
+		Text:           text,
+		Price:          input.Price,
+		CreatedAt:      s.Now(),
+	}
+	for _, a := range input.Attachments {
+		msg.Attachments = append(msg.Attachments, models.MessageAttachment{Type: a.Type, URL: a.URL})
+	}
+	if err := s.Repo.CreateMessage(msg); err != nil {
+		logger.Error("Send message failed", zap.String("conversation_id", conversationID.String()), zap.Error(err))
+		return nil, err
+	}
+	realtime.Publish(other.UserID, realtime.EventMessage, messageView(msg, other.UserID, me, false))
+	view := messageView(msg, sender.ID, other, false)
+	return &view, nil
+}
+
+// MarkRead records that the user has read the conversation up to now and
+// tells the other member.
+func (s *MessageService) MarkRead(userID, conversationID uuid.UUID) error {
+	_, me, other, err := s.membership(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	now := s.Now()
+	me.LastReadAt = &now
+	if err := s.Repo.UpdateMember(me); err != nil {
+		return err
+	}
+	realtime.Publish(other.UserID, realtime.EventMessageRead, map[string]interface{}{"conversation_id": conversationID, "read_at": now})
+	return nil
+}
+
+// SetBlocked blocks or unblocks the other member in this conversation only.
+// While either member blocks, neither can send. A user block has the same
+// effect on every conversation between the two.
+func (s *MessageService) SetBlocked(userID, conversationID uuid.UUID, blocked bool) error {
+	_, me, _, err := s.membership(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if blocked && me.BlockedAt == nil {
+		now := s.Now()
+		me.BlockedAt = &now
+	} else if !blocked {
+		me.BlockedAt = nil
+	}
+	return s.Repo.UpdateMember(me)
+}
+
+// Unlock charges the recipient for a paid message and credits the sender
+// like a post purchase. It returns the message with its attachments.
+func (s *MessageService) Unlock(user *models.User, messageID uuid.UUID) (*dto.MessageResponseDTO, error) {
+	logger := logging.GetLogger()
+	msg, err := s.Repo.FindMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	_, me, other, err := s.membership(user.ID, msg.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Price == 0 || msg.SenderID == user.ID {
+		return nil, ErrMessageFree
+	}
+	unlocked, err := s.Repo.UnlockedIDs(user.ID, []uuid.UUID{msg.ID})
+	if err != nil {
+		return nil, err
+	}
+	if unlocked[msg.ID] {
+		return nil, ErrMessageUnlocked
+	}
+	profile, err := s.Profiles.FindByUserID(msg.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	unlock := &models.MessageUnlock{ID: uuid.New(), MessageID: msg.ID, UserID: user.ID, Price: msg.Price, CreatedAt: now}
+	earning := NewEarning(msg.SenderID, profile.ID, models.EarningMessage, unlock.ID, nil, msg.Price, now)
+	if err := s.Repo.Unlock(unlock, earning); err != nil {
+		logger.Warn("Unlock message failed", zap.String("message_id", msg.ID.String()), zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Message unlocked", zap.String("message_id", msg.ID.String()), zap.String("user_id", user.ID.String()), zap.Int("price", msg.Price))
+	view := messageView(msg, me.UserID, other, true)
+	return &view, nil
+}
+
+// membership loads the conversation and both members, or
+// gorm.ErrRecordNotFound when userID is not a member.
+func (s *MessageService) membership(userID, conversationID uuid.UUID) (*models.Conversation, *models.ConversationMember, *models.ConversationMember, error) {
+	conv, err := s.Repo.FindConversation(conversationID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var me, other *models.ConversationMember
+	for i := range conv.Members {
+		if conv.Members[i].UserID == userID {
+			me = &conv.Members[i]
+		} else {
+			other = &conv.Members[i]
+		}
+	}
+	if me == nil || other == nil {
+		return nil, nil, nil, gorm.ErrRecordNotFound
+	}
+	return conv, me, other, nil
+}
+
+func (s *MessageService) isCreator(userID uuid.UUID) bool {
+	_, err := s.Profiles.FindByUserID(userID)
+	return err == nil
+}
+
+func (s *MessageService) conversationViews(userID uuid.UUID, convs []models.Conversation) ([]dto.ConversationResponseDTO, error) {
+	ids := make([]uuid.UUID, 0, len(convs))
+	for _, c := range convs {
+		ids = append(ids, c.ID)
+	}
+	last, err := s.Repo.LastMessages(ids)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.Repo.UnreadCounts(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	var lastIDs []uuid.UUID
+	for _, m := range last {
+		lastIDs = append(lastIDs, m.ID)
+	}
+	unlocked, err := s.Repo.UnlockedIDs(userID, lastIDs)
+	if err != nil {
+		return nil, err
+	}
+	blocked, blockedBy, err := s.Blocks.BlockSets(userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.ConversationResponseDTO, 0, len(convs))
+	for _, c := range convs {
+		var me, other *models.ConversationMember
+		for i := range c.Members {
+			if c.Members[i].UserID == userID {
+				me = &c.Members[i]
+			} else {
+				other = &c.Members[i]
+			}
+		}
+		if me == nil || other == nil {
+			continue
+		}
+		view := dto.ConversationResponseDTO{
+			ID:            c.ID,
+			With:          dto.ConversationUserDTO{ID: other.User.ID, Nickname: other.User.Nickname, AvatarURL: other.User.AvatarURL},
+			Unread:        unread[c.ID],
+			LastMessageAt: c.LastMessageAt,
+			Blocked:       me.BlockedAt != nil || blocked[other.UserID],
+			BlockedBy:     other.BlockedAt != nil || blockedBy[other.UserID],
+		}
+		if m, ok := last[c.ID]; ok {
+			lm := messageView(&m, userID, other, unlocked[m.ID])
+			view.LastMessage = &lm
+		}
+		resp = append(resp, view)
+	}
+	return resp, nil
+}
+
+// messageView renders m for viewerID; other is the viewer's counterpart.
+func messageView(m *models.Message, viewerID uuid.UUID, other *models.ConversationMember, unlocked bool) dto.MessageResponseDTO {
+	own := m.SenderID == viewerID
+	view := dto.MessageResponseDTO{
+		ID:              m.ID,
+		ConversationID:  m.ConversationID,
+		SenderID:        m.SenderID,
+		Text:            m.Text,
+		Price:           m.Price,
+		Locked:          m.Price > 0 && !own && !unlocked,
+		AttachmentCount: len(m.Attachments),
+		Attachments:     []dto.MessageAttachmentDTO{},
+		Read:            own && other.LastReadAt != nil && !other.LastReadAt.Before(m.CreatedAt),
+		CreatedAt:       m.CreatedAt,
+	}
+	if !view.Locked {
+		for _, a := range m.Attachments {
+			view.Attachments = append(view.Attachments, dto.MessageAttachmentDTO{Type: a.Type, URL: a.URL})
+		}
+	}
+	return view
+}
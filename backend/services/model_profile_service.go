@@ -6,6 +6,7 @@ import (
 	"go-backend/models"
 	"go-backend/repository"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -17,23 +18,17 @@ func NewModelProfileService(repo repository.ModelProfileRepository) *ModelProfil
 	return &ModelProfileService{Repo: repo}
 }
 
-func (s *ModelProfileService) GetModelProfiles(limit, offset int) ([]dto.ModelProfileResponseDTO, error) {
+func (s *ModelProfileService) GetModelProfiles(limit, offset int, hiddenUserIDs []uuid.UUID) ([]dto.ModelProfileResponseDTO, error) {
 	logger := logging.GetLogger()
 	logger.Debug("GetModelProfiles called", zap.Int("limit", limit), zap.Int("offset", offset))
-	profiles, err := s.Repo.FindAll(limit, offset)
+	profiles, err := s.Repo.FindAll(limit, offset, hiddenUserIDs)
 	if err != nil {
 		logger.Error("GetModelProfiles failed", zap.Error(err))
 		return nil, err
 	}
 	resp := make([]dto.ModelProfileResponseDTO, 0, len(profiles))
-	for _, p := range profiles {
-		resp = append(resp, dto.ModelProfileResponseDTO{
-			ID:     p.ID,
-			UserID: p.UserID,
-			Name:   p.Name,
-			Bio:    p.Bio,
-			Banner: p.Banner,
-		})
+	for i := range profiles {
+		resp = append(resp, ToModelProfileResponseDTO(&profiles[i]))
 	}
 	logger.Debug("GetModelProfiles success", zap.Int("count", len(resp)))
 	return resp, nil
@@ -57,13 +52,20 @@ func (s *ModelProfileService) CreateModelProfile(input *dto.ModelProfileCreateDT
 		logger.Error("CreateModelProfile failed", zap.Error(err))
 		return dto.ModelProfileResponseDTO{}, err
 	}
-	resp := dto.ModelProfileResponseDTO{
-		ID:     newProfile.ID,
-		UserID: newProfile.UserID,
-		Name:   newProfile.Name,
-		Bio:    newProfile.Bio,
-		Banner: newProfile.Banner,
-	}
+	resp := ToModelProfileResponseDTO(&newProfile)
 	logger.Debug("CreateModelProfile success", zap.String("user_id", newProfile.UserID.String()), zap.String("name", newProfile.Name))
 	return resp, nil
 }
+
+func ToModelProfileResponseDTO(p *models.ModelProfile) dto.ModelProfileResponseDTO {
+	return dto.ModelProfileResponseDTO{
+		ID:         p.ID,
+		UserID:     p.UserID,
+		Name:       p.Name,
+		Bio:        p.Bio,
+		Banner:     p.Banner,
+		Verified:   p.Verified(),
+		VerifiedAt: p.VerifiedAt,
+		HiddenAt:   p.HiddenAt,
+	}
+}
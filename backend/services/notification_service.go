@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/realtime"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrNotificationType = errors.New("unknown notification type")
+
+// NotificationEvent is something Actor did that UserID should hear about.
+// SubjectID is the post it concerns, if any.
+type NotificationEvent struct {
+	Type      string
+	UserID    uuid.UUID
+	Actor     *models.User
+	SubjectID *uuid.UUID
+}
+
+// NotificationService produces notifications and serves the inbox. Events of
+// one type about the same subject are merged into the recipient's unread
+// notification for BatchWindow, so a popular post yields "12 people liked
+// your post" instead of twelve entries.
+type NotificationService struct {
+	Repo        repository.NotificationRepository
+	Blocks      repository.BlockRepository
+	BatchWindow time.Duration
+	Now         func() time.Time
+}
+
+func NewNotificationService(repo repository.NotificationRepository, blocks repository.BlockRepository) *NotificationService {
+	return &NotificationService{
+		Repo:        repo,
+		Blocks:      blocks,
+		BatchWindow: config.AppConfig.NotificationBatchWindow,
+		Now:         time.Now,
+	}
+}
+
+// Notify records the event unless the actor is the recipient, the recipient
+// turned the type off, or blocks or mutes rule out contact between them, and
+// pushes it to the recipient's open event streams.
+func (s *NotificationService) Notify(e NotificationEvent) error {
+	logger := logging.GetLogger()
+	if e.UserID == e.Actor.ID {
+		return nil
+	}
+	hidden, err := s.Blocks.Hides(e.UserID, e.Actor.ID)
+	if err != nil || hidden {
+		return err
+	}
+	enabled, err := s.Repo.Enabled(e.UserID, e.Type)
+	if err != nil || !enabled {
+		return err
+	}
+	now := s.Now()
+	actorID := e.Actor.ID
+	n := &models.Notification{
+		UserID:     e.UserID,
+		Type:       e.Type,
+		SubjectID:  e.SubjectID,
+		ActorID:    &actorID,
+		ActorCount: 1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.Repo.Merge(n, now.Add(-s.BatchWindow)); err != nil {
+		logger.Error("Notify failed", zap.String("type", e.Type), zap.String("user_id", e.UserID.String()), zap.Error(err))
+		return err
+	}
+	n.Actor = e.Actor
+	realtime.Publish(n.UserID, realtime.EventNotification, notificationDTO(n))
+	return nil
+}
+
+// Warn tells the author of subjectID that moderation found it broke the
+// rules. Warnings have no actor, so moderators stay anonymous.
+func (s *NotificationService) Warn(userID, subjectID uuid.UUID) error {
+	return s.system(userID, models.NotifyWarning, subjectID)
+}
+
+// ExportReady tells the user their data export can be downloaded.
+func (s *NotificationService) ExportReady(userID, exportID uuid.UUID) error {
+	return s.system(userID, models.NotifyDataExport, exportID)
+}
+
+// system records a notification from the platform itself: it has no actor,
+// is never batched and cannot be turned off.
+func (s *NotificationService) system(userID uuid.UUID, typ string, subjectID uuid.UUID) error {
+	now := s.Now()
+	n := &models.Notification{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Type:       typ,
+		SubjectID:  &subjectID,
+		ActorCount: 1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.Repo.CreateMany([]models.Notification{*n}); err != nil {
+		logging.GetLogger().Error("System notification failed", zap.String("type", typ), zap.String("user_id", userID.String()), zap.Error(err))
+		return err
+	}
+	realtime.Publish(userID, realtime.EventNotification, notificationDTO(n))
+	return nil
+}
+
+// NotifyFollowers tells the followers of author about a new post.
+func (s *NotificationService) NotifyFollowers(author *models.User, postID uuid.UUID) error {
+	logger := logging.GetLogger()
+	authorID := author.ID
+	followers, err := s.Repo.FollowersToNotify(authorID, models.NotifyNewPost)
+	if err != nil {
+		return err
+	}
+	now := s.Now()
+	notifications := make([]models.Notification, 0, len(followers))
+	for _, id := range followers {
+		if id == authorID {
+			continue
+		}
+		subject, actor := postID, authorID
+		notifications = append(notifications, models.Notification{
+			UserID:     id,
+			Type:       models.NotifyNewPost,
+			SubjectID:  &subject,
+			ActorID:    &actor,
+			ActorCount: 1,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+	if err := s.Repo.CreateMany(notifications); err != nil {
+		logger.Error("Notify followers failed", zap.String("post_id", postID.String()), zap.Int("followers", len(notifications)), zap.Error(err))
+		return err
+	}
+	for i := range notifications {
+		notifications[i].Actor = author
+		realtime.Publish(notifications[i].UserID, realtime.EventNotification, notificationDTO(&notifications[i]))
+	}
+	return nil
+}
+
+func (s *NotificationService) Inbox(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]dto.NotificationResponseDTO, error) {
+	notifications, err := s.Repo.List(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]dto.NotificationResponseDTO, 0, len(notifications))
+	for i := range notifications {
+		resp = append(resp, notificationDTO(&notifications[i]))
+	}
+	return resp, nil
+}
+
+func notificationDTO(n *models.Notification) dto.NotificationResponseDTO {
+	item := dto.NotificationResponseDTO{
+		ID:         n.ID,
+		Type:       n.Type,
+		Message:    notificationMessage(n),
+		SubjectID:  n.SubjectID,
+		ActorCount: n.ActorCount,
+		Read:       n.ReadAt != nil,
+		CreatedAt:  n.CreatedAt,
+		UpdatedAt:  n.UpdatedAt,
+	}
+	if n.Actor != nil {
+		item.Actor = &dto.NotificationActorDTO{ID: n.Actor.ID, Nickname: n.Actor.Nickname, AvatarURL: n.Actor.AvatarURL}
+	}
+	return item
+}
+
+func (s *NotificationService) UnreadCount(userID uuid.UUID) (int64, error) {
+	return s.Repo.CountUnread(userID)
+}
+
+func (s *NotificationService) MarkRead(userID, id uuid.UUID) error {
+	return s.Repo.MarkRead(userID, id, s.Now())
+}
+
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	return s.Repo.MarkAllRead(userID, s.Now())
+}
+
+// Preferences returns every notification type with whether it is enabled.
+func (s *NotificationService) Preferences(userID uuid.UUID) (dto.NotificationPreferencesDTO, error) {
+	prefs, err := s.Repo.Preferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.NotificationPreferencesDTO{}
+	for _, t := range models.NotificationTypes {
+		resp[t] = true
+	}
+	for _, p := range prefs {
+		resp[p.Type] = p.Enabled
+	}
+	return resp, nil
+}
+
+// UpdatePreferences changes the given types and returns all preferences.
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, changes dto.NotificationPreferencesDTO) (dto.NotificationPreferencesDTO, error) {
+	prefs := make([]models.NotificationPreference, 0, len(changes))
+	for t, enabled := range changes {
+		if !isNotificationType(t) {
+			return nil, fmt.Errorf("%w: %s", ErrNotificationType, t)
+		}
+		prefs = append(prefs, models.NotificationPreference{UserID: userID, Type: t, Enabled: enabled})
+	}
+	if err := s.Repo.SavePreferences(prefs); err != nil {
+		return nil, err
+	}
+	return s.Preferences(userID)
+}
+
+func isNotificationType(t string) bool {
+	for _, known := range models.NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationMessage renders the inbox text, naming the latest actor or
+// counting everyone batched into the notification.
+func notificationMessage(n *models.Notification) string {
+	actor := "Someone"
+	if n.Actor != nil && n.Actor.Nickname != "" {
+		actor = n.Actor.Nickname
+	}
+	if n.ActorCount > 1 {
+		actor = fmt.Sprintf("%d people", n.ActorCount)
+	}
+	switch n.Type {
+	case models.NotifyFollow:
+		return actor + " started following you"
+	case models.NotifyLike:
+		return actor + " liked your post"
+	case models.NotifyComment:
+		return actor + " commented on your post"
+	case models.NotifyPurchase:
+		return actor + " bought your post"
+	case models.NotifyNewPost:
+		return actor + " published a new post"
+	case models.NotifyWarning:
+		return "A moderator warned you about your content"
+	case models.NotifyDataExport:
+		return "Your data export is ready to download"
+	case models.NotifyTip:
+		if n.SubjectID != nil {
+			return actor + " tipped you for your post"
+		}
+		return actor + " sent you a tip"
+	default:
+		return actor + " interacted with you"
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/seed"
+
+	"github.com/google/uuid"
+)
+
+// Maintenance operations staff can run through /admin/ops and operators
+// from the command line.
+const (
+	OpMigrate = "migrate"
+	OpSeed    = "seed"
+)
+
+// OpsConfirmationTTL is how long a confirmation token can be used.
+const OpsConfirmationTTL = 5 * time.Minute
+
+var (
+	ErrUnknownOperation  = errors.New("unknown operation")
+	ErrOperationDisabled = errors.New("destructive operations are disabled")
+	ErrOpsConfirmation   = errors.New("invalid, expired or used confirmation token")
+)
+
+// Operation is a maintenance task. Destructive operations wipe data and only
+// run where ALLOW_DESTRUCTIVE_OPS is set.
+type Operation struct {
+	Name        string
+	Destructive bool
+	Run         func() error
+}
+
+// OpsConfirmation lets the actor it was issued to run the operation once
+// until ExpiresAt.
+type OpsConfirmation struct {
+	Operation string    `json:"operation"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OpsService runs maintenance operations. Every run needs a confirmation
+// token that is bound to the actor and the operation, so a stray or forged
+// request cannot trigger one. The token's nonce is recorded when it runs,
+// so a replayed request cannot trigger a second run.
+type OpsService struct {
+	Operations       map[string]Operation
+	Used             repository.OpsConfirmationRepository
+	AllowDestructive bool
+	Secret           []byte
+	Now              func() time.Time
+}
+
+func NewOpsService(used repository.OpsConfirmationRepository) *OpsService {
+	return &OpsService{
+		Operations: map[string]Operation{
+			OpMigrate: {Name: OpMigrate, Run: database.MigrateUp},
+			OpSeed:    {Name: OpSeed, Destructive: true, Run: seed.SeedData},
+		},
+		Used:             used,
+		AllowDestructive: config.AppConfig.AllowDestructiveOps,
+		Secret:           []byte(config.AppConfig.JWTSecret),
+		Now:              time.Now,
+	}
+}
+
+// Lookup returns the named operation if it may run here.
+func (s *OpsService) Lookup(name string) (Operation, error) {
+	op, ok := s.Operations[name]
+	if !ok {
+		return Operation{}, ErrUnknownOperation
+	}
+	if op.Destructive && !s.AllowDestructive {
+		return Operation{}, ErrOperationDisabled
+	}
+	return op, nil
+}
+
+// Confirm issues a confirmation token for the actor to run the operation.
+func (s *OpsService) Confirm(actorID uuid.UUID, name string) (*OpsConfirmation, error) {
+	if _, err := s.Lookup(name); err != nil {
+		return nil, err
+	}
+	expires := s.Now().Add(OpsConfirmationTTL).Truncate(time.Second)
+	nonce := uuid.New()
+	return &OpsConfirmation{
+		Operation: name,
+		Token:     fmt.Sprintf("%d.%s.%s", expires.Unix(), nonce, s.sign(actorID, name, nonce, expires.Unix())),
+		ExpiresAt: expires,
+	}, nil
+}
+
+// Run runs the operation for the actor after checking its confirmation token
+// and using it up. A token is used up even when the operation fails.
+func (s *OpsService) Run(actorID uuid.UUID, name, token string) error {
+	op, err := s.Lookup(name)
+	if err != nil {
+		return err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrOpsConfirmation
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrOpsConfirmation
+	}
+	nonce, err := uuid.Parse(parts[1])
+	if err != nil || !hmac.Equal([]byte(parts[2]), []byte(s.sign(actorID, name, nonce, expires))) || !s.Now().Before(time.Unix(expires, 0)) {
+		return ErrOpsConfirmation
+	}
+	used, err := s.Used.Use(&models.UsedOpsConfirmation{
+		ID:        nonce,
+		ActorID:   actorID,
+		Operation: name,
+		ExpiresAt: time.Unix(expires, 0),
+	})
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrOpsConfirmation
+	}
+	return op.Run()
+}
+
+func (s *OpsService) sign(actorID uuid.UUID, name string, nonce uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "ops|%s|%s|%s|%d", name, actorID, nonce, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
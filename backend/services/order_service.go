@@ -6,6 +6,7 @@ import (
 	"go-backend/models"
 	"go-backend/repository"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -17,10 +18,11 @@ func NewOrderService(repo repository.OrderRepository) *OrderService {
 	return &OrderService{Repo: repo}
 }
 
-func (s *OrderService) GetOrders(limit, offset int) ([]dto.OrderResponseDTO, error) {
+// GetOrders lists the orders of userID, or of everyone when it is nil.
+func (s *OrderService) GetOrders(userID *uuid.UUID, limit, offset int) ([]dto.OrderResponseDTO, error) {
 	logger := logging.GetLogger()
 	logger.Debug("GetOrders called", zap.Int("limit", limit), zap.Int("offset", offset))
-	orders, err := s.Repo.FindAll(limit, offset)
+	orders, err := s.Repo.FindAll(userID, limit, offset)
 	if err != nil {
 		logger.Error("GetOrders failed", zap.Error(err))
 		return nil, err
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go-backend/models"
+)
+
+// Names of the payout providers selected by PAYOUT_PROVIDER.
+const (
+	PayoutProviderManual = "manual"
+	PayoutProviderPlisio = "plisio"
+)
+
+// ErrPayoutDeclined wraps the error of a provider that refused a transfer,
+// so no money left. Any other error of Send leaves the outcome open.
+var ErrPayoutDeclined = errors.New("the payment provider declined the transfer")
+
+// PayoutProvider moves an approved payout to the creator's address.
+type PayoutProvider interface {
+	Name() string
+	// Send transfers the payout and returns the provider's reference for it.
+	// The manual provider returns ref unchanged: staff made the transfer and
+	// entered its transaction hash. Errors wrap ErrPayoutDeclined only when
+	// the provider certainly did not send the money.
+	Send(ctx context.Context, payout *models.Payout, ref string) (string, error)
+}
+
+// NewPayoutProvider returns the provider with the given name.
+func NewPayoutProvider(name string) (PayoutProvider, error) {
+	switch name {
+	case PayoutProviderManual, "":
+		return ManualPayoutProvider{}, nil
+	case PayoutProviderPlisio:
+		return &PlisioPayoutProvider{Client: NewPlisioClient()}, nil
+	default:
+		return nil, fmt.Errorf("unknown PAYOUT_PROVIDER %q", name)
+	}
+}
+
+// ManualPayoutProvider is used when staff pay out by hand.
+type ManualPayoutProvider struct{}
+
+func (ManualPayoutProvider) Name() string { return PayoutProviderManual }
+
+func (ManualPayoutProvider) Send(_ context.Context, _ *models.Payout, ref string) (string, error) {
+	return ref, nil
+}
+
+// PlisioPayoutProvider withdraws from the Plisio shop balance.
+type PlisioPayoutProvider struct {
+	Client *PlisioClient
+}
+
+func (p *PlisioPayoutProvider) Name() string { return PayoutProviderPlisio }
+
+func (p *PlisioPayoutProvider) Send(ctx context.Context, payout *models.Payout, _ string) (string, error) {
+	return p.Client.Withdraw(ctx, payout.Currency, payout.Address, strconv.Itoa(payout.Amount))
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPayoutBelowMinimum   = errors.New("amount is below the minimum payout")
+	ErrPayoutCurrency       = errors.New("unsupported payout currency")
+	ErrPayoutReviewed       = errors.New("payout has already been reviewed")
+	ErrPayoutRefRequired    = errors.New("the transaction reference of the manual transfer is required")
+	ErrPayoutReasonRequired = errors.New("a reason is required to reject a payout")
+)
+
+// PayoutBalance summarizes what a creator earned and can withdraw.
+type PayoutBalance struct {
+	Held          int      `json:"held"`      // still in the holding period
+	Available     int      `json:"available"` // can be withdrawn now
+	Reserved      int      `json:"reserved"`  // requested, not paid yet
+	PaidOut       int      `json:"paid_out"`
+	MinimumPayout int      `json:"minimum_payout"`
+	FeePercent    int      `json:"fee_percent"`
+	HoldDays      int      `json:"hold_days"`
+	Currencies    []string `json:"currencies"`
+}
+
+// PayoutRequest is a creator's withdrawal request.
+type PayoutRequest struct {
+	Amount   int
+	Currency string
+	Address  string
+}
+
+// PayoutService keeps the creator earnings ledger and runs withdrawals:
+// creators request a payout from their available balance, staff approve it
+// (sending it through Provider) or reject it.
+type PayoutService struct {
+	Repo       repository.PayoutRepository
+	Provider   PayoutProvider
+	FeePercent int
+	Hold       time.Duration
+	MinAmount  int
+	Currencies []string
+	Now        func() time.Time
+}
+
+func NewPayoutService(repo repository.PayoutRepository, provider PayoutProvider) *PayoutService {
+	cfg := config.AppConfig
+	return &PayoutService{
+		Repo:       repo,
+		Provider:   provider,
+		FeePercent: cfg.PlatformFeePercent,
+		Hold:       cfg.PayoutHoldPeriod,
+		MinAmount:  cfg.MinPayoutAmount,
+		Currencies: cfg.PayoutCurrencies,
+		Now:        time.Now,
+	}
+}
+
+// NewEarning splits a sale into the platform fee and the creator's share.
+// The fee is rounded down in the platform's disfavour.
+func NewEarning(creatorID, modelID uuid.UUID, source string, sourceID uuid.UUID, postID *uuid.UUID, gross int, now time.Time) *models.Earning {
+	cfg := config.AppConfig
+	fee := gross * cfg.PlatformFeePercent / 100
+	return &models.Earning{
+		CreatorID:   creatorID,
+		ModelID:     modelID,
+		Source:      source,
+		SourceID:    sourceID,
+		PostID:      postID,
+		Gross:       gross,
+		Fee:         fee,
+		Net:         gross - fee,
+		AvailableAt: now.Add(cfg.PayoutHoldPeriod),
+	}
+}
+
+func (s *PayoutService) Balance(userID uuid.UUID) (*PayoutBalance, error) {
+	t, err := s.Repo.Totals(userID, s.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &PayoutBalance{
+		Held:          t.Held,
+		Available:     t.Available,
+		Reserved:      t.Reserved,
+		PaidOut:       t.PaidOut,
+		MinimumPayout: s.MinAmount,
+		FeePercent:    s.FeePercent,
+		HoldDays:      int(s.Hold / (24 * time.Hour)),
+		Currencies:    s.Currencies,
+	}, nil
+}
+
+func (s *PayoutService) Earnings(userID uuid.UUID, limit, offset int) ([]models.Earning, error) {
+	return s.Repo.ListEarnings(userID, limit, offset)
+}
+
+// History lists a creator's payouts, newest first.
+func (s *PayoutService) History(userID uuid.UUID, limit, offset int) ([]models.Payout, error) {
+	return s.Repo.ListPayouts(repository.PayoutFilter{UserID: &userID, Limit: limit, Offset: offset})
+}
+
+// Queue lists payouts in the given status; pending ones oldest first.
+func (s *PayoutService) Queue(status string, limit, offset int) ([]models.Payout, error) {
+	return s.Repo.ListPayouts(repository.PayoutFilter{Status: status, Limit: limit, Offset: offset})
+}
+
+// Request reserves amount from the user's available balance for review.
+// Callers check that the user is a verified creator.
+func (s *PayoutService) Request(user *models.User, req PayoutRequest) (*models.Payout, error) {
+	logger := logging.GetLogger()
+	if req.Amount < s.MinAmount {
+		return nil, ErrPayoutBelowMinimum
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !s.supports(currency) {
+		return nil, ErrPayoutCurrency
+	}
+	payout := &models.Payout{
+		UserID:   user.ID,
+		Amount:   req.Amount,
+		Currency: currency,
+		Address:  strings.TrimSpace(req.Address),
+		Status:   models.PayoutPending,
+		Provider: s.Provider.Name(),
+	}
+	if err := s.Repo.CreatePayout(payout, s.Now()); err != nil {
+		logger.Warn("Payout request failed", zap.String("user_id", user.ID.String()), zap.Int("amount", req.Amount), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Payout requested", zap.String("payout_id", payout.ID.String()), zap.String("user_id", user.ID.String()), zap.Int("amount", payout.Amount))
+	return payout, nil
+}
+
+// Approve sends the payout through the provider and marks it paid. For
+// manual payouts ref is the transaction hash of the transfer staff made. The
+// payout is claimed (processing) before the transfer so it cannot be sent
+// twice; a provider failure marks it failed, releasing the reserved amount.
+func (s *PayoutService) Approve(ctx context.Context, reviewer *models.User, id uuid.UUID, ref string) (*models.Payout, error) {
+	logger := logging.GetLogger()
+	ref = strings.TrimSpace(ref)
+	if s.Provider.Name() == PayoutProviderManual && ref == "" {
+		return nil, ErrPayoutRefRequired
+	}
+	payout, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	s.review(payout, reviewer, models.PayoutProcessing, "")
+	payout.Provider = s.Provider.Name()
+	if err := s.save(payout, models.PayoutPending); err != nil {
+		return nil, err
+	}
+
+	sent, sendErr := s.Provider.Send(ctx, payout, ref)
+	switch {
+	case sendErr == nil:
+		payout.Status = models.PayoutPaid
+		payout.ProviderRef = sent
+	case errors.Is(sendErr, ErrPayoutDeclined):
+		// The reason is shown to the creator; the provider's answer is only
+		// logged.
+		payout.Status = models.PayoutFailed
+		payout.Reason = ErrPayoutDeclined.Error()
+	default:
+		// The transfer may have gone out. The payout stays processing with
+		// its amount reserved until staff check it with the provider.
+		logger.Error("Payout transfer outcome unknown", zap.String("payout_id", id.String()), zap.String("provider", payout.Provider), zap.Error(sendErr))
+		return payout, nil
+	}
+	if err := s.save(payout, models.PayoutProcessing); err != nil {
+		// the transfer may have gone out; the payout stays processing and
+		// keeps its amount reserved until staff resolve it
+		logger.Error("Save payout result failed", zap.String("payout_id", id.String()), zap.String("status", payout.Status), zap.String("provider_ref", sent), zap.Error(err))
+		return nil, err
+	}
+	if sendErr != nil {
+		logger.Error("Payout transfer failed", zap.String("payout_id", id.String()), zap.String("provider", payout.Provider), zap.Error(sendErr))
+		return payout, nil
+	}
+	logger.Info("Payout paid", zap.String("payout_id", id.String()), zap.String("user_id", payout.UserID.String()), zap.Int("amount", payout.Amount), zap.Stringp("reviewer_id", uuidString(payout.ReviewerID)))
+	return payout, nil
+}
+
+// Reject declines the payout and releases the reserved amount.
+func (s *PayoutService) Reject(reviewer *models.User, id uuid.UUID, reason string) (*models.Payout, error) {
+	logger := logging.GetLogger()
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrPayoutReasonRequired
+	}
+	payout, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	s.review(payout, reviewer, models.PayoutRejected, reason)
+	if err := s.save(payout, models.PayoutPending); err != nil {
+		logger.Error("Reject payout failed", zap.String("payout_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Payout rejected", zap.String("payout_id", id.String()), zap.String("user_id", payout.UserID.String()), zap.Stringp("reviewer_id", uuidString(payout.ReviewerID)))
+	return payout, nil
+}
+
+func (s *PayoutService) pending(id uuid.UUID) (*models.Payout, error) {
+	payout, err := s.Repo.FindPayout(id)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status != models.PayoutPending {
+		return nil, ErrPayoutReviewed
+	}
+	return payout, nil
+}
+
+// save moves the payout out of state from, reporting a concurrent review
+// as ErrPayoutReviewed.
+func (s *PayoutService) save(payout *models.Payout, from string) error {
+	err := s.Repo.UpdatePayout(payout, from)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPayoutReviewed
+	}
+	return err
+}
+
+func (s *PayoutService) review(payout *models.Payout, reviewer *models.User, status, reason string) {
+	now := s.Now()
+	payout.Status = status
+	payout.ReviewerID = actorIDOf(reviewer)
+	payout.Reason = strings.TrimSpace(reason)
+	payout.ReviewedAt = &now
+}
+
+func (s *PayoutService) supports(currency string) bool {
+	for _, c := range s.Currencies {
+		if strings.EqualFold(strings.TrimSpace(c), currency) {
+			return true
+		}
+	}
+	return false
+}
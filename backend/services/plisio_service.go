@@ -2,14 +2,18 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
+	"time"
 )
 
 type InvoiceRequest struct {
@@ -29,22 +33,48 @@ type InvoiceResponse struct {
 	} `json:"data"`
 }
 
+// plisioTimeout bounds every request to Plisio.
+const plisioTimeout = 30 * time.Second
+
+// PlisioClient calls the Plisio API. The API key travels in the query
+// string, so errors never carry the request URL.
 type PlisioClient struct {
-	ApiKey string
+	ApiKey  string
+	BaseURL string
+	HTTP    *http.Client
 }
 
 func NewPlisioClient() *PlisioClient {
 	return &PlisioClient{
-		ApiKey: os.Getenv("PLISIO_API_KEY"),
+		ApiKey:  os.Getenv("PLISIO_API_KEY"),
+		BaseURL: "https://plisio.net/api/v1",
+		HTTP:    &http.Client{Timeout: plisioTimeout},
 	}
 }
 
-func (pc *PlisioClient) CreateInvoice(req InvoiceRequest) (*InvoiceResponse, error) {
-	url := fmt.Sprintf("https://plisio.net/api/v1/invoices?api_key=%s", pc.ApiKey)
+// do sends the request with the API key added to q.
+func (pc *PlisioClient) do(ctx context.Context, method, path string, q url.Values, body []byte) (*http.Response, error) {
+	q.Set("api_key", pc.ApiKey)
+	req, err := http.NewRequestWithContext(ctx, method, pc.BaseURL+path+"?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("plisio %s: bad request", path)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := pc.HTTP.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// *url.Error prints the URL and with it the key
+		return nil, fmt.Errorf("plisio %s: %w", path, urlErr.Err)
+	}
+	return resp, err
+}
 
+func (pc *PlisioClient) CreateInvoice(req InvoiceRequest) (*InvoiceResponse, error) {
 	body, _ := json.Marshal(req)
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := pc.do(context.Background(), http.MethodPost, "/invoices", url.Values{}, body)
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +92,48 @@ func (pc *PlisioClient) CreateInvoice(req InvoiceRequest) (*InvoiceResponse, err
 	return &invoiceResp, nil
 }
 
+type WithdrawResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+// Withdraw sends amount of currency from the shop balance to address.
+// It returns the Plisio operation ID. Only an error answer from Plisio
+// wraps ErrPayoutDeclined; after a transport error, a server error or an
+// answer that cannot be read the money may have been sent.
+func (pc *PlisioClient) Withdraw(ctx context.Context, currency, address, amount string) (string, error) {
+	q := url.Values{}
+	q.Set("currency", currency)
+	q.Set("type", "cash_out")
+	q.Set("to", address)
+	q.Set("amount", amount)
+
+	resp, err := pc.do(ctx, http.MethodGet, "/operations/withdraw", q, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("plisio withdraw: status %d", resp.StatusCode)
+	}
+
+	var withdrawResp WithdrawResponse
+	if err := json.NewDecoder(resp.Body).Decode(&withdrawResp); err != nil {
+		return "", fmt.Errorf("plisio withdraw: %w", err)
+	}
+
+	switch withdrawResp.Status {
+	case "success":
+		return withdrawResp.Data.ID, nil
+	case "error":
+		return "", fmt.Errorf("%w: %s", ErrPayoutDeclined, withdrawResp.Data.Message)
+	}
+	return "", fmt.Errorf("plisio withdraw: unexpected status %q", withdrawResp.Status)
+}
+
 func ValidatePlisioSignature(params map[string]string) bool {
 	secretKey := os.Getenv("PLISIO_SECRET_KEY")
 	if secretKey == "" {
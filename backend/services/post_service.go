@@ -7,6 +7,7 @@ import (
 	"go-backend/repository"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -18,10 +19,10 @@ func NewPostService(repo repository.PostRepository) *PostService {
 	return &PostService{Repo: repo}
 }
 
-func (s *PostService) GetPosts(limit, offset int) ([]dto.PostResponseDTO, error) {
+func (s *PostService) GetPosts(limit, offset int, hiddenUserIDs []uuid.UUID) ([]dto.PostResponseDTO, error) {
 	logger := logging.GetLogger()
 	logger.Debug("GetPosts called", zap.Int("limit", limit), zap.Int("offset", offset))
-	posts, err := s.Repo.FindAll(limit, offset)
+	posts, err := s.Repo.FindAll(limit, offset, hiddenUserIDs)
 	if err != nil {
 		logger.Error("GetPosts failed", zap.Error(err))
 		return nil, err
@@ -49,6 +50,7 @@ func (s *PostService) CreatePost(input *dto.PostCreateDTO) (dto.PostResponseDTO,
 	post := models.Post{
 		Text:      input.Text,
 		IsPremium: input.IsPremium,
+		Price:     input.Price,
 		UserID:    input.UserID,
 		ModelID:   input.ModelID,
 	}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"errors"
+	"time"
+
 	"go-backend/dto"
 	"go-backend/logging"
 	"go-backend/models"
@@ -10,6 +13,11 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	ErrNotPremium       = errors.New("post is not premium")
+	ErrAlreadyPurchased = errors.New("post is already purchased")
+)
+
 type PurchaseService struct {
 	Repo     repository.PurchaseRepository
 	PostRepo repository.PostRepository
@@ -50,27 +58,28 @@ func (s *PurchaseService) BuyContent(user *models.User, input *dto.PurchaseCreat
 	}
 	if !post.IsPremium {
 		logger.Error("BuyContent not premium", zap.String("post_id", input.PostID.String()))
-		return dto.PurchaseResponseDTO{}, err // Changed from gorm.ErrInvalidTransaction to err
+		return dto.PurchaseResponseDTO{}, ErrNotPremium
 	}
 	_, err = s.Repo.FindByUserAndPost(user.ID, input.PostID)
 	if err == nil {
 		logger.Error("BuyContent already purchased", zap.String("user_id", user.ID.String()), zap.String("post_id", input.PostID.String()))
-		return dto.PurchaseResponseDTO{}, err // Changed from gorm.ErrInvalidTransaction to err
+		return dto.PurchaseResponseDTO{}, ErrAlreadyPurchased
 	}
-	if user.Balance < post.Price {
-		logger.Error("BuyContent insufficient balance", zap.String("user_id", user.ID.String()), zap.Int("balance", user.Balance), zap.Int("price", post.Price))
-		return dto.PurchaseResponseDTO{}, err // Changed from gorm.ErrInvalidTransaction to err
-	}
-	err = s.Repo.Create(&models.Purchase{
+	// The buyer is charged and the creator credited in one transaction.
+	now := time.Now()
+	purchase := &models.Purchase{
+		ID:        uuid.New(),
 		UserID:    user.ID,
 		PostID:    post.ID,
 		Completed: true,
-	})
-	if err != nil {
-		logger.Error("BuyContent create failed", zap.Error(err))
+		Price:     post.Price,
+		CreatedAt: now,
+	}
+	earning := NewEarning(post.UserID, post.ModelID, models.EarningPurchase, purchase.ID, &post.ID, post.Price, now)
+	if err := s.Repo.Buy(purchase, earning); err != nil {
+		logger.Error("BuyContent failed", zap.String("user_id", user.ID.String()), zap.Int("balance", user.Balance), zap.Int("price", post.Price), zap.Error(err))
 		return dto.PurchaseResponseDTO{}, err
 	}
-	purchase, _ := s.Repo.FindByUserAndPost(user.ID, input.PostID)
 	resp := dto.PurchaseResponseDTO{
 		ID:        purchase.ID,
 		UserID:    purchase.UserID,
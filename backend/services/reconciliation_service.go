@@ -0,0 +1,64 @@
+package services
+
+import (
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"go.uber.org/zap"
+)
+
+// ReconciliationReport lists where wallets and the earnings ledger disagree
+// with the sales they come from. Backfilled counts the unrecorded sales that
+// were added to the ledger.
+type ReconciliationReport struct {
+	UnrecordedSales  []repository.Sale             `json:"unrecorded_sales"`
+	Overdrawn        []repository.OverdrawnCreator `json:"overdrawn"`
+	NegativeBalances []repository.NegativeBalance  `json:"negative_balances"`
+	Backfilled       int                           `json:"backfilled"`
+}
+
+// Clean reports whether nothing is left to look into.
+func (r *ReconciliationReport) Clean() bool {
+	return len(r.UnrecordedSales) == r.Backfilled && len(r.Overdrawn) == 0 && len(r.NegativeBalances) == 0
+}
+
+// ReconciliationService checks balances against the sales behind them.
+type ReconciliationService struct {
+	Repo repository.ReconciliationRepository
+}
+
+func NewReconciliationService(repo repository.ReconciliationRepository) *ReconciliationService {
+	return &ReconciliationService{Repo: repo}
+}
+
+// Reconcile finds sales missing from the earnings ledger, creators who were
+// paid more than they earned and negative wallets. With fix, missing
+// earnings are recorded as of the sale, at the current platform fee; the
+// other findings need a person to look at them.
+func (s *ReconciliationService) Reconcile(fix bool) (*ReconciliationReport, error) {
+	logger := logging.GetLogger()
+	var report ReconciliationReport
+	var err error
+	if report.UnrecordedSales, err = s.Repo.UnrecordedSales(); err != nil {
+		return nil, err
+	}
+	if fix && len(report.UnrecordedSales) > 0 {
+		earnings := make([]*models.Earning, 0, len(report.UnrecordedSales))
+		for _, sale := range report.UnrecordedSales {
+			earnings = append(earnings, NewEarning(sale.CreatorID, sale.ModelID, sale.Source, sale.SourceID, sale.PostID, sale.Gross, sale.CreatedAt))
+		}
+		if err := s.Repo.AddEarnings(earnings); err != nil {
+			return nil, err
+		}
+		report.Backfilled = len(earnings)
+		logger.Info("Unrecorded sales added to the earnings ledger", zap.Int("count", report.Backfilled))
+	}
+	if report.Overdrawn, err = s.Repo.Overdrawn(); err != nil {
+		return nil, err
+	}
+	if report.NegativeBalances, err = s.Repo.NegativeBalances(); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
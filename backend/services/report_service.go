@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportTarget       = errors.New("unknown report target type")
+	ErrReportReason       = errors.New("unknown report reason")
+	ErrReportOwnContent   = errors.New("you cannot report your own content")
+	ErrCaseResolved       = errors.New("case is already resolved")
+	ErrCaseClaimed        = errors.New("case is claimed by another moderator")
+	ErrModerationAction   = errors.New("unknown moderation action")
+	ErrSuspensionDuration = errors.New("a suspension needs a duration of at least one day")
+)
+
+// ReportService takes user reports and runs the moderation queue. Reports
+// about the same content pile up in one case, which hides the content on
+// reaching HideThreshold reports until a moderator resolves it.
+type ReportService struct {
+	Repo          repository.ReportRepository
+	Messages      repository.MessageRepository
+	HideThreshold int
+	Now           func() time.Time
+}
+
+func NewReportService(repo repository.ReportRepository, messages repository.MessageRepository) *ReportService {
+	return &ReportService{
+		Repo:          repo,
+		Messages:      messages,
+		HideThreshold: config.AppConfig.ReportHideThreshold,
+		Now:           time.Now,
+	}
+}
+
+// Report files a report by reporter. Messages can only be reported by the
+// other member of their conversation.
+func (s *ReportService) Report(reporter *models.User, input dto.ReportCreateDTO) (*models.Report, error) {
+	logger := logging.GetLogger()
+	if !contains(models.ReportTargets, input.TargetType) {
+		return nil, ErrReportTarget
+	}
+	if !contains(models.ReportReasons, input.Reason) {
+		return nil, ErrReportReason
+	}
+	ownerID, err := s.Repo.TargetOwner(input.TargetType, input.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporter.ID {
+		return nil, ErrReportOwnContent
+	}
+	if input.TargetType == models.ReportMessage {
+		if err := s.checkRecipient(reporter.ID, input.TargetID); err != nil {
+			return nil, err
+		}
+	}
+	now := s.Now()
+	open := &models.ModerationCase{
+		ID:           uuid.New(),
+		TargetType:   input.TargetType,
+		TargetID:     input.TargetID,
+		TargetUserID: ownerID,
+		Status:       models.CaseOpen,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	report := &models.Report{
+		ID:         uuid.New(),
+		ReporterID: reporter.ID,
+		Reason:     input.Reason,
+		Details:    strings.TrimSpace(input.Details),
+		CreatedAt:  now,
+	}
+	c, err := s.Repo.AddReport(open, report, s.HideThreshold)
+	if err != nil {
+		if !errors.Is(err, repository.ErrDuplicateReport) {
+			logger.Error("File report failed", zap.String("target_type", input.TargetType), zap.String("target_id", input.TargetID.String()), zap.Error(err))
+		}
+		return nil, err
+	}
+	logger.Info("Content reported", zap.String("case_id", c.ID.String()), zap.String("target_type", c.TargetType),
+		zap.String("target_id", c.TargetID.String()), zap.Int("reports", c.ReportCount), zap.Bool("auto_hidden", c.AutoHidden))
+	return report, nil
+}
+
+// checkRecipient fails with gorm.ErrRecordNotFound unless userID is a member
+// of the message's conversation, so outsiders cannot probe message IDs.
+func (s *ReportService) checkRecipient(userID, messageID uuid.UUID) error {
+	msg, err := s.Messages.FindMessage(messageID)
+	if err != nil {
+		return err
+	}
+	conv, err := s.Messages.FindConversation(msg.ConversationID)
+	if err != nil {
+		return err
+	}
+	for _, m := range conv.Members {
+		if m.UserID == userID {
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// Mine lists the reports the user filed, newest first.
+func (s *ReportService) Mine(userID uuid.UUID, limit, offset int) ([]models.Report, error) {
+	return s.Repo.ListByReporter(userID, limit, offset)
+}
+
+// Queue lists cases in the given status, oldest first.
+func (s *ReportService) Queue(status, targetType string, limit, offset int) ([]models.ModerationCase, error) {
+	return s.Repo.ListCases(status, targetType, limit, offset)
+}
+
+// Case returns a case with its reports.
+func (s *ReportService) Case(id uuid.UUID) (*models.ModerationCase, error) {
+	return s.Repo.FindCase(id)
+}
+
+// History lists resolved cases, most recently resolved first.
+func (s *ReportService) History(filter repository.HistoryFilter, limit, offset int) ([]models.ModerationCase, error) {
+	return s.Repo.History(filter, limit, offset)
+}
+
+// Claim assigns an unresolved case to the moderator. Claiming an own case
+// again is a no-op.
+func (s *ReportService) Claim(moderator *models.User, id uuid.UUID) (*models.ModerationCase, error) {
+	c, err := s.Repo.FindCase(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == models.CaseResolved {
+		return nil, ErrCaseResolved
+	}
+	claimed, err := s.Repo.Claim(id, moderator.ID, s.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrCaseClaimed
+	}
+	logging.GetLogger().Info("Moderation case claimed", zap.String("case_id", id.String()), zap.String("moderator_id", moderator.ID.String()))
+	return s.Repo.FindCase(id)
+}
+
+// Resolve closes a case with an action. Cases claimed by another moderator
+// cannot be resolved; unclaimed ones are claimed on the way.
+func (s *ReportService) Resolve(moderator *models.User, id uuid.UUID, input dto.ResolveCaseDTO) (*models.ModerationCase, error) {
+	logger := logging.GetLogger()
+	if !contains(models.ModerationActions, input.Action) {
+		return nil, ErrModerationAction
+	}
+	if input.Action == models.ActionSuspend && input.SuspendDays < 1 {
+		return nil, ErrSuspensionDuration
+	}
+	c, err := s.Repo.FindCase(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == models.CaseResolved {
+		return nil, ErrCaseResolved
+	}
+	if c.AssigneeID != nil && *c.AssigneeID != moderator.ID {
+		return nil, ErrCaseClaimed
+	}
+	now := s.Now()
+	c.Status = models.CaseResolved
+	c.Action = input.Action
+	c.ResolutionNote = strings.TrimSpace(input.Note)
+	c.AssigneeID = &moderator.ID
+	if c.ClaimedAt == nil {
+		c.ClaimedAt = &now
+	}
+	c.ResolvedBy = &moderator.ID
+	c.ResolvedAt = &now
+	c.UpdatedAt = now
+	if input.Action == models.ActionSuspend {
+		until := now.AddDate(0, 0, input.SuspendDays)
+		c.SuspendedUntil = &until
+	}
+	// Warned content was judged acceptable enough to stay up.
+	hide := input.Action == models.ActionHide || input.Action == models.ActionSuspend
+	resolved, err := s.Repo.Resolve(c, hide)
+	if err != nil {
+		logger.Error("Resolve moderation case failed", zap.String("case_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	// Another moderator claimed or resolved the case since it was loaded.
+	if !resolved {
+		return nil, ErrCaseClaimed
+	}
+	logger.Info("Moderation case resolved", zap.String("case_id", id.String()), zap.String("action", c.Action),
+		zap.String("target_user_id", c.TargetUserID.String()), zap.String("moderator_id", moderator.ID.String()))
+	return c, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go-backend/config"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Kinds of soft-deleted content staff can restore.
+const (
+	RestorePost  = "post"
+	RestoreUser  = "user"
+	RestoreVideo = "video"
+	RestoreImage = "image"
+)
+
+var ErrRestoreKind = errors.New("unknown kind of deleted content")
+
+// PurgeResult counts what one purge run removed for good.
+type PurgeResult struct {
+	Posts  int `json:"posts"`
+	Videos int `json:"videos"`
+	Images int `json:"images"`
+}
+
+// RetentionService restores deleted posts, users and uploads, and purges
+// them with their stored files once Retention has passed since deletion.
+type RetentionService struct {
+	Repo      repository.RetentionRepository
+	Storage   MediaStorage
+	Retention time.Duration
+	Now       func() time.Time
+}
+
+func NewRetentionService(repo repository.RetentionRepository) *RetentionService {
+	return &RetentionService{
+		Repo:      repo,
+		Storage:   BunnyMediaStorage{},
+		Retention: config.AppConfig.DeletedRetention,
+		Now:       time.Now,
+	}
+}
+
+// Restore undeletes the content of the given kind.
+func (s *RetentionService) Restore(actor *models.User, kind string, id uuid.UUID) error {
+	var model interface{}
+	switch kind {
+	case RestorePost:
+		model = &models.Post{}
+	case RestoreUser:
+		model = &models.User{}
+	case RestoreVideo:
+		model = &models.Video{}
+	case RestoreImage:
+		model = &models.Image{}
+	default:
+		return ErrRestoreKind
+	}
+	if err := s.Repo.Restore(model, id); err != nil {
+		return err
+	}
+	logging.GetLogger().Info("Deleted content restored", zap.String("kind", kind), zap.String("id", id.String()),
+		zap.Stringp("actor_id", uuidString(actorIDOf(actor))))
+	return nil
+}
+
+// Purge removes content deleted longer than Retention ago. Posts go with
+// their media files, except posts bought before their author deleted them,
+// which buyers keep; bought posts removed by moderation only lose their
+// files. Uploads of users deleted that long ago go as well. Files that
+// cannot be removed are retried on the next run.
+func (s *RetentionService) Purge() (PurgeResult, error) {
+	logger := logging.GetLogger()
+	var result PurgeResult
+	cutoff := s.Now().Add(-s.Retention)
+
+	posts, err := s.Repo.ExpiredPosts(cutoff)
+	if err != nil {
+		return result, err
+	}
+	for _, post := range posts {
+		urls := make([]string, 0, len(post.Media))
+		for _, m := range post.Media {
+			urls = append(urls, m.URL)
+		}
+		videos, images, err := s.Repo.Uploads(urls)
+		if err != nil {
+			return result, err
+		}
+		if !s.purgeUploads(videos, images, &result) {
+			continue
+		}
+		bought, err := s.Repo.Purchased(post.ID)
+		if err != nil {
+			return result, err
+		}
+		if err := s.Repo.PurgePost(post.ID, bought); err != nil {
+			logger.Error("Purge post failed", zap.String("post_id", post.ID.String()), zap.Error(err))
+			continue
+		}
+		result.Posts++
+	}
+
+	videos, err := s.Repo.ExpiredVideos(cutoff)
+	if err != nil {
+		return result, err
+	}
+	images, err := s.Repo.ExpiredImages(cutoff)
+	if err != nil {
+		return result, err
+	}
+	s.purgeUploads(videos, images, &result)
+
+	if result != (PurgeResult{}) {
+		logger.Info("Deleted content purged", zap.Int("posts", result.Posts), zap.Int("videos", result.Videos), zap.Int("images", result.Images))
+	}
+	return result, nil
+}
+
+// purgeUploads removes the stored files and rows of the uploads and reports
+// whether all of them are gone.
+func (s *RetentionService) purgeUploads(videos []models.Video, images []models.Image, result *PurgeResult) bool {
+	logger := logging.GetLogger()
+	ok := true
+	for _, v := range videos {
+		if err := s.Storage.DeleteVideo(v.BunnyVideoID); err != nil {
+			logger.Error("Delete stored video failed", zap.String("video_id", v.ID.String()), zap.Error(err))
+			ok = false
+			continue
+		}
+		if err := s.Repo.PurgeVideo(v.ID); err != nil {
+			logger.Error("Purge video failed", zap.String("video_id", v.ID.String()), zap.Error(err))
+			ok = false
+			continue
+		}
+		result.Videos++
+	}
+	for _, img := range images {
+		if err := s.Storage.DeleteImage(img.Filename); err != nil {
+			logger.Error("Delete stored image failed", zap.String("image_id", img.ID.String()), zap.Error(err))
+			ok = false
+			continue
+		}
+		if err := s.Repo.PurgeImage(img.ID); err != nil {
+			logger.Error("Purge image failed", zap.String("image_id", img.ID.String()), zap.Error(err))
+			ok = false
+			continue
+		}
+		result.Images++
+	}
+	return ok
+}
+
+// StartPurger runs Purge every interval in the background until the
+// returned stop function is called.
+func (s *RetentionService) StartPurger(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.Purge(); err != nil {
+					logging.GetLogger().Error("Purge of deleted content failed", zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
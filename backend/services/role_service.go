@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownRole      = errors.New("unknown role")
+	ErrImplicitRole     = errors.New("every account has the user role, it cannot be granted or revoked")
+	ErrRoleAlreadyHeld  = errors.New("user already has this role")
+	ErrRoleNotHeld      = errors.New("user does not have this role")
+	ErrLastAdmin        = errors.New("cannot revoke the admin role from the last admin")
+	ErrRoleReasonNeeded = errors.New("a reason is required")
+)
+
+// RoleService grants and revokes roles and records every change.
+type RoleService struct {
+	Repo  repository.RoleRepository
+	Users repository.UserRepository
+}
+
+func NewRoleService(repo repository.RoleRepository, users repository.UserRepository) *RoleService {
+	return &RoleService{Repo: repo, Users: users}
+}
+
+// Grant gives the role to the user. actor is nil for system changes.
+func (s *RoleService) Grant(actor *models.User, userID uuid.UUID, role models.Role, reason string) ([]models.UserRole, error) {
+	logger := logging.GetLogger()
+	if err := s.validate(userID, role, reason); err != nil {
+		return nil, err
+	}
+	held, err := s.Repo.FindForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range held {
+		if r.Role == role {
+			return nil, ErrRoleAlreadyHeld
+		}
+	}
+	actorID := actorIDOf(actor)
+	grant := &models.UserRole{UserID: userID, Role: role, GrantedBy: actorID}
+	change := &models.RoleChange{UserID: userID, ActorID: actorID, Role: role, Action: models.RoleGranted, Reason: reason}
+	if err := s.Repo.Grant(grant, change); err != nil {
+		logger.Error("Grant role failed", zap.String("user_id", userID.String()), zap.String("role", string(role)), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Role granted", zap.String("user_id", userID.String()), zap.String("role", string(role)), zap.Stringp("actor_id", uuidString(actorID)))
+	return s.Repo.FindForUser(userID)
+}
+
+// Revoke takes the role away from the user. The last admin cannot lose the
+// admin role, so the platform can't be locked out of role management.
+func (s *RoleService) Revoke(actor *models.User, userID uuid.UUID, role models.Role, reason string) ([]models.UserRole, error) {
+	logger := logging.GetLogger()
+	if err := s.validate(userID, role, reason); err != nil {
+		return nil, err
+	}
+	held, err := s.Repo.FindForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasRole(held, role) {
+		return nil, ErrRoleNotHeld
+	}
+	actorID := actorIDOf(actor)
+	change := &models.RoleChange{UserID: userID, ActorID: actorID, Role: role, Action: models.RoleRevoked, Reason: reason}
+	if err := s.Repo.Revoke(userID, role, change, role == models.RoleAdmin); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrRoleNotHeld
+		case errors.Is(err, repository.ErrLastHolder):
+			return nil, ErrLastAdmin
+		}
+		logger.Error("Revoke role failed", zap.String("user_id", userID.String()), zap.String("role", string(role)), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Role revoked", zap.String("user_id", userID.String()), zap.String("role", string(role)), zap.Stringp("actor_id", uuidString(actorID)))
+	return s.Repo.FindForUser(userID)
+}
+
+func (s *RoleService) RolesOf(userID uuid.UUID) ([]models.UserRole, error) {
+	if _, err := s.Users.FindByID(userID); err != nil {
+		return nil, err
+	}
+	return s.Repo.FindForUser(userID)
+}
+
+func (s *RoleService) Changes(filter repository.RoleChangeFilter) ([]models.RoleChange, error) {
+	return s.Repo.ListChanges(filter)
+}
+
+func (s *RoleService) validate(userID uuid.UUID, role models.Role, reason string) error {
+	if role == models.RoleUser {
+		return ErrImplicitRole
+	}
+	if !role.Valid() {
+		return ErrUnknownRole
+	}
+	if strings.TrimSpace(reason) == "" {
+		return ErrRoleReasonNeeded
+	}
+	_, err := s.Users.FindByID(userID)
+	return err
+}
+
+func actorIDOf(actor *models.User) *uuid.UUID {
+	if actor == nil {
+		return nil
+	}
+	id := actor.ID
+	return &id
+}
+
+func uuidString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
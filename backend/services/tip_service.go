@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTipSelf    = errors.New("you cannot tip yourself")
+	ErrTipAmount  = errors.New("tip amount out of range")
+	ErrTipContext = errors.New("a tip can be for one post, stream or message of the creator")
+)
+
+// TipService moves money from fans to creators outside of fixed prices. A
+// tip is charged and credited like a purchase, through the earnings ledger.
+type TipService struct {
+	Repo      repository.TipRepository
+	Posts     repository.PostRepository
+	Streams   repository.StreamRepository
+	Messages  repository.MessageRepository
+	Blocks    repository.BlockRepository
+	MinAmount int
+	MaxAmount int
+	Now       func() time.Time
+}
+
+func NewTipService(repo repository.TipRepository, posts repository.PostRepository, streams repository.StreamRepository, messages repository.MessageRepository, blocks repository.BlockRepository) *TipService {
+	return &TipService{
+		Repo:      repo,
+		Posts:     posts,
+		Streams:   streams,
+		Messages:  messages,
+		Blocks:    blocks,
+		MinAmount: config.AppConfig.MinTipAmount,
+		MaxAmount: config.AppConfig.MaxTipAmount,
+		Now:       time.Now,
+	}
+}
+
+// Tip charges user and credits the creator of profile unless either blocks
+// the other. Callers check that the profile belongs to a verified creator.
+func (s *TipService) Tip(user *models.User, profile *models.ModelProfile, input dto.TipCreateDTO) (*dto.TipResponseDTO, error) {
+	logger := logging.GetLogger()
+	if profile.UserID == user.ID {
+		return nil, ErrTipSelf
+	}
+	if input.Amount < s.MinAmount || input.Amount > s.MaxAmount {
+		return nil, fmt.Errorf("%w: must be between %d and %d", ErrTipAmount, s.MinAmount, s.MaxAmount)
+	}
+	if blocked, err := s.Blocks.Between(user.ID, profile.UserID); err != nil || blocked {
+		if blocked {
+			err = ErrUserBlocked
+		}
+		return nil, err
+	}
+	tip := &models.Tip{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ModelID:   profile.ID,
+		Amount:    input.Amount,
+		Note:      strings.TrimSpace(input.Note),
+		CreatedAt: s.Now(),
+	}
+	if err := s.resolveContext(tip, user, profile, input); err != nil {
+		return nil, err
+	}
+	earning := NewEarning(profile.UserID, profile.ID, models.EarningTip, tip.ID, tip.PostID, tip.Amount, tip.CreatedAt)
+	if err := s.Repo.Create(tip, earning); err != nil {
+		logger.Warn("Tip failed", zap.String("user_id", user.ID.String()), zap.String("model_id", profile.ID.String()), zap.Error(err))
+		return nil, err
+	}
+	logger.Info("Tip sent", zap.String("tip_id", tip.ID.String()), zap.String("model_id", profile.ID.String()), zap.Int("amount", tip.Amount))
+	tip.User = *user
+	resp := tipDTO(tip)
+	return &resp, nil
+}
+
+// resolveContext checks the post, stream or message a tip is for and sets it
+// on tip. Posts and streams must be the creator's own; messages must be in the
+// conversation between tipper and creator.
+func (s *TipService) resolveContext(tip *models.Tip, user *models.User, profile *models.ModelProfile, input dto.TipCreateDTO) error {
+	set := 0
+	for _, id := range []string{input.PostID, input.StreamID, input.MessageID} {
+		if id != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return ErrTipContext
+	}
+	switch {
+	case input.PostID != "":
+		post, err := s.Posts.FindByID(uuid.MustParse(input.PostID))
+		if err != nil {
+			return err
+		}
+		if post.ModelID != profile.ID {
+			return ErrTipContext
+		}
+		tip.PostID = &post.ID
+	case input.StreamID != "":
+		stream, err := s.Streams.FindByID(uuid.MustParse(input.StreamID))
+		if err != nil {
+			return err
+		}
+		if stream.ModelID == nil || *stream.ModelID != profile.ID {
+			return ErrTipContext
+		}
+		tip.StreamID = &stream.ID
+	case input.MessageID != "":
+		msg, err := s.Messages.FindMessage(uuid.MustParse(input.MessageID))
+		if err != nil {
+			return err
+		}
+		conv, err := s.Messages.FindConversation(msg.ConversationID)
+		if err != nil {
+			return err
+		}
+		if conv.PairKey != repository.PairKey(user.ID, profile.UserID) {
+			return gorm.ErrRecordNotFound
+		}
+		tip.MessageID = &msg.ID
+	}
+	return nil
+}
+
+func (s *TipService) Sent(userID uuid.UUID, limit, offset int) ([]dto.TipResponseDTO, error) {
+	tips, err := s.Repo.ListSent(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return tipDTOs(tips), nil
+}
+
+func (s *TipService) Received(modelID uuid.UUID, limit, offset int) ([]dto.TipResponseDTO, error) {
+	tips, err := s.Repo.ListReceived(modelID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return tipDTOs(tips), nil
+}
+
+func tipDTOs(tips []models.Tip) []dto.TipResponseDTO {
+	resp := make([]dto.TipResponseDTO, 0, len(tips))
+	for i := range tips {
+		resp = append(resp, tipDTO(&tips[i]))
+	}
+	return resp
+}
+
+func tipDTO(t *models.Tip) dto.TipResponseDTO {
+	item := dto.TipResponseDTO{
+		ID:        t.ID,
+		ModelID:   t.ModelID,
+		Amount:    t.Amount,
+		PostID:    t.PostID,
+		StreamID:  t.StreamID,
+		MessageID: t.MessageID,
+		Note:      t.Note,
+		CreatedAt: t.CreatedAt,
+	}
+	if t.User.ID != uuid.Nil {
+		item.From = &dto.TipperDTO{ID: t.User.ID, Nickname: t.User.Nickname, AvatarURL: t.User.AvatarURL}
+	}
+	return item
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"go-backend/config"
+	"go-backend/dto"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/utils"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor setup has not been started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked         = errors.New("too many failed two-factor attempts, try again later")
+)
+
+const (
+	recoveryCodeCount = 10
+	// After maxTwoFactorFailures wrong codes in a row verification is locked
+	// for twoFactorLockout.
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+// TwoFactorService manages TOTP enrollment and recovery codes and turns a
+// verified code into a session marked as having passed 2FA.
+type TwoFactorService struct {
+	Repo   repository.TwoFactorRepository
+	Auth   *AuthService
+	Issuer string
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository, auth *AuthService) *TwoFactorService {
+	return &TwoFactorService{Repo: repo, Auth: auth, Issuer: config.AppConfig.TOTPIssuer}
+}
+
+func (s *TwoFactorService) Status(user *models.User) (dto.TwoFactorStatusDTO, error) {
+	tf, err := s.Repo.FindByUserID(user.ID)
+	if err != nil || !tf.Enabled() {
+		return dto.TwoFactorStatusDTO{}, err
+	}
+	left, err := s.Repo.CountRecoveryCodes(user.ID)
+	if err != nil {
+		return dto.TwoFactorStatusDTO{}, err
+	}
+	return dto.TwoFactorStatusDTO{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// Setup starts (or restarts) enrollment with a new secret. 2FA is not active
+// until Enable confirms a code generated from it.
+func (s *TwoFactorService) Setup(user *models.User) (dto.TwoFactorSetupDTO, error) {
+	tf, err := s.Repo.FindByUserID(user.ID)
+	if err != nil {
+		return dto.TwoFactorSetupDTO{}, err
+	}
+	if tf.Enabled() {
+		return dto.TwoFactorSetupDTO{}, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return dto.TwoFactorSetupDTO{}, err
+	}
+	if err := s.Repo.Save(&models.TwoFactor{UserID: user.ID, Secret: secret}); err != nil {
+		return dto.TwoFactorSetupDTO{}, err
+	}
+	logging.GetLogger().Info("TwoFactor setup started", zap.String("user_id", user.ID.String()))
+	return dto.TwoFactorSetupDTO{
+		Secret:     secret,
+		OTPAuthURL: utils.TOTPProvisioningURI(s.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms enrollment with a code from the app, generates recovery
+// codes and replaces all sessions with a new one that has passed 2FA.
+func (s *TwoFactorService) Enable(user *models.User, code string, meta SessionMeta) (dto.TwoFactorEnabledDTO, error) {
+	tf, err := s.Repo.FindByUserID(user.ID)
+	if err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	if tf == nil {
+		return dto.TwoFactorEnabledDTO{}, ErrTwoFactorNotSetUp
+	}
+	if tf.Enabled() {
+		return dto.TwoFactorEnabledDTO{}, ErrTwoFactorAlreadyEnabled
+	}
+	if err := s.check(tf, code, ""); err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	now := time.Now()
+	tf.EnabledAt = &now
+	if err := s.Repo.Save(tf); err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	codes, err := s.newRecoveryCodes(user)
+	if err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	if err := s.Auth.LogoutAll(user); err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	tokens, err := s.Auth.StartSession(user, true, meta)
+	if err != nil {
+		return dto.TwoFactorEnabledDTO{}, err
+	}
+	logging.GetLogger().Info("TwoFactor enabled", zap.String("user_id", user.ID.String()))
+	return dto.TwoFactorEnabledDTO{RecoveryCodes: codes, Tokens: tokens}, nil
+}
+
+// Verify checks a TOTP or recovery code and starts a session that has passed 2FA.
+func (s *TwoFactorService) Verify(user *models.User, code, recoveryCode string, meta SessionMeta) (dto.TokenPairDTO, error) {
+	tf, err := s.enabled(user)
+	if err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	if err := s.check(tf, code, recoveryCode); err != nil {
+		logging.GetLogger().Warn("TwoFactor verification failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return dto.TokenPairDTO{}, err
+	}
+	return s.Auth.StartSession(user, true, meta)
+}
+
+// Disable removes 2FA after a final code check. All sessions end; the caller
+// gets a new one.
+func (s *TwoFactorService) Disable(user *models.User, code, recoveryCode string, meta SessionMeta) (dto.TokenPairDTO, error) {
+	tf, err := s.enabled(user)
+	if err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	if err := s.check(tf, code, recoveryCode); err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	if err := s.Repo.Delete(user.ID); err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	if err := s.Auth.LogoutAll(user); err != nil {
+		return dto.TokenPairDTO{}, err
+	}
+	logging.GetLogger().Info("TwoFactor disabled", zap.String("user_id", user.ID.String()))
+	return s.Auth.StartSession(user, false, meta)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes. Needs a code from the
+// app, not a recovery code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	tf, err := s.enabled(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(tf, code, ""); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(user)
+}
+
+func (s *TwoFactorService) enabled(user *models.User) (*models.TwoFactor, error) {
+	tf, err := s.Repo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.Enabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return tf, nil
+}
+
+// check accepts a TOTP code that has not been used before, or an unused
+// recovery code, and counts failures towards the lockout.
+func (s *TwoFactorService) check(tf *models.TwoFactor, code, recoveryCode string) error {
+	now := time.Now()
+	if tf.LockedUntil != nil && now.Before(*tf.LockedUntil) {
+		return ErrTwoFactorLocked
+	}
+	ok := false
+	if code != "" {
+		if step, valid := utils.ValidateTOTP(tf.Secret, code, now); valid && step > tf.LastUsedStep {
+			tf.LastUsedStep = step
+			ok = true
+		}
+	} else if recoveryCode != "" {
+		used, err := s.Repo.UseRecoveryCode(tf.UserID, hashToken(normalizeRecoveryCode(recoveryCode)), now)
+		if err != nil {
+			return err
+		}
+		ok = used
+	}
+	if ok {
+		tf.FailedAttempts = 0
+		tf.LockedUntil = nil
+		return s.Repo.Save(tf)
+	}
+	tf.FailedAttempts++
+	if tf.FailedAttempts >= maxTwoFactorFailures {
+		until := now.Add(twoFactorLockout)
+		tf.LockedUntil = &until
+		tf.FailedAttempts = 0
+	}
+	if err := s.Repo.Save(tf); err != nil {
+		return err
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+func (s *TwoFactorService) newRecoveryCodes(user *models.User) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+	if err := s.Repo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// newRecoveryCode returns a code like "k3v9q-x7m2p".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = recoveryCodeAlphabet[n.Int64()]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
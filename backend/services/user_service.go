@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+
 	"go-backend/database"
 	"go-backend/dto"
 	"go-backend/logging"
@@ -12,6 +14,8 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrNicknameTaken = errors.New("nickname is already taken")
+
 type UserService struct {
 	Repo repository.UserRepository
 }
@@ -30,16 +34,7 @@ func (s *UserService) GetUsers(limit, offset int) ([]dto.UserResponseDTO, error)
 	}
 	resp := make([]dto.UserResponseDTO, 0, len(users))
 	for _, user := range users {
-		resp = append(resp, dto.UserResponseDTO{
-			ID:           user.ID,
-			Email:        user.Email,
-			Nickname:     user.Nickname,
-			Balance:      user.Balance,
-			AvatarURL:    user.AvatarURL,
-			IsAdmin:      user.IsAdmin,
-			ReferralCode: user.ReferralCode,
-			ReferredBy:   user.ReferredBy,
-		})
+		resp = append(resp, ToUserResponseDTO(&user))
 	}
 	logger.Debug("GetUsers success", zap.Int("count", len(resp)))
 	return resp, nil
@@ -53,16 +48,7 @@ func (s *UserService) GetUserByID(id uuid.UUID) (dto.UserResponseDTO, error) {
 		logger.Error("GetUserByID failed", zap.String("id", id.String()), zap.Error(err))
 		return dto.UserResponseDTO{}, err
 	}
-	resp := dto.UserResponseDTO{
-		ID:           user.ID,
-		Email:        user.Email,
-		Nickname:     user.Nickname,
-		Balance:      user.Balance,
-		AvatarURL:    user.AvatarURL,
-		IsAdmin:      user.IsAdmin,
-		ReferralCode: user.ReferralCode,
-		ReferredBy:   user.ReferredBy,
-	}
+	resp := ToUserResponseDTO(&user)
 	logger.Debug("GetUserByID success", zap.String("id", id.String()))
 	return resp, nil
 }
@@ -95,8 +81,53 @@ func (s *UserService) CreateUser(user *models.User) error {
 	return nil
 }
 
+// ApplyReferral links the user to the owner of the referral code and records
+// the invitation.
+func (s *UserService) ApplyReferral(user *models.User, code string) error {
+	inviter, err := s.Repo.FindByReferralCode(code)
+	if err != nil {
+		return err
+	}
+	if inviter.ID == user.ID {
+		return nil
+	}
+	user.ReferredBy = &inviter.ID
+	if err := s.Repo.Update(user); err != nil {
+		return err
+	}
+	ref := models.Referral{ID: uuid.New(), UserID: inviter.ID, ReferralCode: code, InvitedUserID: user.ID}
+	return database.DB.Create(&ref).Error
+}
+
+// UpdateUser saves the user. It fails with ErrEmailTaken when another
+// account, deleted ones included, holds the email and with ErrNicknameTaken
+// when one holds the nickname.
 func (s *UserService) UpdateUser(user *models.User) error {
-	return s.Repo.Update(user)
+	if err := s.checkEmailFree(user); err != nil {
+		return err
+	}
+	err := s.Repo.Update(user)
+	if errors.Is(err, repository.ErrUserConflict) {
+		// the email may have been taken since the check
+		if err := s.checkEmailFree(user); err != nil {
+			return err
+		}
+		return ErrNicknameTaken
+	}
+	return err
+}
+
+// checkEmailFree fails with ErrEmailTaken when an account other than user
+// holds its email.
+func (s *UserService) checkEmailFree(user *models.User) error {
+	other, err := s.Repo.FindByEmail(user.Email)
+	switch {
+	case err == nil && other.ID != user.ID, errors.Is(err, repository.ErrUserDeleted):
+		return ErrEmailTaken
+	case err != nil && !errors.Is(err, repository.ErrUserNotFound):
+		return err
+	}
+	return nil
 }
 
 func (s *UserService) DeleteUser(id uuid.UUID) error {
@@ -106,15 +137,49 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 func (s *UserService) GetOrCreateUser(email, avatar, password, refCode string) (*models.User, error) {
 	user, err := s.Repo.FindByEmail(email)
 	if err != nil {
-		if err == repository.ErrUserNotFound {
-			userPtr, err := s.Repo.CreateUser(email, avatar, password, refCode)
-			if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			user = models.User{Email: email, AvatarURL: avatar, Password: password}
+			if err := s.CreateUser(&user); err != nil {
 				return nil, err
 			}
-			return userPtr, nil
+			if refCode != "" {
+				if err := s.ApplyReferral(&user, refCode); err != nil {
+					logging.GetLogger().Warn("GetOrCreateUser referral not applied", zap.String("email", email), zap.Error(err))
+				}
+			}
+			return &user, nil
 		} else {
 			return nil, err
 		}
 	}
 	return &user, nil
 }
+
+// ToUserResponseDTO converts a user into its API representation.
+func ToUserResponseDTO(user *models.User) dto.UserResponseDTO {
+	return dto.UserResponseDTO{
+		ID:            user.ID,
+		Email:         user.Email,
+		Nickname:      user.Nickname,
+		Balance:       user.Balance,
+		AvatarURL:     user.AvatarURL,
+		IsAdmin:       user.HasRole(models.RoleAdmin),
+		Roles:         user.RoleNames(),
+		ReferralCode:  user.ReferralCode,
+		ReferredBy:    user.ReferredBy,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Status:        user.Status,
+	}
+}
+
+// ToPublicUserDTO keeps the fields of a user response anyone may see.
+func ToPublicUserDTO(user dto.UserResponseDTO) dto.PublicUserDTO {
+	return dto.PublicUserDTO{
+		ID:             user.ID,
+		Nickname:       user.Nickname,
+		AvatarURL:      user.AvatarURL,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		IsFollowing:    user.IsFollowing,
+	}
+}
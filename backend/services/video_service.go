@@ -27,7 +27,8 @@ func NewVideoService(db *gorm.DB, logger *zap.Logger) *VideoService {
 	return &VideoService{DB: db, Logger: logger}
 }
 
-func (s *VideoService) UploadVideo(title string, file *multipart.FileHeader) (*models.Video, error) {
+// UploadVideo uploads the file to Bunny Stream and records ownerID as the uploader.
+func (s *VideoService) UploadVideo(ownerID uuid.UUID, title string, file *multipart.FileHeader) (*models.Video, error) {
 	s.Logger.Info("Uploading video to Bunny Stream", zap.String("filename", file.Filename))
 	// Validate file type
 	ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -80,6 +81,7 @@ func (s *VideoService) UploadVideo(title string, file *multipart.FileHeader) (*m
 	cdnURL := fmt.Sprintf("https://%s/%s/playlist.m3u8", bunnyHost, createResp.Guid)
 	video := &models.Video{
 		ID:           uuid.New(),
+		UserID:       ownerID,
 		BunnyVideoID: createResp.Guid,
 		Title:        title,
 		CDNUrl:       cdnURL,
@@ -102,26 +104,14 @@ func (s *VideoService) GetVideo(id uuid.UUID) (*models.Video, error) {
 	return &video, nil
 }
 
+// DeleteVideo soft-deletes the video. The Bunny video stays until the
+// retention period is over, see RetentionService.Purge.
 func (s *VideoService) DeleteVideo(id uuid.UUID) error {
 	var video models.Video
 	if err := s.DB.First(&video, "id = ?", id).Error; err != nil {
 		s.Logger.Error("Video not found", zap.Error(err))
 		return err
 	}
-	bunnyAPI := config.AppConfig.BunnyStreamAPI
-	bunnyKey := config.AppConfig.BunnyStreamAPIKey
-	if bunnyAPI == "" || bunnyKey == "" {
-		s.Logger.Error("Bunny Stream config missing")
-		return fmt.Errorf("Bunny Stream config missing")
-	}
-	deleteURL := fmt.Sprintf("%s/library/videos/%s", bunnyAPI, video.BunnyVideoID)
-	req, _ := http.NewRequest("DELETE", deleteURL, nil)
-	req.Header.Set("AccessKey", bunnyKey)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil || resp.StatusCode > 299 {
-		s.Logger.Error("Bunny delete video failed", zap.Error(err))
-		return fmt.Errorf("Bunny delete video failed: %w", err)
-	}
 	if err := s.DB.Delete(&video).Error; err != nil {
 		s.Logger.Error("DB delete failed", zap.Error(err))
 		return err
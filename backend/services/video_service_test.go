@@ -8,6 +8,7 @@ import (
 
 	"go-backend/models"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"gorm.io/driver/sqlite"
@@ -41,7 +42,7 @@ func TestUploadVideo_Success(t *testing.T) {
 	filePath, fh := createTestVideoFile(t)
 	defer os.Remove(filePath)
 
-	video, err := service.UploadVideo("Test Title", fh)
+	video, err := service.UploadVideo(uuid.New(), "Test Title", fh)
 	assert.NoError(t, err)
 	assert.NotNil(t, video)
 	assert.Equal(t, "Test Title", video.Title)
@@ -66,7 +67,7 @@ func TestUploadVideo_UnsupportedType(t *testing.T) {
 	}
 	defer os.Remove(filePath)
 
-	video, err := service.UploadVideo("Test Title", fh)
+	video, err := service.UploadVideo(uuid.New(), "Test Title", fh)
 	assert.Error(t, err)
 	assert.Nil(t, video)
 }
@@ -80,7 +81,7 @@ func TestUploadVideo_DBInsertFails(t *testing.T) {
 	filePath, fh := createTestVideoFile(t)
 	defer os.Remove(filePath)
 
-	video, err := service.UploadVideo("Test Title", fh)
+	video, err := service.UploadVideo(uuid.New(), "Test Title", fh)
 	assert.Error(t, err)
 	assert.Nil(t, video)
 }
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BunnyStore keeps objects in a Bunny storage zone that has no pull zone, so
+// they are reachable only through the storage API with the zone's key.
+type BunnyStore struct {
+	Host string // storage endpoint, e.g. storage.bunnycdn.com
+	Zone string
+	Key  string
+}
+
+func (s *BunnyStore) do(ctx context.Context, method, key string, body io.Reader) (*http.Response, error) {
+	url := fmt.Sprintf("https://%s/%s/%s", s.Host, s.Zone, key)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("AccessKey", s.Key)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (s *BunnyStore) Put(ctx context.Context, key string, r io.Reader) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("storage: bunny upload failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *BunnyStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("storage: bunny download failed: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *BunnyStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("storage: bunny delete failed: %s", resp.Status)
+	}
+	return nil
+}
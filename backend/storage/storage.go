@@ -0,0 +1,68 @@
+// Package storage keeps private files such as identity documents. Unlike
+// media uploads they are never served from the CDN; handlers stream them to
+// authorized staff only.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go-backend/config"
+)
+
+// ErrNotFound is returned when no object exists under the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store saves and loads private objects by key.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	mu      sync.RWMutex
+	private Store
+)
+
+// SetPrivate overrides the store returned by Private.
+func SetPrivate(s Store) {
+	mu.Lock()
+	defer mu.Unlock()
+	private = s
+}
+
+// Private returns the private store configured by PRIVATE_STORAGE_DRIVER,
+// building it on first use.
+func Private() (Store, error) {
+	mu.RLock()
+	s := private
+	mu.RUnlock()
+	if s != nil {
+		return s, nil
+	}
+	s, err := FromConfig(config.AppConfig)
+	if err != nil {
+		return nil, err
+	}
+	SetPrivate(s)
+	return s, nil
+}
+
+// FromConfig builds the private store selected by PRIVATE_STORAGE_DRIVER.
+func FromConfig(cfg *config.Config) (Store, error) {
+	switch cfg.PrivateStorageDriver {
+	case "bunny":
+		if cfg.PrivateBunnyZone == "" || cfg.PrivateBunnyKey == "" {
+			return nil, fmt.Errorf("storage: PRIVATE_BUNNY_STORAGE_ZONE and PRIVATE_BUNNY_STORAGE_KEY must be set")
+		}
+		return &BunnyStore{Host: cfg.BunnyStorageHost, Zone: cfg.PrivateBunnyZone, Key: cfg.PrivateBunnyKey}, nil
+	case "local", "":
+		return &LocalStore{Root: cfg.PrivateStoragePath}, nil
+	default:
+		return nil, fmt.Errorf("storage: unknown PRIVATE_STORAGE_DRIVER %q", cfg.PrivateStorageDriver)
+	}
+}
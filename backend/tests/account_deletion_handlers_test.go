@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccountDeletion(t *testing.T) {
+	r := SetupRouter(t)
+	const email = "leaving@example.com"
+	session := registerNative(t, r, email)
+	var creator models.User
+	database.DB.Where("email = ?", email).First(&creator)
+	verifyModel(t, createModel(t, r, creator.ID))
+	creatorToken := testTokenPrefix + email
+	fan := createUser(t, r)
+	database.DB.Model(&fan).Update("balance", 100)
+	fanToken := testTokenPrefix + fan.Email
+
+	postID := createPost(t, r, creator, false)
+	database.DB.Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{"is_premium": true, "price": 10})
+	if w := call(r, http.MethodPost, "/purchases", fanToken, gin.H{"post_id": postID}); w.Code != http.StatusCreated {
+		t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	call(r, http.MethodPost, "/posts/"+postID+"/comments", creatorToken, gin.H{"text": "call me at 555-0100"})
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), fanToken)
+	database.DB.Create(&models.Log{Level: "info", Message: `Login {"email":"` + email + `"}`})
+
+	// An identity document in private storage
+	store, _ := storage.Private()
+	app := models.CreatorApplication{UserID: creator.ID, Status: models.ApplicationApproved, LegalName: "Jane Roe", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), Country: "DE", Name: "Jane"}
+	database.DB.Create(&app)
+	docKey := "creator-applications/" + app.ID.String() + "/id"
+	store.Put(context.Background(), docKey, strings.NewReader("scan"))
+	database.DB.Create(&models.CreatorDocument{ApplicationID: app.ID, Kind: models.DocumentID, StorageKey: docKey})
+
+	// Requests can be cancelled during the grace period
+	if w := call(r, http.MethodPost, "/me/deletion", session.AccessToken, gin.H{"reason": "moving on"}); w.Code != http.StatusAccepted {
+		t.Fatalf("deletion request expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, "/me/deletion", session.AccessToken); w.Code != http.StatusConflict {
+		t.Errorf("second deletion request expected 409, got %d", w.Code)
+	}
+	if w := call(r, http.MethodDelete, "/me/deletion", session.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("cancel expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/me/deletion", session.AccessToken); w.Code != http.StatusNotFound {
+		t.Errorf("cancelled deletion expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/me/deletion", session.AccessToken); w.Code != http.StatusAccepted {
+		t.Fatalf("deletion request expected 202, got %d", w.Code)
+	}
+
+	svc := services.NewAccountDeletionService(&repository.GormAccountDeletionRepository{DB: database.DB}, store)
+	if n, err := svc.EraseDue(); err != nil || n != 0 {
+		t.Fatalf("nothing expected to be erased during the grace period, got %d %v", n, err)
+	}
+	if w := call(r, http.MethodGet, "/notifications", session.AccessToken); w.Code != http.StatusOK {
+		t.Errorf("account expected usable during the grace period, got %d", w.Code)
+	}
+	svc.Now = func() time.Time { return time.Now().Add(svc.Grace + time.Minute) }
+	if n, err := svc.EraseDue(); err != nil || n != 1 {
+		t.Fatalf("expected one account erased, got %d %v", n, err)
+	}
+
+	// Personal data is gone, financial records stay
+	var erased models.User
+	database.DB.Unscoped().First(&erased, "id = ?", creator.ID)
+	if erased.AnonymizedAt == nil || erased.Email == email || erased.Nickname == creator.Nickname || erased.Password != "" {
+		t.Errorf("expected an anonymized account, got %+v", erased)
+	}
+	if w := call(r, http.MethodGet, "/notifications", session.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("erased account's session expected 401, got %d", w.Code)
+	}
+	var comment models.Comment
+	database.DB.Where("user_id = ?", creator.ID).First(&comment)
+	if comment.Text != repository.AnonymizedText {
+		t.Errorf("comment expected anonymized, got %q", comment.Text)
+	}
+	var n int64
+	database.DB.Model(&models.Log{}).Where("message LIKE ?", "%"+email+"%").Count(&n)
+	if n != 0 {
+		t.Errorf("logs still mention the email %d times", n)
+	}
+	database.DB.Model(&models.Follow{}).Where("followed_id = ?", creator.ID).Count(&n)
+	if n != 0 {
+		t.Error("follows of the erased account expected removed")
+	}
+	database.DB.Model(&models.CreatorApplication{}).Where("user_id = ?", creator.ID).Count(&n)
+	if n != 0 {
+		t.Error("creator application expected removed")
+	}
+	if _, err := store.Get(context.Background(), docKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("identity document expected removed from storage, got %v", err)
+	}
+	database.DB.Model(&models.Purchase{}).Where("post_id = ?", postID).Count(&n)
+	if n != 1 {
+		t.Error("purchase expected kept")
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, fanToken); w.Code != http.StatusOK {
+		t.Errorf("buyer expected to keep the bought post, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/users/"+creator.ID.String()+"/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restoring an erased account expected 404, got %d", w.Code)
+	}
+	// The address is free again
+	registerNative(t, r, email)
+
+	// Deleted accounts are erased once they can no longer be restored
+	other := createUser(t, r)
+	if w := call(r, http.MethodDelete, "/users/"+other.ID.String(), testTokenPrefix+other.Email); w.Code != http.StatusOK {
+		t.Fatalf("delete account expected 200, got %d", w.Code)
+	}
+	svc.Now = time.Now
+	if n, err := svc.EraseDue(); err != nil || n != 0 {
+		t.Fatalf("deleted account must not be erased within retention, got %d %v", n, err)
+	}
+	svc.Now = func() time.Time { return time.Now().Add(svc.Retention + time.Hour) }
+	if n, err := svc.EraseDue(); err != nil || n != 1 {
+		t.Fatalf("expected the deleted account erased, got %d %v", n, err)
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerNative(t *testing.T, r *gin.Engine, email string) tokenPair {
+	t.Helper()
+	w := postJSON(r, "/auth/register", gin.H{"email": email, "nickname": strings.Split(email, "@")[0], "password": "password123"}, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register expected 201, got %d", w.Code)
+	}
+	var resp struct {
+		Tokens tokenPair `json:"tokens"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Tokens
+}
+
+func TestEmailVerification(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "verify@example.com")
+
+	raw, token := lastMail(t)
+	if !strings.Contains(raw, "To: verify@example.com") || !strings.Contains(raw, "http://frontend.test/verify-email?token=") {
+		t.Fatalf("unexpected verification email:\n%s", raw)
+	}
+
+	w := postJSON(r, "/auth/verify-email", gin.H{"token": "bogus"}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bogus token expected 400, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/verify-email", gin.H{"token": token}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify expected 200, got %d", w.Code)
+	}
+	var user struct {
+		EmailVerified bool `json:"email_verified"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &user)
+	if !user.EmailVerified {
+		t.Fatalf("expected email_verified=true")
+	}
+
+	// tokens are single use
+	w = postJSON(r, "/auth/verify-email", gin.H{"token": token}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("reused token expected 400, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/verify-email/send", nil, tokens.AccessToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("resend after verification expected 409, got %d", w.Code)
+	}
+}
+
+func TestVerificationRateLimit(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "limited@example.com")
+
+	// the registration email counts towards the limit of 3
+	for i := 0; i < 2; i++ {
+		w := postJSON(r, "/auth/verify-email/send", nil, tokens.AccessToken)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("resend %d expected 202, got %d", i, w.Code)
+		}
+	}
+	w := postJSON(r, "/auth/verify-email/send", nil, tokens.AccessToken)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("resend over limit expected 429, got %d", w.Code)
+	}
+	if n := mailCount(); n != 3 {
+		t.Fatalf("expected 3 emails, got %d", n)
+	}
+
+	// only the newest link works
+	_, latest := lastMail(t)
+	w = postJSON(r, "/auth/verify-email", gin.H{"token": latest}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("latest token expected 200, got %d", w.Code)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "reset@example.com")
+	before := mailCount()
+
+	// unknown addresses get the same answer and no email
+	w := postJSON(r, "/auth/password/forgot", gin.H{"email": "nobody@example.com"}, "")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("forgot for unknown address expected 202, got %d", w.Code)
+	}
+	if mailCount() != before {
+		t.Fatalf("no email expected for unknown address")
+	}
+
+	w = postJSON(r, "/auth/password/forgot", gin.H{"email": "reset@example.com"}, "")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("forgot expected 202, got %d", w.Code)
+	}
+	raw, token := lastMail(t)
+	if !strings.Contains(raw, "/reset-password?token=") {
+		t.Fatalf("unexpected reset email:\n%s", raw)
+	}
+
+	w = postJSON(r, "/auth/password/reset", gin.H{"token": token, "new_password": "short"}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("short password expected 400, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/password/reset", gin.H{"token": token, "new_password": "resetpass1"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset expected 200, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/password/reset", gin.H{"token": token, "new_password": "resetpass2"}, "")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("reused reset token expected 400, got %d", w.Code)
+	}
+
+	// sessions from before the reset are gone
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": tokens.RefreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("old refresh token expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/logout-all", nil, tokens.AccessToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("old access token expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/login", gin.H{"email": "reset@example.com", "password": "password123"}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("old password expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/login", gin.H{"email": "reset@example.com", "password": "resetpass1"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("new password expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccountStates(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	creatorToken := testTokenPrefix + creator.Email
+	fan := createUser(t, r)
+	fanToken := testTokenPrefix + fan.Email
+	postID := createPost(t, r, creator, false)
+	statusPath := func(u models.User) string { return "/admin/users/" + u.ID.String() + "/status" }
+	errorCode := func(bearer string) string {
+		w := call(r, http.MethodGet, "/notifications", bearer)
+		var body struct {
+			Code string `json:"code"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusForbidden {
+			return ""
+		}
+		return body.Code
+	}
+
+	cases := map[string]struct {
+		body gin.H
+		want int
+	}{
+		"unknown status":     {gin.H{"status": "frozen", "reason": "x"}, http.StatusBadRequest},
+		"missing reason":     {gin.H{"status": models.AccountBanned}, http.StatusBadRequest},
+		"suspension no end":  {gin.H{"status": models.AccountSuspended, "reason": "spam"}, http.StatusBadRequest},
+		"suspension in past": {gin.H{"status": models.AccountSuspended, "reason": "spam", "until": time.Now().Add(-time.Hour)}, http.StatusBadRequest},
+		"already active":     {gin.H{"status": models.AccountActive, "reason": "x"}, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		if w := call(r, http.MethodPut, statusPath(fan), "", tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if w := call(r, http.MethodPut, statusPath(fan), fanToken, gin.H{"status": models.AccountBanned, "reason": "x"}); w.Code != http.StatusForbidden {
+		t.Errorf("non-staff status change expected 403, got %d", w.Code)
+	}
+
+	// Banning locks the creator out and takes their content out of feeds
+	if !feedHas(t, r, fanToken, postID) {
+		t.Fatal("post expected in feed before the ban")
+	}
+	w := call(r, http.MethodPut, statusPath(creator), "", gin.H{"status": models.AccountBanned, "reason": "fraud"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("ban expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := errorCode(creatorToken); got != utils.CodeAccountBanned {
+		t.Errorf("banned user expected code %s, got %q", utils.CodeAccountBanned, got)
+	}
+	if feedHas(t, r, fanToken, postID) {
+		t.Error("banned creator's post must leave the feed")
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, fanToken); w.Code != http.StatusNotFound {
+		t.Errorf("banned creator's post expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/models/"+profile.ID.String(), fanToken); w.Code != http.StatusNotFound {
+		t.Errorf("banned creator's profile expected 404, got %d", w.Code)
+	}
+	w = call(r, http.MethodGet, "/models", fanToken)
+	var profiles []dto.ModelProfileResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &profiles)
+	for _, p := range profiles {
+		if p.ID == profile.ID {
+			t.Error("banned creator's profile must leave search")
+		}
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, ""); w.Code != http.StatusOK {
+		t.Errorf("staff expected to see the banned creator's post, got %d", w.Code)
+	}
+
+	// Reactivation brings everything back
+	call(r, http.MethodPut, statusPath(creator), "", gin.H{"status": models.AccountActive, "reason": "appeal granted"})
+	if got := errorCode(creatorToken); got != "" {
+		t.Errorf("reactivated user expected access, got code %q", got)
+	}
+	if !feedHas(t, r, fanToken, postID) {
+		t.Error("post expected back in the feed after reactivation")
+	}
+
+	// Deactivated accounts are locked too
+	call(r, http.MethodPut, statusPath(fan), "", gin.H{"status": models.AccountDeactivated, "reason": "user request"})
+	if got := errorCode(fanToken); got != utils.CodeAccountDeactivated {
+		t.Errorf("deactivated user expected code %s, got %q", utils.CodeAccountDeactivated, got)
+	}
+	call(r, http.MethodPut, statusPath(fan), "", gin.H{"status": models.AccountActive, "reason": "came back"})
+
+	// Suspensions end on their own
+	until := time.Now().Add(time.Hour)
+	w = call(r, http.MethodPut, statusPath(fan), "", gin.H{"status": models.AccountSuspended, "reason": "spam", "until": until})
+	if w.Code != http.StatusOK {
+		t.Fatalf("suspend expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := errorCode(fanToken); got != utils.CodeAccountSuspended {
+		t.Errorf("suspended user expected code %s, got %q", utils.CodeAccountSuspended, got)
+	}
+	svc := services.NewAccountStatusService(&repository.GormAccountStatusRepository{DB: database.DB}, &repository.GormUserRepository{DB: database.DB})
+	if n, err := svc.LiftExpiredSuspensions(); err != nil || n != 0 {
+		t.Fatalf("running suspension must not be lifted, got %d %v", n, err)
+	}
+	svc.Now = func() time.Time { return until.Add(time.Minute) }
+	if n, err := svc.LiftExpiredSuspensions(); err != nil || n != 1 {
+		t.Fatalf("expected one suspension lifted, got %d %v", n, err)
+	}
+	w = call(r, http.MethodGet, statusPath(fan), "")
+	var status dto.AccountStatusResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &status)
+	if status.Status != models.AccountActive || status.SuspendedUntil != nil {
+		t.Errorf("expected active account after the sweep, got %+v", status)
+	}
+
+	// Every change is recorded, newest first
+	w = call(r, http.MethodGet, statusPath(fan)+"/history", "")
+	var history []models.AccountStatusChange
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history) != 4 {
+		t.Fatalf("expected 4 changes, got %d: %s", len(history), w.Body.String())
+	}
+	if h := history[0]; h.From != models.AccountSuspended || h.To != models.AccountActive || h.ActorID != nil {
+		t.Errorf("expected system reactivation first, got %+v", h)
+	}
+	if h := history[1]; h.To != models.AccountSuspended || h.Until == nil || h.ActorID == nil || h.Reason != "spam" {
+		t.Errorf("expected staff suspension second, got %+v", h)
+	}
+}
+
+func TestAccountStatusSelfChange(t *testing.T) {
+	r := SetupRouter(t)
+	var admin models.User
+	database.DB.Where("email = ?", "admin@example.com").First(&admin)
+	w := call(r, http.MethodPut, "/admin/users/"+admin.ID.String()+"/status", "", gin.H{"status": models.AccountBanned, "reason": "oops"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("self ban expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestModelAnalytics(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	creatorToken := testTokenPrefix + creator.Email
+
+	w := call(r, http.MethodPost, "/posts", creatorToken, gin.H{"text": "premium", "isPremium": true, "price": 50})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create post expected 201, got %d", w.Code)
+	}
+	var post struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &post)
+
+	for i := 0; i < 2; i++ {
+		buyer := createUser(t, r)
+		database.DB.Model(&buyer).Update("balance", 50)
+		token := testTokenPrefix + buyer.Email
+		if w := call(r, http.MethodPost, "/purchases", token, gin.H{"post_id": post.ID}); w.Code != http.StatusCreated {
+			t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		if i == 0 {
+			call(r, http.MethodPost, "/posts/"+post.ID+"/like", token)
+			call(r, http.MethodPost, "/posts/"+post.ID+"/save", token)
+			database.DB.Create(&models.Follow{ID: uuid.New(), FollowerID: buyer.ID, FollowedID: creator.ID})
+		}
+	}
+
+	url := "/models/" + profile.ID.String() + "/analytics"
+	w = call(r, http.MethodGet, url, creatorToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("analytics expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report services.ModelAnalytics
+	json.Unmarshal(w.Body.Bytes(), &report)
+	want := services.AnalyticsTotals{Revenue: 100, NetRevenue: 80, Purchases: 2, Buyers: 2, NewFollowers: 1, Likes: 1, Saves: 1}
+	if report.Totals != want {
+		t.Fatalf("expected totals %+v, got %+v", want, report.Totals)
+	}
+	if len(report.Series) != 30 {
+		t.Fatalf("expected 30 days, got %d", len(report.Series))
+	}
+	today := report.Series[len(report.Series)-1]
+	if today.Period != time.Now().UTC().Format("2006-01-02") || today.Revenue != 100 || today.Likes != 1 {
+		t.Fatalf("unexpected last day %+v", today)
+	}
+	if len(report.TopPosts) != 1 || report.TopPosts[0].PostID.String() != post.ID || report.TopPosts[0].Purchases != 2 {
+		t.Fatalf("unexpected top posts %+v", report.TopPosts)
+	}
+
+	w = call(r, http.MethodGet, url+"?interval=month&from=2026-01-01&to=2026-12-31", creatorToken)
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || len(report.Series) != 12 || report.Series[1].Period != "2026-02-01" {
+		t.Fatalf("monthly analytics unexpected: %d %s", w.Code, w.Body.String())
+	}
+	w = call(r, http.MethodGet, url+"?interval=week&from=2026-10-01&to=2026-10-14", creatorToken)
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || len(report.Series) != 3 || report.Series[0].Period != "2026-09-28" {
+		t.Fatalf("weekly analytics unexpected: %d %s", w.Code, w.Body.String())
+	}
+
+	stranger := createUser(t, r)
+	for name, tc := range map[string]struct {
+		path   string
+		bearer string
+		want   int
+	}{
+		"staff":          {url, "", http.StatusOK},
+		"other user":     {url, testTokenPrefix + stranger.Email, http.StatusForbidden},
+		"bad interval":   {url + "?interval=hour", creatorToken, http.StatusBadRequest},
+		"bad date":       {url + "?from=yesterday", creatorToken, http.StatusBadRequest},
+		"reversed range": {url + "?from=2026-02-01&to=2026-01-01", creatorToken, http.StatusBadRequest},
+		"range too long": {url + "?from=2024-01-01&to=2026-01-01", creatorToken, http.StatusBadRequest},
+		"unknown model":  {"/models/" + uuid.NewString() + "/analytics", creatorToken, http.StatusNotFound},
+	} {
+		if w := call(r, http.MethodGet, tc.path, tc.bearer); w.Code != tc.want {
+			t.Fatalf("%s expected %d, got %d", name, tc.want, w.Code)
+		}
+	}
+}
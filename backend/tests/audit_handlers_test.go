@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type auditEntry struct {
+	Seq        int64                             `json:"seq"`
+	ActorID    *string                           `json:"actor_id"`
+	Action     string                            `json:"action"`
+	EntityType string                            `json:"entity_type"`
+	EntityID   string                            `json:"entity_id"`
+	RequestID  string                            `json:"request_id"`
+	Changes    map[string]map[string]interface{} `json:"changes"`
+}
+
+func auditLog(t *testing.T, r *gin.Engine, query url.Values) []auditEntry {
+	t.Helper()
+	w := call(r, http.MethodGet, "/admin/audit?"+query.Encode(), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("audit log expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var entries []auditEntry
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	return entries
+}
+
+func TestAuditLog(t *testing.T) {
+	r := SetupRouter(t)
+	creator, _ := createUserWithModel(t, r)
+	creatorToken := testTokenPrefix + creator.Email
+	target := createUser(t, r)
+	var admin models.User
+	database.DB.Where("email = ?", "admin@example.com").First(&admin)
+
+	// Staff changes are recorded with their diff, actor and request
+	w := call(r, http.MethodPut, "/admin/users/"+target.ID.String()+"/status", "", gin.H{"status": models.AccountBanned, "reason": "spam"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status change expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	requestID := w.Header().Get("X-Request-ID")
+	entries := auditLog(t, r, url.Values{"entity_type": {"user"}, "entity_id": {target.ID.String()}})
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry for the user, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Action != "account_status.change" || e.ActorID == nil || *e.ActorID != admin.ID.String() || e.RequestID == "" || e.RequestID != requestID {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if change, ok := e.Changes["status"]; !ok || change["from"] != models.AccountActive || change["to"] != models.AccountBanned {
+		t.Errorf("expected the status change in the diff, got %+v", e.Changes)
+	}
+	if len(auditLog(t, r, url.Values{"request_id": {requestID}})) != 1 {
+		t.Error("request_id filter expected to find the entry")
+	}
+
+	// Role grants and staff changes to other users' content
+	call(r, http.MethodPost, "/admin/users/"+target.ID.String()+"/roles", "", gin.H{"role": models.RoleModerator, "reason": "new hire"})
+	postID := createPost(t, r, creator, false)
+	// Users editing their own content are not staff actions
+	if w := call(r, http.MethodPut, "/posts/"+postID, creatorToken, gin.H{"text": "mine", "published_time": time.Now().Format(time.RFC3339), "model": gin.H{"nickname": creator.Nickname}}); w.Code != http.StatusOK {
+		t.Fatalf("own post edit expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if entries := auditLog(t, r, url.Values{"actor_id": {creator.ID.String()}}); len(entries) != 0 {
+		t.Errorf("own edits expected unrecorded, got %+v", entries)
+	}
+	if w := call(r, http.MethodDelete, "/posts/"+postID, ""); w.Code != http.StatusOK {
+		t.Fatalf("staff post delete expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if entries := auditLog(t, r, url.Values{"action": {"role.grant"}}); len(entries) != 1 || entries[0].Changes["rows"] == nil {
+		t.Errorf("expected the role grant with the changed rows, got %+v", entries)
+	}
+	if entries := auditLog(t, r, url.Values{"entity_type": {"post"}, "entity_id": {postID}}); len(entries) != 1 || entries[0].Action != "post.delete" || entries[0].Changes["deleted_at"] == nil {
+		t.Errorf("expected the staff post delete, got %+v", entries)
+	}
+
+	// Failed requests are not recorded either
+	call(r, http.MethodPost, "/admin/payouts/"+postID+"/approve", "")
+	if entries := auditLog(t, r, url.Values{"action": {"payout.approve"}}); len(entries) != 0 {
+		t.Errorf("failed request expected unrecorded, got %+v", entries)
+	}
+
+	if entries := auditLog(t, r, url.Values{"since": {time.Now().Add(time.Hour).Format(time.RFC3339)}}); len(entries) != 0 {
+		t.Errorf("since filter expected no entries, got %d", len(entries))
+	}
+	if w := call(r, http.MethodGet, "/admin/audit?since=yesterday", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid since expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/admin/audit", creatorToken); w.Code != http.StatusForbidden {
+		t.Errorf("non-staff expected 403, got %d", w.Code)
+	}
+
+	// Editing an entry breaks the chain from that entry on
+	verify := func() (valid bool, brokenAt *int64) {
+		w := call(r, http.MethodGet, "/admin/audit/verify", "")
+		var body struct {
+			Valid    bool   `json:"valid"`
+			Entries  int64  `json:"entries"`
+			BrokenAt *int64 `json:"broken_at"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body.Valid, body.BrokenAt
+	}
+	if valid, _ := verify(); !valid {
+		t.Fatal("untouched chain expected valid")
+	}
+	database.DB.Model(&models.AuditEntry{}).Where("seq = ?", e.Seq).Update("changes", `{"status":{}}`)
+	if valid, brokenAt := verify(); valid || brokenAt == nil || *brokenAt != e.Seq {
+		t.Errorf("expected the chain broken at %d, got %v %v", e.Seq, valid, brokenAt)
+	}
+}
+
+func TestAuditLogKeepsNoPersonalData(t *testing.T) {
+	r := SetupRouter(t)
+	target := createUser(t, r)
+	update := gin.H{"email": "renamed" + target.Email, "nickname": "renamed" + target.Nickname, "avatarUrl": "https://cdn.example.com/" + target.Nickname + ".png"}
+	if w := call(r, http.MethodPut, "/users/"+target.ID.String(), "", update); w.Code != http.StatusOK {
+		t.Fatalf("staff update expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The change is logged without its values
+	entries := auditLog(t, r, url.Values{"action": {"user.update"}, "entity_id": {target.ID.String()}})
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry for the update, got %+v", entries)
+	}
+	for _, field := range []string{"email", "nickname", "avatar_url"} {
+		if change := entries[0].Changes[field]; change == nil || change["from"] != "[redacted]" || change["to"] != "[redacted]" {
+			t.Errorf("expected %s changed and redacted, got %+v", field, entries[0].Changes[field])
+		}
+	}
+
+	svc := services.NewAccountDeletionService(&repository.GormAccountDeletionRepository{DB: database.DB}, nil)
+	if err := svc.Erase(target.ID); err != nil {
+		t.Fatalf("erase failed: %v", err)
+	}
+	var stored []models.AuditEntry
+	database.DB.Find(&stored)
+	for _, e := range stored {
+		for _, personal := range []string{target.Email, target.Nickname, update["email"].(string), update["nickname"].(string)} {
+			if strings.Contains(e.Changes, personal) {
+				t.Errorf("audit entry %d keeps %q after erasure: %s", e.Seq, personal, e.Changes)
+			}
+		}
+	}
+	w := call(r, http.MethodGet, "/admin/audit/verify", "")
+	var body struct {
+		Valid bool `json:"valid"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if !body.Valid {
+		t.Errorf("chain expected valid after erasure, got %s", w.Body.String())
+	}
+}
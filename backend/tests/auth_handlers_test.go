@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type tokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func TestNativeAuthFlow(t *testing.T) {
+	r := SetupRouter(t)
+
+	// register
+	w := postJSON(r, "/auth/register", gin.H{"email": "native@example.com", "nickname": "native", "password": "password123"}, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register expected 201, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/register", gin.H{"email": "native@example.com", "nickname": "native2", "password": "password123"}, "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate register expected 409, got %d", w.Code)
+	}
+
+	// login
+	w = postJSON(r, "/auth/login", gin.H{"email": "native@example.com", "password": "wrong-password"}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad login expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/login", gin.H{"email": "native@example.com", "password": "password123"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login expected 200, got %d", w.Code)
+	}
+	var login struct {
+		Tokens tokenPair `json:"tokens"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &login)
+
+	// the access token authenticates protected routes
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/purchases", nil)
+	req.Header.Set("Authorization", "Bearer "+login.Tokens.AccessToken)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("purchases with access token expected 200, got %d", w.Code)
+	}
+
+	// refresh rotates; reusing the old token revokes the family
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": login.Tokens.RefreshToken}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh expected 200, got %d", w.Code)
+	}
+	var rotated tokenPair
+	json.Unmarshal(w.Body.Bytes(), &rotated)
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": login.Tokens.RefreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": rotated.RefreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after reuse expected 401, got %d", w.Code)
+	}
+
+	// logout revokes the session
+	w = postJSON(r, "/auth/login", gin.H{"email": "native@example.com", "password": "password123"}, "")
+	json.Unmarshal(w.Body.Bytes(), &login)
+	w = postJSON(r, "/auth/logout", gin.H{"refresh_token": login.Tokens.RefreshToken}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout expected 200, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": login.Tokens.RefreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout expected 401, got %d", w.Code)
+	}
+
+	// password change invalidates old access tokens
+	w = postJSON(r, "/auth/password", gin.H{"current_password": "wrong-password", "new_password": "newpassword1"}, login.Tokens.AccessToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("password change with wrong password expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/password", gin.H{"current_password": "password123", "new_password": "newpassword1"}, login.Tokens.AccessToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("password change expected 200, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/logout-all", nil, login.Tokens.AccessToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("old access token expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/login", gin.H{"email": "native@example.com", "password": "newpassword1"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login with new password expected 200, got %d", w.Code)
+	}
+}
+
+func TestFirebaseUsersCannotLoginWithPassword(t *testing.T) {
+	r := SetupRouter(t)
+
+	// first Firebase request creates the account
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/purchases", nil)
+	req.Header.Set("Authorization", "Bearer "+testTokenPrefix+"fb@example.com")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("firebase request expected 200, got %d", w.Code)
+	}
+
+	w = postJSON(r, "/auth/login", gin.H{"email": "fb@example.com", "password": "changeme123"}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("login with placeholder password expected 401, got %d", w.Code)
+	}
+}
+
+// racingTokens makes every caller read the refresh token before any of them
+// rotates it, as concurrent refreshes with the same token can.
+type racingTokens struct {
+	*repository.GormRefreshTokenRepository
+	read *sync.WaitGroup
+}
+
+func (r racingTokens) FindByHash(hash string) (models.RefreshToken, error) {
+	token, err := r.GormRefreshTokenRepository.FindByHash(hash)
+	r.read.Done()
+	r.read.Wait()
+	return token, err
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "racer@example.com")
+	// in-memory SQLite is per connection
+	sqlDB, _ := database.DB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	var read, done sync.WaitGroup
+	read.Add(2)
+	svc := services.NewAuthService(
+		&repository.GormUserRepository{DB: database.DB},
+		racingTokens{&repository.GormRefreshTokenRepository{DB: database.DB}, &read},
+		&repository.GormTwoFactorRepository{DB: database.DB},
+	)
+	errs := make([]error, 2)
+	for i := range errs {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			_, errs[i] = svc.Refresh(tokens.RefreshToken, services.SessionMeta{})
+		}(i)
+	}
+	done.Wait()
+
+	won := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, services.ErrInvalidRefreshToken):
+			t.Fatalf("unexpected refresh error: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("expected one refresh to rotate the token, got %d", won)
+	}
+	// the loser counts as reuse, so no session of the family survives
+	var live int64
+	database.DB.Model(&models.RefreshToken{}).Where("revoked_at IS NULL").Count(&live)
+	if live != 0 {
+		t.Fatalf("expected the token family revoked, %d tokens still live", live)
+	}
+}
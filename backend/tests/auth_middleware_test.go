@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthModes(t *testing.T) {
+	r := SetupRouter(t)
+	user := createUser(t, r)
+
+	code := func(w *httptest.ResponseRecorder) string {
+		var body struct {
+			Code string `json:"code"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body.Code
+	}
+
+	// anonymous: public GETs pass, protected routes need auth
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/posts", nil)
+	asAnonymous(req)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("anonymous list posts expected 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/purchases", nil)
+	asAnonymous(req)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || code(w) != "auth_required" {
+		t.Fatalf("anonymous purchases expected 401 auth_required, got %d %q", w.Code, code(w))
+	}
+
+	// invalid token is rejected even on public routes
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/posts", nil)
+	req.Header.Set("Authorization", "Bearer garbage")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || code(w) != "invalid_token" {
+		t.Fatalf("invalid token expected 401 invalid_token, got %d %q", w.Code, code(w))
+	}
+
+	// regular user
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/purchases", nil)
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("user purchases expected 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/admin/models/x/portfolio/batch", nil)
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || code(w) != "forbidden" {
+		t.Fatalf("user on admin route expected 403 forbidden, got %d %q", w.Code, code(w))
+	}
+
+	// admin (default caller in tests)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/admin/models/x/portfolio/batch", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("admin on admin route expected to reach handler (400), got %d", w.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-backend/dto"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func feedHas(t *testing.T, r *gin.Engine, bearer, postID string) bool {
+	t.Helper()
+	w := call(r, http.MethodGet, "/posts", bearer)
+	var posts []dto.PostResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &posts)
+	for _, p := range posts {
+		if p.ID.String() == postID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBlocksAndMutes(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	creatorToken := testTokenPrefix + creator.Email
+	fan, other := createUser(t, r), createUser(t, r)
+	fanToken, otherToken := testTokenPrefix+fan.Email, testTokenPrefix+other.Email
+	postID := createPost(t, r, creator, false)
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), fanToken)
+	call(r, http.MethodPost, "/posts/"+postID+"/comments", otherToken, gin.H{"text": "first"})
+
+	if w := call(r, http.MethodPost, "/blocks/"+creator.ID.String(), creatorToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("self block expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/blocks/"+uuid.NewString(), fanToken); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user expected 404, got %d", w.Code)
+	}
+
+	// the creator blocked the fan: the follow is gone and contact fails both ways
+	if w := call(r, http.MethodPost, "/blocks/"+fan.ID.String(), creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("block expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if users := followList(t, r, "/following", fanToken); len(users) != 0 {
+		t.Fatalf("block expected to remove the follow, got %+v", users)
+	}
+	for name, req := range map[string]struct {
+		method, path string
+		body         gin.H
+	}{
+		"follow":  {http.MethodPost, "/follow/" + creator.ID.String(), nil},
+		"like":    {http.MethodPost, "/posts/" + postID + "/like", nil},
+		"comment": {http.MethodPost, "/posts/" + postID + "/comments", gin.H{"text": "hey"}},
+		"message": {http.MethodPost, "/conversations", gin.H{"user_id": creator.ID.String()}},
+		"tip":     {http.MethodPost, "/models/" + profile.ID.String() + "/tips", gin.H{"amount": 1}},
+	} {
+		if w := call(r, req.method, req.path, fanToken, req.body); w.Code != http.StatusForbidden {
+			t.Errorf("%s while blocked expected 403, got %d", name, w.Code)
+		}
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, fanToken); w.Code != http.StatusNotFound {
+		t.Fatalf("blocked post expected 404, got %d", w.Code)
+	}
+	if feedHas(t, r, fanToken, postID) || !feedHas(t, r, otherToken, postID) {
+		t.Fatalf("post expected hidden from the blocked fan only")
+	}
+	for _, path := range []string{"/models/" + profile.ID.String(), "/users/" + creator.ID.String() + "/model-profile"} {
+		if w := call(r, http.MethodGet, path, fanToken); w.Code != http.StatusNotFound {
+			t.Errorf("blocked creator's profile at %s expected 404, got %d", path, w.Code)
+		}
+		if w := call(r, http.MethodGet, path, otherToken); w.Code != http.StatusOK {
+			t.Errorf("profile at %s expected visible to others, got %d", path, w.Code)
+		}
+	}
+	w := call(r, http.MethodGet, "/models", fanToken)
+	var profiles []dto.ModelProfileResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &profiles)
+	for _, p := range profiles {
+		if p.ID == profile.ID {
+			t.Fatalf("blocked creator expected missing from discovery")
+		}
+	}
+	w = call(r, http.MethodGet, "/blocks", creatorToken)
+	var blocked []dto.RelatedUserDTO
+	json.Unmarshal(w.Body.Bytes(), &blocked)
+	if len(blocked) != 1 || blocked[0].ID != fan.ID {
+		t.Fatalf("block list expected the fan, got %+v", blocked)
+	}
+	if w := call(r, http.MethodDelete, "/blocks/"+fan.ID.String(), creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("unblock expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/follow/"+creator.ID.String(), fanToken); w.Code != http.StatusOK {
+		t.Fatalf("follow after unblock expected 200, got %d", w.Code)
+	}
+
+	// muting hides content and notifications from the muter only
+	if w := call(r, http.MethodPost, "/mutes/"+other.ID.String(), creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("mute expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/mutes/"+other.ID.String(), creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("repeated mute expected 200, got %d", w.Code)
+	}
+	call(r, http.MethodPost, "/posts/"+postID+"/like", otherToken)
+	if n := findNotification(inbox(t, r, creatorToken, ""), models.NotifyLike); n != nil {
+		t.Fatalf("muted user's like expected no notification, got %+v", n)
+	}
+	w = call(r, http.MethodGet, "/posts/"+postID, creatorToken)
+	var post models.Post
+	json.Unmarshal(w.Body.Bytes(), &post)
+	if len(post.Comments) != 0 {
+		t.Fatalf("muted user's comment expected hidden, got %+v", post.Comments)
+	}
+	w = call(r, http.MethodGet, "/posts/"+postID, fanToken)
+	json.Unmarshal(w.Body.Bytes(), &post)
+	if len(post.Comments) != 1 {
+		t.Fatalf("comment expected visible to others, got %+v", post.Comments)
+	}
+	w = call(r, http.MethodGet, "/mutes", creatorToken)
+	var muted []dto.RelatedUserDTO
+	json.Unmarshal(w.Body.Bytes(), &muted)
+	if len(muted) != 1 || muted[0].ID != other.ID {
+		t.Fatalf("mute list expected the other user, got %+v", muted)
+	}
+}
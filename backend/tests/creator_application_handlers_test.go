@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pngBytes is a 1x1 PNG, enough for content sniffing.
+var pngBytes = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+// submitApplication posts a creator application as a multipart form. Files
+// maps form field names to contents.
+func submitApplication(r *gin.Engine, bearer string, fields map[string]string, files map[string][]byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	for k, v := range files {
+		fw, _ := mw.CreateFormFile(k, k+".bin")
+		fw.Write(v)
+	}
+	mw.Close()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/creators/applications", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func applicationFields(dob string) map[string]string {
+	return map[string]string{
+		"legal_name":    "Jane Roe",
+		"date_of_birth": dob,
+		"country":       "de",
+		"name":          "Jane",
+		"bio":           "hello",
+	}
+}
+
+func TestCreatorApplicationWorkflow(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "applicant@example.com")
+	var applicant models.User
+	database.DB.Where("email = ?", "applicant@example.com").First(&applicant)
+	docs := map[string][]byte{"id_document": pngBytes, "selfie": pngBytes}
+	adult := time.Now().AddDate(-25, 0, 0).Format("2006-01-02")
+
+	// users can no longer make themselves models
+	w := call(r, http.MethodPost, "/models", tokens.AccessToken, gin.H{"user_id": applicant.ID, "name": "Jane"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("self-service model profile expected 403, got %d", w.Code)
+	}
+
+	if w := call(r, http.MethodGet, "/creators/applications/me", tokens.AccessToken); w.Code != http.StatusNotFound {
+		t.Fatalf("no application yet expected 404, got %d", w.Code)
+	}
+	for name, tc := range map[string]struct {
+		fields map[string]string
+		files  map[string][]byte
+		want   int
+	}{
+		"missing selfie": {applicationFields(adult), map[string][]byte{"id_document": pngBytes}, http.StatusBadRequest},
+		"not an image":   {applicationFields(adult), map[string][]byte{"id_document": []byte("plain text"), "selfie": pngBytes}, http.StatusBadRequest},
+		"underage":       {applicationFields(time.Now().AddDate(-17, 0, 0).Format("2006-01-02")), docs, http.StatusBadRequest},
+		"bad date":       {applicationFields("01/02/1990"), docs, http.StatusBadRequest},
+	} {
+		if w := submitApplication(r, tokens.AccessToken, tc.fields, tc.files); w.Code != tc.want {
+			t.Fatalf("%s expected %d, got %d: %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	w = submitApplication(r, tokens.AccessToken, applicationFields(adult), docs)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("submit expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var app models.CreatorApplication
+	json.Unmarshal(w.Body.Bytes(), &app)
+	if app.Status != models.ApplicationPending || app.Country != "DE" || len(app.Documents) != 2 {
+		t.Fatalf("unexpected application: %+v", app)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("storage_key")) || bytes.Contains(w.Body.Bytes(), []byte("creator-applications/")) {
+		t.Fatalf("storage keys must not be exposed: %s", w.Body.String())
+	}
+	if w := submitApplication(r, tokens.AccessToken, applicationFields(adult), docs); w.Code != http.StatusConflict {
+		t.Fatalf("second pending application expected 409, got %d", w.Code)
+	}
+
+	// the queue is staff only
+	if w := call(r, http.MethodGet, "/admin/creator-applications", tokens.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("applicant on review queue expected 403, got %d", w.Code)
+	}
+	w = call(r, http.MethodGet, "/admin/creator-applications", "")
+	var queue []models.CreatorApplication
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if w.Code != http.StatusOK || len(queue) != 1 || queue[0].ID != app.ID {
+		t.Fatalf("queue expected the application, got %d %s", w.Code, w.Body.String())
+	}
+	appURL := "/admin/creator-applications/" + app.ID.String()
+	w = call(r, http.MethodGet, appURL+"/documents/"+app.Documents[0].ID.String(), "")
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), pngBytes) || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("document download failed: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// rejection needs a reason, which the applicant then sees
+	if w := call(r, http.MethodPost, appURL+"/reject", "", gin.H{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("reject without reason expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, appURL+"/reject", "", gin.H{"reason": "selfie is blurry"}); w.Code != http.StatusOK {
+		t.Fatalf("reject expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, appURL+"/approve", ""); w.Code != http.StatusConflict {
+		t.Fatalf("approving a reviewed application expected 409, got %d", w.Code)
+	}
+	w = call(r, http.MethodGet, "/creators/applications/me", tokens.AccessToken)
+	json.Unmarshal(w.Body.Bytes(), &app)
+	if app.Status != models.ApplicationRejected || app.ReviewReason != "selfie is blurry" {
+		t.Fatalf("applicant should see the rejection, got %+v", app)
+	}
+
+	// apply again and get approved
+	w = submitApplication(r, tokens.AccessToken, applicationFields(adult), docs)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("resubmit expected 201, got %d", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &app)
+	if w := call(r, http.MethodPost, "/admin/creator-applications/"+app.ID.String()+"/approve", ""); w.Code != http.StatusOK {
+		t.Fatalf("approve expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var profile models.ModelProfile
+	if err := database.DB.Where("user_id = ?", applicant.ID).First(&profile).Error; err != nil || !profile.Verified() || profile.Name != "Jane" {
+		t.Fatalf("approval should create a verified profile, got %+v (%v)", profile, err)
+	}
+	var role models.UserRole
+	if err := database.DB.Where("user_id = ? AND role = ?", applicant.ID, models.RoleCreator).First(&role).Error; err != nil {
+		t.Fatalf("approval should grant the creator role: %v", err)
+	}
+	if w := submitApplication(r, tokens.AccessToken, applicationFields(adult), docs); w.Code != http.StatusConflict {
+		t.Fatalf("verified creator applying again expected 409, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/posts", tokens.AccessToken, gin.H{"text": "premium", "isPremium": true}); w.Code != http.StatusCreated {
+		t.Fatalf("verified creator premium post expected 201, got %d", w.Code)
+	}
+}
+
+func TestPremiumPostsNeedVerifiedCreator(t *testing.T) {
+	r := SetupRouter(t)
+	user, model := createUserWithModel(t, r)
+
+	body, _ := json.Marshal(gin.H{"text": "premium", "isPremium": true})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	var resp struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusForbidden || resp.Code != "creator_not_verified" {
+		t.Fatalf("unverified premium post expected 403 creator_not_verified, got %d %s", w.Code, w.Body.String())
+	}
+
+	// free posts are fine, but cannot be turned premium
+	postID := createPost(t, r, user, false)
+	body, _ = json.Marshal(gin.H{"text": "now premium", "isPremium": true, "published_time": time.Now().Format(time.RFC3339)})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPut, "/posts/"+postID, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("making a post premium while unverified expected 403, got %d", w.Code)
+	}
+
+	verifyModel(t, model)
+	createPost(t, r, user, true)
+}
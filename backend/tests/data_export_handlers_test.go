@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+	"go-backend/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// waitForExport polls the user's exports until the newest one is built.
+func waitForExport(t *testing.T, r *gin.Engine, bearer string) dto.DataExportResponseDTO {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		w := call(r, http.MethodGet, "/me/exports", bearer)
+		var exports []dto.DataExportResponseDTO
+		json.Unmarshal(w.Body.Bytes(), &exports)
+		if len(exports) > 0 && exports[0].Status != models.ExportPending {
+			return exports[0]
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("data export not built in time")
+	return dto.DataExportResponseDTO{}
+}
+
+func TestDataExport(t *testing.T) {
+	r := SetupRouter(t)
+	creator, _ := createUserWithModel(t, r)
+	token := testTokenPrefix + creator.Email
+	fan := createUser(t, r)
+	fanToken := testTokenPrefix + fan.Email
+	postID := createPost(t, r, creator, false)
+	call(r, http.MethodPost, "/posts/"+postID+"/like", token)
+	call(r, http.MethodPost, "/posts/"+postID+"/comments", token, gin.H{"text": "my own comment"})
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), fanToken)
+
+	w := call(r, http.MethodPost, "/me/export", token)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("export expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	export := waitForExport(t, r, token)
+	if export.Status != models.ExportReady || export.DownloadURL == "" || export.ExpiresAt == nil {
+		t.Fatalf("expected a ready export with a link, got %+v", export)
+	}
+	if findNotification(inbox(t, r, token, ""), models.NotifyDataExport) == nil {
+		t.Error("expected a notification once the export is ready")
+	}
+
+	// The link works without a session
+	w = call(r, http.MethodGet, export.DownloadURL, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("download expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("download is not a zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	for _, name := range []string{"profile.json", "purchases.json", "payments.json", "following.json", "followers.json",
+		"likes.json", "saved_posts.json", "comments.json", "referrals.json", "posts.json", "videos.json", "images.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("archive expected to contain %s", name)
+		}
+	}
+	if !strings.Contains(files["profile.json"], creator.Email) {
+		t.Errorf("profile expected to hold the user's email, got %s", files["profile.json"])
+	}
+	if !strings.Contains(files["posts.json"], postID) || !strings.Contains(files["likes.json"], postID) {
+		t.Error("posts and likes expected to reference the post")
+	}
+	if !strings.Contains(files["comments.json"], "my own comment") {
+		t.Errorf("comments expected in the archive, got %s", files["comments.json"])
+	}
+	if !strings.Contains(files["followers.json"], fan.ID.String()) {
+		t.Errorf("followers expected in the archive, got %s", files["followers.json"])
+	}
+	if strings.Contains(files["profile.json"], "password") {
+		t.Error("archive must not contain the password hash")
+	}
+
+	// Tampered and expired links
+	if w := call(r, http.MethodGet, strings.Replace(export.DownloadURL, "signature=", "signature=0", 1), ""); w.Code != http.StatusForbidden {
+		t.Errorf("tampered link expected 403, got %d", w.Code)
+	}
+	store, _ := storage.Private()
+	svc := services.NewDataExportService(&repository.GormDataExportRepository{DB: database.DB}, store, nil)
+	svc.Now = func() time.Time { return export.ExpiresAt.Add(time.Minute) }
+	link, _ := url.Parse(export.DownloadURL)
+	if _, _, err := svc.Open(t.Context(), export.ID, link.Query().Get("expires"), link.Query().Get("signature")); err != services.ErrExportExpired {
+		t.Errorf("expired link expected ErrExportExpired, got %v", err)
+	}
+	if n, err := svc.RemoveExpired(); err != nil || n != 1 {
+		t.Fatalf("expected the expired export removed, got %d %v", n, err)
+	}
+	if w := call(r, http.MethodGet, export.DownloadURL, ""); w.Code != http.StatusNotFound {
+		t.Errorf("removed export expected 404, got %d", w.Code)
+	}
+
+	// One export at a time
+	database.DB.Create(&models.DataExport{UserID: fan.ID, Status: models.ExportPending})
+	if w := call(r, http.MethodPost, "/me/export", fanToken); w.Code != http.StatusConflict {
+		t.Errorf("second export while one is pending expected 409, got %d", w.Code)
+	}
+}
+
+// panickingStore fails every write with a panic.
+type panickingStore struct{ storage.Store }
+
+func (panickingStore) Put(context.Context, string, io.Reader) error { panic("disk on fire") }
+
+func TestLostDataExports(t *testing.T) {
+	r := SetupRouter(t)
+	user := createUser(t, r)
+	token := testTokenPrefix + user.Email
+
+	// an export whose build was lost no longer blocks a new request
+	stale := models.DataExport{UserID: user.ID, Status: models.ExportPending, CreatedAt: time.Now().Add(-services.ExportTimeout - time.Minute)}
+	database.DB.Create(&stale)
+	if w := call(r, http.MethodPost, "/me/export", token); w.Code != http.StatusAccepted {
+		t.Fatalf("export next to a stale one expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	waitForExport(t, r, token)
+
+	store, _ := storage.Private()
+	svc := services.NewDataExportService(&repository.GormDataExportRepository{DB: database.DB}, store, nil)
+	fresh := models.DataExport{UserID: user.ID, Status: models.ExportPending, CreatedAt: time.Now()}
+	database.DB.Create(&fresh)
+	if n, err := svc.FailStale(); err != nil || n != 1 {
+		t.Fatalf("expected the stale export failed, got %d %v", n, err)
+	}
+	database.DB.First(&stale, "id = ?", stale.ID)
+	database.DB.First(&fresh, "id = ?", fresh.ID)
+	if stale.Status != models.ExportFailed || stale.Error == "" || fresh.Status != models.ExportPending {
+		t.Fatalf("unexpected states %s %s", stale.Status, fresh.Status)
+	}
+
+	// a panicking build fails the export instead of leaving it pending
+	svc.Store = panickingStore{}
+	svc.Run(fresh)
+	database.DB.First(&fresh, "id = ?", fresh.ID)
+	if fresh.Status != models.ExportFailed || fresh.CompletedAt == nil {
+		t.Fatalf("panicked export expected failed, got %+v", fresh)
+	}
+}
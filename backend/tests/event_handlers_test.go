@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type sseEvent struct {
+	ID, Type, Data string
+}
+
+// sseStream is an open /events connection.
+type sseStream struct {
+	events chan sseEvent
+	pings  chan struct{}
+	cancel context.CancelFunc
+}
+
+// openStream connects as the owner of token, passing it as access_token like
+// a browser EventSource would.
+func openStream(t *testing.T, srv *httptest.Server, token, lastEventID string) *sseStream {
+	t.Helper()
+	return openEvents(t, srv.URL+"/events?access_token="+token, lastEventID)
+}
+
+// openEvents connects to the Server-Sent Events endpoint at url.
+func openEvents(t *testing.T, url, lastEventID string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req.Header.Set(anonymousHeader, "1")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		cancel()
+		t.Fatalf("stream expected 200 text/event-stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	s := &sseStream{events: make(chan sseEvent, 16), pings: make(chan struct{}, 16), cancel: cancel}
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		var e sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if e.Type != "" {
+					s.events <- e
+				}
+				e = sseEvent{}
+			case strings.HasPrefix(line, ": ping"):
+				select {
+				case s.pings <- struct{}{}:
+				default:
+				}
+			case strings.HasPrefix(line, "id: "):
+				e.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				e.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				e.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	t.Cleanup(cancel)
+	return s
+}
+
+func (s *sseStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	select {
+	case e := <-s.events:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return sseEvent{}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	r := SetupRouter(t)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close) // runs after the streams' own cleanups cancel them
+
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	creatorToken := testTokenPrefix + creator.Email
+	fan := createUser(t, r)
+	fanToken := testTokenPrefix + fan.Email
+	database.DB.Model(&fan).Update("balance", 10)
+	w := call(r, http.MethodPost, "/posts", creatorToken, gin.H{"text": "premium", "isPremium": true, "price": 4})
+	var post struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &post)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set(anonymousHeader, "1")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("stream without token expected 401, got %v %v", resp, err)
+	}
+
+	creatorStream := openStream(t, srv, creatorToken, "")
+	fanStream := openStream(t, srv, fanToken, "")
+	select {
+	case <-creatorStream.pings:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a heartbeat on an idle stream")
+	}
+
+	call(r, http.MethodPost, "/posts/"+post.ID+"/like", fanToken)
+	e := creatorStream.next(t)
+	if e.Type != "notification" || !strings.Contains(e.Data, fan.Nickname+" liked your post") {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	// the buyer gets the purchase and the new balance
+	creatorStream.cancel()
+	if w := call(r, http.MethodPost, "/purchases", fanToken, gin.H{"post_id": post.ID}); w.Code != http.StatusCreated {
+		t.Fatalf("buy expected 201, got %d", w.Code)
+	}
+	if e := fanStream.next(t); e.Type != "purchase" || !strings.Contains(e.Data, post.ID) {
+		t.Fatalf("expected purchase event, got %+v", e)
+	}
+	var balance struct {
+		Balance int `json:"balance"`
+	}
+	e2 := fanStream.next(t)
+	json.Unmarshal([]byte(e2.Data), &balance)
+	if e2.Type != "balance" || balance.Balance != 6 {
+		t.Fatalf("expected balance 6, got %+v", e2)
+	}
+
+	// reconnecting replays what the creator missed
+	replayed := openStream(t, srv, creatorToken, e.ID).next(t)
+	if replayed.Type != "notification" || !strings.Contains(replayed.Data, "bought your post") {
+		t.Fatalf("expected the missed purchase notification, got %+v", replayed)
+	}
+	var notification models.Notification
+	database.DB.Where("user_id = ? AND type = ?", creator.ID, models.NotifyPurchase).First(&notification)
+	if !strings.Contains(replayed.Data, notification.ID.String()) {
+		t.Fatalf("replayed event should carry the notification, got %s", replayed.Data)
+	}
+}
@@ -1,9 +1,18 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func TestFollowHandlers(t *testing.T) {
@@ -35,3 +44,153 @@ func TestFollowHandlers(t *testing.T) {
 		t.Fatalf("followers expected 200, got %d", w.Code)
 	}
 }
+
+func followList(t *testing.T, r *gin.Engine, path, bearer string) []dto.FollowUserDTO {
+	t.Helper()
+	w := call(r, http.MethodGet, path, bearer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s expected 200, got %d: %s", path, w.Code, w.Body.String())
+	}
+	var users []dto.FollowUserDTO
+	json.Unmarshal(w.Body.Bytes(), &users)
+	return users
+}
+
+func TestFollowGraph(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	a, b := createUser(t, r), createUser(t, r)
+	aToken, bToken := testTokenPrefix+a.Email, testTokenPrefix+b.Email
+
+	if w := call(r, http.MethodPost, "/follow/"+a.ID.String(), aToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("self follow expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/follow/"+uuid.NewString(), aToken); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user expected 404, got %d", w.Code)
+	}
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), aToken)
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), aToken) // already following
+	call(r, http.MethodPost, "/follow/"+creator.ID.String(), bToken)
+	call(r, http.MethodPost, "/follow/"+b.ID.String(), aToken)
+	var n int64
+	database.DB.Model(&models.Follow{}).Where("follower_id = ? AND followed_id = ?", a.ID, creator.ID).Count(&n)
+	if n != 1 {
+		t.Fatalf("expected one follow row, got %d", n)
+	}
+
+	// public lists, newest first, with the viewer's flags
+	followers := followList(t, r, "/users/"+creator.ID.String()+"/followers", bToken)
+	if len(followers) != 2 || followers[0].ID != b.ID || followers[1].ID != a.ID {
+		t.Fatalf("unexpected followers %+v", followers)
+	}
+	if followers[1].FollowingCount != 2 || followers[0].FollowersCount != 1 || followers[0].IsFollowing || followers[1].IsFollowing {
+		t.Fatalf("unexpected follower summaries %+v", followers)
+	}
+	following := followList(t, r, "/users/"+a.ID.String()+"/following", aToken)
+	if len(following) != 2 || !following[0].IsFollowing || !following[1].IsFollowing {
+		t.Fatalf("unexpected following %+v", following)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/users/"+a.ID.String()+"/following?limit=1", nil)
+	asAnonymous(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &following)
+	if w.Code != http.StatusOK || len(following) != 1 || following[0].IsFollowing {
+		t.Fatalf("anonymous page expected one unflagged user, got %d %+v", w.Code, following)
+	}
+	if mine := followList(t, r, "/following", bToken); len(mine) != 1 || mine[0].ID != creator.ID {
+		t.Fatalf("unexpected /following %+v", mine)
+	}
+	if mine := followList(t, r, "/followers", bToken); len(mine) != 1 || mine[0].ID != a.ID {
+		t.Fatalf("unexpected /followers %+v", mine)
+	}
+	if w := call(r, http.MethodGet, "/users/"+uuid.NewString()+"/followers", aToken); w.Code != http.StatusNotFound {
+		t.Fatalf("followers of unknown user expected 404, got %d", w.Code)
+	}
+
+	// counts and flags on user and model responses
+	w = call(r, http.MethodGet, "/users/"+creator.ID.String(), aToken)
+	var user dto.UserResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &user)
+	if user.FollowersCount != 2 || user.FollowingCount != 0 || !user.IsFollowing {
+		t.Fatalf("unexpected user follow stats %+v", user)
+	}
+	w = call(r, http.MethodGet, "/models/"+profile.ID.String(), aToken)
+	var model dto.ModelProfileResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &model)
+	if model.FollowersCount != 2 || !model.IsFollowing {
+		t.Fatalf("unexpected model follow stats %+v", model)
+	}
+
+	call(r, http.MethodDelete, "/follow/"+creator.ID.String(), aToken)
+	w = call(r, http.MethodGet, "/models/"+profile.ID.String(), aToken)
+	json.Unmarshal(w.Body.Bytes(), &model)
+	if model.FollowersCount != 1 || model.IsFollowing {
+		t.Fatalf("after unfollow unexpected model follow stats %+v", model)
+	}
+}
+
+func TestFollowListsLeaveOutHiddenUsers(t *testing.T) {
+	r := SetupRouter(t)
+	owner := createUser(t, r)
+	ownerToken := testTokenPrefix + owner.Email
+	viewer := createUser(t, r)
+	viewerToken := testTokenPrefix + viewer.Email
+	visible := createUser(t, r)
+
+	// every user follows owner and is followed back before the case applies
+	follow := func(t *testing.T, u models.User) {
+		t.Helper()
+		for _, w := range []int{
+			call(r, http.MethodPost, "/follow/"+owner.ID.String(), testTokenPrefix+u.Email).Code,
+			call(r, http.MethodPost, "/follow/"+u.ID.String(), ownerToken).Code,
+		} {
+			if w != http.StatusOK {
+				t.Fatalf("follow expected 200, got %d", w)
+			}
+		}
+	}
+	follow(t, visible)
+
+	cases := map[string]func(t *testing.T, u models.User){
+		"banned": func(t *testing.T, u models.User) {
+			database.DB.Model(&u).Update("status", models.AccountBanned)
+		},
+		"deactivated": func(t *testing.T, u models.User) {
+			database.DB.Model(&u).Update("status", models.AccountDeactivated)
+		},
+		"deleted": func(t *testing.T, u models.User) {
+			database.DB.Delete(&u)
+		},
+		"erased": func(t *testing.T, u models.User) {
+			database.DB.Model(&u).Updates(map[string]interface{}{"status": models.AccountDeactivated, "deleted_at": time.Now(), "anonymized_at": time.Now()})
+		},
+		"blocked by the viewer": func(t *testing.T, u models.User) {
+			if w := call(r, http.MethodPost, "/blocks/"+u.ID.String(), viewerToken); w.Code != http.StatusOK {
+				t.Fatalf("block expected 200, got %d", w.Code)
+			}
+		},
+		"blocking the viewer": func(t *testing.T, u models.User) {
+			if w := call(r, http.MethodPost, "/blocks/"+viewer.ID.String(), testTokenPrefix+u.Email); w.Code != http.StatusOK {
+				t.Fatalf("block expected 200, got %d", w.Code)
+			}
+		},
+	}
+	for name, apply := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := createUser(t, r)
+			follow(t, u)
+			apply(t, u)
+			for _, list := range []string{"followers", "following"} {
+				users := followList(t, r, "/users/"+owner.ID.String()+"/"+list, viewerToken)
+				found := map[uuid.UUID]bool{}
+				for _, f := range users {
+					found[f.ID] = true
+				}
+				if found[u.ID] || !found[visible.ID] {
+					t.Errorf("%s: expected only visible users, got %+v", list, users)
+				}
+			}
+		})
+	}
+}
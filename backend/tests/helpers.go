@@ -6,15 +6,82 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"testing"
 	"time"
 
+	"go-backend/database"
 	"go-backend/models"
+	"go-backend/tracking"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	// testTokenPrefix marks bearer tokens accepted by fakeVerifier.
+	testTokenPrefix = "test-token:"
+	// anonymousHeader disables the default admin user in SetupRouter.
+	anonymousHeader = "X-Test-Anonymous"
+)
+
+// asUser authenticates the request as the given user.
+func asUser(req *http.Request, u models.User) {
+	req.Header.Set("Authorization", "Bearer "+testTokenPrefix+u.Email)
+}
+
+// asAnonymous makes the request carry no credentials at all.
+func asAnonymous(req *http.Request) {
+	req.Header.Set(anonymousHeader, "1")
+}
+
+func postJSON(r *gin.Engine, path string, payload interface{}, bearer string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	} else {
+		asAnonymous(req)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// mailDir receives the emails sent during the current test.
+var mailDir string
+
+var mailTokenRe = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// lastMail returns the raw text of the most recent email and the token in its link.
+func lastMail(t *testing.T) (string, string) {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(mailDir, "*.eml"))
+	if len(files) == 0 {
+		t.Fatalf("no email was sent")
+	}
+	sort.Strings(files)
+	raw, err := os.ReadFile(files[len(files)-1])
+	if err != nil {
+		t.Fatalf("read email: %v", err)
+	}
+	m := mailTokenRe.FindStringSubmatch(string(raw))
+	if m == nil {
+		t.Fatalf("no token in email:\n%s", raw)
+	}
+	return string(raw), m[1]
+}
+
+// mailCount returns how many emails were sent during the current test.
+func mailCount() int {
+	files, _ := filepath.Glob(filepath.Join(mailDir, "*.eml"))
+	return len(files)
+}
+
 func createUser(t *testing.T, r *gin.Engine) models.User {
 	t.Helper()
 	email := fmt.Sprintf("u%v@example.com", time.Now().UnixNano())
@@ -52,8 +119,41 @@ func createModel(t *testing.T, r *gin.Engine, userID uuid.UUID) models.ModelProf
 	return m
 }
 
+// verifyModel marks the profile as a verified creator, as an approved
+// creator application would.
+func verifyModel(t *testing.T, m models.ModelProfile) {
+	t.Helper()
+	if err := database.DB.Model(&m).Update("verified_at", time.Now()).Error; err != nil {
+		t.Fatalf("verify model: %v", err)
+	}
+}
+
 func createUserWithModel(t *testing.T, r *gin.Engine) (models.User, models.ModelProfile) {
+	t.Helper()
 	u := createUser(t, r)
 	m := createModel(t, r, u.ID)
 	return u, m
 }
+
+func createPost(t *testing.T, r *gin.Engine, author models.User, premium bool) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"text": "post", "isPremium": premium})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, author)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create post expected 201, got %d", w.Code)
+	}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ID
+}
+
+// flushViews writes the queued view and watch-progress events.
+func flushViews() {
+	tracking.Default().Flush()
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func messages(t *testing.T, r *gin.Engine, bearer, convID string) []dto.MessageResponseDTO {
+	t.Helper()
+	w := call(r, http.MethodGet, "/conversations/"+convID+"/messages", bearer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("messages expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var items []dto.MessageResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &items)
+	return items
+}
+
+func TestDirectMessages(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	creatorToken := testTokenPrefix + creator.Email
+	fan, other := createUser(t, r), createUser(t, r)
+	fanToken, otherToken := testTokenPrefix+fan.Email, testTokenPrefix+other.Email
+
+	// fans can only talk to creators
+	if w := call(r, http.MethodPost, "/conversations", fanToken, gin.H{"user_id": other.ID.String()}); w.Code != http.StatusForbidden {
+		t.Fatalf("fan to fan expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/conversations", fanToken, gin.H{"user_id": fan.ID.String()}); w.Code != http.StatusBadRequest {
+		t.Fatalf("self conversation expected 400, got %d", w.Code)
+	}
+	w := call(r, http.MethodPost, "/conversations", fanToken, gin.H{"user_id": creator.ID.String()})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("open expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var conv dto.ConversationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &conv)
+	if conv.With.ID != creator.ID {
+		t.Fatalf("expected conversation with the creator, got %+v", conv.With)
+	}
+	// the creator reopening it gets the same conversation
+	w = call(r, http.MethodPost, "/conversations", creatorToken, gin.H{"user_id": fan.ID.String()})
+	var same dto.ConversationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &same)
+	if w.Code != http.StatusOK || same.ID != conv.ID {
+		t.Fatalf("reopen expected 200 with %s, got %d %s", conv.ID, w.Code, same.ID)
+	}
+	convID := conv.ID.String()
+	messagesURL := "/conversations/" + convID + "/messages"
+
+	if w := call(r, http.MethodPost, messagesURL, fanToken, gin.H{"text": "  "}); w.Code != http.StatusBadRequest {
+		t.Fatalf("empty message expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, messagesURL, fanToken, gin.H{"text": "hi"}); w.Code != http.StatusCreated {
+		t.Fatalf("send expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodGet, messagesURL, otherToken); w.Code != http.StatusNotFound {
+		t.Fatalf("outsider expected 404, got %d", w.Code)
+	}
+
+	// paid messages need a verified creator and an attachment
+	paid := gin.H{"text": "just for you", "price": 7, "attachments": []gin.H{{"type": "photo", "url": "https://cdn.example.com/p.jpg"}}}
+	if w := call(r, http.MethodPost, messagesURL, creatorToken, paid); w.Code != http.StatusForbidden {
+		t.Fatalf("unverified paid message expected 403, got %d", w.Code)
+	}
+	verifyModel(t, profile)
+	if w := call(r, http.MethodPost, messagesURL, creatorToken, gin.H{"text": "no media", "price": 7}); w.Code != http.StatusBadRequest {
+		t.Fatalf("paid message without attachments expected 400, got %d", w.Code)
+	}
+	w = call(r, http.MethodPost, messagesURL, creatorToken, paid)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("paid message expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var sent dto.MessageResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &sent)
+	if sent.Locked || len(sent.Attachments) != 1 {
+		t.Fatalf("sender expected to see the attachment, got %+v", sent)
+	}
+
+	// the fan sees the teaser without the attachment
+	items := messages(t, r, fanToken, convID)
+	if len(items) != 2 || items[0].ID != sent.ID || !items[0].Locked || len(items[0].Attachments) != 0 || items[0].AttachmentCount != 1 {
+		t.Fatalf("fan expected a locked paid message first, got %+v", items)
+	}
+	w = call(r, http.MethodGet, "/conversations", fanToken)
+	var convs []dto.ConversationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &convs)
+	if len(convs) != 1 || convs[0].Unread != 1 || convs[0].LastMessage == nil || !convs[0].LastMessage.Locked {
+		t.Fatalf("fan expected one conversation with one unread locked message, got %+v", convs)
+	}
+
+	// read receipts
+	if w := call(r, http.MethodPost, "/conversations/"+convID+"/read", creatorToken); w.Code != http.StatusNoContent {
+		t.Fatalf("read expected 204, got %d", w.Code)
+	}
+	if items := messages(t, r, fanToken, convID); !items[1].Read {
+		t.Fatalf("fan's message expected read, got %+v", items[1])
+	}
+
+	// unlocking goes through the balance and credits the creator
+	unlockURL := "/messages/" + sent.ID.String() + "/unlock"
+	if w := call(r, http.MethodPost, unlockURL, fanToken); w.Code != http.StatusPaymentRequired {
+		t.Fatalf("unlock without balance expected 402, got %d", w.Code)
+	}
+	database.DB.Model(&fan).Update("balance", 10)
+	w = call(r, http.MethodPost, unlockURL, fanToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlock expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var unlocked dto.MessageResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &unlocked)
+	if unlocked.Locked || len(unlocked.Attachments) != 1 {
+		t.Fatalf("unlocked message expected its attachment, got %+v", unlocked)
+	}
+	if w := call(r, http.MethodPost, unlockURL, fanToken); w.Code != http.StatusConflict {
+		t.Fatalf("second unlock expected 409, got %d", w.Code)
+	}
+	var balance models.User
+	database.DB.First(&balance, "id = ?", fan.ID)
+	if balance.Balance != 3 {
+		t.Fatalf("fan balance expected 3, got %d", balance.Balance)
+	}
+	var earning models.Earning
+	if err := database.DB.First(&earning, "source = ? AND creator_id = ?", models.EarningMessage, creator.ID).Error; err != nil || earning.Gross != 7 {
+		t.Fatalf("creator expected a message earning of 7, got %+v (%v)", earning, err)
+	}
+
+	// blocking stops both sides until lifted
+	blockURL := "/conversations/" + convID + "/block"
+	if w := call(r, http.MethodPost, blockURL, fanToken); w.Code != http.StatusNoContent {
+		t.Fatalf("block expected 204, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, messagesURL, creatorToken, gin.H{"text": "hello?"}); w.Code != http.StatusForbidden {
+		t.Fatalf("send to blocker expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, messagesURL, fanToken, gin.H{"text": "hello?"}); w.Code != http.StatusForbidden {
+		t.Fatalf("send by blocker expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodDelete, blockURL, fanToken); w.Code != http.StatusNoContent {
+		t.Fatalf("unblock expected 204, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, messagesURL, creatorToken, gin.H{"text": "hello again"}); w.Code != http.StatusCreated {
+		t.Fatalf("send after unblock expected 201, got %d", w.Code)
+	}
+}
@@ -0,0 +1,395 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// replayMigrations builds the schema the up migrations in dir leave behind by
+// replaying their DDL, without a database. It knows the statements the
+// migrations use and fails on any other, so a new kind of statement has to
+// be taught here instead of being skipped. Data statements are ignored.
+func replayMigrations(dir string) (*dbSchema, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	s := &dbSchema{Columns: map[string]map[string]dbColumn{}, Indexes: map[string][]dbIndex{}}
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range splitStatements(sqlComment.ReplaceAllString(string(raw), "")) {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" {
+				continue
+			}
+			if err := s.apply(stmt); err != nil {
+				return nil, fmt.Errorf("%s: %w in %q", filepath.Base(p), err, firstLine(stmt))
+			}
+		}
+	}
+	return s, nil
+}
+
+var (
+	sqlComment  = regexp.MustCompile(`--[^\n]*`)
+	createTable = regexp.MustCompile(`(?is)^CREATE TABLE (IF NOT EXISTS )?(\w+)\s*\((.*)\)$`)
+	alterTable  = regexp.MustCompile(`(?is)^ALTER TABLE (?:IF EXISTS )?(\w+)\s+(.*)$`)
+	createIndex = regexp.MustCompile(`(?is)^CREATE (UNIQUE )?INDEX (IF NOT EXISTS )?(\w+) ON (\w+)(?: USING \w+)?\s*\(([^)]*)\)(.*)$`)
+	dropIndex   = regexp.MustCompile(`(?i)^DROP INDEX (?:IF EXISTS )?(\w+)$`)
+	dropTable   = regexp.MustCompile(`(?i)^DROP TABLE (?:IF EXISTS )?(\w+)(?: CASCADE)?$`)
+	dataStmt    = regexp.MustCompile(`(?i)^(CREATE EXTENSION|INSERT|UPDATE|DELETE|SELECT)\b`)
+	doBlock     = regexp.MustCompile(`(?is)^DO\s+\$\$.*\$\$$`)
+
+	columnDef  = regexp.MustCompile(`(?is)^"?(\w+)"?\s+(TIMESTAMP WITH(?:OUT)? TIME ZONE|CHARACTER VARYING\s*\(\d+\)|DOUBLE PRECISION|\w+(?:\s*\(\d+(?:\s*,\s*\d+)?\))?)(.*)$`)
+	sizedSQL   = regexp.MustCompile(`^(\w+(?: \w+)?)\s*\((\d+)(?:\s*,\s*\d+)?\)$`)
+	tableKey   = regexp.MustCompile(`(?is)^(?:CONSTRAINT (\w+)\s+)?(PRIMARY KEY|UNIQUE)\s*\(([^)]*)\)$`)
+	tableRule  = regexp.MustCompile(`(?is)^(?:CONSTRAINT \w+\s+)?(FOREIGN KEY|CHECK)\b`)
+	hasDefault = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	isUnique   = regexp.MustCompile(`(?i)\bUNIQUE\b`)
+)
+
+func (s *dbSchema) apply(stmt string) error {
+	if m := createTable.FindStringSubmatch(stmt); m != nil {
+		table := m[2]
+		if _, ok := s.Columns[table]; ok {
+			if m[1] != "" {
+				return nil
+			}
+			return fmt.Errorf("table %s exists", table)
+		}
+		s.Columns[table] = map[string]dbColumn{}
+		for _, def := range splitTopLevel(m[3]) {
+			if err := s.addDefinition(table, def); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m := alterTable.FindStringSubmatch(stmt); m != nil {
+		if _, ok := s.Columns[m[1]]; !ok {
+			return fmt.Errorf("no table %s", m[1])
+		}
+		for _, action := range splitTopLevel(m[2]) {
+			if err := s.alter(m[1], action); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m := createIndex.FindStringSubmatch(stmt); m != nil {
+		table := m[4]
+		if _, ok := s.Columns[table]; !ok {
+			return fmt.Errorf("no table %s", table)
+		}
+		if s.index(m[3]) != nil {
+			if m[2] != "" {
+				return nil
+			}
+			return fmt.Errorf("index %s exists", m[3])
+		}
+		var columns []string
+		for _, c := range splitTopLevel(m[5]) {
+			c = strings.TrimSpace(regexp.MustCompile(`(?i)\s+(ASC|DESC)$`).ReplaceAllString(c, ""))
+			if _, ok := s.Columns[table][c]; !ok {
+				return fmt.Errorf("no column %s.%s", table, c)
+			}
+			columns = append(columns, c)
+		}
+		partial := regexp.MustCompile(`(?i)^\s*WHERE\b`).MatchString(m[6])
+		s.Indexes[table] = append(s.Indexes[table], dbIndex{TableName: table, IndexName: m[3], IsUnique: m[1] != "", IsPartial: partial, Columns: strings.Join(columns, ",")})
+		return nil
+	}
+	if m := dropIndex.FindStringSubmatch(stmt); m != nil {
+		s.dropIndex(m[1])
+		return nil
+	}
+	if m := dropTable.FindStringSubmatch(stmt); m != nil {
+		delete(s.Columns, m[1])
+		delete(s.Indexes, m[1])
+		return nil
+	}
+	if dataStmt.MatchString(stmt) {
+		return nil
+	}
+	// DO blocks only repair databases that took an older path to the same
+	// schema; on the path replayed here they change nothing.
+	if doBlock.MatchString(stmt) {
+		return nil
+	}
+	return fmt.Errorf("unsupported statement")
+}
+
+// addDefinition adds a column or table constraint of CREATE TABLE.
+func (s *dbSchema) addDefinition(table, def string) error {
+	if m := tableKey.FindStringSubmatch(def); m != nil {
+		columns := splitTopLevel(m[3])
+		for _, c := range columns {
+			if _, ok := s.Columns[table][c]; !ok {
+				return fmt.Errorf("no column %s.%s", table, c)
+			}
+		}
+		s.addKey(table, m[1], strings.ToUpper(m[2]) == "PRIMARY KEY", columns)
+		return nil
+	}
+	if tableRule.MatchString(def) {
+		return nil
+	}
+	return s.addColumn(table, def)
+}
+
+func (s *dbSchema) addColumn(table, def string) error {
+	m := columnDef.FindStringSubmatch(def)
+	if m == nil {
+		return fmt.Errorf("bad column %q", def)
+	}
+	name, rest := m[1], strings.ToUpper(m[3])
+	if _, ok := s.Columns[table][name]; ok {
+		return fmt.Errorf("column %s.%s exists", table, name)
+	}
+	col := dbColumn{TableName: table, ColumnName: name, IsNullable: "YES"}
+	if err := col.setType(m[2]); err != nil {
+		return err
+	}
+	primary := strings.Contains(rest, "PRIMARY KEY")
+	if primary || strings.Contains(rest, "NOT NULL") {
+		col.IsNullable = "NO"
+	}
+	if hasDefault.MatchString(rest) || strings.HasSuffix(col.UdtName, "serial") {
+		col.ColumnDefault = &rest
+	}
+	s.Columns[table][name] = col
+	if primary {
+		s.addKey(table, "", true, []string{name})
+	} else if isUnique.MatchString(rest) {
+		s.addKey(table, "", false, []string{name})
+	}
+	return nil
+}
+
+// addKey adds the unique index behind a primary key or unique constraint,
+// named as Postgres names them.
+func (s *dbSchema) addKey(table, name string, primary bool, columns []string) {
+	if name == "" {
+		name = table + "_" + strings.Join(columns, "_") + "_key"
+		if primary {
+			name = table + "_pkey"
+		}
+	}
+	if primary {
+		for _, c := range columns {
+			col := s.Columns[table][c]
+			col.IsNullable = "NO"
+			s.Columns[table][c] = col
+		}
+	}
+	s.Indexes[table] = append(s.Indexes[table], dbIndex{TableName: table, IndexName: name, IsUnique: true, Columns: strings.Join(columns, ",")})
+}
+
+var (
+	addColumn     = regexp.MustCompile(`(?is)^ADD COLUMN (IF NOT EXISTS )?(.*)$`)
+	addConstraint = regexp.MustCompile(`(?is)^ADD (.*)$`)
+	dropColumn    = regexp.MustCompile(`(?i)^DROP COLUMN (IF EXISTS )?(\w+)(?: CASCADE)?$`)
+	dropConstr    = regexp.MustCompile(`(?i)^DROP CONSTRAINT (?:IF EXISTS )?(\w+)$`)
+	alterColumn   = regexp.MustCompile(`(?is)^ALTER COLUMN (\w+)\s+(.*)$`)
+	alterType     = regexp.MustCompile(`(?is)^(?:SET DATA )?TYPE\s+(.*?)(?:\s+USING\s.*)?$`)
+	renameColumn  = regexp.MustCompile(`(?i)^RENAME COLUMN (\w+) TO (\w+)$`)
+)
+
+func (s *dbSchema) alter(table, action string) error {
+	if m := addColumn.FindStringSubmatch(action); m != nil {
+		if c := columnDef.FindStringSubmatch(m[2]); c != nil && m[1] != "" {
+			if _, ok := s.Columns[table][c[1]]; ok {
+				return nil
+			}
+		}
+		return s.addColumn(table, m[2])
+	}
+	if m := dropColumn.FindStringSubmatch(action); m != nil {
+		if _, ok := s.Columns[table][m[2]]; !ok {
+			if m[1] != "" {
+				return nil
+			}
+			return fmt.Errorf("no column %s.%s", table, m[2])
+		}
+		delete(s.Columns[table], m[2])
+		var kept []dbIndex
+		for _, idx := range s.Indexes[table] {
+			if !containsColumn(idx.Columns, m[2]) {
+				kept = append(kept, idx)
+			}
+		}
+		s.Indexes[table] = kept
+		return nil
+	}
+	if m := dropConstr.FindStringSubmatch(action); m != nil {
+		s.dropIndex(m[1])
+		return nil
+	}
+	if m := alterColumn.FindStringSubmatch(action); m != nil {
+		col, ok := s.Columns[table][m[1]]
+		if !ok {
+			return fmt.Errorf("no column %s.%s", table, m[1])
+		}
+		change := strings.TrimSpace(m[2])
+		switch upper := strings.ToUpper(change); {
+		case alterType.MatchString(change):
+			if err := col.setType(alterType.FindStringSubmatch(change)[1]); err != nil {
+				return err
+			}
+		case upper == "SET NOT NULL":
+			col.IsNullable = "NO"
+		case upper == "DROP NOT NULL":
+			col.IsNullable = "YES"
+		case strings.HasPrefix(upper, "SET DEFAULT"):
+			col.ColumnDefault = &change
+		case upper == "DROP DEFAULT":
+			col.ColumnDefault = nil
+		default:
+			return fmt.Errorf("unsupported column change %q", change)
+		}
+		s.Columns[table][m[1]] = col
+		return nil
+	}
+	if m := renameColumn.FindStringSubmatch(action); m != nil {
+		col, ok := s.Columns[table][m[1]]
+		if !ok {
+			return fmt.Errorf("no column %s.%s", table, m[1])
+		}
+		delete(s.Columns[table], m[1])
+		col.ColumnName = m[2]
+		s.Columns[table][m[2]] = col
+		for i, idx := range s.Indexes[table] {
+			columns := strings.Split(idx.Columns, ",")
+			for j, c := range columns {
+				if c == m[1] {
+					columns[j] = m[2]
+				}
+			}
+			s.Indexes[table][i].Columns = strings.Join(columns, ",")
+		}
+		return nil
+	}
+	if m := addConstraint.FindStringSubmatch(action); m != nil {
+		return s.addDefinition(table, m[1])
+	}
+	return fmt.Errorf("unsupported change %q", action)
+}
+
+// setType records a column type the way information_schema reports it.
+func (c *dbColumn) setType(sqlType string) error {
+	t := strings.ToLower(strings.Join(strings.Fields(sqlType), " "))
+	c.CharacterMaximumLength = nil
+	if m := sizedSQL.FindStringSubmatch(t); m != nil {
+		t = m[1]
+		if t == "varchar" || t == "character varying" || t == "char" || t == "character" {
+			size, _ := strconv.Atoi(m[2])
+			c.CharacterMaximumLength = &size
+		}
+	}
+	udt, ok := map[string]string{
+		"uuid": "uuid", "text": "text", "varchar": "varchar", "character varying": "varchar",
+		"char": "bpchar", "character": "bpchar",
+		"smallint": "int2", "integer": "int4", "int": "int4", "bigint": "int8",
+		"serial": "int4", "bigserial": "int8",
+		"boolean": "bool", "bool": "bool", "date": "date",
+		"timestamptz": "timestamptz", "timestamp with time zone": "timestamptz",
+		"timestamp": "timestamp", "timestamp without time zone": "timestamp",
+		"numeric": "numeric", "decimal": "numeric", "double precision": "float8", "real": "float4",
+		"json": "json", "jsonb": "jsonb", "bytea": "bytea",
+	}[t]
+	if !ok {
+		return fmt.Errorf("unsupported type %q", sqlType)
+	}
+	if t == "serial" || t == "bigserial" {
+		serial := "nextval"
+		c.ColumnDefault = &serial
+	}
+	c.UdtName = udt
+	return nil
+}
+
+func (s *dbSchema) index(name string) *dbIndex {
+	for table := range s.Indexes {
+		for i := range s.Indexes[table] {
+			if s.Indexes[table][i].IndexName == name {
+				return &s.Indexes[table][i]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *dbSchema) dropIndex(name string) {
+	for table, indexes := range s.Indexes {
+		var kept []dbIndex
+		for _, idx := range indexes {
+			if idx.IndexName != name {
+				kept = append(kept, idx)
+			}
+		}
+		s.Indexes[table] = kept
+	}
+}
+
+// splitStatements splits SQL on semicolons outside $$-quoted bodies.
+func splitStatements(sql string) []string {
+	var stmts []string
+	quoted, start := false, 0
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "$$"):
+			quoted = !quoted
+			i++
+		case sql[i] == ';' && !quoted:
+			stmts = append(stmts, sql[start:i])
+			start = i + 1
+		}
+	}
+	return append(stmts, sql[start:])
+}
+
+// splitTopLevel splits on commas outside parentheses and trims the parts.
+func splitTopLevel(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(list[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+func containsColumn(columns, column string) bool {
+	for _, c := range strings.Split(columns, ",") {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func firstLine(stmt string) string {
+	line, _, _ := strings.Cut(stmt, "\n")
+	return line
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func inbox(t *testing.T, r *gin.Engine, bearer, query string) []dto.NotificationResponseDTO {
+	t.Helper()
+	w := call(r, http.MethodGet, "/notifications"+query, bearer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("inbox expected 200, got %d", w.Code)
+	}
+	var items []dto.NotificationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &items)
+	return items
+}
+
+func unreadCount(t *testing.T, r *gin.Engine, bearer string) int {
+	t.Helper()
+	w := call(r, http.MethodGet, "/notifications/unread-count", bearer)
+	var resp struct {
+		Count int `json:"count"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Count
+}
+
+func findNotification(items []dto.NotificationResponseDTO, typ string) *dto.NotificationResponseDTO {
+	for i := range items {
+		if items[i].Type == typ {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestNotifications(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	creatorToken := testTokenPrefix + creator.Email
+	fan, other := createUser(t, r), createUser(t, r)
+	fanToken, otherToken := testTokenPrefix+fan.Email, testTokenPrefix+other.Email
+
+	if w := call(r, http.MethodPost, "/follow/"+creator.ID.String(), fanToken); w.Code != http.StatusOK {
+		t.Fatalf("follow expected 200, got %d", w.Code)
+	}
+	w := call(r, http.MethodPost, "/posts", creatorToken, gin.H{"text": "premium", "isPremium": true, "price": 5})
+	var post struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &post)
+
+	// followers hear about new posts
+	items := inbox(t, r, fanToken, "")
+	if len(items) != 1 || items[0].Type != models.NotifyNewPost || items[0].SubjectID.String() != post.ID {
+		t.Fatalf("fan expected a new post notification, got %+v", items)
+	}
+
+	// likes of the same post are batched; liking again does not count twice,
+	// not even after someone else liked it in between
+	likeURL := "/posts/" + post.ID + "/like"
+	call(r, http.MethodPost, likeURL, fanToken)
+	call(r, http.MethodPost, likeURL, fanToken)
+	call(r, http.MethodPost, likeURL, fanToken)
+	call(r, http.MethodPost, likeURL, otherToken)
+	call(r, http.MethodPost, likeURL, fanToken)
+	call(r, http.MethodPost, likeURL, fanToken)
+	call(r, http.MethodPost, likeURL, creatorToken) // own like, no notification
+	if w := call(r, http.MethodPost, "/posts/"+post.ID+"/comments", otherToken, gin.H{"text": "nice"}); w.Code != http.StatusCreated {
+		t.Fatalf("comment expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	database.DB.Model(&fan).Update("balance", 10)
+	if w := call(r, http.MethodPost, "/purchases", fanToken, gin.H{"post_id": post.ID}); w.Code != http.StatusCreated {
+		t.Fatalf("buy expected 201, got %d", w.Code)
+	}
+
+	items = inbox(t, r, creatorToken, "")
+	if len(items) != 4 {
+		t.Fatalf("creator expected 4 notifications, got %+v", items)
+	}
+	like := findNotification(items, models.NotifyLike)
+	if like == nil || like.ActorCount != 2 || like.Message != "2 people liked your post" {
+		t.Fatalf("unexpected like notification %+v", like)
+	}
+	follow := findNotification(items, models.NotifyFollow)
+	if follow == nil || follow.Actor == nil || follow.Actor.ID != fan.ID || follow.Message != fan.Nickname+" started following you" {
+		t.Fatalf("unexpected follow notification %+v", follow)
+	}
+	if findNotification(items, models.NotifyComment) == nil || findNotification(items, models.NotifyPurchase) == nil {
+		t.Fatalf("expected comment and purchase notifications, got %+v", items)
+	}
+	if n := unreadCount(t, r, creatorToken); n != 4 {
+		t.Fatalf("expected 4 unread, got %d", n)
+	}
+
+	// preferences
+	prefsURL := "/notifications/preferences"
+	if w := call(r, http.MethodPut, prefsURL, creatorToken, gin.H{"shouting": false}); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown type expected 400, got %d", w.Code)
+	}
+	w = call(r, http.MethodPut, prefsURL, creatorToken, gin.H{"comment": false})
+	var prefs map[string]bool
+	json.Unmarshal(w.Body.Bytes(), &prefs)
+	if w.Code != http.StatusOK || prefs["comment"] || !prefs["like"] {
+		t.Fatalf("unexpected preferences %d %s", w.Code, w.Body.String())
+	}
+	call(r, http.MethodPost, "/posts/"+post.ID+"/comments", fanToken, gin.H{"text": "again"})
+	if c := findNotification(inbox(t, r, creatorToken, ""), models.NotifyComment); c.ActorCount != 1 {
+		t.Fatalf("disabled comment notifications should not merge, got %+v", c)
+	}
+
+	// reading
+	readURL := "/notifications/" + like.ID.String() + "/read"
+	if w := call(r, http.MethodPost, readURL, fanToken); w.Code != http.StatusNotFound {
+		t.Fatalf("reading another user's notification expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/notifications/"+uuid.NewString()+"/read", creatorToken); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown notification expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, readURL, creatorToken); w.Code != http.StatusNoContent {
+		t.Fatalf("read expected 204, got %d", w.Code)
+	}
+	if n := unreadCount(t, r, creatorToken); n != 3 {
+		t.Fatalf("expected 3 unread, got %d", n)
+	}
+	if len(inbox(t, r, creatorToken, "?unread=true")) != 3 {
+		t.Fatal("unread filter should hide read notifications")
+	}
+	// a read notification no longer absorbs events
+	call(r, http.MethodPost, likeURL, otherToken)
+	call(r, http.MethodPost, likeURL, otherToken)
+	if n := unreadCount(t, r, creatorToken); n != 4 {
+		t.Fatalf("new like after reading expected 4 unread, got %d", n)
+	}
+	if w := call(r, http.MethodPost, "/notifications/read-all", creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("read all expected 200, got %d", w.Code)
+	}
+	if n := unreadCount(t, r, creatorToken); n != 0 {
+		t.Fatalf("expected 0 unread, got %d", n)
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestOperations(t *testing.T) {
+	r := SetupRouter(t)
+	user := createUser(t, r)
+	userToken := testTokenPrefix + user.Email
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if w := call(r, method, "/seed", ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s /seed expected 404, got %d", method, w.Code)
+		}
+	}
+	if w := call(r, http.MethodPost, "/admin/ops/migrate/confirmation", userToken); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin confirmation expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/ops/drop/confirmation", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown operation expected 404, got %d", w.Code)
+	}
+	// Destructive operations are off unless ALLOW_DESTRUCTIVE_OPS is set
+	if w := call(r, http.MethodPost, "/admin/ops/seed/confirmation", ""); w.Code != http.StatusForbidden {
+		t.Errorf("seed expected 403, got %d", w.Code)
+	}
+
+	w := call(r, http.MethodPost, "/admin/ops/migrate/confirmation", "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("confirmation expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var confirmation services.OpsConfirmation
+	json.Unmarshal(w.Body.Bytes(), &confirmation)
+	if w := call(r, http.MethodPost, "/admin/ops/migrate", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("run without confirmation expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/ops/migrate", "", gin.H{"confirm": confirmation.Token + "0"}); w.Code != http.StatusForbidden {
+		t.Errorf("run with a bad token expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/ops/migrate", "", gin.H{"confirm": confirmation.Token}); w.Code != http.StatusOK {
+		t.Fatalf("migrate expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, "/admin/ops/migrate", "", gin.H{"confirm": confirmation.Token}); w.Code != http.StatusForbidden {
+		t.Errorf("replayed token expected 403, got %d", w.Code)
+	}
+	if entries := auditLog(t, r, url.Values{"action": {"operation.run"}}); len(entries) != 1 || entries[0].EntityID != services.OpMigrate || entries[0].ActorID == nil {
+		t.Errorf("expected the run in the audit log, got %+v", entries)
+	}
+
+	// Tokens are bound to the actor and run out
+	svc := services.NewOpsService(&repository.GormOpsConfirmationRepository{DB: database.DB})
+	actor := uuid.New()
+	c, _ := svc.Confirm(actor, services.OpMigrate)
+	if err := svc.Run(uuid.New(), services.OpMigrate, c.Token); !errors.Is(err, services.ErrOpsConfirmation) {
+		t.Errorf("token of another actor expected rejected, got %v", err)
+	}
+	svc.Now = func() time.Time { return time.Now().Add(services.OpsConfirmationTTL + time.Second) }
+	if err := svc.Run(actor, services.OpMigrate, c.Token); !errors.Is(err, services.ErrOpsConfirmation) {
+		t.Errorf("expired token expected rejected, got %v", err)
+	}
+}
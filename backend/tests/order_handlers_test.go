@@ -31,4 +31,44 @@ func TestOrderHandlers(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("list orders expected 200, got %d", w.Code)
 	}
+	var all []models.Order
+	json.Unmarshal(w.Body.Bytes(), &all)
+	if len(all) != 1 {
+		t.Fatalf("order readers expected every order, got %d", len(all))
+	}
+
+	// Other users neither list nor read someone else's orders
+	stranger := createUser(t, r)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/orders", nil)
+	asUser(req, stranger)
+	r.ServeHTTP(w, req)
+	var own []models.Order
+	json.Unmarshal(w.Body.Bytes(), &own)
+	if w.Code != http.StatusOK || len(own) != 0 {
+		t.Fatalf("stranger expected an empty list, got %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/orders/"+order.ID.String(), nil)
+	asUser(req, stranger)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("stranger read expected 403, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/orders", nil)
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &own)
+	if len(own) != 1 || own[0].ID != order.ID {
+		t.Fatalf("owner expected their order, got %s", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/orders/"+order.ID.String(), nil)
+	asUser(req, user)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("owner read expected 200, got %d", w.Code)
+	}
 }
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const tronAddress = "TQn9Y2khEsLJW1ChVWFMSMeRDow5KcbLSE"
+
+type payoutBalance struct {
+	Held      int `json:"held"`
+	Available int `json:"available"`
+	Reserved  int `json:"reserved"`
+	PaidOut   int `json:"paid_out"`
+}
+
+func getBalance(t *testing.T, r *gin.Engine, bearer string) payoutBalance {
+	t.Helper()
+	w := call(r, http.MethodGet, "/payouts/balance", bearer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("balance expected 200, got %d", w.Code)
+	}
+	var b payoutBalance
+	json.Unmarshal(w.Body.Bytes(), &b)
+	return b
+}
+
+func TestCreatorPayouts(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "creator@example.com")
+	var creator models.User
+	database.DB.Where("email = ?", "creator@example.com").First(&creator)
+	_, _, session := enableTwoFactor(t, r, tokens.AccessToken)
+	creatorToken := session.AccessToken
+	verifyModel(t, createModel(t, r, creator.ID))
+
+	w := call(r, http.MethodPost, "/posts", creatorToken, gin.H{"text": "premium", "isPremium": true, "price": 100})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create post expected 201, got %d", w.Code)
+	}
+	var post struct {
+		ID    string `json:"id"`
+		Price int    `json:"price"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &post)
+	if post.Price != 100 {
+		t.Fatalf("expected price 100, got %d", post.Price)
+	}
+
+	// buying charges the buyer and credits the creator net of the 20% fee
+	buyer := createUser(t, r)
+	database.DB.Model(&buyer).Update("balance", 250)
+	buyerToken := testTokenPrefix + buyer.Email
+	if w := call(r, http.MethodPost, "/purchases", buyerToken, gin.H{"post_id": post.ID}); w.Code != http.StatusCreated {
+		t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, "/purchases", buyerToken, gin.H{"post_id": post.ID}); w.Code != http.StatusConflict {
+		t.Fatalf("second buy expected 409, got %d", w.Code)
+	}
+	database.DB.First(&buyer, "id = ?", buyer.ID)
+	if buyer.Balance != 150 {
+		t.Fatalf("buyer balance expected 150, got %d", buyer.Balance)
+	}
+	poor := createUser(t, r)
+	if w := call(r, http.MethodPost, "/purchases", testTokenPrefix+poor.Email, gin.H{"post_id": post.ID}); w.Code != http.StatusPaymentRequired {
+		t.Fatalf("buy without funds expected 402, got %d", w.Code)
+	}
+
+	// earnings are held first
+	if b := getBalance(t, r, creatorToken); b.Held != 80 || b.Available != 0 {
+		t.Fatalf("expected 80 held, got %+v", b)
+	}
+	payout := gin.H{"amount": 50, "currency": "usdt_trx", "address": tronAddress}
+	if w := call(r, http.MethodPost, "/payouts", creatorToken, payout); w.Code != http.StatusConflict {
+		t.Fatalf("payout of held earnings expected 409, got %d", w.Code)
+	}
+	database.DB.Model(&models.Earning{}).Where("creator_id = ?", creator.ID).Update("available_at", time.Now().Add(-time.Hour))
+	if b := getBalance(t, r, creatorToken); b.Held != 0 || b.Available != 80 {
+		t.Fatalf("expected 80 available, got %+v", b)
+	}
+
+	for name, tc := range map[string]struct {
+		bearer string
+		body   gin.H
+		want   int
+	}{
+		"below minimum":  {creatorToken, gin.H{"amount": 5, "currency": "USDT_TRX", "address": tronAddress}, http.StatusBadRequest},
+		"bad currency":   {creatorToken, gin.H{"amount": 50, "currency": "DOGE", "address": tronAddress}, http.StatusBadRequest},
+		"no 2FA session": {testTokenPrefix + creator.Email, payout, http.StatusForbidden},
+		"not a creator":  {buyerToken, payout, http.StatusForbidden},
+	} {
+		if w := call(r, http.MethodPost, "/payouts", tc.bearer, tc.body); w.Code != tc.want {
+			t.Fatalf("%s expected %d, got %d: %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	w = call(r, http.MethodPost, "/payouts", creatorToken, gin.H{"amount": 60, "currency": "USDT_TRX", "address": tronAddress})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("payout expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var first models.Payout
+	json.Unmarshal(w.Body.Bytes(), &first)
+	if b := getBalance(t, r, creatorToken); b.Available != 20 || b.Reserved != 60 {
+		t.Fatalf("expected 60 reserved, got %+v", b)
+	}
+	if w := call(r, http.MethodPost, "/payouts", creatorToken, payout); w.Code != http.StatusConflict {
+		t.Fatalf("overdrawing payout expected 409, got %d", w.Code)
+	}
+
+	// staff review
+	if w := call(r, http.MethodGet, "/admin/payouts", creatorToken); w.Code != http.StatusForbidden {
+		t.Fatalf("creator on payout queue expected 403, got %d", w.Code)
+	}
+	w = call(r, http.MethodGet, "/admin/payouts", "")
+	var queue []models.Payout
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	if len(queue) != 1 || queue[0].ID != first.ID {
+		t.Fatalf("queue expected the payout, got %s", w.Body.String())
+	}
+	approveURL := "/admin/payouts/" + first.ID.String() + "/approve"
+	if w := call(r, http.MethodPost, approveURL, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("manual approve without tx_ref expected 400, got %d", w.Code)
+	}
+	w = call(r, http.MethodPost, approveURL, "", gin.H{"tx_ref": "0xabc"})
+	json.Unmarshal(w.Body.Bytes(), &first)
+	if w.Code != http.StatusOK || first.Status != models.PayoutPaid || first.ProviderRef != "0xabc" {
+		t.Fatalf("approve expected paid payout, got %d %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, approveURL, "", gin.H{"tx_ref": "0xabc"}); w.Code != http.StatusConflict {
+		t.Fatalf("second approve expected 409, got %d", w.Code)
+	}
+
+	w = call(r, http.MethodPost, "/payouts", creatorToken, gin.H{"amount": 20, "currency": "USDT_TRX", "address": tronAddress})
+	var second models.Payout
+	json.Unmarshal(w.Body.Bytes(), &second)
+	rejectURL := "/admin/payouts/" + second.ID.String() + "/reject"
+	if w := call(r, http.MethodPost, rejectURL, "", gin.H{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("reject without reason expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, rejectURL, "", gin.H{"reason": "address is on a sanctions list"}); w.Code != http.StatusOK {
+		t.Fatalf("reject expected 200, got %d", w.Code)
+	}
+	if b := getBalance(t, r, creatorToken); b.Available != 20 || b.Reserved != 0 || b.PaidOut != 60 {
+		t.Fatalf("rejection should release the amount, got %+v", b)
+	}
+
+	w = call(r, http.MethodGet, "/payouts", creatorToken)
+	var history []models.Payout
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history) != 2 || history[0].Status != models.PayoutRejected || history[0].Reason == "" {
+		t.Fatalf("unexpected payout history: %s", w.Body.String())
+	}
+	w = call(r, http.MethodGet, "/payouts/earnings", creatorToken)
+	var earnings []models.Earning
+	json.Unmarshal(w.Body.Bytes(), &earnings)
+	if len(earnings) != 1 || earnings[0].Gross != 100 || earnings[0].Fee != 20 || earnings[0].Net != 80 {
+		t.Fatalf("unexpected earnings: %s", w.Body.String())
+	}
+}
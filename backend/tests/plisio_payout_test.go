@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+)
+
+func TestPlisioPayoutOutcomes(t *testing.T) {
+	r := SetupRouter(t)
+	creator := createUser(t, r)
+	reviewer := createUser(t, r)
+	const key = "secret-plisio-key"
+
+	cases := map[string]struct {
+		handler http.HandlerFunc
+		status  string
+		ref     string
+	}{
+		"sent": {func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"id":"op-1"}}`))
+		}, models.PayoutPaid, "op-1"},
+		"declined": {func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"error","data":{"message":"not enough funds for ` + key + `"}}`))
+		}, models.PayoutFailed, ""},
+		"server error": {func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}, models.PayoutProcessing, ""},
+		"unreadable answer": {func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html>`))
+		}, models.PayoutProcessing, ""},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/operations/withdraw" || r.URL.Query().Get("api_key") != key {
+					t.Errorf("unexpected request %s", r.URL.Path)
+				}
+				tc.handler(w, r)
+			}))
+			defer srv.Close()
+			client := services.NewPlisioClient()
+			client.ApiKey, client.BaseURL = key, srv.URL
+			svc := services.NewPayoutService(&repository.GormPayoutRepository{DB: database.DB}, &services.PlisioPayoutProvider{Client: client})
+
+			payout := models.Payout{UserID: creator.ID, Amount: 50, Currency: "USDT_TRX", Address: tronAddress, Status: models.PayoutPending}
+			database.DB.Create(&payout)
+			got, err := svc.Approve(context.Background(), &reviewer, payout.ID, "")
+			if err != nil {
+				t.Fatalf("approve: %v", err)
+			}
+			var stored models.Payout
+			database.DB.First(&stored, "id = ?", payout.ID)
+			if got.Status != tc.status || stored.Status != tc.status || stored.ProviderRef != tc.ref {
+				t.Fatalf("expected %s with ref %q, got %+v", tc.status, tc.ref, stored)
+			}
+			if strings.Contains(stored.Reason, key) || (tc.status == models.PayoutFailed && stored.Reason != services.ErrPayoutDeclined.Error()) {
+				t.Errorf("unexpected reason %q", stored.Reason)
+			}
+		})
+	}
+
+	// transport errors leave the URL, and with it the key, out
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	client := services.NewPlisioClient()
+	client.ApiKey, client.BaseURL = key, srv.URL
+	_, err := client.Withdraw(context.Background(), "USDT_TRX", tronAddress, "50")
+	if err == nil || strings.Contains(err.Error(), key) || errors.Is(err, services.ErrPayoutDeclined) {
+		t.Fatalf("expected an undecided error without the key, got %v", err)
+	}
+}
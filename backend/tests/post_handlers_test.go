@@ -26,6 +26,7 @@ func TestPostHandlers(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user)
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create post expected 201, got %d", w.Code)
@@ -72,3 +73,102 @@ func TestPostHandlers(t *testing.T) {
 		t.Fatalf("delete post expected 200, got %d", w.Code)
 	}
 }
+
+func TestPostOwnership(t *testing.T) {
+	r := SetupRouter(t)
+	author, _ := createUserWithModel(t, r)
+	stranger := createUser(t, r)
+	postID := createPost(t, r, author, false)
+
+	update, _ := json.Marshal(map[string]interface{}{
+		"text":           "edited",
+		"published_time": "2025-01-01T00:00:00Z",
+		"model": map[string]interface{}{
+			"name":     "Model",
+			"nickname": author.Nickname,
+			"balance":  1000000,
+			"email":    "evil@example.com",
+		},
+	})
+
+	// anonymous caller
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/posts/"+postID, nil)
+	asAnonymous(req)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous delete expected 401, got %d", w.Code)
+	}
+
+	// another user
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPut, "/posts/"+postID, bytes.NewReader(update))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, stranger)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("foreign update expected 403, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/posts/"+postID, nil)
+	asUser(req, stranger)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("foreign delete expected 403, got %d", w.Code)
+	}
+
+	// the author can edit, but not their balance or email
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPut, "/posts/"+postID, bytes.NewReader(update))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, author)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("author update expected 200, got %d", w.Code)
+	}
+	var dbAuthor models.User
+	database.DB.First(&dbAuthor, "id = ?", author.ID)
+	if dbAuthor.Balance != 0 || dbAuthor.Email != author.Email {
+		t.Fatalf("post update must not change author balance or email")
+	}
+
+	// posts are created under the caller's own profile
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/posts", bytes.NewReader([]byte(`{"text":"x"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	asUser(req, stranger)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("create post without model profile expected 403, got %d", w.Code)
+	}
+}
+
+func TestStaffOverrideRequiresTwoFactor(t *testing.T) {
+	r := SetupRouter(t)
+	author, _ := createUserWithModel(t, r)
+	postID := createPost(t, r, author, false)
+	tokens := registerNative(t, r, "mod@example.com")
+	var mod models.User
+	database.DB.Where("email = ?", "mod@example.com").First(&mod)
+	database.DB.Create(&models.UserRole{UserID: mod.ID, Role: models.RoleModerator})
+
+	// Deleting someone else's post is a staff action and needs 2FA
+	w := call(r, http.MethodDelete, "/posts/"+postID, tokens.AccessToken)
+	var body struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusForbidden || body.Code != "two_factor_required" {
+		t.Fatalf("moderator without 2FA expected 403 two_factor_required, got %d %q", w.Code, body.Code)
+	}
+	var count int64
+	database.DB.Model(&models.Post{}).Where("id = ?", postID).Count(&count)
+	if count != 1 {
+		t.Fatal("post must survive a moderator without 2FA")
+	}
+
+	_, _, session := enableTwoFactor(t, r, tokens.AccessToken)
+	if w := call(r, http.MethodDelete, "/posts/"+postID, session.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("moderator with 2FA expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -13,6 +13,7 @@ import (
 func TestPurchaseHandlers(t *testing.T) {
 	r := SetupRouter(t)
 	user, model := createUserWithModel(t, r)
+	verifyModel(t, model)
 	// Set balance directly in DB for test setup
 	user.Balance = 10
 	if err := database.DB.Save(&user).Error; err != nil {
@@ -28,6 +29,7 @@ func TestPurchaseHandlers(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(postBody))
 	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user)
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create post expected 201, got %d", w.Code)
@@ -42,6 +44,7 @@ func TestPurchaseHandlers(t *testing.T) {
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest(http.MethodPost, "/purchases", bytes.NewReader(buyBody))
 	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user) // the post costs 5, paid from the balance set above
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("buy content expected 201, got %d", w.Code)
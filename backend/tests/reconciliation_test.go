@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReconcileBalances(t *testing.T) {
+	r := SetupRouter(t)
+	creator, _ := createUserWithModel(t, r)
+	fan := createUser(t, r)
+	database.DB.Model(&fan).Update("balance", 100)
+	postID := createPost(t, r, creator, false)
+	database.DB.Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{"is_premium": true, "price": 10})
+	if w := call(r, http.MethodPost, "/purchases", testTokenPrefix+fan.Email, gin.H{"post_id": postID}); w.Code != http.StatusCreated {
+		t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	svc := services.NewReconciliationService(&repository.GormReconciliationRepository{DB: database.DB})
+	if report, err := svc.Reconcile(false); err != nil || !report.Clean() {
+		t.Fatalf("consistent ledger expected clean, got %+v %v", report, err)
+	}
+
+	// A sale from before the earnings ledger
+	var earning models.Earning
+	database.DB.Where("creator_id = ?", creator.ID).First(&earning)
+	database.DB.Delete(&earning)
+	report, err := svc.Reconcile(false)
+	if err != nil || len(report.UnrecordedSales) != 1 || report.Backfilled != 0 || report.Clean() {
+		t.Fatalf("expected one unrecorded sale, got %+v %v", report, err)
+	}
+	if sale := report.UnrecordedSales[0]; sale.SourceID != earning.SourceID || sale.CreatorID != creator.ID || sale.Gross != 10 {
+		t.Errorf("unexpected sale %+v", sale)
+	}
+	if report, err = svc.Reconcile(true); err != nil || report.Backfilled != 1 || !report.Clean() {
+		t.Fatalf("expected the sale backfilled, got %+v %v", report, err)
+	}
+	var restored models.Earning
+	database.DB.Where("source_id = ?", earning.SourceID).First(&restored)
+	if restored.Net != earning.Net || !restored.AvailableAt.Equal(earning.AvailableAt) {
+		t.Errorf("backfilled earning %+v differs from %+v", restored, earning)
+	}
+
+	// Findings that need a person
+	database.DB.Create(&models.Payout{UserID: creator.ID, Amount: earning.Net + 1, Currency: "USDT_TRX", Address: "T1", Status: models.PayoutPaid})
+	database.DB.Model(&fan).Update("balance", -5)
+	report, err = svc.Reconcile(true)
+	if err != nil || report.Clean() || len(report.Overdrawn) != 1 || report.Overdrawn[0].Earned != earning.Net || len(report.NegativeBalances) != 1 || report.NegativeBalances[0].UserID != fan.ID {
+		t.Errorf("expected an overdrawn creator and a negative wallet, got %+v %v", report, err)
+	}
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func moderationCase(t *testing.T, r *gin.Engine, bearer, query string) models.ModerationCase {
+	t.Helper()
+	w := call(r, http.MethodGet, "/admin/moderation/cases"+query, bearer)
+	var cases []models.ModerationCase
+	json.Unmarshal(w.Body.Bytes(), &cases)
+	if w.Code != http.StatusOK || len(cases) != 1 {
+		t.Fatalf("expected one case for %q, got %d %s", query, w.Code, w.Body.String())
+	}
+	return cases[0]
+}
+
+func TestReportsAndModeration(t *testing.T) {
+	r := SetupRouter(t)
+	creator, _ := createUserWithModel(t, r)
+	creatorToken := testTokenPrefix + creator.Email
+	fans := []models.User{createUser(t, r), createUser(t, r), createUser(t, r)}
+	fanToken := testTokenPrefix + fans[0].Email
+	// staff sessions need 2FA
+	_, _, session := enableTwoFactor(t, r, registerNative(t, r, "mod@example.com").AccessToken)
+	modToken := session.AccessToken
+	var moderator models.User
+	database.DB.Where("email = ?", "mod@example.com").First(&moderator)
+	call(r, http.MethodPost, "/admin/users/"+moderator.ID.String()+"/roles", "", gin.H{"role": "moderator", "reason": "mod team"})
+	postID := createPost(t, r, creator, false)
+	report := func(bearer, targetType, targetID string) int {
+		return call(r, http.MethodPost, "/reports", bearer, gin.H{"target_type": targetType, "target_id": targetID, "reason": models.ReasonSpam}).Code
+	}
+
+	cases := map[string]struct {
+		token string
+		body  gin.H
+		want  int
+	}{
+		"unknown reason": {fanToken, gin.H{"target_type": "post", "target_id": postID, "reason": "boring"}, http.StatusBadRequest},
+		"unknown type":   {fanToken, gin.H{"target_type": "story", "target_id": postID, "reason": "spam"}, http.StatusBadRequest},
+		"own content":    {creatorToken, gin.H{"target_type": "post", "target_id": postID, "reason": "spam"}, http.StatusBadRequest},
+		"missing target": {fanToken, gin.H{"target_type": "comment", "target_id": postID, "reason": "spam"}, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		if w := call(r, http.MethodPost, "/reports", tc.token, tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if code := report(fanToken, models.ReportPost, postID); code != http.StatusCreated {
+		t.Fatalf("report expected 201, got %d", code)
+	}
+	if code := report(fanToken, models.ReportPost, postID); code != http.StatusConflict {
+		t.Fatalf("second report by the same user expected 409, got %d", code)
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, fanToken); w.Code != http.StatusOK {
+		t.Fatalf("post below the threshold expected visible, got %d", w.Code)
+	}
+
+	// the third reporter hides the post from everyone but its author
+	for _, fan := range fans[1:] {
+		report(testTokenPrefix+fan.Email, models.ReportPost, postID)
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, fanToken); w.Code != http.StatusNotFound {
+		t.Fatalf("auto-hidden post expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/posts/"+postID, creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("author expected to see the hidden post, got %d", w.Code)
+	}
+	if feedHas(t, r, fanToken, postID) {
+		t.Fatalf("auto-hidden post expected missing from the feed")
+	}
+	if w := call(r, http.MethodGet, "/admin/moderation/cases", fanToken); w.Code != http.StatusForbidden {
+		t.Fatalf("fan reading the queue expected 403, got %d", w.Code)
+	}
+	mc := moderationCase(t, r, modToken, "?target_type=post")
+	if mc.ReportCount != 3 || !mc.AutoHidden || mc.TargetUserID != creator.ID {
+		t.Fatalf("unexpected case %+v", mc)
+	}
+	caseURL := "/admin/moderation/cases/" + mc.ID.String()
+	w := call(r, http.MethodGet, caseURL, modToken)
+	json.Unmarshal(w.Body.Bytes(), &mc)
+	if len(mc.Reports) != 3 {
+		t.Fatalf("case expected its 3 reports, got %+v", mc.Reports)
+	}
+
+	// claims are exclusive; dismissing shows the post again
+	if w := call(r, http.MethodPost, caseURL+"/claim", modToken); w.Code != http.StatusOK {
+		t.Fatalf("claim expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, caseURL+"/claim", ""); w.Code != http.StatusConflict {
+		t.Fatalf("claim by another moderator expected 409, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, caseURL+"/resolve", "", gin.H{"action": "dismiss"}); w.Code != http.StatusConflict {
+		t.Fatalf("resolve by another moderator expected 409, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, caseURL+"/resolve", modToken, gin.H{"action": "ban"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown action expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, caseURL+"/resolve", modToken, gin.H{"action": "dismiss", "note": "not spam"}); w.Code != http.StatusOK {
+		t.Fatalf("dismiss expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodPost, caseURL+"/resolve", modToken, gin.H{"action": "hide"}); w.Code != http.StatusConflict {
+		t.Fatalf("resolving twice expected 409, got %d", w.Code)
+	}
+	if !feedHas(t, r, fanToken, postID) {
+		t.Fatalf("dismissed post expected back in the feed")
+	}
+
+	// a warned commenter is notified and the comment stays up
+	commenter := fans[1]
+	w = call(r, http.MethodPost, "/posts/"+postID+"/comments", testTokenPrefix+commenter.Email, gin.H{"text": "buy followers"})
+	var comment models.Comment
+	json.Unmarshal(w.Body.Bytes(), &comment)
+	if code := report(creatorToken, models.ReportComment, comment.ID.String()); code != http.StatusCreated {
+		t.Fatalf("comment report expected 201, got %d", code)
+	}
+	mc = moderationCase(t, r, modToken, "")
+	if w := call(r, http.MethodPost, "/admin/moderation/cases/"+mc.ID.String()+"/resolve", modToken, gin.H{"action": "warn"}); w.Code != http.StatusOK {
+		t.Fatalf("warn expected 200, got %d", w.Code)
+	}
+	if n := findNotification(inbox(t, r, testTokenPrefix+commenter.Email, ""), models.NotifyWarning); n == nil || n.Actor != nil {
+		t.Fatalf("commenter expected an anonymous warning, got %+v", n)
+	}
+
+	// only the recipient can report a message; suspending hides it and locks the sender out
+	w = call(r, http.MethodPost, "/conversations", fanToken, gin.H{"user_id": creator.ID.String()})
+	var conv dto.ConversationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &conv)
+	messagesURL := "/conversations/" + conv.ID.String() + "/messages"
+	w = call(r, http.MethodPost, messagesURL, creatorToken, gin.H{"text": "send me money"})
+	var msg dto.MessageResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &msg)
+	if code := report(testTokenPrefix+fans[2].Email, models.ReportMessage, msg.ID.String()); code != http.StatusNotFound {
+		t.Fatalf("outsider reporting a message expected 404, got %d", code)
+	}
+	if code := report(fanToken, models.ReportMessage, msg.ID.String()); code != http.StatusCreated {
+		t.Fatalf("message report expected 201, got %d", code)
+	}
+	mc = moderationCase(t, r, modToken, "?target_type=message")
+	resolveURL := "/admin/moderation/cases/" + mc.ID.String() + "/resolve"
+	if w := call(r, http.MethodPost, resolveURL, modToken, gin.H{"action": "suspend"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("suspend without duration expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, resolveURL, modToken, gin.H{"action": "suspend", "suspend_days": 3}); w.Code != http.StatusOK {
+		t.Fatalf("suspend expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if items := messages(t, r, fanToken, conv.ID.String()); len(items) != 0 {
+		t.Fatalf("suspended sender's message expected hidden, got %+v", items)
+	}
+	w = call(r, http.MethodGet, "/notifications", creatorToken)
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusForbidden || body["code"] != "account_suspended" || body["suspended_until"] == nil {
+		t.Fatalf("suspended user expected 403 account_suspended, got %d %s", w.Code, w.Body.String())
+	}
+
+	// resolution history by author
+	w = call(r, http.MethodGet, "/admin/moderation/history?user_id="+creator.ID.String(), modToken)
+	var history []models.ModerationCase
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history) != 2 || history[0].Action != models.ActionSuspend || history[1].Action != models.ActionDismiss {
+		t.Fatalf("creator history expected suspend then dismiss, got %+v", history)
+	}
+	w = call(r, http.MethodGet, "/reports", fanToken)
+	var mine []models.Report
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if len(mine) != 2 {
+		t.Fatalf("fan expected 2 reports, got %+v", mine)
+	}
+}
+
+func TestResolveIsConditional(t *testing.T) {
+	r := SetupRouter(t)
+	creator, _ := createUserWithModel(t, r)
+	fan := createUser(t, r)
+	fanToken := testTokenPrefix + fan.Email
+	postID := createPost(t, r, creator, false)
+	report := func() models.ModerationCase {
+		if w := call(r, http.MethodPost, "/reports", fanToken, gin.H{"target_type": models.ReportPost, "target_id": postID, "reason": models.ReasonSpam}); w.Code != http.StatusCreated {
+			t.Fatalf("report expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		return moderationCase(t, r, "", "?status="+models.CaseOpen)
+	}
+
+	// A case resolved by someone else after it was loaded stays theirs
+	mc := report()
+	repo := &repository.GormReportRepository{DB: database.DB}
+	stale, _ := repo.FindCase(mc.ID)
+	if w := call(r, http.MethodPost, "/admin/moderation/cases/"+mc.ID.String()+"/claim", ""); w.Code != http.StatusOK {
+		t.Fatalf("claim expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	other := uuid.New()
+	now := time.Now()
+	stale.Status, stale.Action, stale.AssigneeID, stale.ResolvedBy, stale.ResolvedAt = models.CaseResolved, models.ActionDismiss, &other, &other, &now
+	if resolved, err := repo.Resolve(stale, false); err != nil || resolved {
+		t.Fatalf("resolving a case claimed by another moderator expected refused, got %v %v", resolved, err)
+	}
+	if current, _ := repo.FindCase(mc.ID); current.Status != models.CaseClaimed || current.ResolvedBy != nil {
+		t.Fatalf("claimed case expected untouched, got %+v", current)
+	}
+	if w := call(r, http.MethodPost, "/admin/moderation/cases/"+mc.ID.String()+"/resolve", "", gin.H{"action": "hide"}); w.Code != http.StatusOK {
+		t.Fatalf("hide expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Dismissing a later case that did not hide the post leaves it hidden
+	mc = report()
+	if mc.AutoHidden {
+		t.Fatalf("single report expected below the threshold, got %+v", mc)
+	}
+	if w := call(r, http.MethodPost, "/admin/moderation/cases/"+mc.ID.String()+"/resolve", "", gin.H{"action": "dismiss"}); w.Code != http.StatusOK {
+		t.Fatalf("dismiss expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var post models.Post
+	database.DB.Unscoped().First(&post, "id = ?", postID)
+	if post.HiddenAt == nil {
+		t.Fatal("post hidden by an earlier case expected to stay hidden")
+	}
+}
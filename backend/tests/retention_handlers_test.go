@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeStorage records the files the purge job removes.
+type fakeStorage struct {
+	videos, images []string
+}
+
+func (s *fakeStorage) DeleteVideo(id string) error {
+	s.videos = append(s.videos, id)
+	return nil
+}
+
+func (s *fakeStorage) DeleteImage(filename string) error {
+	s.images = append(s.images, filename)
+	return nil
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	creatorToken := testTokenPrefix + creator.Email
+	buyer := createUser(t, r)
+	database.DB.Model(&buyer).Update("balance", 100)
+	buyerToken := testTokenPrefix + buyer.Email
+	other := createUser(t, r)
+	otherToken := testTokenPrefix + other.Email
+	_, _, session := enableTwoFactor(t, r, registerNative(t, r, "mod@example.com").AccessToken)
+	modToken := session.AccessToken
+	var moderator models.User
+	database.DB.Where("email = ?", "mod@example.com").First(&moderator)
+	call(r, http.MethodPost, "/admin/users/"+moderator.ID.String()+"/roles", "", gin.H{"role": "moderator", "reason": "mod team"})
+
+	// Bought posts with an uploaded video each
+	premium := func(cdn string) (string, models.Video) {
+		postID := createPost(t, r, creator, false)
+		id := uuid.MustParse(postID)
+		database.DB.Model(&models.Post{}).Where("id = ?", id).Updates(map[string]interface{}{"is_premium": true, "price": 10})
+		video := models.Video{ID: uuid.New(), UserID: creator.ID, BunnyVideoID: "bunny-" + cdn, CDNUrl: cdn}
+		database.DB.Create(&video)
+		database.DB.Create(&models.Media{PostID: id, Type: "video", URL: cdn})
+		if w := call(r, http.MethodPost, "/purchases", buyerToken, gin.H{"post_id": postID}); w.Code != http.StatusCreated {
+			t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		return postID, video
+	}
+	ownerDeleted, keptVideo := premium("https://cdn/kept.m3u8")
+	modDeleted, modVideo := premium("https://cdn/removed.m3u8")
+	unbought := createPost(t, r, creator, false)
+
+	for _, del := range []struct{ token, postID string }{{creatorToken, ownerDeleted}, {modToken, modDeleted}, {creatorToken, unbought}} {
+		if w := call(r, http.MethodDelete, "/posts/"+del.postID, del.token); w.Code != http.StatusOK {
+			t.Fatalf("delete expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if feedHas(t, r, buyerToken, ownerDeleted) {
+		t.Error("deleted post must leave the feed")
+	}
+	// Buyers keep posts the author deleted, not those removed by moderation
+	if w := call(r, http.MethodGet, "/posts/"+ownerDeleted, buyerToken); w.Code != http.StatusOK {
+		t.Errorf("buyer expected to keep the deleted post, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/posts/"+ownerDeleted, otherToken); w.Code != http.StatusNotFound {
+		t.Errorf("deleted post expected 404 for others, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/posts/"+modDeleted, buyerToken); w.Code != http.StatusNotFound {
+		t.Errorf("post removed by moderation expected 404 for the buyer, got %d", w.Code)
+	}
+	w := call(r, http.MethodGet, "/users/"+buyer.ID.String()+"/purchased-posts", buyerToken)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), ownerDeleted) || strings.Contains(w.Body.String(), modDeleted) {
+		t.Errorf("purchased posts expected to keep only the owner-deleted post, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Staff restore
+	if w := call(r, http.MethodPost, "/admin/posts/"+unbought+"/restore", creatorToken); w.Code != http.StatusForbidden {
+		t.Errorf("restore by the author expected 403, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/posts/"+unbought+"/restore", modToken); w.Code != http.StatusOK {
+		t.Fatalf("restore expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !feedHas(t, r, otherToken, unbought) {
+		t.Error("restored post expected back in the feed")
+	}
+	if w := call(r, http.MethodPost, "/admin/posts/"+unbought+"/restore", modToken); w.Code != http.StatusConflict {
+		t.Errorf("restoring a live post expected 409, got %d", w.Code)
+	}
+	call(r, http.MethodDelete, "/posts/"+unbought, creatorToken)
+
+	// Accounts
+	if w := call(r, http.MethodDelete, "/users/"+other.ID.String(), otherToken); w.Code != http.StatusOK {
+		t.Fatalf("delete account expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/notifications", otherToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("deleted account expected 401, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/users/"+other.ID.String()+"/restore", ""); w.Code != http.StatusOK {
+		t.Fatalf("restore account expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodGet, "/notifications", otherToken); w.Code != http.StatusOK {
+		t.Errorf("restored account expected access, got %d", w.Code)
+	}
+
+	// Uploads
+	image := models.Image{ID: uuid.New(), UserID: creator.ID, Filename: "a.png", CDNUrl: "https://cdn/a.png"}
+	database.DB.Create(&image)
+	if w := call(r, http.MethodDelete, "/images/"+image.ID.String(), creatorToken); w.Code != http.StatusOK {
+		t.Fatalf("delete image expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodGet, "/images/"+image.ID.String(), creatorToken); w.Code != http.StatusNotFound {
+		t.Errorf("deleted image expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/images/"+image.ID.String()+"/restore", modToken); w.Code != http.StatusOK {
+		t.Fatalf("restore image expected 200, got %d", w.Code)
+	}
+	call(r, http.MethodDelete, "/images/"+image.ID.String(), creatorToken)
+
+	// Purge once the retention period is over
+	storage := &fakeStorage{}
+	svc := services.NewRetentionService(&repository.GormRetentionRepository{DB: database.DB})
+	svc.Storage = storage
+	if res, err := svc.Purge(); err != nil || res != (services.PurgeResult{}) {
+		t.Fatalf("nothing expected to be purged within retention, got %+v %v", res, err)
+	}
+	svc.Now = func() time.Time { return time.Now().Add(svc.Retention + time.Hour) }
+	res, err := svc.Purge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (services.PurgeResult{Posts: 2, Videos: 1, Images: 1}) {
+		t.Errorf("unexpected purge result %+v", res)
+	}
+	if len(storage.videos) != 1 || storage.videos[0] != modVideo.BunnyVideoID || len(storage.images) != 1 {
+		t.Errorf("expected the moderated video and the image removed from storage, got %v %v", storage.videos, storage.images)
+	}
+	var n int64
+	database.DB.Unscoped().Model(&models.Post{}).Where("id = ?", unbought).Count(&n)
+	if n != 0 {
+		t.Error("unbought post expected purged")
+	}
+	database.DB.Unscoped().Model(&models.Post{}).Where("id IN ?", []string{ownerDeleted, modDeleted}).Count(&n)
+	if n != 2 {
+		t.Error("bought posts must keep their rows for the purchase history")
+	}
+	database.DB.Model(&models.Video{}).Where("id = ?", keptVideo.ID).Count(&n)
+	if n != 1 {
+		t.Error("video of a post buyers kept must not be purged")
+	}
+	if w := call(r, http.MethodGet, "/posts/"+ownerDeleted, buyerToken); w.Code != http.StatusOK {
+		t.Errorf("buyer expected to keep the deleted post after the purge, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/admin/posts/"+unbought+"/restore", modToken); w.Code != http.StatusNotFound {
+		t.Errorf("restoring a purged post expected 404, got %d", w.Code)
+	}
+	if res, _ := svc.Purge(); res != (services.PurgeResult{}) {
+		t.Errorf("second purge expected to find nothing, got %+v", res)
+	}
+}
+
+func TestPurchasedPostsVisibility(t *testing.T) {
+	r := SetupRouter(t)
+	buyer := createUser(t, r)
+	database.DB.Model(&buyer).Update("balance", 100)
+	buyerToken := testTokenPrefix + buyer.Email
+	other := createUser(t, r)
+
+	bought := func() (models.User, string) {
+		creator, profile := createUserWithModel(t, r)
+		verifyModel(t, profile)
+		postID := createPost(t, r, creator, false)
+		database.DB.Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{"is_premium": true, "price": 10})
+		if w := call(r, http.MethodPost, "/purchases", buyerToken, gin.H{"post_id": postID}); w.Code != http.StatusCreated {
+			t.Fatalf("buy expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		return creator, postID
+	}
+	_, visible := bought()
+	_, hidden := bought()
+	database.DB.Model(&models.Post{}).Where("id = ?", hidden).Update("hidden_at", time.Now())
+	banned, bannedPost := bought()
+	database.DB.Model(&banned).Update("status", models.AccountBanned)
+	deactivated, deactivatedPost := bought()
+	database.DB.Model(&deactivated).Update("status", models.AccountDeactivated)
+	blocker, blockerPost := bought()
+	if w := call(r, http.MethodPost, "/blocks/"+buyer.ID.String(), testTokenPrefix+blocker.Email); w.Code != http.StatusOK {
+		t.Fatalf("block expected 200, got %d", w.Code)
+	}
+	leaver, leaverPost := bought()
+	if w := call(r, http.MethodDelete, "/posts/"+leaverPost, testTokenPrefix+leaver.Email); w.Code != http.StatusOK {
+		t.Fatalf("delete expected 200, got %d", w.Code)
+	}
+	database.DB.Model(&leaver).Update("status", models.AccountDeactivated)
+
+	path := "/users/" + buyer.ID.String() + "/purchased-posts"
+	if w := call(r, http.MethodGet, path, testTokenPrefix+other.Email); w.Code != http.StatusForbidden {
+		t.Errorf("another user's purchases expected 403, got %d", w.Code)
+	}
+	for name, token := range map[string]string{"buyer": buyerToken, "admin": ""} {
+		w := call(r, http.MethodGet, path, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: purchased posts expected 200, got %d: %s", name, w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		for _, id := range []string{visible, leaverPost} {
+			if !strings.Contains(body, id) {
+				t.Errorf("%s: expected post %s in the purchases", name, id)
+			}
+		}
+		for what, id := range map[string]string{"hidden": hidden, "banned author's": bannedPost, "deactivated author's": deactivatedPost, "blocking author's": blockerPost} {
+			if strings.Contains(body, id) {
+				t.Errorf("%s: %s post expected left out", name, what)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/repository"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// call sends a request as the default admin (bearer "") or with a bearer token.
+func call(r *gin.Engine, method, path, bearer string, payload ...interface{}) *httptest.ResponseRecorder {
+	var body io.Reader
+	if len(payload) > 0 {
+		raw, _ := json.Marshal(payload[0])
+		body = bytes.NewReader(raw)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRoleManagement(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "support@example.com")
+	var support models.User
+	database.DB.Where("email = ?", "support@example.com").First(&support)
+	rolesURL := "/admin/users/" + support.ID.String() + "/roles"
+
+	// staff sessions need 2FA, so enroll before getting any role
+	_, _, session := enableTwoFactor(t, r, tokens.AccessToken)
+
+	if w := call(r, http.MethodGet, "/users", session.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("plain user listing users expected 403, got %d", w.Code)
+	}
+
+	for _, tc := range []struct {
+		body gin.H
+		want int
+	}{
+		{gin.H{"role": "support"}, http.StatusBadRequest},
+		{gin.H{"role": "overlord", "reason": "x"}, http.StatusBadRequest},
+		{gin.H{"role": "user", "reason": "x"}, http.StatusBadRequest},
+		{gin.H{"role": "support", "reason": "helpdesk"}, http.StatusCreated},
+		{gin.H{"role": "support", "reason": "again"}, http.StatusConflict},
+	} {
+		if w := call(r, http.MethodPost, rolesURL, "", tc.body); w.Code != tc.want {
+			t.Fatalf("grant %v expected %d, got %d", tc.body, tc.want, w.Code)
+		}
+	}
+
+	// support can read users but not manage roles
+	if w := call(r, http.MethodGet, "/users", session.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("support listing users expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/admin/roles", session.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("support on role admin expected 403, got %d", w.Code)
+	}
+
+	if w := call(r, http.MethodDelete, rolesURL+"/support", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("revoke without reason expected 400, got %d", w.Code)
+	}
+	if w := call(r, http.MethodDelete, rolesURL+"/support?reason=left+team", ""); w.Code != http.StatusOK {
+		t.Fatalf("revoke expected 200, got %d", w.Code)
+	}
+	if w := call(r, http.MethodDelete, rolesURL+"/support?reason=again", ""); w.Code != http.StatusConflict {
+		t.Fatalf("revoke of missing role expected 409, got %d", w.Code)
+	}
+	if w := call(r, http.MethodGet, "/users", session.AccessToken); w.Code != http.StatusForbidden {
+		t.Fatalf("revoked support listing users expected 403, got %d", w.Code)
+	}
+
+	// every change is in the audit trail, newest first
+	w := call(r, http.MethodGet, "/admin/roles/changes?user_id="+support.ID.String(), "")
+	var changes []models.RoleChange
+	json.Unmarshal(w.Body.Bytes(), &changes)
+	if len(changes) != 2 || changes[0].Action != models.RoleRevoked || changes[0].Reason != "left team" ||
+		changes[1].Action != models.RoleGranted || changes[1].ActorID == nil {
+		t.Fatalf("unexpected role changes %s", w.Body.String())
+	}
+
+	// the last admin keeps the role; someone without it is not an admin
+	// being revoked
+	var admin models.User
+	database.DB.Where("email = ?", "admin@example.com").First(&admin)
+	if w := call(r, http.MethodDelete, "/admin/users/"+admin.ID.String()+"/roles/admin?reason=oops", ""); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), services.ErrLastAdmin.Error()) {
+		t.Fatalf("revoking the last admin expected 409, got %d %s", w.Code, w.Body.String())
+	}
+	if w := call(r, http.MethodDelete, rolesURL+"/admin?reason=oops", ""); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), services.ErrRoleNotHeld.Error()) {
+		t.Fatalf("revoking an admin role not held expected 409 not held, got %d %s", w.Code, w.Body.String())
+	}
+
+	// with a second admin one of them can go, the other stays
+	call(r, http.MethodPost, rolesURL, "", gin.H{"role": "admin", "reason": "co-owner"})
+	if w := call(r, http.MethodDelete, rolesURL+"/admin?reason=handover", ""); w.Code != http.StatusOK {
+		t.Fatalf("revoking one of two admins expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	repo := &repository.GormRoleRepository{DB: database.DB}
+	change := &models.RoleChange{UserID: admin.ID, Role: models.RoleAdmin, Action: models.RoleRevoked, Reason: "race"}
+	if err := repo.Revoke(admin.ID, models.RoleAdmin, change, true); !errors.Is(err, repository.ErrLastHolder) {
+		t.Fatalf("repository revoke of the last admin expected ErrLastHolder, got %v", err)
+	}
+}
+
+func TestModeratorCanRemoveForeignPosts(t *testing.T) {
+	r := SetupRouter(t)
+	author, _ := createUserWithModel(t, r)
+	moderator := createUser(t, r)
+	postID := createPost(t, r, author, false)
+	// this is about the grant; TestStaffOverrideRequiresTwoFactor covers 2FA
+	config.AppConfig.RequireStaffTwoFactor = false
+
+	remove := func() int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/posts/"+postID, nil)
+		asUser(req, moderator)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := remove(); code != http.StatusForbidden {
+		t.Fatalf("delete before grant expected 403, got %d", code)
+	}
+	if w := call(r, http.MethodPost, "/admin/users/"+moderator.ID.String()+"/roles", "", gin.H{"role": "moderator", "reason": "mod team"}); w.Code != http.StatusCreated {
+		t.Fatalf("grant expected 201, got %d", w.Code)
+	}
+	if code := remove(); code != http.StatusOK {
+		t.Fatalf("moderator delete expected 200, got %d", code)
+	}
+}
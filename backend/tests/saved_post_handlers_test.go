@@ -21,6 +21,7 @@ func TestSavedPostHandlers(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	asUser(req, user)
 	r.ServeHTTP(w, req)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("create post expected 201, got %d", w.Code)
@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend/database"
+
+	"github.com/golang-migrate/migrate/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type dbColumn struct {
+	TableName              string
+	ColumnName             string
+	UdtName                string
+	CharacterMaximumLength *int
+	IsNullable             string
+	ColumnDefault          *string
+}
+
+type dbIndex struct {
+	TableName string
+	IndexName string
+	IsUnique  bool
+	IsPartial bool
+	Columns   string
+}
+
+// dbSchema is the shape of the tables the models are checked against.
+type dbSchema struct {
+	Columns map[string]map[string]dbColumn // by table and column
+	Indexes map[string][]dbIndex           // by table
+}
+
+// TestMigrationsMatchModels replays the SQL migrations and checks every
+// model against the tables they build, so a model change without a
+// migration (or the other way round) fails here rather than in production.
+// TestSchemaDrift runs the same check against a real Postgres.
+func TestMigrationsMatchModels(t *testing.T) {
+	s, err := replayMigrations("../" + database.MigrationsDir)
+	if err != nil {
+		t.Fatalf("failed to replay migrations: %v", err)
+	}
+	for _, d := range schemaDrift(t, s) {
+		t.Error(d)
+	}
+}
+
+// TestSchemaDrift applies the SQL migrations to an empty Postgres schema and
+// checks every model against the result, which also proves the migrations
+// run. It needs a database it can create schemas in:
+//
+//	TEST_POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=postgres sslmode=disable" go test ./tests -run TestSchemaDrift
+func TestSchemaDrift(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection, so the search_path set below holds for the migrator
+	// and every query after it.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	name := fmt.Sprintf("schema_drift_%d", time.Now().UnixNano())
+	if err := db.Exec("CREATE SCHEMA " + name).Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DROP SCHEMA " + name + " CASCADE") })
+	if err := db.Exec("SET search_path TO " + name + ", public").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := database.NewMigrator(db, "../"+database.MigrationsDir)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrations failed: %v", err)
+	}
+	m.Close()
+
+	var columns []dbColumn
+	if err := db.Raw(`SELECT table_name, column_name, udt_name, character_maximum_length, is_nullable, column_default
+		FROM information_schema.columns WHERE table_schema = ?`, name).Scan(&columns).Error; err != nil {
+		t.Fatal(err)
+	}
+	var indexes []dbIndex
+	if err := db.Raw(`SELECT t.relname AS table_name, i.relname AS index_name, ix.indisunique AS is_unique,
+			ix.indpred IS NOT NULL AS is_partial,
+			array_to_string(ARRAY(
+				SELECT a.attname FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+				ORDER BY k.n), ',') AS columns
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace ns ON ns.oid = t.relnamespace
+		WHERE ns.nspname = ?`, name).Scan(&indexes).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s := &dbSchema{Columns: map[string]map[string]dbColumn{}, Indexes: map[string][]dbIndex{}}
+	for _, c := range columns {
+		if c.TableName == "schema_migrations" {
+			continue
+		}
+		if s.Columns[c.TableName] == nil {
+			s.Columns[c.TableName] = map[string]dbColumn{}
+		}
+		s.Columns[c.TableName][c.ColumnName] = c
+	}
+	for _, i := range indexes {
+		s.Indexes[i.TableName] = append(s.Indexes[i.TableName], i)
+	}
+	for _, d := range schemaDrift(t, s) {
+		t.Error(d)
+	}
+}
+
+// schemaDrift lists where the models in database.Models disagree with s:
+// tables and columns on one side only, types, nullability and indexes.
+func schemaDrift(t *testing.T, s *dbSchema) []string {
+	t.Helper()
+	dialect := postgres.Dialector{Config: &postgres.Config{}}
+	cache := &sync.Map{}
+
+	var drift []string
+	report := func(format string, args ...interface{}) {
+		drift = append(drift, fmt.Sprintf(format, args...))
+	}
+	modelled := map[string]bool{}
+	for _, model := range database.Models {
+		ms, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("failed to parse %T: %v", model, err)
+		}
+		modelled[ms.Table] = true
+		cols, ok := s.Columns[ms.Table]
+		if !ok {
+			report("%s: table %s is not created by any migration", ms.Name, ms.Table)
+			continue
+		}
+
+		fields := map[string]bool{}
+		for _, f := range ms.Fields {
+			if f.DBName == "" || f.IgnoreMigration {
+				continue
+			}
+			fields[f.DBName] = true
+			col, ok := cols[f.DBName]
+			if !ok {
+				report("%s.%s: column missing from table %s", ms.Name, f.Name, ms.Table)
+				continue
+			}
+			if problem := typeDrift(dialect.DataTypeOf(f), col); problem != "" {
+				report("%s.%s: %s", ms.Name, f.Name, problem)
+			}
+			nullable := col.IsNullable == "YES"
+			if (f.NotNull || f.PrimaryKey) && nullable {
+				report("%s.%s: model requires a value but %s.%s is nullable", ms.Name, f.Name, ms.Table, col.ColumnName)
+			}
+			if f.FieldType.Kind() == reflect.Ptr && !nullable && col.ColumnDefault == nil {
+				report("%s.%s: pointer field backs %s.%s, which is NOT NULL without a default", ms.Name, f.Name, ms.Table, col.ColumnName)
+			}
+			if f.Unique && !hasIndex(s.Indexes[ms.Table], []string{f.DBName}, true, false) {
+				report("%s.%s: unique in the model but %s.%s has no unique index", ms.Name, f.Name, ms.Table, col.ColumnName)
+			}
+		}
+		for column := range cols {
+			if !fields[column] {
+				report("%s: column %s.%s has no field", ms.Name, ms.Table, column)
+			}
+		}
+
+		for _, idx := range ms.ParseIndexes() {
+			names := make([]string, 0, len(idx.Fields))
+			for _, f := range idx.Fields {
+				names = append(names, f.DBName)
+			}
+			unique := idx.Class == "UNIQUE"
+			if !hasIndex(s.Indexes[ms.Table], names, unique, idx.Where != "") {
+				kind := "index"
+				if unique {
+					kind = "unique index"
+				}
+				report("%s: %s %s on %s(%s) is not created by any migration", ms.Name, kind, idx.Name, ms.Table, strings.Join(names, ", "))
+			}
+		}
+	}
+	for table := range s.Columns {
+		if !modelled[table] {
+			report("table %s has no model in database.Models", table)
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+var sizedType = regexp.MustCompile(`^([a-z ]+)\((\d+)\)$`)
+
+// typeDrift compares the column type the model asks for with the one the
+// migrations created. Integer widths and text versus varchar are treated as
+// the same; a varchar shorter than the size the model declares and a
+// timestamp without time zone are not.
+func typeDrift(modelType string, col dbColumn) string {
+	modelSize := 0
+	if m := sizedType.FindStringSubmatch(modelType); m != nil {
+		modelType = m[1]
+		modelSize, _ = strconv.Atoi(m[2])
+	}
+	want, got := typeFamily(modelType), typeFamily(col.UdtName)
+	if want != got {
+		return fmt.Sprintf("model type %s, column %s.%s is %s", modelType, col.TableName, col.ColumnName, col.UdtName)
+	}
+	if want == "text" && modelSize > 0 && col.CharacterMaximumLength != nil && modelSize > *col.CharacterMaximumLength {
+		return fmt.Sprintf("model allows %d characters, column %s.%s only %d", modelSize, col.TableName, col.ColumnName, *col.CharacterMaximumLength)
+	}
+	return ""
+}
+
+func typeFamily(name string) string {
+	switch name {
+	case "text", "varchar", "character varying", "bpchar":
+		return "text"
+	case "smallint", "integer", "bigint", "int2", "int4", "int8":
+		return "integer"
+	case "bool", "boolean":
+		return "boolean"
+	case "decimal", "numeric":
+		return "numeric"
+	}
+	return name
+}
+
+// hasIndex reports whether an index backs the columns. A unique index must
+// cover exactly them; any index leading with them serves a plain one.
+func hasIndex(indexes []dbIndex, columns []string, unique, partial bool) bool {
+	want := strings.Join(columns, ",")
+	for _, i := range indexes {
+		if i.IsUnique != unique || i.IsPartial != partial {
+			continue
+		}
+		if i.Columns == want || (!unique && strings.HasPrefix(i.Columns, want+",")) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,26 @@
 package tests
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"go-backend/config"
 	"go-backend/database"
 	"go-backend/logging"
+	"go-backend/mailer"
+	"go-backend/middleware"
 	"go-backend/models"
+	"go-backend/realtime"
+	"go-backend/repository"
 	"go-backend/routes"
+	"go-backend/storage"
+	"go-backend/tracking"
+	"go-backend/utils"
 
+	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -24,24 +36,72 @@ func SetupRouter(t *testing.T) *gin.Engine {
 		t.Fatalf("failed to open db: %v", err)
 	}
 	database.DB = db
-	if err := database.MigrateAll(); err != nil {
+	database.SetMigrateUp(func() error { return db.AutoMigrate(database.Models...) })
+	if err := database.MigrateUp(); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
 	// create default admin user so UserMiddlewareGin can find it
-	db.Create(&models.User{Email: "admin@example.com", IsAdmin: true, Password: "admin123"})
+	db.Create(&models.User{Email: "admin@example.com", Password: "admin123", Roles: []models.UserRole{{Role: models.RoleAdmin}}})
 
 	// minimal config
-	config.AppConfig = &config.Config{}
+	config.AppConfig = &config.Config{
+		JWTSecret:       "test-secret",
+		JWTIssuer:       "clicx",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+
+		TOTPIssuer:            "Clicx",
+		RequireStaffTwoFactor: true,
+
+		FrontendURL:      "http://frontend.test",
+		VerifyEmailTTL:   time.Hour,
+		PasswordResetTTL: time.Hour,
+		MailRateLimit:    3,
+		MailRateWindow:   time.Hour,
+
+		PlatformFeePercent: 20,
+		PayoutHoldPeriod:   7 * 24 * time.Hour,
+		MinPayoutAmount:    10,
+		PayoutProvider:     "manual",
+		PayoutCurrencies:   []string{"USDT_TRX"},
+
+		ViewDedupWindow:   30 * time.Minute,
+		ViewFlushInterval: time.Hour, // tests flush explicitly, see flushViews
+
+		MinTipAmount: 1,
+		MaxTipAmount: 1000,
+
+		ReportHideThreshold:  3,
+		DeletedRetention:     30 * 24 * time.Hour,
+		DataExportTTL:        7 * 24 * time.Hour,
+		AccountDeletionGrace: 30 * 24 * time.Hour,
+
+		NotificationBatchWindow: 24 * time.Hour,
+		EventHeartbeatInterval:  50 * time.Millisecond,
+	}
+	// emails are written to a per-test directory, see lastMail
+	mailDir = t.TempDir()
+	mailer.SetMailer(&mailer.FileMailer{Dir: mailDir, From: "test@clicx.local"})
+	storage.SetPrivate(&storage.LocalStore{Root: t.TempDir()})
+	tracker := tracking.New(&repository.GormViewRepository{DB: db})
+	tracking.SetTracker(tracker)
+	realtime.SetBroker(realtime.NewHub())
+	t.Cleanup(tracker.Close)
 
 	r := gin.Default()
+	r.Use(logging.RequestIDMiddleware())
 	logger, _ := logging.InitLogger()
-	// For tests, set the first user as the authenticated user if no token provided
+	middleware.SetTokenVerifier(fakeVerifier{})
+	// For tests, requests without credentials act as the default admin (with
+	// 2FA passed) unless they are explicitly marked anonymous with asAnonymous.
 	r.Use(func(c *gin.Context) {
-		if _, exists := c.Get("user"); !exists {
+		if c.GetHeader("Authorization") == "" && c.GetHeader(anonymousHeader) == "" {
 			var u models.User
-			if err := database.DB.First(&u, 1).Error; err == nil {
+			admins := database.DB.Model(&models.UserRole{}).Select("user_id").Where("role = ?", models.RoleAdmin)
+			if err := database.DB.Preload("Roles").Where("id IN (?)", admins).First(&u).Error; err == nil {
 				c.Set("user", &u)
+				utils.SetTwoFactorPassed(c)
 			}
 		}
 		c.Next()
@@ -49,3 +109,15 @@ func SetupRouter(t *testing.T) *gin.Engine {
 	routes.InitRoutes(r, logger)
 	return r
 }
+
+// fakeVerifier accepts tokens of the form "test-token:<email>" in place of
+// Firebase ID tokens.
+type fakeVerifier struct{}
+
+func (fakeVerifier) VerifyIDToken(_ context.Context, token string) (*auth.Token, error) {
+	email, ok := strings.CutPrefix(token, testTokenPrefix)
+	if !ok || email == "" {
+		return nil, errors.New("invalid test token")
+	}
+	return &auth.Token{Claims: map[string]interface{}{"email": email}}, nil
+}
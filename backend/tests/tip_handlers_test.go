@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/dto"
+	"go-backend/models"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestTips(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	creatorToken := testTokenPrefix + creator.Email
+	fan := createUser(t, r)
+	fanToken := testTokenPrefix + fan.Email
+	other, otherProfile := createUserWithModel(t, r)
+	verifyModel(t, otherProfile)
+	postID := createPost(t, r, creator, false)
+	otherPost := createPost(t, r, other, false)
+	stream := models.Stream{ID: uuid.New(), Title: "live", Status: "live", ModelID: &profile.ID}
+	database.DB.Create(&stream)
+	otherStream := models.Stream{ID: uuid.New(), Title: "elsewhere", Status: "live", ModelID: &otherProfile.ID}
+	database.DB.Create(&otherStream)
+	orphanStream := models.Stream{ID: uuid.New(), Title: "unowned", Status: "live"}
+	database.DB.Create(&orphanStream)
+	tipURL := "/models/" + profile.ID.String() + "/tips"
+
+	if w := call(r, http.MethodPost, tipURL, fanToken, gin.H{"amount": 5}); w.Code != http.StatusForbidden {
+		t.Fatalf("tip to unverified creator expected 403, got %d", w.Code)
+	}
+	verifyModel(t, profile)
+	database.DB.Model(&fan).Update("balance", 20)
+
+	cases := map[string]struct {
+		token string
+		body  gin.H
+		want  int
+	}{
+		"zero amount":      {fanToken, gin.H{"amount": 0}, http.StatusBadRequest},
+		"above maximum":    {fanToken, gin.H{"amount": 100000}, http.StatusBadRequest},
+		"self":             {creatorToken, gin.H{"amount": 5}, http.StatusBadRequest},
+		"two contexts":     {fanToken, gin.H{"amount": 5, "post_id": postID, "stream_id": stream.ID.String()}, http.StatusBadRequest},
+		"someone's post":   {fanToken, gin.H{"amount": 5, "post_id": otherPost}, http.StatusBadRequest},
+		"someone's stream": {fanToken, gin.H{"amount": 5, "stream_id": otherStream.ID.String()}, http.StatusBadRequest},
+		"unowned stream":   {fanToken, gin.H{"amount": 5, "stream_id": orphanStream.ID.String()}, http.StatusBadRequest},
+		"unknown stream":   {fanToken, gin.H{"amount": 5, "stream_id": uuid.NewString()}, http.StatusNotFound},
+		"unknown message":  {fanToken, gin.H{"amount": 5, "message_id": uuid.NewString()}, http.StatusNotFound},
+		"not enough money": {fanToken, gin.H{"amount": 50}, http.StatusPaymentRequired},
+	}
+	for name, tc := range cases {
+		if w := call(r, http.MethodPost, tipURL, tc.token, tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	w := call(r, http.MethodPost, tipURL, fanToken, gin.H{"amount": 5, "post_id": postID, "note": "love it"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("tip expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var tip dto.TipResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &tip)
+	if tip.Amount != 5 || tip.PostID == nil || tip.PostID.String() != postID || tip.Note != "love it" {
+		t.Fatalf("unexpected tip %+v", tip)
+	}
+
+	// viewers of a stream see the tips for it
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	if w := call(r, http.MethodGet, "/streams/"+uuid.NewString()+"/events", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("events of an unknown stream expected 404, got %d", w.Code)
+	}
+	viewers := openEvents(t, srv.URL+"/streams/"+stream.ID.String()+"/events", "")
+	if w := call(r, http.MethodPost, tipURL, fanToken, gin.H{"amount": 3, "stream_id": stream.ID.String(), "note": "hi from chat"}); w.Code != http.StatusCreated {
+		t.Fatalf("stream tip expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if e := viewers.next(t); e.Type != "tip" || !strings.Contains(e.Data, "hi from chat") || !strings.Contains(e.Data, fan.Nickname) {
+		t.Fatalf("viewers expected the stream tip, got %+v", e)
+	}
+
+	// tipping inside a conversation with the creator
+	w = call(r, http.MethodPost, "/conversations", fanToken, gin.H{"user_id": creator.ID.String()})
+	var conv dto.ConversationResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &conv)
+	w = call(r, http.MethodPost, "/conversations/"+conv.ID.String()+"/messages", creatorToken, gin.H{"text": "thanks!"})
+	var msg dto.MessageResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &msg)
+	if w := call(r, http.MethodPost, tipURL, fanToken, gin.H{"amount": 2, "message_id": msg.ID.String()}); w.Code != http.StatusCreated {
+		t.Fatalf("message tip expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	otherTipURL := "/models/" + otherProfile.ID.String() + "/tips"
+	if w := call(r, http.MethodPost, otherTipURL, fanToken, gin.H{"amount": 2, "message_id": msg.ID.String()}); w.Code != http.StatusNotFound {
+		t.Fatalf("tip with another creator's message expected 404, got %d", w.Code)
+	}
+
+	var balance models.User
+	database.DB.First(&balance, "id = ?", fan.ID)
+	if balance.Balance != 10 {
+		t.Fatalf("fan balance expected 10, got %d", balance.Balance)
+	}
+	var earned int
+	database.DB.Model(&models.Earning{}).Where("creator_id = ? AND source = ?", creator.ID, models.EarningTip).
+		Select("COALESCE(SUM(gross), 0)").Scan(&earned)
+	if earned != 10 {
+		t.Fatalf("creator tip earnings expected 10, got %d", earned)
+	}
+
+	// the creator is notified and sees the tips; the fan sees what they sent
+	if n := findNotification(inbox(t, r, creatorToken, ""), models.NotifyTip); n == nil {
+		t.Fatalf("creator expected a tip notification")
+	}
+	w = call(r, http.MethodGet, tipURL, creatorToken)
+	var received []dto.TipResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &received)
+	if w.Code != http.StatusOK || len(received) != 3 || received[0].From == nil || received[0].From.ID != fan.ID {
+		t.Fatalf("creator expected 3 tips from the fan, got %d %+v", w.Code, received)
+	}
+	if w := call(r, http.MethodGet, tipURL, fanToken); w.Code != http.StatusForbidden {
+		t.Fatalf("fan listing creator tips expected 403, got %d", w.Code)
+	}
+	w = call(r, http.MethodGet, "/tips", fanToken)
+	var sent []dto.TipResponseDTO
+	json.Unmarshal(w.Body.Bytes(), &sent)
+	if len(sent) != 3 {
+		t.Fatalf("fan expected 3 sent tips, got %+v", sent)
+	}
+
+	// tips count in analytics and in the post's revenue, not its purchases
+	w = call(r, http.MethodGet, "/models/"+profile.ID.String()+"/analytics", creatorToken)
+	var report services.ModelAnalytics
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if report.Totals.Tips != 3 || report.Totals.TipRevenue != 10 || report.Totals.Revenue != 10 || report.Totals.Purchases != 0 {
+		t.Fatalf("unexpected analytics totals %+v", report.Totals)
+	}
+	if len(report.TopPosts) != 1 || report.TopPosts[0].Revenue != 5 || report.TopPosts[0].Purchases != 0 {
+		t.Fatalf("unexpected top posts %+v", report.TopPosts)
+	}
+}
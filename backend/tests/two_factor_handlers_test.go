@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// totpCode returns the code for the current time step plus offset. Each
+// accepted code must be newer than the last one, so tests walk the offsets
+// -1, 0, +1.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatalf("totp: %v", err)
+	}
+	return code
+}
+
+// enableTwoFactor enrolls the session's user and returns the secret, the
+// recovery codes and the new session.
+func enableTwoFactor(t *testing.T, r *gin.Engine, access string) (string, []string, tokenPair) {
+	t.Helper()
+	w := postJSON(r, "/auth/2fa/setup", nil, access)
+	if w.Code != http.StatusOK {
+		t.Fatalf("setup expected 200, got %d", w.Code)
+	}
+	var setup struct {
+		Secret     string `json:"secret"`
+		OTPAuthURL string `json:"otpauth_url"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &setup)
+	if !strings.HasPrefix(setup.OTPAuthURL, "otpauth://totp/Clicx:") || !strings.Contains(setup.OTPAuthURL, "secret="+setup.Secret) {
+		t.Fatalf("unexpected provisioning uri %q", setup.OTPAuthURL)
+	}
+
+	w = postJSON(r, "/auth/2fa/enable", gin.H{"code": "000000"}, access)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("enable with wrong code expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/2fa/enable", gin.H{"code": totpCode(t, setup.Secret, -1)}, access)
+	if w.Code != http.StatusOK {
+		t.Fatalf("enable expected 200, got %d", w.Code)
+	}
+	var enabled struct {
+		RecoveryCodes []string  `json:"recovery_codes"`
+		Tokens        tokenPair `json:"tokens"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &enabled)
+	if len(enabled.RecoveryCodes) != 10 {
+		t.Fatalf("expected 10 recovery codes, got %d", len(enabled.RecoveryCodes))
+	}
+	return setup.Secret, enabled.RecoveryCodes, enabled.Tokens
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "totp@example.com")
+	secret, recovery, _ := enableTwoFactor(t, r, tokens.AccessToken)
+
+	// enabling 2FA ends the sessions that existed before
+	w := postJSON(r, "/auth/refresh", gin.H{"refresh_token": tokens.RefreshToken}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("old session expected 401, got %d", w.Code)
+	}
+
+	w = postJSON(r, "/auth/login", gin.H{"email": "totp@example.com", "password": "password123"}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login expected 200, got %d", w.Code)
+	}
+	var challenge struct {
+		TwoFactorRequired bool   `json:"two_factor_required"`
+		ChallengeToken    string `json:"challenge_token"`
+		Tokens            *tokenPair
+	}
+	json.Unmarshal(w.Body.Bytes(), &challenge)
+	if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" || challenge.Tokens != nil {
+		t.Fatalf("expected a 2FA challenge without tokens, got %s", w.Body.String())
+	}
+
+	// the challenge is not an access token
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/purchases", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge.ChallengeToken)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("challenge as access token expected 401, got %d", w.Code)
+	}
+
+	// a code that was already used is rejected
+	w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "code": totpCode(t, secret, -1)}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed code expected 401, got %d", w.Code)
+	}
+	w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "code": totpCode(t, secret, 0)}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify expected 200, got %d", w.Code)
+	}
+
+	// recovery codes work once
+	w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "recovery_code": strings.ToUpper(recovery[0])}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify with recovery code expected 200, got %d", w.Code)
+	}
+	var session struct {
+		Tokens tokenPair `json:"tokens"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &session)
+	w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "recovery_code": recovery[0]}, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused recovery code expected 401, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/auth/2fa", nil)
+	req.Header.Set("Authorization", "Bearer "+session.Tokens.AccessToken)
+	r.ServeHTTP(w, req)
+	var status struct {
+		Enabled           bool `json:"enabled"`
+		RecoveryCodesLeft int  `json:"recovery_codes_left"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &status)
+	if !status.Enabled || status.RecoveryCodesLeft != 9 {
+		t.Fatalf("unexpected status %s", w.Body.String())
+	}
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "lockout@example.com")
+	secret, _, _ := enableTwoFactor(t, r, tokens.AccessToken)
+
+	w := postJSON(r, "/auth/login", gin.H{"email": "lockout@example.com", "password": "password123"}, "")
+	var challenge struct {
+		ChallengeToken string `json:"challenge_token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &challenge)
+
+	for i := 0; i < 5; i++ {
+		w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "code": "000000"}, "")
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code expected 401, got %d", w.Code)
+		}
+	}
+	w = postJSON(r, "/auth/2fa/verify", gin.H{"challenge_token": challenge.ChallengeToken, "code": totpCode(t, secret, 0)}, "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked out verify expected 429, got %d", w.Code)
+	}
+}
+
+func TestAdminRequiresTwoFactor(t *testing.T) {
+	r := SetupRouter(t)
+	tokens := registerNative(t, r, "boss@example.com")
+	var boss models.User
+	database.DB.Where("email = ?", "boss@example.com").First(&boss)
+	database.DB.Create(&models.UserRole{UserID: boss.ID, Role: models.RoleAdmin})
+
+	adminRoute := func(access string) *httptest.ResponseRecorder {
+		return postJSON(r, "/admin/models/x/portfolio/batch", nil, access)
+	}
+	code := func(w *httptest.ResponseRecorder) string {
+		var body struct {
+			Code string `json:"code"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body.Code
+	}
+
+	w := adminRoute(tokens.AccessToken)
+	if w.Code != http.StatusForbidden || code(w) != "two_factor_required" {
+		t.Fatalf("admin without 2FA expected 403 two_factor_required, got %d %q", w.Code, code(w))
+	}
+
+	secret, _, session := enableTwoFactor(t, r, tokens.AccessToken)
+	if w := adminRoute(session.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("admin with 2FA expected to reach handler (400), got %d", w.Code)
+	}
+
+	// the 2FA state survives refresh
+	w = postJSON(r, "/auth/refresh", gin.H{"refresh_token": session.RefreshToken}, "")
+	var refreshed tokenPair
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+	if w := adminRoute(refreshed.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("refreshed admin session expected 400, got %d", w.Code)
+	}
+
+	// Firebase sessions are upgraded by verifying a code
+	firebase := func(method, path string, body gin.H) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(string(raw)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testTokenPrefix+"boss@example.com")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := firebase(http.MethodPost, "/admin/models/x/portfolio/batch", nil); w.Code != http.StatusForbidden {
+		t.Fatalf("firebase admin without 2FA expected 403, got %d", w.Code)
+	}
+	w = firebase(http.MethodPost, "/auth/2fa/verify", gin.H{"code": totpCode(t, secret, 0)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("firebase step-up expected 200, got %d", w.Code)
+	}
+	var upgraded struct {
+		Tokens tokenPair `json:"tokens"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &upgraded)
+	if w := adminRoute(upgraded.Tokens.AccessToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("upgraded admin session expected 400, got %d", w.Code)
+	}
+}
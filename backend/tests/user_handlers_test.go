@@ -6,7 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"go-backend/database"
+	"go-backend/models"
+
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
@@ -40,3 +45,88 @@ func TestUserHandlers(t *testing.T) {
 		t.Fatalf("expected valid UUID, got %v", created.ID)
 	}
 }
+
+func TestGetUserByIDHidesPrivateFields(t *testing.T) {
+	r := SetupRouter(t)
+	user := createUser(t, r)
+	other := createUser(t, r)
+	path := "/users/" + user.ID.String()
+
+	fields := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body["nickname"] != user.Nickname {
+			t.Fatalf("expected the user's nickname, got %v", body)
+		}
+		return body
+	}
+	private := []string{"email", "balance", "roles", "isAdmin", "referral_code", "referred_by", "email_verified", "status"}
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	asAnonymous(req)
+	anonymous := httptest.NewRecorder()
+	r.ServeHTTP(anonymous, req)
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"anonymous": anonymous,
+		"foreign":   call(r, http.MethodGet, path, testTokenPrefix+other.Email),
+	} {
+		body := fields(w)
+		for _, f := range private {
+			if _, ok := body[f]; ok {
+				t.Errorf("%s caller got %s", name, f)
+			}
+		}
+	}
+	for name, token := range map[string]string{"owner": testTokenPrefix + user.Email, "admin": ""} {
+		if body := fields(call(r, http.MethodGet, path, token)); body["email"] != user.Email {
+			t.Errorf("%s expected the full account, got %v", name, body)
+		}
+	}
+}
+
+func TestUpdateUserEmail(t *testing.T) {
+	r := SetupRouter(t)
+	user, other := createUser(t, r), createUser(t, r)
+	path := "/users/" + user.ID.String()
+	now := time.Now()
+	database.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified_at", &now)
+
+	// another account's email or nickname is a conflict, not a server error
+	for name, body := range map[string]gin.H{
+		"email":    {"email": other.Email},
+		"nickname": {"nickname": other.Nickname},
+	} {
+		w := call(r, http.MethodPut, path, "", body)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusConflict || resp["code"] != name+"_taken" {
+			t.Errorf("taken %s expected 409 %s_taken, got %d %s", name, name, w.Code, w.Body.String())
+		}
+	}
+	var stored models.User
+	database.DB.First(&stored, "id = ?", user.ID)
+	if stored.Email != user.Email || stored.EmailVerifiedAt == nil {
+		t.Fatalf("refused update expected to change nothing, got %+v", stored)
+	}
+
+	// keeping the own email keeps it verified, a new one has to be verified again
+	if w := call(r, http.MethodPut, path, "", gin.H{"email": user.Email, "nickname": "renamed" + user.Nickname}); w.Code != http.StatusOK {
+		t.Fatalf("update expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	database.DB.First(&stored, "id = ?", user.ID)
+	if stored.EmailVerifiedAt == nil {
+		t.Fatal("unchanged email expected to stay verified")
+	}
+	if w := call(r, http.MethodPut, path, "", gin.H{"email": "new" + user.Email}); w.Code != http.StatusOK {
+		t.Fatalf("email change expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var changed models.User
+	database.DB.First(&changed, "id = ?", user.ID)
+	if changed.Email != "new"+user.Email || changed.EmailVerifiedAt != nil {
+		t.Fatalf("changed email expected unverified, got %s %v", changed.Email, changed.EmailVerifiedAt)
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-backend/database"
+	"go-backend/models"
+	"go-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// anonymousPost sends payload as a signed-out client with the given session.
+func anonymousPost(r *gin.Engine, path, session string, payload interface{}) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Session-ID", session)
+	asAnonymous(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func counted(t *testing.T, w *httptest.ResponseRecorder) bool {
+	t.Helper()
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("view expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Counted bool `json:"counted"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Counted
+}
+
+func TestViewTracking(t *testing.T) {
+	r := SetupRouter(t)
+	creator, profile := createUserWithModel(t, r)
+	verifyModel(t, profile)
+	postID := createPost(t, r, creator, false)
+	video := models.Media{PostID: uuid.MustParse(postID), Type: "video", Duration: 100}
+	database.DB.Create(&video)
+	viewURL := "/posts/" + postID + "/view"
+
+	// the same session counts once within the window, other viewers count too
+	if !counted(t, anonymousPost(r, viewURL, "session-a", gin.H{})) {
+		t.Fatal("first view should count")
+	}
+	if counted(t, anonymousPost(r, viewURL, "session-a", gin.H{})) {
+		t.Fatal("repeated view should not count")
+	}
+	if !counted(t, anonymousPost(r, viewURL, "session-b", gin.H{})) {
+		t.Fatal("view of another session should count")
+	}
+	viewer := createUser(t, r)
+	viewerToken := testTokenPrefix + viewer.Email
+	if !counted(t, call(r, http.MethodPost, viewURL, viewerToken)) {
+		t.Fatal("signed-in view should count")
+	}
+	if !counted(t, call(r, http.MethodPost, viewURL, viewerToken, gin.H{"media_id": video.ID.String()})) {
+		t.Fatal("media view should count separately")
+	}
+	if w := call(r, http.MethodPost, viewURL, viewerToken, gin.H{"media_id": uuid.NewString()}); w.Code != http.StatusNotFound {
+		t.Fatalf("view of foreign media expected 404, got %d", w.Code)
+	}
+	if w := call(r, http.MethodPost, "/posts/"+uuid.NewString()+"/view", viewerToken); w.Code != http.StatusNotFound {
+		t.Fatalf("view of unknown post expected 404, got %d", w.Code)
+	}
+
+	// progress keeps the furthest position; 90% completes the video
+	progressURL := "/posts/" + postID + "/media/" + video.ID.String() + "/progress"
+	for _, position := range []int{30, 95, 10} {
+		if w := call(r, http.MethodPost, progressURL, viewerToken, gin.H{"position": position}); w.Code != http.StatusAccepted {
+			t.Fatalf("progress expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	anonymousPost(r, progressURL, "session-a", gin.H{"position": 20})
+	if w := call(r, http.MethodPost, progressURL, viewerToken, gin.H{"position": -1}); w.Code != http.StatusBadRequest {
+		t.Fatalf("negative position expected 400, got %d", w.Code)
+	}
+
+	flushViews()
+	var post models.Post
+	database.DB.First(&post, "id = ?", postID)
+	if post.ViewsCount != 3 {
+		t.Fatalf("expected 3 post views, got %d", post.ViewsCount)
+	}
+	var watch models.VideoWatch
+	database.DB.First(&watch, "media_id = ? AND user_id = ?", video.ID, viewer.ID)
+	if watch.Position != 95 || !watch.Completed {
+		t.Fatalf("unexpected watch %+v", watch)
+	}
+
+	w := call(r, http.MethodGet, "/models/"+profile.ID.String()+"/analytics", testTokenPrefix+creator.Email)
+	var report services.ModelAnalytics
+	json.Unmarshal(w.Body.Bytes(), &report)
+	totals := report.Totals
+	if totals.Views != 3 || totals.VideoStarts != 2 || totals.VideoCompletes != 1 || totals.CompletionRate != 0.5 {
+		t.Fatalf("unexpected analytics totals %+v", totals)
+	}
+}
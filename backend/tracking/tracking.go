@@ -0,0 +1,268 @@
+// Package tracking ingests post views and video watch progress. Events are
+// buffered in memory and written in batches by a background worker, like the
+// database log core, so recording a view never waits on the database.
+package tracking
+
+import (
+	"sync"
+	"time"
+
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/logging"
+	"go-backend/models"
+	"go-backend/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CompletedRatio is the share of a video a viewer must reach for the watch
+// to count as completed; credits and outros are rarely watched.
+const CompletedRatio = 0.9
+
+const queueSize = 1000
+
+// View is a view of a post, or of one of its media items.
+type View struct {
+	PostID  uuid.UUID
+	MediaID *uuid.UUID
+	UserID  *uuid.UUID
+	Viewer  string
+}
+
+// Progress is a viewer's position in a video of a post.
+type Progress struct {
+	PostID   uuid.UUID
+	MediaID  uuid.UUID
+	UserID   *uuid.UUID
+	Viewer   string
+	Position int // seconds
+	Duration int // seconds
+}
+
+// Tracker deduplicates views per viewer within Window and writes views and
+// progress in batches of BatchSize, at least every FlushInterval. Events are
+// dropped when the queue is full. Deduplication is per process, so with
+// several instances a view may be counted once per instance.
+type Tracker struct {
+	Repo          repository.ViewRepository
+	Window        time.Duration
+	BatchSize     int
+	FlushInterval time.Duration
+	Now           func() time.Time
+
+	events  chan interface{}
+	flushes chan chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// New starts a tracker configured by VIEW_DEDUP_WINDOW, VIEW_BATCH_SIZE and
+// VIEW_FLUSH_INTERVAL. Close stops it.
+func New(repo repository.ViewRepository) *Tracker {
+	cfg := config.AppConfig
+	t := &Tracker{
+		Repo:          repo,
+		Window:        cfg.ViewDedupWindow,
+		BatchSize:     cfg.ViewBatchSize,
+		FlushInterval: cfg.ViewFlushInterval,
+		Now:           time.Now,
+		events:        make(chan interface{}, queueSize),
+		flushes:       make(chan chan struct{}),
+		done:          make(chan struct{}),
+		seen:          map[string]time.Time{},
+	}
+	if t.BatchSize <= 0 {
+		t.BatchSize = 100
+	}
+	if t.FlushInterval <= 0 {
+		t.FlushInterval = 5 * time.Second
+	}
+	go t.worker()
+	return t
+}
+
+var (
+	mu      sync.RWMutex
+	tracker *Tracker
+)
+
+// SetTracker overrides the tracker returned by Default.
+func SetTracker(t *Tracker) {
+	mu.Lock()
+	defer mu.Unlock()
+	tracker = t
+}
+
+// Default returns the process-wide tracker, starting it on first use.
+func Default() *Tracker {
+	mu.RLock()
+	t := tracker
+	mu.RUnlock()
+	if t != nil {
+		return t
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if tracker == nil {
+		tracker = New(&repository.GormViewRepository{DB: database.GetDB()})
+	}
+	return tracker
+}
+
+// RecordView queues the view unless the viewer already viewed the same post
+// or media item within the window. It reports whether the view counts.
+func (t *Tracker) RecordView(v View) bool {
+	key := v.Viewer + "|" + v.PostID.String()
+	if v.MediaID != nil {
+		key += "|" + v.MediaID.String()
+	}
+	now := t.Now()
+	t.mu.Lock()
+	if last, ok := t.seen[key]; ok && now.Sub(last) < t.Window {
+		t.mu.Unlock()
+		return false
+	}
+	t.seen[key] = now
+	t.mu.Unlock()
+
+	return t.enqueue(models.PostView{
+		PostID:    v.PostID,
+		MediaID:   v.MediaID,
+		UserID:    v.UserID,
+		Viewer:    v.Viewer,
+		CreatedAt: now,
+	})
+}
+
+// RecordProgress queues a watch-progress event. Positions past the duration
+// are clamped to it.
+func (t *Tracker) RecordProgress(p Progress) bool {
+	if p.Duration > 0 && p.Position > p.Duration {
+		p.Position = p.Duration
+	}
+	now := t.Now()
+	return t.enqueue(models.VideoWatch{
+		MediaID:   p.MediaID,
+		Viewer:    p.Viewer,
+		PostID:    p.PostID,
+		UserID:    p.UserID,
+		Position:  p.Position,
+		Duration:  p.Duration,
+		Completed: p.Duration > 0 && float64(p.Position) >= CompletedRatio*float64(p.Duration),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (t *Tracker) enqueue(event interface{}) bool {
+	select {
+	case t.events <- event:
+		return true
+	default:
+		logging.GetLogger().Warn("View tracking queue full, dropping event")
+		return false
+	}
+}
+
+// Flush writes everything queued so far and waits for it.
+func (t *Tracker) Flush() {
+	ack := make(chan struct{})
+	select {
+	case t.flushes <- ack:
+		<-ack
+	case <-t.done:
+	}
+}
+
+// Close flushes the queue and stops the worker.
+func (t *Tracker) Close() {
+	t.Flush()
+	t.once.Do(func() { close(t.done) })
+}
+
+func (t *Tracker) worker() {
+	var views []models.PostView
+	watches := map[string]models.VideoWatch{}
+	ticker := time.NewTicker(t.FlushInterval)
+	defer ticker.Stop()
+
+	write := func() {
+		// drain what is already queued so Flush covers every earlier event
+		for {
+			select {
+			case e := <-t.events:
+				views = t.add(e, views, watches)
+				continue
+			default:
+			}
+			break
+		}
+		if err := t.Repo.SaveViews(views); err != nil {
+			logging.GetLogger().Error("Save post views failed", zap.Int("count", len(views)), zap.Error(err))
+		}
+		batch := make([]models.VideoWatch, 0, len(watches))
+		for _, w := range watches {
+			batch = append(batch, w)
+		}
+		if err := t.Repo.SaveWatches(batch); err != nil {
+			logging.GetLogger().Error("Save video watches failed", zap.Int("count", len(batch)), zap.Error(err))
+		}
+		views = nil
+		watches = map[string]models.VideoWatch{}
+	}
+
+	for {
+		select {
+		case e := <-t.events:
+			views = t.add(e, views, watches)
+			if len(views)+len(watches) >= t.BatchSize {
+				write()
+			}
+		case <-ticker.C:
+			write()
+			t.prune()
+		case ack := <-t.flushes:
+			write()
+			close(ack)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// add appends a view, or merges progress into the batch so it holds one
+// watch per viewer and video, as the upsert requires.
+func (t *Tracker) add(event interface{}, views []models.PostView, watches map[string]models.VideoWatch) []models.PostView {
+	switch e := event.(type) {
+	case models.PostView:
+		return append(views, e)
+	case models.VideoWatch:
+		key := e.MediaID.String() + "|" + e.Viewer
+		if prev, ok := watches[key]; ok {
+			if prev.Position > e.Position {
+				e.Position = prev.Position
+			}
+			e.Completed = e.Completed || prev.Completed
+			e.CreatedAt = prev.CreatedAt
+		}
+		watches[key] = e
+	}
+	return views
+}
+
+// prune forgets viewers whose window has passed.
+func (t *Tracker) prune() {
+	now := t.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, last := range t.seen {
+		if now.Sub(last) >= t.Window {
+			delete(t.seen, key)
+		}
+	}
+}
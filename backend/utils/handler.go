@@ -1,14 +1,41 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"go-backend/config"
 	"go-backend/models"
+	"go-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Machine-readable error codes for authentication and authorization failures.
+const (
+	CodeAuthRequired = "auth_required"
+	CodeInvalidToken = "invalid_token"
+	CodeForbidden    = "forbidden"
+	// CodeTwoFactorRequired asks the client to verify a second factor at
+	// /auth/2fa/verify (enrolling first if needed) and retry.
+	CodeTwoFactorRequired = "two_factor_required"
+	// CodeCreatorNotVerified asks the creator to get verified through
+	// /creators/applications first.
+	CodeCreatorNotVerified = "creator_not_verified"
+	// CodeAccountSuspended: staff locked the account for a while; the
+	// response carries suspended_until.
+	CodeAccountSuspended = "account_suspended"
+	// CodeAccountBanned: staff locked the account for good.
+	CodeAccountBanned = "account_banned"
+	// CodeAccountDeactivated: the account was closed and must be
+	// reactivated by staff.
+	CodeAccountDeactivated = "account_deactivated"
+)
+
+// twoFactorKey marks a request whose session passed two-factor verification.
+const twoFactorKey = "two_factor"
+
 // BindAndValidate binds JSON and validates using ValidateStruct. Returns false and aborts if error.
 func BindAndValidate(c *gin.Context, obj interface{}) bool {
 	if err := c.ShouldBindJSON(obj); err != nil {
@@ -28,6 +55,11 @@ func AbortWithError(c *gin.Context, status int, message string, err error) {
 	c.AbortWithStatusJSON(status, gin.H{"error": message, "details": err.Error()})
 }
 
+// AbortWithCode aborts the request with a status, a machine-readable code and a message.
+func AbortWithCode(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": message, "code": code})
+}
+
 // GetCurrentUser extracts the current user from context.
 func GetCurrentUser(c *gin.Context) (*models.User, bool) {
 	val, exists := c.Get("user")
@@ -38,6 +70,44 @@ func GetCurrentUser(c *gin.Context) (*models.User, bool) {
 	return user, ok
 }
 
+// SetTwoFactorPassed marks the request's session as having passed 2FA.
+func SetTwoFactorPassed(c *gin.Context) {
+	c.Set(twoFactorKey, true)
+}
+
+// TwoFactorPassed reports whether the request's session passed 2FA.
+func TwoFactorPassed(c *gin.Context) bool {
+	return c.GetBool(twoFactorKey)
+}
+
+// StaffTwoFactor reports whether the session may use staff permissions: it
+// passed 2FA, or REQUIRE_STAFF_2FA is off.
+func StaffTwoFactor(c *gin.Context) bool {
+	return !config.AppConfig.RequireStaffTwoFactor || TwoFactorPassed(c)
+}
+
+// Authorize translates a policy decision into a 401 or 403 response.
+// Returns true when the request may proceed.
+func Authorize(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, policy.ErrUnauthenticated) {
+		AbortWithCode(c, http.StatusUnauthorized, CodeAuthRequired, "Authentication required")
+		return false
+	}
+	if errors.Is(err, policy.ErrTwoFactorRequired) {
+		AbortWithCode(c, http.StatusForbidden, CodeTwoFactorRequired, err.Error())
+		return false
+	}
+	if errors.Is(err, policy.ErrCreatorNotVerified) {
+		AbortWithCode(c, http.StatusForbidden, CodeCreatorNotVerified, err.Error())
+		return false
+	}
+	AbortWithCode(c, http.StatusForbidden, CodeForbidden, err.Error())
+	return false
+}
+
 // GetPagination extracts limit and offset from query params.
 func GetPagination(c *gin.Context) (limit, offset int) {
 	limitStr := c.DefaultQuery("limit", "20")